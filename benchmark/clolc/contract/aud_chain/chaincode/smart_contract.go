@@ -1,7 +1,6 @@
 package chaincode
 
 import (
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 
@@ -18,8 +17,13 @@ type SmartContract struct {
 	contractapi.Contract
 }
 
-// InitLedger adds a base set of digests to the Aud Chain.
-func (s *SmartContract) InitLedger(tci contractapi.TransactionContextInterface) error {
+// InitLedger pins the registrar enrolling the signers and adds a base set of digests to the Aud Chain,
+// it is invoked once by the deployer and does nothing if the same registrar is already pinned.
+func (s *SmartContract) InitLedger(tci contractapi.TransactionContextInterface, registrar string) error {
+	pinned, err := pinRegistrar(tci, registrar)
+	if err != nil || !pinned {
+		return err
+	}
 	transactions := []Transaction{
 		{
 			ID:        "0",
@@ -41,10 +45,18 @@ func (s *SmartContract) InitLedger(tci contractapi.TransactionContextInterface)
 	return nil
 }
 
-// CreateTX issues a new transaction to the world state with given details.
-func (s *SmartContract) CreateTX(ctx contractapi.TransactionContextInterface,
-	id, cipherRes, cipherB, cipherC, cipherD string) (string, error) {
-	tx := NewTransaction(id, cipherRes, cipherB, cipherC, cipherD)
+// CreateTX issues a new transaction to the world state from its hex encoded canonical encoding.
+func (s *SmartContract) CreateTX(ctx contractapi.TransactionContextInterface, encoding string) (string, error) {
+	tx, err := decodeTransaction(encoding)
+	if err != nil {
+		return "", err
+	}
+	if err := tx.VerifySignature(); err != nil {
+		return "", err
+	}
+	if err := s.checkSigner(ctx, tx.Signer); err != nil {
+		return "", err
+	}
 	key, val, err := tx.KeyVal()
 	if err != nil {
 		return "", err
@@ -60,18 +72,31 @@ func (s *SmartContract) CreateTX(ctx contractapi.TransactionContextInterface,
 	return key, ctx.GetStub().PutState(key, val)
 }
 
-func (s *SmartContract) CreateBatchTXs(ctx contractapi.TransactionContextInterface, txListJSONString string) ([]string, error) {
-	digestListJSONBytes, err := hex.DecodeString(txListJSONString)
-	if err != nil {
+// CreateBatchTXs issues the transactions from the JSON list of their hex encoded canonical encodings.
+func (s *SmartContract) CreateBatchTXs(ctx contractapi.TransactionContextInterface,
+	encodingListJSON string) ([]string, error) {
+	var encodingList []string
+	if err := json.Unmarshal([]byte(encodingListJSON), &encodingList); err != nil {
 		return nil, err
 	}
-	var txList []*Transaction
-	err = json.Unmarshal(digestListJSONBytes, &txList)
-	if err != nil {
-		return nil, err
+	txList := make([]*Transaction, len(encodingList))
+	for i, encoding := range encodingList {
+		tx, err := decodeTransaction(encoding)
+		if err != nil {
+			return nil, err
+		}
+		txList[i] = tx
 	}
 	keys := make([]string, len(txList))
+	// the writes of the batch are not visible to GetState before the commit
+	batchKeys := make(map[string]bool, len(txList))
 	for i, tx := range txList {
+		if err := tx.VerifySignature(); err != nil {
+			return nil, err
+		}
+		if err := s.checkSigner(ctx, tx.Signer); err != nil {
+			return nil, err
+		}
 		key, val, err := tx.KeyVal()
 		if err != nil {
 			return nil, err
@@ -81,9 +106,10 @@ func (s *SmartContract) CreateBatchTXs(ctx contractapi.TransactionContextInterfa
 		if err != nil {
 			return nil, err
 		}
-		if exists {
+		if exists || batchKeys[key] {
 			return nil, fmt.Errorf("the transaction %s already exists", key)
 		}
+		batchKeys[key] = true
 		if err := ctx.GetStub().PutState(key, val); err != nil {
 			return nil, fmt.Errorf("failed to put to world state: %v", err)
 		}
@@ -103,7 +129,7 @@ func (s *SmartContract) ReadTX(ctx contractapi.TransactionContextInterface,
 		return nil, fmt.Errorf("the transaction %s does not exist", key)
 	}
 	var txObj Transaction
-	err = json.Unmarshal(tx, &txObj)
+	err = txObj.UnmarshalBinary(tx)
 	if err != nil {
 		return nil, err
 	}
@@ -151,7 +177,7 @@ func (s *SmartContract) ReadAllTXs(ctx contractapi.TransactionContextInterface)
 			return
 		}
 		var tx Transaction
-		err = json.Unmarshal(response.Value, &tx)
+		err = tx.UnmarshalBinary(response.Value)
 		if err != nil {
 			return
 		}
@@ -189,7 +215,7 @@ func (s *SmartContract) ReadAllTXsByPage(ctx contractapi.TransactionContextInter
 			return
 		}
 		var tx Transaction
-		err = json.Unmarshal(qr.Value, &tx)
+		err = tx.UnmarshalBinary(qr.Value)
 		if err != nil {
 			return
 		}
@@ -1,6 +1,7 @@
 package chaincode
 
-import "encoding/json"
+// typeTag mirrors codec.TypeCLOLCAudOnChain, the type tag of the canonical encoding of the transaction.
+const typeTag byte = 0x06
 
 type Transaction struct {
 	ID        string
@@ -8,6 +9,8 @@ type Transaction struct {
 	CipherB   string `json:"cipher_b"`
 	CipherC   string `json:"cipher_c"`
 	CipherD   string `json:"cipher_d"`
+	Signer    string `json:"signer,omitempty"`
+	Signature string `json:"signature,omitempty"`
 }
 
 func NewTransaction(id, cipherRes, cipherB, cipherC, cipherD string) *Transaction {
@@ -20,10 +23,34 @@ func NewTransaction(id, cipherRes, cipherB, cipherC, cipherD string) *Transactio
 	}
 }
 
-func (t *Transaction) KeyVal() (string, []byte, error) {
-	txJSON, err := json.Marshal(t)
+// encode returns the canonical encoding of the transaction with the given signature.
+func (t *Transaction) encode(signature string) []byte {
+	return encodeFields(typeTag, t.ID, t.CipherRes, t.CipherB, t.CipherC, t.CipherD, t.Signer, signature)
+}
+
+// MarshalBinary mirrors the canonical encoding of the on-chain transaction in internal/codec.
+func (t *Transaction) MarshalBinary() ([]byte, error) {
+	return t.encode(t.Signature), nil
+}
+
+func (t *Transaction) UnmarshalBinary(data []byte) error {
+	fields, err := decodeFields(data, typeTag, 7)
 	if err != nil {
-		return "", nil, err
+		return err
 	}
-	return t.ID, txJSON, nil
+	*t = Transaction{
+		ID:        fields[0],
+		CipherRes: fields[1],
+		CipherB:   fields[2],
+		CipherC:   fields[3],
+		CipherD:   fields[4],
+		Signer:    fields[5],
+		Signature: fields[6],
+	}
+	return nil
+}
+
+// KeyVal returns the ID of the transaction and its canonical encoding.
+func (t *Transaction) KeyVal() (string, []byte, error) {
+	return t.ID, t.encode(t.Signature), nil
 }
@@ -3,13 +3,24 @@ package chaincode
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 )
 
+// typeTag mirrors codec.TypeCLOLCLocalOnChain, the type tag of the canonical encoding of the transaction.
+const typeTag byte = 0x04
+
+// txIDDomain mirrors the domain of hashing.TagTXID, the ID of a transaction is the tagged hash
+// of its canonical encoding without the signature.
+const txIDDomain = "auti-v1/tx-id"
+
 type Transaction struct {
-	CounterParty string `json:"counter_party"`
-	Commitment   string `json:"commitment"`
-	Timestamp    string `json:"timestamp"`
+	CounterParty      string `json:"counter_party"`
+	Commitment        string `json:"commitment"`
+	Asset             string `json:"asset,omitempty"`
+	BlindingGenerator string `json:"blinding_generator,omitempty"`
+	Timestamp         string `json:"timestamp"`
+	RangeProof        string `json:"range_proof,omitempty"`
+	Signer            string `json:"signer,omitempty"`
+	Signature         string `json:"signature,omitempty"`
 }
 
 func NewTransaction(counterParty, commitment, timestamp string) *Transaction {
@@ -20,12 +31,41 @@ func NewTransaction(counterParty, commitment, timestamp string) *Transaction {
 	}
 }
 
-func (t *Transaction) KeyVal() (string, []byte, error) {
-	txJSON, err := json.Marshal(t)
+// encode returns the canonical encoding of the transaction with the given signature.
+func (t *Transaction) encode(signature string) []byte {
+	return encodeFields(
+		typeTag, t.CounterParty, t.Commitment, t.Asset, t.BlindingGenerator, t.Timestamp, t.RangeProof, t.Signer, signature,
+	)
+}
+
+// MarshalBinary mirrors the canonical encoding of the on-chain transaction in internal/codec.
+func (t *Transaction) MarshalBinary() ([]byte, error) {
+	return t.encode(t.Signature), nil
+}
+
+func (t *Transaction) UnmarshalBinary(data []byte) error {
+	fields, err := decodeFields(data, typeTag, 8)
 	if err != nil {
-		return "", nil, err
+		return err
+	}
+	*t = Transaction{
+		CounterParty:      fields[0],
+		Commitment:        fields[1],
+		Asset:             fields[2],
+		BlindingGenerator: fields[3],
+		Timestamp:         fields[4],
+		RangeProof:        fields[5],
+		Signer:            fields[6],
+		Signature:         fields[7],
 	}
+	return nil
+}
+
+// KeyVal returns the content ID of the transaction and its canonical encoding.
+func (t *Transaction) KeyVal() (string, []byte, error) {
 	sha256Func := sha256.New()
-	sha256Func.Write(txJSON)
-	return hex.EncodeToString(sha256Func.Sum(nil)), txJSON, nil
+	sha256Func.Write([]byte{byte(len(txIDDomain))})
+	sha256Func.Write([]byte(txIDDomain))
+	sha256Func.Write(t.encode(""))
+	return hex.EncodeToString(sha256Func.Sum(nil)), t.encode(t.Signature), nil
 }
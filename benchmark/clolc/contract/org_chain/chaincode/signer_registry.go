@@ -0,0 +1,132 @@
+package chaincode
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+const (
+	// registrarObjectType and signerObjectType are the object types of the composite keys of the registrar
+	// and the signers, the range queries of the transactions do not return the composite keys.
+	registrarObjectType = "registrar"
+	signerObjectType    = "signer"
+	// enrollmentTag and revocationTag must match the tags of ledger.EnrollmentMessage and ledger.RevocationMessage.
+	enrollmentTag = "auti-signer-enrollment"
+	revocationTag = "auti-signer-revocation"
+)
+
+var (
+	signerEnrolled = []byte("enrolled")
+	signerRevoked  = []byte("revoked")
+)
+
+// pinRegistrar stores the Ed25519 public key of the registrar, the only key allowed to enroll
+// and revoke the signers. It returns false if the same registrar is already pinned,
+// and fails if another one is.
+func pinRegistrar(ctx contractapi.TransactionContextInterface, registrar string) (bool, error) {
+	if _, err := decodePublicKey(registrar); err != nil {
+		return false, err
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(registrarObjectType, nil)
+	if err != nil {
+		return false, err
+	}
+	pinned, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if pinned != nil {
+		if string(pinned) != registrar {
+			return false, errors.New("another registrar is already pinned")
+		}
+		return false, nil
+	}
+	return true, ctx.GetStub().PutState(key, []byte(registrar))
+}
+
+// verifyRegistrarSignature checks the signature of the registrar on the message.
+func verifyRegistrarSignature(ctx contractapi.TransactionContextInterface, signature string, msg []byte) error {
+	key, err := ctx.GetStub().CreateCompositeKey(registrarObjectType, nil)
+	if err != nil {
+		return err
+	}
+	registrar, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if registrar == nil {
+		return errors.New("no registrar is pinned, the ledger is not initialized")
+	}
+	ok, err := verifyEd25519(string(registrar), signature, msg)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("invalid registrar signature")
+	}
+	return nil
+}
+
+// EnrollSigner enrolls the hex encoded public key of an entity with the signature of the registrar,
+// the transactions are accepted only from the enrolled signers. A revoked signer cannot be enrolled again.
+func (s *SmartContract) EnrollSigner(ctx contractapi.TransactionContextInterface,
+	signer, registrarSignature string) error {
+	if _, err := decodePublicKey(signer); err != nil {
+		return err
+	}
+	if err := verifyRegistrarSignature(ctx, registrarSignature, taggedMessage(enrollmentTag, signer)); err != nil {
+		return err
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(signerObjectType, []string{signer})
+	if err != nil {
+		return err
+	}
+	status, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return fmt.Errorf("failed to read from world state: %v", err)
+	}
+	if string(status) == string(signerRevoked) {
+		return fmt.Errorf("the signer %s is revoked", signer)
+	}
+	return ctx.GetStub().PutState(key, signerEnrolled)
+}
+
+// RevokeSigner revokes an enrolled signer with the signature of the registrar.
+func (s *SmartContract) RevokeSigner(ctx contractapi.TransactionContextInterface,
+	signer, registrarSignature string) error {
+	if err := verifyRegistrarSignature(ctx, registrarSignature, taggedMessage(revocationTag, signer)); err != nil {
+		return err
+	}
+	key, err := ctx.GetStub().CreateCompositeKey(signerObjectType, []string{signer})
+	if err != nil {
+		return err
+	}
+	return ctx.GetStub().PutState(key, signerRevoked)
+}
+
+// SignerEnrolled returns true if the signer is enrolled and not revoked.
+func (s *SmartContract) SignerEnrolled(ctx contractapi.TransactionContextInterface, signer string) (bool, error) {
+	key, err := ctx.GetStub().CreateCompositeKey(signerObjectType, []string{signer})
+	if err != nil {
+		return false, err
+	}
+	status, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return false, fmt.Errorf("failed to read from world state: %v", err)
+	}
+	return string(status) == string(signerEnrolled), nil
+}
+
+// checkSigner rejects the transactions whose signer is not enrolled.
+func (s *SmartContract) checkSigner(ctx contractapi.TransactionContextInterface, signer string) error {
+	enrolled, err := s.SignerEnrolled(ctx, signer)
+	if err != nil {
+		return err
+	}
+	if !enrolled {
+		return fmt.Errorf("the signer %s is not enrolled", signer)
+	}
+	return nil
+}
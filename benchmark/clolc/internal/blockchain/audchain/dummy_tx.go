@@ -16,6 +16,17 @@ var (
 	kyberSuite = edwards25519.NewBlakeSHA256Ed25519()
 )
 
+// dummySigningKey signs the dummy on-chain transactions, the chaincode rejects unsigned transactions
+var dummySigningKey, _ = crypto.SigningKeyGen(crypto.RandomStream())
+
+var (
+	// dummySigner is the hex encoded public key of dummySigningKey, the signer enrolled on the chaincode
+	dummySigner, _ = crypto.SignerHex(dummySigningKey)
+	// registrarKey enrolls dummySigner, it is derived from a fixed seed
+	// so the benchmark processes running against the same network pin the same registrar
+	registrarKey, _ = crypto.SigningKeyGen(crypto.NewSeededStream([]byte("auti-benchmark-registrar")))
+)
+
 func DummyOnChainTransactions(numTXs int) []*transaction.AudOnChain {
 	results := make([]*transaction.AudOnChain, numTXs)
 	var wg sync.WaitGroup
@@ -56,5 +67,9 @@ func DummyOnChainTransaction() (*transaction.AudOnChain, error) {
 	tx := transaction.NewAudPlain(
 		randIDBytes, randCipherBytes[0], randCipherBytes[1], randCipherBytes[2], randCipherBytes[3],
 	)
-	return tx.ToOnChain(), nil
+	onChainTX := tx.ToOnChain()
+	if err = onChainTX.Sign(dummySigningKey, crypto.RandomStream()); err != nil {
+		return nil, err
+	}
+	return onChainTX, nil
 }
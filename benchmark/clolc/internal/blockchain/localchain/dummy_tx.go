@@ -11,10 +11,23 @@ import (
 
 	"github.com/auti-project/auti/internal/clolc/organization"
 	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/money"
 )
 
 var numCPUs = runtime.NumCPU()
 
+// dummySigningKey signs the dummy on-chain transactions, the chaincode rejects unsigned transactions
+var dummySigningKey, _ = crypto.SigningKeyGen(crypto.RandomStream())
+
+var (
+	// dummySigner is the hex encoded public key of dummySigningKey, the signer enrolled on the chaincode
+	dummySigner, _ = crypto.SignerHex(dummySigningKey)
+	// registrarKey enrolls dummySigner, it is derived from a fixed seed
+	// so the benchmark processes running against the same network pin the same registrar
+	registrarKey, _ = crypto.SigningKeyGen(crypto.NewSeededStream([]byte("auti-benchmark-registrar")))
+)
+
 func DummyOnChainTransactions(numTXs int) []*transaction.LocalOnChain {
 	results := make([]*transaction.LocalOnChain, numTXs)
 	var wg sync.WaitGroup
@@ -45,11 +58,15 @@ func DummyOnChainTransaction() (*transaction.LocalOnChain, error) {
 	if err != nil {
 		return nil, err
 	}
-	hiddenTX, _, _, err := plainTX.Hide()
+	hiddenTX, _, _, err := plainTX.Hide(crypto.RandomStream())
 	if err != nil {
 		return nil, err
 	}
-	return hiddenTX.ToOnChain(), nil
+	onChainTX := hiddenTX.ToOnChain()
+	if err = onChainTX.Sign(dummySigningKey, crypto.RandomStream()); err != nil {
+		return nil, err
+	}
+	return onChainTX, nil
 }
 
 func DummyPlainTransactions(numTXs int) []*transaction.LocalPlain {
@@ -74,9 +91,12 @@ func DummyPlainTransactions(numTXs int) []*transaction.LocalPlain {
 
 func DummyPlainTransaction() (*transaction.LocalPlain, error) {
 	currTimestamp := time.Now().UnixNano()
-	randAmount := rand.Float64()
+	randAmount, err := money.New(rand.Int63n(100), "USD")
+	if err != nil {
+		return nil, err
+	}
 	dummyCounterPartyBytes := make([]byte, 32)
-	_, err := crand.Read(dummyCounterPartyBytes)
+	_, err = crand.Read(dummyCounterPartyBytes)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +120,7 @@ func DummyHiddenTXCommitments(numTXs int) []kyber.Point {
 				if err != nil {
 					panic(err)
 				}
-				_, commitment, _, err := tx.Hide()
+				_, commitment, _, err := tx.Hide(crypto.RandomStream())
 				if err != nil {
 					panic(err)
 				}
@@ -127,7 +147,7 @@ func DummyHiddenTXWithCounterPartyID(counterPartyID organization.TypeID, numTXs
 				if err != nil {
 					panic(err)
 				}
-				hiddenTX, commitment, randScalar, err := tx.Hide()
+				hiddenTX, commitment, randScalar, err := tx.Hide(crypto.RandomStream())
 				if err != nil {
 					panic(err)
 				}
@@ -7,6 +7,7 @@ import (
 	"math/rand"
 	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
@@ -26,6 +27,10 @@ const (
 )
 
 var (
+	memorySeedOnce sync.Once
+	memoryTXIDs    []string
+	memorySeedErr  error
+
 	fabloFilePath string
 
 	org1CCPPath  string
@@ -115,17 +120,7 @@ func SubmitTX(numTXs int) ([]string, error) {
 }
 
 func ReadTX() error {
-	f, err := os.Open(constant.LocalChainTXIDLogPath)
-	if err != nil {
-		return err
-	}
-	fileScanner := bufio.NewScanner(f)
-	fileScanner.Split(bufio.ScanLines)
-	var txIDList []string
-	for fileScanner.Scan() {
-		txIDList = append(txIDList, fileScanner.Text())
-	}
-	err = f.Close()
+	txIDList, err := loadTXIDs()
 	if err != nil {
 		return err
 	}
@@ -143,6 +138,11 @@ func ReadTX() error {
 }
 
 func ReadAllTXsByPage() error {
+	if useMemoryLedger {
+		if _, err := loadTXIDs(); err != nil {
+			return err
+		}
+	}
 	lc, err := NewController(audWalletPath, audWalletLabel, aud1CCPPath)
 	if err != nil {
 		return err
@@ -172,6 +172,33 @@ func ReadAllTXsByPage() error {
 	return err
 }
 
+// loadTXIDs reads the IDs of the prepared transactions from the log.
+// The in-memory ledger does not outlive the process that prepared the transactions,
+// so it is filled once with as many transactions instead.
+func loadTXIDs() ([]string, error) {
+	f, err := os.Open(constant.LocalChainTXIDLogPath)
+	if err != nil {
+		return nil, err
+	}
+	fileScanner := bufio.NewScanner(f)
+	fileScanner.Split(bufio.ScanLines)
+	var txIDList []string
+	for fileScanner.Scan() {
+		txIDList = append(txIDList, fileScanner.Text())
+	}
+	err = f.Close()
+	if err != nil {
+		return nil, err
+	}
+	if !useMemoryLedger {
+		return txIDList, nil
+	}
+	memorySeedOnce.Do(func() {
+		memoryTXIDs, memorySeedErr = memoryLedger.SubmitBatchTXs(DummyOnChainTransactions(len(txIDList)))
+	})
+	return memoryTXIDs, memorySeedErr
+}
+
 func SaveTXIDs(txIDs []string) error {
 	f, err := os.OpenFile(constant.LocalChainTXIDLogPath, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
 	if err != nil {
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"crypto/cipher"
 	"fmt"
 	"runtime"
 	"sync"
@@ -18,11 +19,11 @@ import (
 	"github.com/auti-project/auti/internal/crypto"
 )
 
-func CEAccumulateCommitment(numOrganizations, iterations int) error {
+func CEAccumulateCommitment(randStream cipher.Stream, numOrganizations, iterations int) error {
 	fmt.Println("[CLOLC-CE] Accumulate Commitment")
 	fmt.Printf("Num org %d, Num iter: %d\n", numOrganizations, iterations)
 	for i := 0; i < iterations; i++ {
-		com, auditors, organizations := generateEntities(numOrganizations)
+		com, auditors, organizations := generateEntities(randStream, numOrganizations)
 		_, err := com.InitializeEpoch(auditors, organizations)
 		if err != nil {
 			return err
@@ -39,23 +40,25 @@ func CEAccumulateCommitment(numOrganizations, iterations int) error {
 	return nil
 }
 
-func CEComputeB(numOrganizations, iterations int) error {
+func CEComputeB(randStream cipher.Stream, numOrganizations, iterations int) error {
 	fmt.Println("[CLOLC-CE] Compute B")
 	fmt.Printf("Num org %d, Num iter: %d\n", numOrganizations, iterations)
 	for i := 0; i < iterations; i++ {
-		com, auditors, organizations := generateEntities(numOrganizations)
+		com, auditors, organizations := generateEntities(randStream, numOrganizations)
 		_, err := com.InitializeEpoch(auditors, organizations)
 		if err != nil {
 			return err
 		}
 		randScalars1 := make([]kyber.Scalar, constants.MaxNumTXInEpoch)
 		randScalars2 := make([]kyber.Scalar, constants.MaxNumTXInEpoch)
+		generators := make([]crypto.BlindingGeneratorVersion, constants.MaxNumTXInEpoch)
 		for i := 0; i < constants.MaxNumTXInEpoch; i++ {
-			randScalars1[i] = crypto.KyberSuite.Scalar().Pick(crypto.KyberSuite.RandomStream())
-			randScalars2[i] = crypto.KyberSuite.Scalar().Pick(crypto.KyberSuite.RandomStream())
+			randScalars1[i] = crypto.KyberSuite.Scalar().Pick(randStream)
+			randScalars2[i] = crypto.KyberSuite.Scalar().Pick(randStream)
+			generators[i] = crypto.DefaultBlindingGenerator
 		}
 		startTime := time.Now()
-		if _, err = auditors[0].ComputeB(randScalars1, randScalars2); err != nil {
+		if _, err = auditors[0].ComputeB(randScalars1, randScalars2, generators); err != nil {
 			return err
 		}
 		elapsed := time.Since(startTime)
@@ -65,17 +68,17 @@ func CEComputeB(numOrganizations, iterations int) error {
 	return nil
 }
 
-func CEComputeC(numOrganizations, iterations int) error {
+func CEComputeC(randStream cipher.Stream, numOrganizations, iterations int) error {
 	fmt.Println("[CLOLC-CE] Compute C")
 	fmt.Printf("Num org %d, Num iter: %d\n", numOrganizations, iterations)
 	for i := 0; i < iterations; i++ {
-		com, auditors, organizations := generateEntities(numOrganizations)
+		com, auditors, organizations := generateEntities(randStream, numOrganizations)
 		_, err := com.InitializeEpoch(auditors, organizations)
 		if err != nil {
 			return err
 		}
-		randPoint1 := crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-		randPoint2 := crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+		randPoint1 := crypto.KyberSuite.Point().Pick(randStream)
+		randPoint2 := crypto.KyberSuite.Point().Pick(randStream)
 		startTime := time.Now()
 		_ = auditors[0].ComputeC(randPoint1, randPoint2)
 		elapsed := time.Since(startTime)
@@ -85,17 +88,17 @@ func CEComputeC(numOrganizations, iterations int) error {
 	return nil
 }
 
-func CEComputeD(numOrganizations, iterations int) error {
+func CEComputeD(randStream cipher.Stream, numOrganizations, iterations int) error {
 	fmt.Println("[CLOLC-CE] Compute D")
 	fmt.Printf("Num org %d, Num iter: %d\n", numOrganizations, iterations)
 	for i := 0; i < iterations; i++ {
-		com, auditors, organizations := generateEntities(numOrganizations)
+		com, auditors, organizations := generateEntities(randStream, numOrganizations)
 		_, err := com.InitializeEpoch(auditors, organizations)
 		if err != nil {
 			return err
 		}
-		randPoint1 := crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-		randPoint2 := crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+		randPoint1 := crypto.KyberSuite.Point().Pick(randStream)
+		randPoint2 := crypto.KyberSuite.Point().Pick(randStream)
 		startTime := time.Now()
 		_ = auditors[0].ComputeD(randPoint1, randPoint2)
 		elapsed := time.Since(startTime)
@@ -105,24 +108,24 @@ func CEComputeD(numOrganizations, iterations int) error {
 	return nil
 }
 
-func CEEncrypt(numOrganizations, iterations int) error {
+func CEEncrypt(randStream cipher.Stream, numOrganizations, iterations int) error {
 	fmt.Println("[CLOLC-CE] Encrypt")
 	fmt.Printf("Num org %d, Num iter: %d\n", numOrganizations, iterations)
 	for i := 0; i < iterations; i++ {
-		com, auditors, organizations := generateEntities(numOrganizations)
+		com, auditors, organizations := generateEntities(randStream, numOrganizations)
 		_, err := com.InitializeEpoch(auditors, organizations)
 		if err != nil {
 			return err
 		}
 		counterPartyHashStr := organization.IDHashString(organizations[1].ID)
-		_, publicKey, err := crypto.KeyGen()
+		_, publicKey, err := crypto.KeyGen(randStream)
 		if err != nil {
 			return err
 		}
-		randPoint1 := crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-		randPoint2 := crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-		randPoint3 := crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-		randPoint4 := crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+		randPoint1 := crypto.KyberSuite.Point().Pick(randStream)
+		randPoint2 := crypto.KyberSuite.Point().Pick(randStream)
+		randPoint3 := crypto.KyberSuite.Point().Pick(randStream)
+		randPoint4 := crypto.KyberSuite.Point().Pick(randStream)
 		startTime := time.Now()
 		if _, err := auditors[0].EncryptConsistencyExamResult(
 			organizations[0].ID, counterPartyHashStr, randPoint1, randPoint2, randPoint3, randPoint4, publicKey,
@@ -186,10 +189,10 @@ func CEAudPrepareTX(numTotalTXs int) error {
 	return nil
 }
 
-func CEDecrypt(iterations int) error {
+func CEDecrypt(randStream cipher.Stream, iterations int) error {
 	fmt.Println("[CLOLC-CE] Decrypt")
 	fmt.Printf("Num iter: %d\n", iterations)
-	com, auditors, organizations := generateEntities(2)
+	com, auditors, organizations := generateEntities(randStream, 2)
 	_, err := com.InitializeEpoch(auditors, organizations)
 	if err != nil {
 		return err
@@ -211,18 +214,18 @@ func CEDecrypt(iterations int) error {
 	return nil
 }
 
-func CECheck(iterations int) error {
+func CECheck(randStream cipher.Stream, iterations int) error {
 	fmt.Println("[CLOLC-CE] Check")
 	fmt.Printf("Num iter: %d\n", iterations)
 	for i := 0; i < iterations; i++ {
-		com, auditors, organizations := generateEntities(2)
+		com, auditors, organizations := generateEntities(randStream, 2)
 		_, err := com.InitializeEpoch(auditors, organizations)
 		if err != nil {
 			return err
 		}
 		randPoints := make([]kyber.Point, 4)
 		for i := 0; i < 4; i++ {
-			randPoints[i] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+			randPoints[i] = crypto.KyberSuite.Point().Pick(randStream)
 		}
 		startTime := time.Now()
 		_ = auditors[0].CheckResultConsistency(
@@ -235,7 +238,7 @@ func CECheck(iterations int) error {
 	return nil
 }
 
-func CEBatchConsistencyExaminationPartOne(iterations, numbRoutines int) error {
+func CEBatchConsistencyExaminationPartOne(randStream cipher.Stream, iterations, numbRoutines int) error {
 	fmt.Println("[CLOLC-CE] Batch consistency examination")
 	if numbRoutines <= 0 {
 		numbRoutines = runtime.NumCPU()
@@ -243,7 +246,7 @@ func CEBatchConsistencyExaminationPartOne(iterations, numbRoutines int) error {
 	for iter := 0; iter < iterations; iter++ {
 		fmt.Printf("Num iter: %d, Num routines: %d\n", iterations, numbRoutines)
 		// generate dummy data
-		com, auditors, organizations := generateEntities(256)
+		com, auditors, organizations := generateEntities(randStream, 256)
 		publicKeyMap, err := com.InitializeEpoch(auditors, organizations)
 		if err != nil {
 			return err
@@ -270,7 +273,7 @@ func CEBatchConsistencyExaminationPartOne(iterations, numbRoutines int) error {
 						organizations[j+1].ID,
 						organizations[0].EpochID,
 						dummyOrgPlainTXs[j], dummyLocalHiddenTXLists[j],
-						auditors[0].GetEpochTXRandomness(organizations[0].ID, organizations[j+1].ID),
+						auditors[0].GetEpochTXRandomness(organizations[0].ID, organizations[j+1].ID, constants.MaxNumTXInEpoch),
 						dummyCommitmentRandScalars[j], publicKeyMap,
 					)
 				}
@@ -289,7 +292,7 @@ func CEBatchConsistencyExaminationPartOne(iterations, numbRoutines int) error {
 	return nil
 }
 
-func CEBatchConsistencyExaminationPartTwo(iterations, numRoutines int) error {
+func CEBatchConsistencyExaminationPartTwo(randStream cipher.Stream, iterations, numRoutines int) error {
 	fmt.Println("[CLOLC-CE] Batch consistency examination")
 	if numRoutines <= 0 {
 		numRoutines = runtime.NumCPU()
@@ -297,7 +300,7 @@ func CEBatchConsistencyExaminationPartTwo(iterations, numRoutines int) error {
 	for iter := 0; iter < iterations; iter++ {
 		fmt.Printf("Num iter: %d, Num routines: %d\n", iterations, numRoutines)
 		// generate dummy data
-		com, auditors, organizations := generateEntities(256)
+		com, auditors, organizations := generateEntities(randStream, 256)
 		if _, err := com.InitializeEpoch(auditors, organizations); err != nil {
 			return err
 		}
@@ -305,8 +308,8 @@ func CEBatchConsistencyExaminationPartTwo(iterations, numRoutines int) error {
 		randPointAccResultList := make([]kyber.Point, 255)
 		randPointAList := make([]kyber.Point, 255)
 		for i := 0; i < 255; i++ {
-			randPointAccResultList[i] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-			randPointAList[i] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+			randPointAccResultList[i] = crypto.KyberSuite.Point().Pick(randStream)
+			randPointAList[i] = crypto.KyberSuite.Point().Pick(randStream)
 		}
 		runtime.GC()
 		startTime := time.Now()
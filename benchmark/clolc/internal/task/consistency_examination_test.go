@@ -7,16 +7,19 @@ import (
 
 	"go.dedis.ch/kyber/v3"
 
+	clolcaud "github.com/auti-project/auti/internal/clolc/auditor"
 	"github.com/auti-project/auti/internal/clolc/organization"
 	"github.com/auti-project/auti/internal/clolc/transaction"
 	"github.com/auti-project/auti/internal/constants"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/money"
 )
 
 const testNumTXs = constants.MaxNumTXInEpoch
 
 func TestCECheck(t *testing.T) {
 	// setup
-	com, auditors, organizations := generateEntities(2)
+	com, auditors, organizations := generateEntities(crypto.RandomStream(), 2)
 	_, err := com.InitializeEpoch(auditors, organizations)
 	if err != nil {
 		t.Fatal(err)
@@ -33,14 +36,14 @@ func TestCECheck(t *testing.T) {
 		randScalars2 = make([]kyber.Scalar, testNumTXs)
 	)
 	for i := 0; i < testNumTXs; i++ {
-		hiddenTX1, point1, scalar1, err := txList1[i].Hide()
+		hiddenTX1, point1, scalar1, err := txList1[i].Hide(crypto.RandomStream())
 		if err != nil {
 			t.Fatal(err)
 		}
 		hiddenTXs1[i] = hiddenTX1
 		points1[i] = point1
 		randScalars1[i] = scalar1
-		hiddenTX2, point2, scalar2, err := txList2[i].Hide()
+		hiddenTX2, point2, scalar2, err := txList2[i].Hide(crypto.RandomStream())
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -56,15 +59,15 @@ func TestCECheck(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	txRandList := auditors[0].GetEpochTXRandomness(organizations[0].ID, organizations[1].ID)
+	txRandList := auditors[0].GetEpochTXRandomness(organizations[0].ID, organizations[1].ID, len(randScalars1))
 	if txRandList == nil {
 		t.Fatal("txRandList is nil")
 	}
-	b1, err := auditors[0].ComputeB(randScalars1, txRandList)
+	b1, err := auditors[0].ComputeB(randScalars1, txRandList, clolcaud.BlindingGenerators(hiddenTXs1))
 	if err != nil {
 		t.Fatal(err)
 	}
-	b2, err := auditors[1].ComputeB(randScalars2, txRandList)
+	b2, err := auditors[1].ComputeB(randScalars2, txRandList, clolcaud.BlindingGenerators(hiddenTXs2))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -73,10 +76,8 @@ func TestCECheck(t *testing.T) {
 	}
 }
 
-func randAmount() float64 {
-	amount := rand.Float64()
-	integerPart := rand.Int()%10000 - 5000
-	amount += float64(integerPart)
+func randAmount() money.Amount {
+	amount, _ := money.New(rand.Int63n(1000000)-500000, "USD")
 	return amount
 }
 
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"crypto/cipher"
 	"fmt"
 	"time"
 
@@ -10,24 +11,28 @@ import (
 	clolcorg "github.com/auti-project/auti/internal/clolc/organization"
 )
 
-func generateEntities(numOrganizations int) (*clolccom.Committee, []*clolcaud.Auditor, []*clolcorg.Organization) {
+func generateEntities(
+	randStream cipher.Stream, numOrganizations int,
+) (*clolccom.Committee, []*clolcaud.Auditor, []*clolcorg.Organization) {
 	organizations := make([]*clolcorg.Organization, numOrganizations)
 	for i := 0; i < numOrganizations; i++ {
-		organizations[i] = clolcorg.New("org" + string(rune(i)))
+		organizations[i] = clolcorg.NewWithRandStream("org"+string(rune(i)), nil, randStream)
 	}
 	auditors := make([]*clolcaud.Auditor, numOrganizations)
 	for i := 0; i < numOrganizations; i++ {
-		auditors[i] = clolcaud.New("aud"+string(rune(i)), []*clolcorg.Organization{organizations[i]})
+		auditors[i] = clolcaud.NewWithRandStream(
+			"aud"+string(rune(i)), []*clolcorg.Organization{organizations[i]}, randStream,
+		)
 	}
-	com := clolccom.New("com", auditors)
+	com := clolccom.NewWithRandStream("com", auditors, randStream)
 	return com, auditors, organizations
 }
 
-func INDefault(numOrganizations, iterations int) error {
+func INDefault(randStream cipher.Stream, numOrganizations, iterations int) error {
 	fmt.Println("[CLOLC-IN] Default")
 	fmt.Printf("Num Org: %d, Num iter: %d\n", numOrganizations, iterations)
 	for i := 0; i < iterations; i++ {
-		com, auditors, organizations := generateEntities(numOrganizations)
+		com, auditors, organizations := generateEntities(randStream, numOrganizations)
 		startTime := time.Now()
 		_, err := com.InitializeEpoch(auditors, organizations)
 		if err != nil {
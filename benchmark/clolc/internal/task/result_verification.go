@@ -1,6 +1,7 @@
 package task
 
 import (
+	"crypto/cipher"
 	"fmt"
 	"runtime"
 	"sync"
@@ -14,11 +15,11 @@ import (
 	"github.com/auti-project/auti/internal/crypto"
 )
 
-func RVVerifyOrgAndAudResult(numOrganizations, iterations int) error {
+func RVVerifyOrgAndAudResult(randStream cipher.Stream, numOrganizations, iterations int) error {
 	fmt.Println("[CLOLC-RV] Verify org and aud result")
 	for i := 0; i < iterations; i++ {
 		fmt.Printf("Num org %d, Num iter: %d\n", numOrganizations, iterations)
-		com, auditors, organizations := generateEntities(numOrganizations)
+		com, auditors, organizations := generateEntities(randStream, numOrganizations)
 		_, err := com.InitializeEpoch(auditors, organizations)
 		if err != nil {
 			return err
@@ -47,11 +48,11 @@ func RVVerifyOrgAndAudResult(numOrganizations, iterations int) error {
 	return nil
 }
 
-func RVVerifyAuditPairResult(numOrganizations, iterations int) error {
+func RVVerifyAuditPairResult(randStream cipher.Stream, numOrganizations, iterations int) error {
 	fmt.Println("[CLOLC-RV] Verify audit pair result")
 	for i := 0; i < iterations; i++ {
 		fmt.Printf("Num org %d, Num iter: %d\n", numOrganizations, iterations)
-		com, auditors, organizations := generateEntities(numOrganizations)
+		com, auditors, organizations := generateEntities(randStream, numOrganizations)
 		_, err := com.InitializeEpoch(auditors, organizations)
 		if err != nil {
 			return err
@@ -82,7 +83,7 @@ func RVVerifyAuditPairResult(numOrganizations, iterations int) error {
 	return nil
 }
 
-func RVBatchDecrypt(iterations, numRoutines int) error {
+func RVBatchDecrypt(randStream cipher.Stream, iterations, numRoutines int) error {
 	if numRoutines <= 0 {
 		numRoutines = runtime.NumCPU()
 	}
@@ -91,11 +92,11 @@ func RVBatchDecrypt(iterations, numRoutines int) error {
 	for iter := 0; iter < iterations; iter++ {
 		fmt.Printf("Num iter: %d, Num routines: %d\n", iter, numRoutines)
 		dummyAudOnChainTXs := audchain.DummyOnChainTransactions(numTXs)
-		priKey, _, err := crypto.KeyGen()
+		priKey, _, err := crypto.KeyGen(randStream)
 		if err != nil {
 			return err
 		}
-		com, _, _ := generateEntities(1)
+		com, _, _ := generateEntities(randStream, 1)
 		runtime.GC()
 		startTime := time.Now()
 		var wg sync.WaitGroup
@@ -118,7 +119,7 @@ func RVBatchDecrypt(iterations, numRoutines int) error {
 	return nil
 }
 
-func RVBatchCheckOrgAndAudPair(iterations, numRoutines int) error {
+func RVBatchCheckOrgAndAudPair(randStream cipher.Stream, iterations, numRoutines int) error {
 	if numRoutines <= 0 {
 		numRoutines = runtime.NumCPU()
 	}
@@ -130,10 +131,10 @@ func RVBatchCheckOrgAndAudPair(iterations, numRoutines int) error {
 		dummyPointBList := make([]kyber.Point, numTXs)
 		dummyPointCList := make([]kyber.Point, numTXs)
 		for i := 0; i < numTXs; i++ {
-			dummyPointBList[i] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-			dummyPointCList[i] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+			dummyPointBList[i] = crypto.KyberSuite.Point().Pick(randStream)
+			dummyPointCList[i] = crypto.KyberSuite.Point().Pick(randStream)
 		}
-		com, auditors, organizations := generateEntities(1)
+		com, auditors, organizations := generateEntities(randStream, 1)
 		runtime.GC()
 		startTime := time.Now()
 		var wg sync.WaitGroup
@@ -162,7 +163,7 @@ func RVBatchCheckOrgAndAudPair(iterations, numRoutines int) error {
 	return nil
 }
 
-func RVBatchCheckAudPair(iterations, numRoutines int) error {
+func RVBatchCheckAudPair(randStream cipher.Stream, iterations, numRoutines int) error {
 	if numRoutines <= 0 {
 		numRoutines = runtime.NumCPU()
 	}
@@ -175,12 +176,12 @@ func RVBatchCheckAudPair(iterations, numRoutines int) error {
 		dummyPointD1List := make([]kyber.Point, numTXs)
 		dummyPointD2List := make([]kyber.Point, numTXs)
 		for i := 0; i < numTXs; i++ {
-			dummyPointC1List[i] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-			dummyPointC2List[i] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-			dummyPointD1List[i] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-			dummyPointD2List[i] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+			dummyPointC1List[i] = crypto.KyberSuite.Point().Pick(randStream)
+			dummyPointC2List[i] = crypto.KyberSuite.Point().Pick(randStream)
+			dummyPointD1List[i] = crypto.KyberSuite.Point().Pick(randStream)
+			dummyPointD2List[i] = crypto.KyberSuite.Point().Pick(randStream)
 		}
-		com, auditors, organizations := generateEntities(2)
+		com, auditors, organizations := generateEntities(randStream, 2)
 		if _, err := com.InitializeEpoch(auditors, organizations); err != nil {
 			return err
 		}
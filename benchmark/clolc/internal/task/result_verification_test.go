@@ -6,6 +6,8 @@ import (
 
 	"go.dedis.ch/kyber/v3"
 
+	clolcaud "github.com/auti-project/auti/internal/clolc/auditor"
+	clolccom "github.com/auti-project/auti/internal/clolc/committee"
 	"github.com/auti-project/auti/internal/clolc/organization"
 	"github.com/auti-project/auti/internal/clolc/transaction"
 	"github.com/auti-project/auti/internal/crypto"
@@ -13,7 +15,7 @@ import (
 
 func TestRVOrgAndAudResult(t *testing.T) {
 	// entity setup
-	com, auditors, organizations := generateEntities(2)
+	com, auditors, organizations := generateEntities(crypto.RandomStream(), 2)
 	publicKeyMap, err := com.InitializeEpoch(auditors, organizations)
 	if err != nil {
 		t.Fatal(err)
@@ -30,7 +32,7 @@ func TestRVOrgAndAudResult(t *testing.T) {
 		randScalars2 = make([]kyber.Scalar, testNumTXs)
 	)
 	for i := 0; i < testNumTXs; i++ {
-		hiddenTX1, point1, scalar1, err := localTXs1[i].Hide()
+		hiddenTX1, point1, scalar1, err := localTXs1[i].Hide(crypto.RandomStream())
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -38,7 +40,7 @@ func TestRVOrgAndAudResult(t *testing.T) {
 		points1[i] = point1
 		randScalars1[i] = scalar1
 		organizations[0].Accumulate(organizations[1].ID, point1)
-		hiddenTX2, point2, scalar2, err := localTXs2[i].Hide()
+		hiddenTX2, point2, scalar2, err := localTXs2[i].Hide(crypto.RandomStream())
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -66,15 +68,15 @@ func TestRVOrgAndAudResult(t *testing.T) {
 		t.Fatal(err)
 	}
 	// compute b
-	txRandList := auditors[0].GetEpochTXRandomness(organizations[0].ID, organizations[1].ID)
+	txRandList := auditors[0].GetEpochTXRandomness(organizations[0].ID, organizations[1].ID, len(randScalars1))
 	if txRandList == nil {
 		t.Fatal("txRandList is nil")
 	}
-	b1, err := auditors[0].ComputeB(randScalars1, txRandList)
+	b1, err := auditors[0].ComputeB(randScalars1, txRandList, clolcaud.BlindingGenerators(hiddenTXs1))
 	if err != nil {
 		t.Fatal(err)
 	}
-	b2, err := auditors[1].ComputeB(randScalars2, txRandList)
+	b2, err := auditors[1].ComputeB(randScalars2, txRandList, clolcaud.BlindingGenerators(hiddenTXs2))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -131,11 +133,45 @@ func TestRVOrgAndAudResult(t *testing.T) {
 	if !res {
 		t.Fatal("verify failed")
 	}
+	// publicly verifiable verdict
+	verdict, err := com.ProveOrgAndAudResult(organizations[0].ID, auditors[0].ID, orgTX1.ToOnChain(), audTX1.ToOnChain())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verdict.Result {
+		t.Fatal("verdict result is false")
+	}
+	publicKeys, commitments := com.PublishPublicKeys(), com.PublishEpochIDCommitments()
+	res, err = clolccom.VerifyOrgAndAudVerdict(publicKeys, commitments, orgTX1.ToOnChain(), audTX1.ToOnChain(), verdict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res {
+		t.Fatal("verdict verification failed")
+	}
+	verdict.Result = false
+	res, err = clolccom.VerifyOrgAndAudVerdict(publicKeys, commitments, orgTX1.ToOnChain(), audTX1.ToOnChain(), verdict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res {
+		t.Fatal("forged verdict is accepted")
+	}
+	// a committee cannot pick the epoch ID that makes its result check out
+	verdict.Result = true
+	verdict.AudEpochID = auditors[1].EpochID
+	res, err = clolccom.VerifyOrgAndAudVerdict(publicKeys, commitments, orgTX1.ToOnChain(), audTX1.ToOnChain(), verdict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res {
+		t.Fatal("verdict with a substituted epoch ID is accepted")
+	}
 }
 
 func TestRVVerifyAuditPairResult(t *testing.T) {
 	// entity setup
-	com, auditors, organizations := generateEntities(2)
+	com, auditors, organizations := generateEntities(crypto.RandomStream(), 2)
 	publicKeyMap, err := com.InitializeEpoch(auditors, organizations)
 	if err != nil {
 		t.Fatal(err)
@@ -152,7 +188,7 @@ func TestRVVerifyAuditPairResult(t *testing.T) {
 		randScalars2 = make([]kyber.Scalar, testNumTXs)
 	)
 	for i := 0; i < testNumTXs; i++ {
-		hiddenTX1, point1, scalar1, err := localTXs1[i].Hide()
+		hiddenTX1, point1, scalar1, err := localTXs1[i].Hide(crypto.RandomStream())
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -160,7 +196,7 @@ func TestRVVerifyAuditPairResult(t *testing.T) {
 		points1[i] = point1
 		randScalars1[i] = scalar1
 		organizations[0].Accumulate(organizations[1].ID, point1)
-		hiddenTX2, point2, scalar2, err := localTXs2[i].Hide()
+		hiddenTX2, point2, scalar2, err := localTXs2[i].Hide(crypto.RandomStream())
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -188,15 +224,15 @@ func TestRVVerifyAuditPairResult(t *testing.T) {
 		t.Fatal(err)
 	}
 	// compute b
-	txRandList := auditors[0].GetEpochTXRandomness(organizations[0].ID, organizations[1].ID)
+	txRandList := auditors[0].GetEpochTXRandomness(organizations[0].ID, organizations[1].ID, len(randScalars1))
 	if txRandList == nil {
 		t.Fatal("txRandList is nil")
 	}
-	b1, err := auditors[0].ComputeB(randScalars1, txRandList)
+	b1, err := auditors[0].ComputeB(randScalars1, txRandList, clolcaud.BlindingGenerators(hiddenTXs1))
 	if err != nil {
 		t.Fatal(err)
 	}
-	b2, err := auditors[1].ComputeB(randScalars2, txRandList)
+	b2, err := auditors[1].ComputeB(randScalars2, txRandList, clolcaud.BlindingGenerators(hiddenTXs2))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -250,5 +286,151 @@ func TestRVVerifyAuditPairResult(t *testing.T) {
 	if !res {
 		t.Fatal("verify failed")
 	}
+	// publicly verifiable verdict
+	verdict, err := com.ProveAuditPairResult(
+		organizations[0].ID, organizations[1].ID,
+		auditors[0].ID, auditors[1].ID,
+		audTX1.ToOnChain(), audTX2.ToOnChain(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeys, commitments := com.PublishPublicKeys(), com.PublishEpochIDCommitments()
+	res, err = clolccom.VerifyAuditPairVerdict(publicKeys, commitments, audTX1.ToOnChain(), audTX2.ToOnChain(), verdict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res || !verdict.Result {
+		t.Fatal("verdict verification failed")
+	}
+	verdict.AudEpochID1, verdict.AudEpochID2 = verdict.AudEpochID2, verdict.AudEpochID1
+	res, err = clolccom.VerifyAuditPairVerdict(publicKeys, commitments, audTX1.ToOnChain(), audTX2.ToOnChain(), verdict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res {
+		t.Fatal("verdict with swapped epoch IDs is accepted")
+	}
+}
 
+func TestRVThresholdOrgAndAudResult(t *testing.T) {
+	// entity setup
+	_, auditors, organizations := generateEntities(crypto.RandomStream(), 2)
+	com, err := clolccom.NewThreshold("com", auditors, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyMap, err := com.InitializeEpoch(auditors, organizations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// compose and hide the local transactions
+	localTXs1, _ := generateLocalTXPairList(organizations[0].ID, organizations[1].ID)
+	hiddenTXs1 := make([]*transaction.LocalHidden, testNumTXs)
+	randScalars1 := make([]kyber.Scalar, testNumTXs)
+	for i := 0; i < testNumTXs; i++ {
+		hiddenTX1, point1, scalar1, err := localTXs1[i].Hide(crypto.RandomStream())
+		if err != nil {
+			t.Fatal(err)
+		}
+		hiddenTXs1[i] = hiddenTX1
+		randScalars1[i] = scalar1
+		organizations[0].Accumulate(organizations[1].ID, point1)
+	}
+	orgTX1, err := organizations[0].ComposeTXOrgChain(organizations[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txRandList := auditors[0].GetEpochTXRandomness(organizations[0].ID, organizations[1].ID, len(randScalars1))
+	audTX1, err := auditors[0].ConsistencyExaminationPartOne(
+		organizations[0].ID, organizations[1].ID, organizations[0].EpochID,
+		orgTX1, hiddenTXs1, randScalars1, txRandList, publicKeyMap,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// verify with exactly the threshold of members
+	res, err := com.VerifyOrgAndAudResult(
+		organizations[0].ID, auditors[0].ID, orgTX1.ToOnChain(), audTX1.ToOnChain(), com.Members[1:4],
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res {
+		t.Fatal("verify failed")
+	}
+	// verify with less than the threshold of members
+	_, err = com.VerifyOrgAndAudResult(
+		organizations[0].ID, auditors[0].ID, orgTX1.ToOnChain(), audTX1.ToOnChain(), com.Members[:2],
+	)
+	if err == nil {
+		t.Fatal("verify succeeded with less than threshold members")
+	}
+}
+
+func TestRVThresholdOrgAndAudVerdict(t *testing.T) {
+	// entity setup
+	_, auditors, organizations := generateEntities(crypto.RandomStream(), 2)
+	com, err := clolccom.NewThreshold("com", auditors, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyMap, err := com.InitializeEpoch(auditors, organizations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	localTXs1, _ := generateLocalTXPairList(organizations[0].ID, organizations[1].ID)
+	hiddenTXs1 := make([]*transaction.LocalHidden, testNumTXs)
+	randScalars1 := make([]kyber.Scalar, testNumTXs)
+	for i := 0; i < testNumTXs; i++ {
+		hiddenTX1, point1, scalar1, err := localTXs1[i].Hide(crypto.RandomStream())
+		if err != nil {
+			t.Fatal(err)
+		}
+		hiddenTXs1[i] = hiddenTX1
+		randScalars1[i] = scalar1
+		organizations[0].Accumulate(organizations[1].ID, point1)
+	}
+	orgTX1, err := organizations[0].ComposeTXOrgChain(organizations[1].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txRandList := auditors[0].GetEpochTXRandomness(organizations[0].ID, organizations[1].ID, len(randScalars1))
+	audTX1, err := auditors[0].ConsistencyExaminationPartOne(
+		organizations[0].ID, organizations[1].ID, organizations[0].EpochID,
+		orgTX1, hiddenTXs1, randScalars1, txRandList, publicKeyMap,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the promoted methods needing the whole epoch secret keys refuse
+	if _, err = com.Committee.ProveOrgAndAudResult(
+		organizations[0].ID, auditors[0].ID, orgTX1.ToOnChain(), audTX1.ToOnChain(),
+	); err == nil {
+		t.Fatal("prove succeeded without the epoch secret keys")
+	}
+	verdict, err := com.ProveOrgAndAudResult(
+		organizations[0].ID, auditors[0].ID, orgTX1.ToOnChain(), audTX1.ToOnChain(), com.Members[1:4],
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeys := com.PublishPublicKeys()
+	commitments := com.PublishEpochIDCommitments()
+	res, err := clolccom.VerifyOrgAndAudVerdict(publicKeys, commitments, orgTX1.ToOnChain(), audTX1.ToOnChain(), verdict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res || !verdict.Result {
+		t.Fatal("verdict verification failed")
+	}
+	// drop a share, the remaining ones are below the threshold
+	verdict.ThresholdB.Shares = verdict.ThresholdB.Shares[1:]
+	res, err = clolccom.VerifyOrgAndAudVerdict(publicKeys, commitments, orgTX1.ToOnChain(), audTX1.ToOnChain(), verdict)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res {
+		t.Fatal("verdict with less than threshold shares is accepted")
+	}
 }
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"crypto/cipher"
 	"fmt"
 	"time"
 
@@ -62,14 +63,14 @@ func TRLocalReadAllTXs(numTotalTXs, iterations int) error {
 	return nil
 }
 
-func TRCommitment(numTotalTXs, iterations int) error {
+func TRCommitment(randStream cipher.Stream, numTotalTXs, iterations int) error {
 	fmt.Println("[CLOLC-TR] Commitment")
 	fmt.Printf("Num TX: %d, Num iter: %d\n", numTotalTXs, iterations)
 	for i := 0; i < iterations; i++ {
 		dummyTXs := localchain.DummyPlainTransactions(numTotalTXs)
 		startTime := time.Now()
 		for _, tx := range dummyTXs {
-			if _, _, _, err := tx.Hide(); err != nil {
+			if _, _, _, err := tx.Hide(randStream); err != nil {
 				return err
 			}
 		}
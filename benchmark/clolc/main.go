@@ -6,6 +6,7 @@ import (
 
 	. "github.com/auti-project/auti/benchmark/clolc/internal/flag"
 	"github.com/auti-project/auti/benchmark/clolc/internal/task"
+	"github.com/auti-project/auti/internal/crypto"
 )
 
 func main() {
@@ -15,14 +16,19 @@ func main() {
 	numIterPtr := flag.Int("numIter", 10, "Number of iterations")
 	numTXsPtr := flag.Int("numTXs", 100, "Number of transactions")
 	numRoutinesPtr := flag.Int("numRoutines", 0, "Number of routines")
+	seedPtr := flag.String("seed", "", "Seed of the entities and the values drawn by the tasks, fresh randomness if empty")
 	flag.Parse()
+	randStream := crypto.RandomStream()
+	if *seedPtr != "" {
+		randStream = crypto.NewSeededStream([]byte(*seedPtr))
+	}
 
 	var err error
 	switch *benchPhasePtr {
 	case PhaseInitialization:
 		switch *benchProcessPtr {
 		case ProcessINDefault:
-			err = task.INDefault(*numOrgPtr, *numIterPtr)
+			err = task.INDefault(randStream, *numOrgPtr, *numIterPtr)
 		}
 	case PhaseTransactionRecord:
 		switch *benchProcessPtr {
@@ -43,24 +49,24 @@ func main() {
 		case ProcessTROrgChainReadAll:
 			err = task.TROrgReadAllTXs(*numTXsPtr, *numIterPtr)
 		case ProcessTRCommitment:
-			err = task.TRCommitment(*numTXsPtr, *numIterPtr)
+			err = task.TRCommitment(randStream, *numTXsPtr, *numIterPtr)
 		case ProcessTRAccumulate:
 			err = task.TRAccumulate(*numTXsPtr, *numIterPtr)
 		}
 	case PhaseConsistencyExamination:
 		switch *benchProcessPtr {
 		case ProcessCEAccumulateCommitment:
-			err = task.CEAccumulateCommitment(*numOrgPtr, *numIterPtr)
+			err = task.CEAccumulateCommitment(randStream, *numOrgPtr, *numIterPtr)
 		case ProcessCEComputeB:
-			err = task.CEComputeB(*numOrgPtr, *numIterPtr)
+			err = task.CEComputeB(randStream, *numOrgPtr, *numIterPtr)
 		case ProcessCEComputeC:
-			err = task.CEComputeC(*numOrgPtr, *numIterPtr)
+			err = task.CEComputeC(randStream, *numOrgPtr, *numIterPtr)
 		case ProcessCEComputeD:
-			err = task.CEComputeD(*numOrgPtr, *numIterPtr)
+			err = task.CEComputeD(randStream, *numOrgPtr, *numIterPtr)
 		case ProcessCEEncrypt:
-			err = task.CEEncrypt(*numOrgPtr, *numIterPtr)
+			err = task.CEEncrypt(randStream, *numOrgPtr, *numIterPtr)
 		case ProcessCEDecrypt:
-			err = task.CEDecrypt(*numIterPtr)
+			err = task.CEDecrypt(randStream, *numIterPtr)
 		case ProcessCEAudChainSubmit:
 			err = task.CEAudSubmitTX(*numTXsPtr, *numIterPtr)
 		case ProcessCEAudChainPrepare:
@@ -70,24 +76,24 @@ func main() {
 		case ProcessCEAudChainReadAll:
 			err = task.CEAudReadAllTXs(*numTXsPtr, *numIterPtr)
 		case ProcessCECheck:
-			err = task.CECheck(*numIterPtr)
+			err = task.CECheck(randStream, *numIterPtr)
 		case ProcessCEConsistencyExaminationPartOneParallel:
-			err = task.CEBatchConsistencyExaminationPartOne(*numIterPtr, *numRoutinesPtr)
+			err = task.CEBatchConsistencyExaminationPartOne(randStream, *numIterPtr, *numRoutinesPtr)
 		case ProcessCEConsistencyExaminationPartTwoParallel:
-			err = task.CEBatchConsistencyExaminationPartTwo(*numIterPtr, *numRoutinesPtr)
+			err = task.CEBatchConsistencyExaminationPartTwo(randStream, *numIterPtr, *numRoutinesPtr)
 		}
 	case PhaseResultVerification:
 		switch *benchProcessPtr {
 		case ProcessRVVerifyOrgAndAudResult:
-			err = task.RVVerifyOrgAndAudResult(*numOrgPtr, *numIterPtr)
+			err = task.RVVerifyOrgAndAudResult(randStream, *numOrgPtr, *numIterPtr)
 		case ProcessRVVerifyAuditPairResult:
-			err = task.RVVerifyAuditPairResult(*numOrgPtr, *numIterPtr)
+			err = task.RVVerifyAuditPairResult(randStream, *numOrgPtr, *numIterPtr)
 		case ProcessRVDecryptParallel:
-			err = task.RVBatchDecrypt(*numIterPtr, *numRoutinesPtr)
+			err = task.RVBatchDecrypt(randStream, *numIterPtr, *numRoutinesPtr)
 		case ProcessRVCheckOrgAndAudPairParallel:
-			err = task.RVBatchCheckOrgAndAudPair(*numIterPtr, *numRoutinesPtr)
+			err = task.RVBatchCheckOrgAndAudPair(randStream, *numIterPtr, *numRoutinesPtr)
 		case ProcessRVCheckAudPairParallel:
-			err = task.RVBatchCheckAudPair(*numIterPtr, *numRoutinesPtr)
+			err = task.RVBatchCheckAudPair(randStream, *numIterPtr, *numRoutinesPtr)
 		}
 
 	default:
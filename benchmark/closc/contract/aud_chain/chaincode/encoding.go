@@ -0,0 +1,67 @@
+package chaincode
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// encodingVersion mirrors the version of codec.TypeCLOSCAudOnChain, the first byte of its canonical encodings.
+const encodingVersion byte = 3
+
+// encodeFields mirrors the canonical encoding of internal/codec: the version, the type tag,
+// and the fields, each prefixed with its length as a big-endian uint32.
+func encodeFields(tag byte, fields ...string) []byte {
+	encoding := []byte{encodingVersion, tag}
+	for _, field := range fields {
+		encoding = binary.BigEndian.AppendUint32(encoding, uint32(len(field)))
+		encoding = append(encoding, field...)
+	}
+	return encoding
+}
+
+// decodeFields reads numFields fields of the canonical encoding,
+// it rejects other versions and type tags, and trailing bytes.
+func decodeFields(encoding []byte, tag byte, numFields int) ([]string, error) {
+	if len(encoding) < 2 {
+		return nil, errors.New("encoding is too short")
+	}
+	if encoding[0] != encodingVersion {
+		return nil, fmt.Errorf("unsupported encoding version: %d", encoding[0])
+	}
+	if encoding[1] != tag {
+		return nil, fmt.Errorf("unexpected type tag: %d, want %d", encoding[1], tag)
+	}
+	encoding = encoding[2:]
+	fields := make([]string, numFields)
+	for i := range fields {
+		if len(encoding) < 4 {
+			return nil, errors.New("missing field length")
+		}
+		length := binary.BigEndian.Uint32(encoding)
+		encoding = encoding[4:]
+		if uint64(len(encoding)) < uint64(length) {
+			return nil, errors.New("field is truncated")
+		}
+		fields[i] = string(encoding[:length])
+		encoding = encoding[length:]
+	}
+	if len(encoding) != 0 {
+		return nil, errors.New("trailing bytes after the last field")
+	}
+	return fields, nil
+}
+
+// decodeTransaction decodes the hex encoded canonical encoding of a transaction.
+func decodeTransaction(encodingHex string) (*Transaction, error) {
+	encoding, err := hex.DecodeString(encodingHex)
+	if err != nil {
+		return nil, err
+	}
+	tx := new(Transaction)
+	if err = tx.UnmarshalBinary(encoding); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
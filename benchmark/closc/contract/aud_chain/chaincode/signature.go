@@ -0,0 +1,70 @@
+package chaincode
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+)
+
+// signingTag must match the tag of the transaction type in internal/closc/transaction.
+const signingTag = "auti-closc-aud"
+
+// signingMessage returns the message signed by the signer of the transaction.
+func signingMessage(fields ...string) []byte {
+	return taggedMessage(signingTag, fields...)
+}
+
+// taggedMessage mirrors crypto.SigningMessage: the tag and the fields,
+// each prefixed with its length as a big-endian uint32.
+func taggedMessage(tag string, fields ...string) []byte {
+	msg := binary.BigEndian.AppendUint32(nil, uint32(len(tag)))
+	msg = append(msg, tag...)
+	for _, field := range fields {
+		msg = binary.BigEndian.AppendUint32(msg, uint32(len(field)))
+		msg = append(msg, field...)
+	}
+	return msg
+}
+
+// VerifySignature rejects unsigned transactions and transactions whose Ed25519 signature
+// does not verify under the signer public key.
+func (t *Transaction) VerifySignature() error {
+	if t.Signer == "" || t.Signature == "" {
+		return errors.New("the transaction is not signed")
+	}
+	ok, err := verifyEd25519(t.Signer, t.Signature, signingMessage(t.Commitment, t.Hash, t.Signer))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("invalid transaction signature")
+	}
+	return nil
+}
+
+// decodePublicKey decodes a hex encoded Ed25519 public key.
+func decodePublicKey(publicKeyHex string) (ed25519.PublicKey, error) {
+	publicKey, err := hex.DecodeString(publicKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid signer public key")
+	}
+	return publicKey, nil
+}
+
+// verifyEd25519 returns true if the hex encoded Ed25519 signature of the message verifies
+// under the hex encoded public key.
+func verifyEd25519(publicKeyHex, signatureHex string, msg []byte) (bool, error) {
+	publicKey, err := decodePublicKey(publicKeyHex)
+	if err != nil {
+		return false, err
+	}
+	signature, err := hex.DecodeString(signatureHex)
+	if err != nil {
+		return false, err
+	}
+	return ed25519.Verify(publicKey, msg, signature), nil
+}
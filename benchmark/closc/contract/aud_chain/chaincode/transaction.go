@@ -3,12 +3,20 @@ package chaincode
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 )
 
+// typeTag mirrors codec.TypeCLOSCAudOnChain, the type tag of the canonical encoding of the transaction.
+const typeTag byte = 0x14
+
+// txIDDomain mirrors the domain of hashing.TagTXID, the ID of a transaction is the tagged hash
+// of its canonical encoding without the signature.
+const txIDDomain = "auti-v1/tx-id"
+
 type Transaction struct {
 	Commitment string `json:"commitment"`
 	Hash       string `json:"hash"`
+	Signer     string `json:"signer,omitempty"`
+	Signature  string `json:"signature,omitempty"`
 }
 
 func NewTransaction(commitment, hash string) *Transaction {
@@ -18,12 +26,35 @@ func NewTransaction(commitment, hash string) *Transaction {
 	}
 }
 
-func (t *Transaction) KeyVal() (string, []byte, error) {
-	txJSON, err := json.Marshal(t)
+// encode returns the canonical encoding of the transaction with the given signature.
+func (t *Transaction) encode(signature string) []byte {
+	return encodeFields(typeTag, t.Commitment, t.Hash, t.Signer, signature)
+}
+
+// MarshalBinary mirrors the canonical encoding of the on-chain transaction in internal/codec.
+func (t *Transaction) MarshalBinary() ([]byte, error) {
+	return t.encode(t.Signature), nil
+}
+
+func (t *Transaction) UnmarshalBinary(data []byte) error {
+	fields, err := decodeFields(data, typeTag, 4)
 	if err != nil {
-		return "", nil, err
+		return err
+	}
+	*t = Transaction{
+		Commitment: fields[0],
+		Hash:       fields[1],
+		Signer:     fields[2],
+		Signature:  fields[3],
 	}
+	return nil
+}
+
+// KeyVal returns the content ID of the transaction and its canonical encoding.
+func (t *Transaction) KeyVal() (string, []byte, error) {
 	sha256Func := sha256.New()
-	sha256Func.Write(txJSON)
-	return hex.EncodeToString(sha256Func.Sum(nil)), txJSON, nil
+	sha256Func.Write([]byte{byte(len(txIDDomain))})
+	sha256Func.Write([]byte(txIDDomain))
+	sha256Func.Write(t.encode(""))
+	return hex.EncodeToString(sha256Func.Sum(nil)), t.encode(t.Signature), nil
 }
@@ -3,13 +3,24 @@ package chaincode
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
 )
 
+// typeTag mirrors codec.TypeCLOSCLocalOnChain, the type tag of the canonical encoding of the transaction.
+const typeTag byte = 0x13
+
+// txIDDomain mirrors the domain of hashing.TagTXID, the ID of a transaction is the tagged hash
+// of its canonical encoding without the signature.
+const txIDDomain = "auti-v1/tx-id"
+
 type Transaction struct {
 	Commitment  string `json:"commitment"`
+	Asset       string `json:"asset,omitempty"`
+	Scheme      string `json:"scheme,omitempty"`
 	MerkleRoot  string `json:"merkle_root"`
 	MerkleProof string `json:"merkle_proof"`
+	RangeProof  string `json:"range_proof,omitempty"`
+	Signer      string `json:"signer,omitempty"`
+	Signature   string `json:"signature,omitempty"`
 }
 
 func NewTransaction(commitment, merkleRoot, merkleProof string) *Transaction {
@@ -20,12 +31,39 @@ func NewTransaction(commitment, merkleRoot, merkleProof string) *Transaction {
 	}
 }
 
-func (t *Transaction) KeyVal() (string, []byte, error) {
-	txJSON, err := json.Marshal(t)
+// encode returns the canonical encoding of the transaction with the given signature.
+func (t *Transaction) encode(signature string) []byte {
+	return encodeFields(typeTag, t.Commitment, t.Asset, t.Scheme, t.MerkleRoot, t.MerkleProof, t.RangeProof, t.Signer, signature)
+}
+
+// MarshalBinary mirrors the canonical encoding of the on-chain transaction in internal/codec.
+func (t *Transaction) MarshalBinary() ([]byte, error) {
+	return t.encode(t.Signature), nil
+}
+
+func (t *Transaction) UnmarshalBinary(data []byte) error {
+	fields, err := decodeFields(data, typeTag, 8)
 	if err != nil {
-		return "", nil, err
+		return err
+	}
+	*t = Transaction{
+		Commitment:  fields[0],
+		Asset:       fields[1],
+		Scheme:      fields[2],
+		MerkleRoot:  fields[3],
+		MerkleProof: fields[4],
+		RangeProof:  fields[5],
+		Signer:      fields[6],
+		Signature:   fields[7],
 	}
+	return nil
+}
+
+// KeyVal returns the content ID of the transaction and its canonical encoding.
+func (t *Transaction) KeyVal() (string, []byte, error) {
 	sha256Func := sha256.New()
-	sha256Func.Write(txJSON)
-	return hex.EncodeToString(sha256Func.Sum(nil)), txJSON, nil
+	sha256Func.Write([]byte{byte(len(txIDDomain))})
+	sha256Func.Write([]byte(txIDDomain))
+	sha256Func.Write(t.encode(""))
+	return hex.EncodeToString(sha256Func.Sum(nil)), t.encode(t.Signature), nil
 }
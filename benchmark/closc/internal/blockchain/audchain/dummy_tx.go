@@ -8,6 +8,7 @@ import (
 	"go.dedis.ch/kyber/v3/group/edwards25519"
 
 	"github.com/auti-project/auti/internal/closc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
 )
 
 var (
@@ -16,6 +17,17 @@ var (
 	hashByteLen = 32
 )
 
+// dummySigningKey signs the dummy on-chain transactions, the chaincode rejects unsigned transactions
+var dummySigningKey, _ = crypto.SigningKeyGen(crypto.RandomStream())
+
+var (
+	// dummySigner is the hex encoded public key of dummySigningKey, the signer enrolled on the chaincode
+	dummySigner, _ = crypto.SignerHex(dummySigningKey)
+	// registrarKey enrolls dummySigner, it is derived from a fixed seed
+	// so the benchmark processes running against the same network pin the same registrar
+	registrarKey, _ = crypto.SigningKeyGen(crypto.NewSeededStream([]byte("auti-benchmark-registrar")))
+)
+
 func DummyOnChainTransactions(numTXs int) []*transaction.AudOnChain {
 	results := make([]*transaction.AudOnChain, numTXs)
 	var wg sync.WaitGroup
@@ -52,5 +64,9 @@ func DummyOnChainTransaction() (*transaction.AudOnChain, error) {
 	if err != nil {
 		return nil, err
 	}
-	return tx.ToOnChain(), nil
+	onChainTX := tx.ToOnChain()
+	if err = onChainTX.Sign(dummySigningKey, crypto.RandomStream()); err != nil {
+		return nil, err
+	}
+	return onChainTX, nil
 }
@@ -19,6 +19,17 @@ const (
 
 var numCPUs = runtime.NumCPU()
 
+// dummySigningKey signs the dummy on-chain transactions, the chaincode rejects unsigned transactions
+var dummySigningKey, _ = crypto.SigningKeyGen(crypto.RandomStream())
+
+var (
+	// dummySigner is the hex encoded public key of dummySigningKey, the signer enrolled on the chaincode
+	dummySigner, _ = crypto.SignerHex(dummySigningKey)
+	// registrarKey enrolls dummySigner, it is derived from a fixed seed
+	// so the benchmark processes running against the same network pin the same registrar
+	registrarKey, _ = crypto.SigningKeyGen(crypto.NewSeededStream([]byte("auti-benchmark-registrar")))
+)
+
 func DummyOnChainTransactions(numTXs int) []*transaction.LocalOnChain {
 	results := make([]*transaction.LocalOnChain, numTXs)
 	var wg sync.WaitGroup
@@ -49,7 +60,11 @@ func DummyOnChainTransaction() (*transaction.LocalOnChain, error) {
 	if err != nil {
 		return nil, err
 	}
-	return plainTX.ToOnChain(), nil
+	onChainTX := plainTX.ToOnChain()
+	if err = onChainTX.Sign(dummySigningKey, crypto.RandomStream()); err != nil {
+		return nil, err
+	}
+	return onChainTX, nil
 }
 
 func DummyPlainTransactions(numTXs int) []*transaction.LocalPlain {
@@ -11,6 +11,17 @@ import (
 
 var numCPUs = runtime.NumCPU()
 
+// dummySigningKey signs the dummy on-chain transactions, the chaincode rejects unsigned transactions
+var dummySigningKey, _ = crypto.SigningKeyGen(crypto.RandomStream())
+
+var (
+	// dummySigner is the hex encoded public key of dummySigningKey, the signer enrolled on the chaincode
+	dummySigner, _ = crypto.SignerHex(dummySigningKey)
+	// registrarKey enrolls dummySigner, it is derived from a fixed seed
+	// so the benchmark processes running against the same network pin the same registrar
+	registrarKey, _ = crypto.SigningKeyGen(crypto.NewSeededStream([]byte("auti-benchmark-registrar")))
+)
+
 func DummyCommitmentOnChainTransactions(numTXs int) []*transaction.LocalCommitmentOnChain {
 	results := make([]*transaction.LocalCommitmentOnChain, numTXs)
 	var wg sync.WaitGroup
@@ -41,7 +52,11 @@ func DummyCommitmentOnChainTransaction() (*transaction.LocalCommitmentOnChain, e
 	if err != nil {
 		return nil, err
 	}
-	return plainTX.ToOnChain(), nil
+	onChainTX := plainTX.ToOnChain()
+	if err = onChainTX.Sign(dummySigningKey, crypto.RandomStream()); err != nil {
+		return nil, err
+	}
+	return onChainTX, nil
 }
 
 func DummyCommitmentPlainTransactions(numTXs int) []*transaction.LocalCommitmentPlain {
@@ -15,6 +15,17 @@ const (
 	proofDepth  = 5
 )
 
+// dummySigningKey signs the dummy on-chain transactions, the chaincode rejects unsigned transactions
+var dummySigningKey, _ = crypto.SigningKeyGen(crypto.RandomStream())
+
+var (
+	// dummySigner is the hex encoded public key of dummySigningKey, the signer enrolled on the chaincode
+	dummySigner, _ = crypto.SignerHex(dummySigningKey)
+	// registrarKey enrolls dummySigner, it is derived from a fixed seed
+	// so the benchmark processes running against the same network pin the same registrar
+	registrarKey, _ = crypto.SigningKeyGen(crypto.NewSeededStream([]byte("auti-benchmark-registrar")))
+)
+
 func DummyOnChainTransaction() (*transaction.LocalOnChain, error) {
 	dummyCounterPartyBytes := make([]byte, 32)
 	_, err := crand.Read(dummyCounterPartyBytes)
@@ -25,7 +36,11 @@ func DummyOnChainTransaction() (*transaction.LocalOnChain, error) {
 	if err != nil {
 		return nil, err
 	}
-	return plainTX.ToOnChain(), nil
+	onChainTX := plainTX.ToOnChain()
+	if err = onChainTX.Sign(dummySigningKey, crypto.RandomStream()); err != nil {
+		return nil, err
+	}
+	return onChainTX, nil
 }
 
 func DummyPlainTransaction() (*transaction.LocalPlain, error) {
@@ -13,6 +13,17 @@ var (
 	numCPUs = runtime.NumCPU()
 )
 
+// dummySigningKey signs the dummy on-chain transactions, the chaincode rejects unsigned transactions
+var dummySigningKey, _ = crypto.SigningKeyGen(crypto.RandomStream())
+
+var (
+	// dummySigner is the hex encoded public key of dummySigningKey, the signer enrolled on the chaincode
+	dummySigner, _ = crypto.SignerHex(dummySigningKey)
+	// registrarKey enrolls dummySigner, it is derived from a fixed seed
+	// so the benchmark processes running against the same network pin the same registrar
+	registrarKey, _ = crypto.SigningKeyGen(crypto.NewSeededStream([]byte("auti-benchmark-registrar")))
+)
+
 func DummyOnChainTransactions(numTXs int) []*transaction.OrgOnChain {
 	results := make([]*transaction.OrgOnChain, numTXs)
 	var wg sync.WaitGroup
@@ -34,7 +45,7 @@ func DummyOnChainTransactions(numTXs int) []*transaction.OrgOnChain {
 }
 
 func DummyOnChainTransaction() (*transaction.OrgOnChain, error) {
-	randID, err := crypto.RandBytes()
+	randID, err := crypto.RandBytes(crypto.RandomStream())
 	if err != nil {
 		return nil, err
 	}
@@ -51,5 +62,9 @@ func DummyOnChainTransaction() (*transaction.OrgOnChain, error) {
 		return nil, err
 	}
 	tx := transaction.NewOrgPlain(accumulatorBytes)
-	return tx.ToOnChain(), nil
+	onChainTX := tx.ToOnChain()
+	if err = onChainTX.Sign(dummySigningKey, crypto.RandomStream()); err != nil {
+		return nil, err
+	}
+	return onChainTX, nil
 }
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"crypto/cipher"
 	"encoding/hex"
 	"fmt"
 	"math/rand"
@@ -64,7 +65,7 @@ func genDummyLocalOnChainTX(treeDepth int) (txList []transaction.LocalOnChain, e
 	return txList, nil
 }
 
-func CEMerkleProofVerify(treeDepth, iterations int) error {
+func CEMerkleProofVerify(randStream cipher.Stream, treeDepth, iterations int) error {
 	fmt.Println("[CLOLC-CE] Merkle Proof Verify")
 	fmt.Printf("Tree depth: %d, Num iter: %d\n", treeDepth, iterations)
 	txList, err := genDummyLocalOnChainTX(treeDepth)
@@ -72,7 +73,7 @@ func CEMerkleProofVerify(treeDepth, iterations int) error {
 		return err
 	}
 	numTXs := 1 << treeDepth
-	aud := auditor.New("aud", nil)
+	aud := auditor.NewWithRandStream("aud", nil, randStream)
 	for i := 0; i < iterations; i++ {
 		randIdx := rand.Int() % numTXs
 		startTime := time.Now()
@@ -110,7 +111,7 @@ func randIndexes(numIdx, max int) []int {
 	return pool[:numIdx]
 }
 
-func CEMerkleProofMerge(numTXs, iterations int) error {
+func CEMerkleProofMerge(randStream cipher.Stream, numTXs, iterations int) error {
 	fmt.Println("[CLOLC-CE] Merkle Proof Merge")
 	fmt.Printf("Num TXs: %d, Num iter: %d\n", numTXs, iterations)
 	numTotalTXs := 1 << mergeTreeDepth
@@ -118,7 +119,7 @@ func CEMerkleProofMerge(numTXs, iterations int) error {
 	if err != nil {
 		return err
 	}
-	aud := auditor.New("aud", nil)
+	aud := auditor.NewWithRandStream("aud", nil, randStream)
 	for i := 0; i < iterations; i++ {
 		indexes := randIndexes(numTXs, numTotalTXs)
 		selectedBlocks := make([]mt.DataBlock, numTXs)
@@ -139,7 +140,7 @@ func CEMerkleProofMerge(numTXs, iterations int) error {
 	return nil
 }
 
-func CESummarizeMerkleProofVerificationResults(numResults, iterations int) error {
+func CESummarizeMerkleProofVerificationResults(randStream cipher.Stream, numResults, iterations int) error {
 	fmt.Println("[CLOLC-CE] Summarize Merkle Proof Verification Results")
 	fmt.Printf("Num results: %d, Num iter: %d\n", numResults, iterations)
 	results := make([]uint, numResults)
@@ -147,7 +148,7 @@ func CESummarizeMerkleProofVerificationResults(numResults, iterations int) error
 		for j := 0; j < numResults; j++ {
 			results[j] = uint(rand.Int() % 2)
 		}
-		aud := auditor.New("aud", nil)
+		aud := auditor.NewWithRandStream("aud", nil, randStream)
 		startTime := time.Now()
 		aud.SummarizeMerkleProofVerificationResults(results)
 		elapsed := time.Since(startTime)
@@ -157,10 +158,10 @@ func CESummarizeMerkleProofVerificationResults(numResults, iterations int) error
 	return nil
 }
 
-func CEVerifyCommitments(numCommitments, iterations int) error {
+func CEVerifyCommitments(randStream cipher.Stream, numCommitments, iterations int) error {
 	fmt.Println("[CLOLC-CE] Verify Commitments")
 	fmt.Printf("Num commitments: %d, Num iter: %d\n", numCommitments, iterations)
-	aud := auditor.New("aud", nil)
+	aud := auditor.NewWithRandStream("aud", nil, randStream)
 	for i := 0; i < iterations; i++ {
 		commitments1 := make([][]byte, numCommitments)
 		commitments2 := make([][]byte, numCommitments)
@@ -173,8 +174,8 @@ func CEVerifyCommitments(numCommitments, iterations int) error {
 			go func(idx, step int) {
 				defer wg.Done()
 				for j := idx; j < numCommitments; j += step {
-					randPoint1 := crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-					randPoint2 := crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+					randPoint1 := crypto.KyberSuite.Point().Pick(randStream)
+					randPoint2 := crypto.KyberSuite.Point().Pick(randStream)
 					commitments1[j], err = randPoint1.MarshalBinary()
 					if err != nil {
 						panic(err)
@@ -183,8 +184,8 @@ func CEVerifyCommitments(numCommitments, iterations int) error {
 					if err != nil {
 						panic(err)
 					}
-					hashPoints1[j] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
-					hashPoints2[j] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+					hashPoints1[j] = crypto.KyberSuite.Point().Pick(randStream)
+					hashPoints2[j] = crypto.KyberSuite.Point().Pick(randStream)
 				}
 			}(i, numCPU)
 		}
@@ -200,11 +201,11 @@ func CEVerifyCommitments(numCommitments, iterations int) error {
 	return nil
 }
 
-func CEAccumulateCommitments(numCommitments, iterations int) error {
+func CEAccumulateCommitments(randStream cipher.Stream, numCommitments, iterations int) error {
 	fmt.Println("[CLOLC-CE] Accumulate Commitments")
 	fmt.Printf("Num commitments: %d, Num iter: %d\n", numCommitments, iterations)
-	aud := auditor.New("aud", nil)
-	aud.EpochID = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+	aud := auditor.NewWithRandStream("aud", nil, randStream)
+	aud.EpochID = crypto.KyberSuite.Point().Pick(randStream)
 	for i := 0; i < iterations; i++ {
 		dummyCommitments := make([]kyber.Point, numCommitments)
 		var wg sync.WaitGroup
@@ -213,7 +214,7 @@ func CEAccumulateCommitments(numCommitments, iterations int) error {
 			go func(idx, step int) {
 				defer wg.Done()
 				for j := idx; j < numCommitments; j += step {
-					randPoint := crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+					randPoint := crypto.KyberSuite.Point().Pick(randStream)
 					dummyCommitments[j] = randPoint
 				}
 			}(i, numCPU)
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"crypto/cipher"
 	"fmt"
 	"time"
 
@@ -13,24 +14,28 @@ import (
 	closcorg "github.com/auti-project/auti/internal/closc/organization"
 )
 
-func generateEntities(numOrganizations int) (*closccom.Committee, []*closcaud.Auditor, []*closcorg.Organization) {
+func generateEntities(
+	randStream cipher.Stream, numOrganizations int,
+) (*closccom.Committee, []*closcaud.Auditor, []*closcorg.Organization) {
 	organizations := make([]*closcorg.Organization, numOrganizations)
 	for i := 0; i < numOrganizations; i++ {
-		organizations[i] = closcorg.New("org" + string(rune(i)))
+		organizations[i] = closcorg.NewWithRandStream("org"+string(rune(i)), randStream)
 	}
 	auditors := make([]*closcaud.Auditor, numOrganizations)
 	for i := 0; i < numOrganizations; i++ {
-		auditors[i] = closcaud.New("aud"+string(rune(i)), []*closcorg.Organization{organizations[i]})
+		auditors[i] = closcaud.NewWithRandStream(
+			"aud"+string(rune(i)), []*closcorg.Organization{organizations[i]}, randStream,
+		)
 	}
-	com := closccom.New("com", auditors)
+	com := closccom.NewWithRandStream("com", auditors, randStream)
 	return com, auditors, organizations
 }
 
-func INEpoch(numOrganizations, iterations int) error {
+func INEpoch(randStream cipher.Stream, numOrganizations, iterations int) error {
 	fmt.Println("[CLOSC-IN] Default")
 	fmt.Printf("Num Org: %d, Num iter: %d\n", numOrganizations, iterations)
 	for i := 0; i < iterations; i++ {
-		com, auditors, _ := generateEntities(numOrganizations)
+		com, auditors, _ := generateEntities(randStream, numOrganizations)
 		startTime := time.Now()
 		err := com.InitializeEpoch(auditors)
 		if err != nil {
@@ -43,14 +48,14 @@ func INEpoch(numOrganizations, iterations int) error {
 	return nil
 }
 
-func INRandGen(num, iterations int) error {
+func INRandGen(randStream cipher.Stream, num, iterations int) error {
 	fmt.Println("[CLOSC-IN] Random generation")
 	fmt.Printf("Num: %d, Num iter: %d\n", num, iterations)
 	for i := 0; i < iterations; i++ {
 		startTime := time.Now()
 		epochIDs := make([]kyber.Point, num)
 		for j := 0; j < num; j++ {
-			epochIDs[j] = committee.GenerateAuditorEpochID()
+			epochIDs[j] = committee.GenerateAuditorEpochID(randStream)
 		}
 		elapsed := time.Since(startTime)
 		timecounter.Print(elapsed)
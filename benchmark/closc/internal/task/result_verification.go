@@ -1,6 +1,7 @@
 package task
 
 import (
+	"crypto/cipher"
 	"fmt"
 	"math/rand"
 	"sync"
@@ -15,7 +16,7 @@ import (
 	"github.com/auti-project/auti/internal/crypto"
 )
 
-func RVVerifyMerkleBatchProof(numTXs, iterations int) error {
+func RVVerifyMerkleBatchProof(randStream cipher.Stream, numTXs, iterations int) error {
 	fmt.Println("[CLOSC-RV] Verify merkle batch proof")
 	fmt.Printf("Num TXs: %d, Num iter: %d\n", numTXs, iterations)
 	numTotalTXs := 1 << mergeTreeDepth
@@ -23,8 +24,8 @@ func RVVerifyMerkleBatchProof(numTXs, iterations int) error {
 	if err != nil {
 		return err
 	}
-	aud := auditor.New("aud", nil)
-	com := committee.New("com", nil)
+	aud := auditor.NewWithRandStream("aud", nil, randStream)
+	com := committee.NewWithRandStream("com", nil, randStream)
 	for i := 0; i < iterations; i++ {
 		indexes := randIndexes(numTXs, numTotalTXs)
 		selectedBlocks := make([]mt.DataBlock, numTXs)
@@ -56,7 +57,7 @@ func RVVerifyMerkleBatchProof(numTXs, iterations int) error {
 	return nil
 }
 
-func RVSummarizeMerkleBatchProofVerificationResults(numResults, iterations int) error {
+func RVSummarizeMerkleBatchProofVerificationResults(randStream cipher.Stream, numResults, iterations int) error {
 	fmt.Println("[CLOSC-RV] Summarize merkle batch proof verification results")
 	fmt.Printf("Num results: %d, Num iter: %d\n", numResults, iterations)
 	results := make([]uint, numResults)
@@ -64,7 +65,7 @@ func RVSummarizeMerkleBatchProofVerificationResults(numResults, iterations int)
 		for j := 0; j < numResults; j++ {
 			results[j] = uint(rand.Int() % 2)
 		}
-		com := committee.New("com", nil)
+		com := committee.NewWithRandStream("com", nil, randStream)
 		startTime := time.Now()
 		com.SummarizeMerkleBatchProofVerificationResults(results)
 		elapsed := time.Since(startTime)
@@ -74,7 +75,7 @@ func RVSummarizeMerkleBatchProofVerificationResults(numResults, iterations int)
 	return nil
 }
 
-func genDummyPoints(num int) []kyber.Point {
+func genDummyPoints(randStream cipher.Stream, num int) []kyber.Point {
 	results := make([]kyber.Point, num)
 	var wg sync.WaitGroup
 	for i := 0; i < numCPU; i++ {
@@ -82,7 +83,7 @@ func genDummyPoints(num int) []kyber.Point {
 		go func(idx, step int) {
 			defer wg.Done()
 			for j := idx; j < num; j += step {
-				results[j] = crypto.KyberSuite.Point().Pick(crypto.KyberSuite.RandomStream())
+				results[j] = crypto.KyberSuite.Point().Pick(randStream)
 			}
 		}(i, numCPU)
 	}
@@ -90,12 +91,12 @@ func genDummyPoints(num int) []kyber.Point {
 	return results
 }
 
-func RVVerifyCommitments(numCommitments, iterations int) error {
+func RVVerifyCommitments(randStream cipher.Stream, numCommitments, iterations int) error {
 	fmt.Println("[CLOSC-RV] Verify commitment")
 	fmt.Printf("Num commitments: %d, Num iter: %d\n", numCommitments, iterations)
 	for i := 0; i < iterations; i++ {
-		commitments := genDummyPoints(numCommitments)
-		com := committee.New("com", nil)
+		commitments := genDummyPoints(randStream, numCommitments)
+		com := committee.NewWithRandStream("com", nil, randStream)
 		startTime := time.Now()
 		com.VerifyCommitment(commitments)
 		elapsed := time.Since(startTime)
@@ -1,6 +1,7 @@
 package task
 
 import (
+	"crypto/cipher"
 	crand "crypto/rand"
 	"fmt"
 	"math/rand"
@@ -56,7 +57,7 @@ func generateRandInputs(num int) []randInput {
 	return results
 }
 
-func TRCommitment(num, iterations int) error {
+func TRCommitment(randStream cipher.Stream, num, iterations int) error {
 	fmt.Println("[CLOSC-TR] Commitment")
 	fmt.Printf("Num: %d, Num iter: %d\n", num, iterations)
 	for i := 0; i < iterations; i++ {
@@ -64,6 +65,8 @@ func TRCommitment(num, iterations int) error {
 		startTime := time.Now()
 		for j := 0; j < num; j++ {
 			if _, _, err := crypto.PedersonCommitWithHash(
+				crypto.DefaultCommitmentScheme,
+				"USD",
 				randInputs[j].amount,
 				randInputs[j].timestamp,
 				randInputs[j].receiverHash,
@@ -6,6 +6,7 @@ import (
 
 	. "github.com/auti-project/auti/benchmark/closc/internal/flag"
 	"github.com/auti-project/auti/benchmark/closc/internal/task"
+	"github.com/auti-project/auti/internal/crypto"
 )
 
 func main() {
@@ -14,21 +15,26 @@ func main() {
 	numOrgPtr := flag.Int("numOrg", 2, "Number of organizations")
 	numIterPtr := flag.Int("numIter", 10, "Number of iterations")
 	numPtr := flag.Int("num", 100, "Number/Quantity/Depth/Number of SC")
+	seedPtr := flag.String("seed", "", "Seed of the entities and the values drawn by the tasks, fresh randomness if empty")
 	flag.Parse()
+	randStream := crypto.RandomStream()
+	if *seedPtr != "" {
+		randStream = crypto.NewSeededStream([]byte(*seedPtr))
+	}
 
 	var err error
 	switch *benchPhasePtr {
 	case PhaseInitialization:
 		switch *benchProcessPtr {
 		case ProcessINDefault:
-			err = task.INEpoch(*numOrgPtr, *numIterPtr)
+			err = task.INEpoch(randStream, *numOrgPtr, *numIterPtr)
 		case ProcessINRandGen:
-			err = task.INRandGen(*numPtr, *numIterPtr)
+			err = task.INRandGen(randStream, *numPtr, *numIterPtr)
 		}
 	case PhaseTransactionRecord:
 		switch *benchProcessPtr {
 		case ProcessTRCommitment:
-			err = task.TRCommitment(*numPtr, *numIterPtr)
+			err = task.TRCommitment(randStream, *numPtr, *numIterPtr)
 		case ProcessTRMerkleProofGen:
 			err = task.TRMerkleProofGen(*numPtr, *numIterPtr)
 		case ProcessTRLocalChainSubmit:
@@ -61,15 +67,15 @@ func main() {
 	case PhaseConsistencyExamination:
 		switch *benchProcessPtr {
 		case ProcessCEMerkleProofVerify:
-			err = task.CEMerkleProofVerify(*numPtr, *numIterPtr)
+			err = task.CEMerkleProofVerify(randStream, *numPtr, *numIterPtr)
 		case ProcessCEMerkleProofMerge:
-			err = task.CEMerkleProofMerge(*numPtr, *numIterPtr)
+			err = task.CEMerkleProofMerge(randStream, *numPtr, *numIterPtr)
 		case ProcessCESummarizeMerkleProofVerificationResults:
-			err = task.CESummarizeMerkleProofVerificationResults(*numPtr, *numIterPtr)
+			err = task.CESummarizeMerkleProofVerificationResults(randStream, *numPtr, *numIterPtr)
 		case ProcessCEVerifyCommitments:
-			err = task.CEVerifyCommitments(*numPtr, *numIterPtr)
+			err = task.CEVerifyCommitments(randStream, *numPtr, *numIterPtr)
 		case ProcessCEAccumulateCommitments:
-			err = task.CEAccumulateCommitments(*numPtr, *numIterPtr)
+			err = task.CEAccumulateCommitments(randStream, *numPtr, *numIterPtr)
 		case ProcessCEAudChainSubmit:
 			err = task.CEAudSubmitTX(*numPtr, *numIterPtr)
 		case ProcessCEAudChainPrepare:
@@ -82,11 +88,11 @@ func main() {
 	case PhaseResultVerification:
 		switch *benchProcessPtr {
 		case ProcessRVVerifyMerkleBatchProof:
-			err = task.RVVerifyMerkleBatchProof(*numPtr, *numIterPtr)
+			err = task.RVVerifyMerkleBatchProof(randStream, *numPtr, *numIterPtr)
 		case ProcessRVSummarizeMerkleBatchProofVerificationResults:
-			err = task.RVSummarizeMerkleBatchProofVerificationResults(*numPtr, *numIterPtr)
+			err = task.RVSummarizeMerkleBatchProofVerificationResults(randStream, *numPtr, *numIterPtr)
 		case ProcessRVVerifyCommitments:
-			err = task.RVVerifyCommitments(*numPtr, *numIterPtr)
+			err = task.RVVerifyCommitments(randStream, *numPtr, *numIterPtr)
 		}
 	default:
 		log.Fatalf("Error: %v", "Invalid phase")
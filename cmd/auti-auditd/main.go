@@ -0,0 +1,131 @@
+// Command auti-auditd runs the daemon of a CLOLC auditor. It registers with the committee service,
+// takes the submissions of the audited organizations and submits the examination results to the auditor chain.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/auti-project/auti/internal/clolc/auditd"
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/config"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/service"
+	"github.com/auti-project/auti/internal/clolc/service/client"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/ledger"
+)
+
+const defaultInterval = 30 * time.Second
+
+func main() {
+	configPathPtr := flag.String("config", "auditd.json", "Path of the configuration file")
+	genKeyPtr := flag.Bool("genkey", false, "Write a new signing key to the file of the configuration and print its public key")
+	flag.Parse()
+	var cfg config.AuditorDaemon
+	if err := config.Load(*configPathPtr, &cfg); err != nil {
+		log.Fatal(err)
+	}
+	if *genKeyPtr {
+		publicKey, err := config.GenerateSigningKey(cfg.SigningKeyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(publicKey)
+		return
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, &cfg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, cfg *config.AuditorDaemon) error {
+	signingKey, err := config.LoadSigningKey(cfg.SigningKeyFile)
+	if err != nil {
+		return err
+	}
+	committeePublicKey, err := cfg.Committee.SigningPublicKey()
+	if err != nil {
+		return err
+	}
+	aud := auditor.New(cfg.ID, nil)
+	aud.SetSigningKey(signingKey)
+	aud.LegacyCutoff = cfg.LegacyCutoff
+	for _, org := range cfg.Organizations {
+		aud.AuditedOrgIDs = append(aud.AuditedOrgIDs, organization.TypeID(org.ID))
+	}
+	committeeClient := client.ForAuditor(cfg.Committee.URL, aud, committeePublicKey)
+	if err = committeeClient.Register(ctx, service.RoleAuditor); err != nil {
+		return err
+	}
+
+	orgChain, err := config.Open(cfg.OrgChain, func() ledger.Ledger[*transaction.OrgOnChain] {
+		return organization.NewMemoryOrgChain()
+	})
+	if err != nil {
+		return err
+	}
+	defer orgChain.Close()
+	audChain, err := config.Open(cfg.AudChain, func() ledger.Ledger[*transaction.AudOnChain] {
+		return auditor.NewMemoryAudChain()
+	})
+	if err != nil {
+		return err
+	}
+	defer audChain.Close()
+	daemon := auditd.New(aud, committeeClient, orgChain, audChain)
+	for _, org := range cfg.Organizations {
+		publicKey, err := org.SigningPublicKey()
+		if err != nil {
+			return err
+		}
+		localChain, err := config.Open(org.LocalChain, func() ledger.Ledger[*transaction.LocalOnChain] {
+			return organization.NewMemoryLocalChain()
+		})
+		if err != nil {
+			return err
+		}
+		defer localChain.Close()
+		if err = daemon.AddOrganization(organization.TypeID(org.ID), publicKey, localChain); err != nil {
+			return err
+		}
+	}
+	interval := cfg.Interval.Duration
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	daemonErr := make(chan error, 1)
+	go func() {
+		daemonErr <- daemon.Run(ctx, interval)
+		// the server does not outlive the daemon
+		cancel()
+	}()
+
+	httpServer := &http.Server{Addr: cfg.Listen, Handler: daemon, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+	log.Printf("auditor %s listening on %s", cfg.ID, cfg.Listen)
+	if err = httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	if err = <-daemonErr; !errors.Is(err, context.Canceled) {
+		return fmt.Errorf("daemon: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Command auti-committee runs the CLOLC committee service. It opens the epochs of the schedule,
+// keeps their secrets in the key store, and registers only the auditors and organizations of the configuration.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/committee"
+	"github.com/auti-project/auti/internal/clolc/config"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/service"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/keystore"
+)
+
+const defaultInterval = time.Minute
+
+func main() {
+	configPathPtr := flag.String("config", "committee.json", "Path of the configuration file")
+	genKeyPtr := flag.Bool("genkey", false, "Write a new signing key to the file of the configuration and print its public key")
+	flag.Parse()
+	var cfg config.Committee
+	if err := config.Load(*configPathPtr, &cfg); err != nil {
+		log.Fatal(err)
+	}
+	if *genKeyPtr {
+		publicKey, err := config.GenerateSigningKey(cfg.SigningKeyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(publicKey)
+		return
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, &cfg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, cfg *config.Committee) error {
+	signingKey, err := config.LoadSigningKey(cfg.SigningKeyFile)
+	if err != nil {
+		return err
+	}
+	schedule, err := epoch.NewSchedule(cfg.Genesis, cfg.EpochDuration)
+	if err != nil {
+		return err
+	}
+	passphrase := os.Getenv(cfg.KeyStorePassphraseEnv)
+	store, err := keystore.Open(cfg.KeyStoreDir, []byte(passphrase))
+	if err != nil {
+		return err
+	}
+	// the committee only reads the IDs of the managed auditors and organizations
+	auditors := make([]*auditor.Auditor, len(cfg.Auditors))
+	enrollments := make(map[string]*service.Enrollment)
+	for idx, audPeer := range cfg.Auditors {
+		publicKey, err := audPeer.SigningPublicKey()
+		if err != nil {
+			return err
+		}
+		enrollments[audPeer.ID] = &service.Enrollment{Role: service.RoleAuditor, PublicKey: publicKey}
+		auditors[idx] = &auditor.Auditor{ID: auditor.TypeID(audPeer.ID)}
+		for _, orgPeer := range audPeer.Organizations {
+			if publicKey, err = orgPeer.SigningPublicKey(); err != nil {
+				return err
+			}
+			enrollments[orgPeer.ID] = &service.Enrollment{Role: service.RoleOrganization, PublicKey: publicKey}
+			auditors[idx].AuditedOrgIDs = append(auditors[idx].AuditedOrgIDs, organization.TypeID(orgPeer.ID))
+		}
+	}
+	com := committee.New(cfg.ID, auditors)
+	com.SetSigningKey(signingKey)
+	com.LegacyCutoff = cfg.LegacyCutoff
+	if err = restore(com, store, cfg.ID); err != nil {
+		return err
+	}
+	server := service.NewServer(com, enrollments)
+	interval := cfg.Interval.Duration
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	go runEpochs(ctx, server, store, cfg, schedule, interval)
+
+	httpServer := &http.Server{Addr: cfg.Listen, Handler: server, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = httpServer.Shutdown(shutdownCtx)
+	}()
+	log.Printf("committee %s listening on %s", cfg.ID, cfg.Listen)
+	if err = httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// restore archives the epoch secrets of the key store, the latest epoch becomes the current one.
+func restore(com *committee.Committee, store *keystore.Store, holder string) error {
+	epochIDs, err := store.Epochs(holder)
+	if err != nil {
+		return err
+	}
+	for _, epochID := range epochIDs {
+		secrets, err := store.Get(holder, epochID)
+		if err != nil {
+			return err
+		}
+		if err = com.RestoreEpochSecrets(secrets); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runEpochs opens the epochs of the schedule every interval until the context is done.
+// The secrets of the current epoch are written again every time to keep the edges added in the middle of the epoch,
+// and the secrets past their retention are pruned.
+func runEpochs(
+	ctx context.Context, server *service.Server, store *keystore.Store, cfg *config.Committee,
+	schedule *epoch.Schedule, interval time.Duration,
+) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := stepEpoch(server, store, cfg, schedule); err != nil {
+			log.Printf("epoch: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func stepEpoch(server *service.Server, store *keystore.Store, cfg *config.Committee, schedule *epoch.Schedule) error {
+	now := time.Now().Unix()
+	e, err := server.OpenScheduledEpoch(schedule, now)
+	if err != nil {
+		return err
+	}
+	secrets, err := server.ExportEpochSecrets(e.ID, e.EndTime+int64(cfg.Retention.Seconds()))
+	if err != nil {
+		return err
+	}
+	if err = store.Put(cfg.ID, secrets); err != nil {
+		return err
+	}
+	_, err = store.Prune(now)
+	return err
+}
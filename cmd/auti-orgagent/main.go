@@ -0,0 +1,97 @@
+// Command auti-orgagent runs the agent of a CLOLC organization. It registers with the committee service,
+// records the transfers of the feed files dropped in the feed directory on the local chain,
+// and posts the accumulators and the submissions for the auditor once an epoch ends.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/auti-project/auti/internal/clolc/auditd"
+	"github.com/auti-project/auti/internal/clolc/config"
+	"github.com/auti-project/auti/internal/clolc/orgagent"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/service"
+	"github.com/auti-project/auti/internal/clolc/service/client"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/ledger"
+)
+
+const defaultInterval = 10 * time.Second
+
+func main() {
+	configPathPtr := flag.String("config", "orgagent.json", "Path of the configuration file")
+	genKeyPtr := flag.Bool("genkey", false, "Write a new signing key to the file of the configuration and print its public key")
+	flag.Parse()
+	var cfg config.OrgAgent
+	if err := config.Load(*configPathPtr, &cfg); err != nil {
+		log.Fatal(err)
+	}
+	if *genKeyPtr {
+		publicKey, err := config.GenerateSigningKey(cfg.SigningKeyFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(publicKey)
+		return
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := run(ctx, &cfg); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, cfg *config.OrgAgent) error {
+	signingKey, err := config.LoadSigningKey(cfg.SigningKeyFile)
+	if err != nil {
+		return err
+	}
+	committeePublicKey, err := cfg.Committee.SigningPublicKey()
+	if err != nil {
+		return err
+	}
+	auditorPublicKey, err := cfg.Auditor.SigningPublicKey()
+	if err != nil {
+		return err
+	}
+	localChain, err := config.Open(cfg.LocalChain, func() ledger.Ledger[*transaction.LocalOnChain] {
+		return organization.NewMemoryLocalChain()
+	})
+	if err != nil {
+		return err
+	}
+	defer localChain.Close()
+	orgChain, err := config.Open(cfg.OrgChain, func() ledger.Ledger[*transaction.OrgOnChain] {
+		return organization.NewMemoryOrgChain()
+	})
+	if err != nil {
+		return err
+	}
+	defer orgChain.Close()
+
+	org := organization.New(cfg.ID, localChain)
+	org.SetSigningKey(signingKey)
+	committeeClient := client.ForOrganization(cfg.Committee.URL, org, committeePublicKey)
+	if err = committeeClient.Register(ctx, service.RoleOrganization); err != nil {
+		return err
+	}
+	auditorClient := auditd.NewClient(cfg.Auditor.URL, cfg.ID, org)
+	agent := orgagent.New(org, committeeClient, auditorClient, auditorPublicKey, orgChain)
+	interval := cfg.Interval.Duration
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	log.Printf("organization %s watching %s", cfg.ID, cfg.FeedDir)
+	if err = agent.Run(ctx, cfg.FeedDir, interval); !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
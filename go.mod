@@ -54,6 +54,6 @@ require (
 	github.com/hyperledger/fabric-sdk-go v1.0.0
 	github.com/stretchr/testify v1.8.2 // indirect
 	go.dedis.ch/fixbuf v1.0.3 // indirect
-	golang.org/x/crypto v0.9.0 // indirect
+	golang.org/x/crypto v0.9.0
 	golang.org/x/sys v0.8.0 // indirect
 )
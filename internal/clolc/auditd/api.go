@@ -0,0 +1,123 @@
+package auditd
+
+import (
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"strconv"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+// The endpoints of the auditor daemon, the bodies are JSON.
+const (
+	// PathSubmissions takes the submission of an audited organization, signed like the requests to the committee.
+	PathSubmissions = "/v1/submissions"
+	// PathStatus returns the progress of the examination of the current epoch, it needs no authentication.
+	PathStatus = "/v1/status"
+)
+
+const randomnessSealTag = "auti-auditd-randomness"
+
+// Submission is what an audited organization hands its auditor for one counterparty at the close of an epoch.
+// The local transaction IDs are in the order the transactions were recorded, which is the order of the randomness.
+type Submission struct {
+	EpochID      uint64   `json:"epoch_id"`
+	CounterParty string   `json:"counter_party"`
+	OrgTXID      string   `json:"org_tx_id"`
+	LocalTXIDs   []string `json:"local_tx_ids"`
+	// SealedRandomness is the commitment randomness sealed to the signing public key of the auditor.
+	SealedRandomness string `json:"sealed_randomness"`
+}
+
+// SealRandomness seals the commitment randomness of the submission of the organization to the auditor.
+func (s *Submission) SealRandomness(
+	auditorPublicKey crypto.TypePublicKey, orgID string, randList []kyber.Scalar, rand cipher.Stream,
+) error {
+	var data []byte
+	for _, r := range randList {
+		rBytes, err := r.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		data = append(data, rBytes...)
+	}
+	sealed, err := crypto.Seal(auditorPublicKey, data, s.randomnessAdditionalData(orgID), rand)
+	if err != nil {
+		return err
+	}
+	s.SealedRandomness = hex.EncodeToString(sealed)
+	return nil
+}
+
+// openRandomness decrypts the commitment randomness with the open function of the auditor.
+func (s *Submission) openRandomness(
+	orgID string, open func(sealed, additionalData []byte) ([]byte, error),
+) ([]kyber.Scalar, error) {
+	sealed, err := hex.DecodeString(s.SealedRandomness)
+	if err != nil {
+		return nil, err
+	}
+	data, err := open(sealed, s.randomnessAdditionalData(orgID))
+	if err != nil {
+		return nil, err
+	}
+	scalarLen := crypto.KyberSuite.ScalarLen()
+	if len(data)%scalarLen != 0 {
+		return nil, errors.New("invalid length of the randomness")
+	}
+	randList := make([]kyber.Scalar, len(data)/scalarLen)
+	for idx := range randList {
+		randList[idx] = crypto.KyberSuite.Scalar()
+		if err = randList[idx].UnmarshalBinary(data[idx*scalarLen : (idx+1)*scalarLen]); err != nil {
+			return nil, err
+		}
+	}
+	return randList, nil
+}
+
+// randomnessAdditionalData binds the sealed randomness to the organization, the pair and the epoch.
+func (s *Submission) randomnessAdditionalData(orgID string) []byte {
+	return crypto.SigningMessage(
+		randomnessSealTag, orgID, s.CounterParty, strconv.FormatUint(s.EpochID, 10), s.OrgTXID,
+	)
+}
+
+// The states of an examination.
+const (
+	StatePending  = "pending"
+	StateExamined = "examined"
+	StateFailed   = "failed"
+)
+
+type Status struct {
+	AuditorID    string               `json:"auditor_id"`
+	EpochID      uint64               `json:"epoch_id"`
+	Synced       bool                 `json:"synced"`
+	LastError    string               `json:"last_error,omitempty"`
+	Examinations []*ExaminationStatus `json:"examinations"`
+	PairChecks   []*PairCheckStatus   `json:"pair_checks"`
+}
+
+type ExaminationStatus struct {
+	OrgID        string `json:"org_id"`
+	CounterParty string `json:"counter_party"`
+	NumTXs       int    `json:"num_txs"`
+	State        string `json:"state"`
+	AudTXID      string `json:"aud_tx_id,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// PairCheckStatus is the result of ConsistencyExaminationPartTwo for a pair of organizations both audited
+// by the auditor.
+type PairCheckStatus struct {
+	OrgID1     string `json:"org_id_1"`
+	OrgID2     string `json:"org_id_2"`
+	Consistent bool   `json:"consistent"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
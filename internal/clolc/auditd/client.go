@@ -0,0 +1,88 @@
+package auditd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/auti-project/auti/internal/clolc/service"
+)
+
+const maxResponseBytes = 1 << 20
+
+// Client submits the transactions of an organization to the daemon of its auditor.
+type Client struct {
+	// HTTPClient sends the requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+	baseURL    string
+	orgID      string
+	signer     service.Signer
+}
+
+func NewClient(baseURL, orgID string, signer service.Signer) *Client {
+	return &Client{
+		baseURL: baseURL,
+		orgID:   orgID,
+		signer:  signer,
+	}
+}
+
+// Submit hands the submission to the auditor, seal the randomness with Submission.SealRandomness first.
+func (c *Client) Submit(ctx context.Context, submission *Submission) error {
+	return c.do(ctx, http.MethodPost, PathSubmissions, submission, nil)
+}
+
+func (c *Client) Status(ctx context.Context) (*Status, error) {
+	status := &Status{}
+	if err := c.do(ctx, http.MethodGet, PathStatus, nil, status); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, reqBody, resp any) error {
+	var body []byte
+	if reqBody != nil {
+		var err error
+		if body, err = json.Marshal(reqBody); err != nil {
+			return err
+		}
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if reqBody != nil {
+		if _, err = service.SignRequest(httpReq, c.orgID, body, c.signer); err != nil {
+			return err
+		}
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseBytes))
+	if err != nil {
+		return err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		errResp := &ErrorResponse{}
+		if err = json.Unmarshal(respBody, errResp); err != nil {
+			errResp.Error = string(respBody)
+		}
+		return fmt.Errorf("auditor daemon: %s (status %d)", errResp.Error, httpResp.StatusCode)
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, resp)
+}
@@ -0,0 +1,426 @@
+package auditd
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/service/client"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/ledger"
+)
+
+// auditedOrg is an organization audited by the daemon.
+type auditedOrg struct {
+	signingPublicKey crypto.TypePublicKey
+	localChain       ledger.Ledger[*transaction.LocalOnChain]
+}
+
+// examination is the consistency examination of the transactions of an organization with a counterparty.
+type examination struct {
+	orgID          organization.TypeID
+	counterPartyID organization.TypeID
+	submission     *Submission
+	orgTXRandList  []kyber.Scalar
+	state          string
+	// running is set while the examination reads and writes the ledgers without the lock of the daemon,
+	// the submission cannot be replaced meanwhile
+	running bool
+	err     error
+	audTXID string
+	// kept for the second part of the examination
+	pointRes kyber.Point
+	pointB   kyber.Point
+}
+
+// Daemon runs the consistency examination of an auditor as a separate process.
+// It pulls the epoch parameters from the committee service, takes the submissions of the audited organizations,
+// reads their transactions from the ledgers and submits the results to the auditor chain.
+type Daemon struct {
+	// examineMu serializes the examinations, mu guards the state and is released around the ledger calls
+	examineMu    sync.Mutex
+	mu           sync.Mutex
+	auditor      *auditor.Auditor
+	committee    *client.Client
+	orgChain     ledger.Ledger[*transaction.OrgOnChain]
+	audChain     ledger.Ledger[*transaction.AudOnChain]
+	orgMap       map[organization.TypeID]*auditedOrg
+	epochID      uint64
+	synced       bool
+	lastErr      error
+	publicKeyMap map[string]crypto.TypePublicKey
+	examinations map[[2]organization.TypeID]*examination
+	pairChecks   map[[2]organization.TypeID]bool
+}
+
+// New creates a daemon for the auditor, the committee client must act as the auditor.
+func New(
+	aud *auditor.Auditor,
+	committee *client.Client,
+	orgChain ledger.Ledger[*transaction.OrgOnChain],
+	audChain ledger.Ledger[*transaction.AudOnChain],
+) *Daemon {
+	return &Daemon{
+		auditor:      aud,
+		committee:    committee,
+		orgChain:     orgChain,
+		audChain:     audChain,
+		orgMap:       make(map[organization.TypeID]*auditedOrg),
+		examinations: make(map[[2]organization.TypeID]*examination),
+		pairChecks:   make(map[[2]organization.TypeID]bool),
+	}
+}
+
+// AddOrganization registers the signing public key and the local chain of an audited organization.
+func (d *Daemon) AddOrganization(
+	orgID organization.TypeID, signingPublicKey crypto.TypePublicKey, localChain ledger.Ledger[*transaction.LocalOnChain],
+) error {
+	audited := false
+	for _, id := range d.auditor.AuditedOrgIDs {
+		if id == orgID {
+			audited = true
+			break
+		}
+	}
+	if !audited {
+		return fmt.Errorf("organization %s not audited by %s", orgID, d.auditor.ID)
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.orgMap[orgID] = &auditedOrg{signingPublicKey: signingPublicKey, localChain: localChain}
+	return nil
+}
+
+// Sync pulls the parameters of the current epoch from the committee, the examinations of the previous epoch
+// are dropped once a new epoch starts. The parameters are pulled every time to pick up the edges added
+// in the middle of the epoch.
+func (d *Daemon) Sync(ctx context.Context) error {
+	epochResp, err := d.committee.Epoch(ctx)
+	if err != nil {
+		return err
+	}
+	secrets, err := d.committee.AuditorParameters(ctx)
+	if err != nil {
+		return err
+	}
+	publicKeyMap, err := d.committee.PublicKeys(ctx)
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if uint64(secrets.EpochID) != epochResp.ID {
+		return fmt.Errorf("parameters of epoch %d fetched in epoch %d", secrets.EpochID, epochResp.ID)
+	}
+	if err = d.auditor.RestoreEpochSecrets(secrets); err != nil {
+		return err
+	}
+	if !d.synced || d.epochID != epochResp.ID {
+		d.examinations = make(map[[2]organization.TypeID]*examination)
+		d.pairChecks = make(map[[2]organization.TypeID]bool)
+	}
+	d.epochID = epochResp.ID
+	d.publicKeyMap = publicKeyMap
+	d.synced = true
+	return nil
+}
+
+// Submit queues the submission of the audited organization, a pending or failed submission of the same pair
+// is replaced, an examined one cannot be.
+func (d *Daemon) Submit(orgID organization.TypeID, submission *Submission) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.synced {
+		return fmt.Errorf("daemon not synced with the committee")
+	}
+	if submission.EpochID != d.epochID {
+		return fmt.Errorf("submission for epoch %d in epoch %d", submission.EpochID, d.epochID)
+	}
+	if _, ok := d.orgMap[orgID]; !ok {
+		return fmt.Errorf("organization %s not audited by %s", orgID, d.auditor.ID)
+	}
+	counterPartyID := organization.TypeID(submission.CounterParty)
+	if counterPartyID == "" || counterPartyID == orgID {
+		return fmt.Errorf("invalid counterparty %q", submission.CounterParty)
+	}
+	if len(submission.LocalTXIDs) == 0 {
+		return fmt.Errorf("empty transaction list")
+	}
+	orgTXRandList, err := submission.openRandomness(string(orgID), d.auditor.OpenSealed)
+	if err != nil {
+		return fmt.Errorf("open randomness: %v", err)
+	}
+	if len(orgTXRandList) != len(submission.LocalTXIDs) {
+		return fmt.Errorf("%d randomnesses for %d transactions", len(orgTXRandList), len(submission.LocalTXIDs))
+	}
+	key := [2]organization.TypeID{orgID, counterPartyID}
+	if exam, ok := d.examinations[key]; ok && exam.state == StateExamined {
+		return fmt.Errorf("transactions of %s with %s already examined", orgID, counterPartyID)
+	} else if ok && exam.running {
+		return fmt.Errorf("transactions of %s with %s being examined", orgID, counterPartyID)
+	}
+	d.examinations[key] = &examination{
+		orgID:          orgID,
+		counterPartyID: counterPartyID,
+		submission:     submission,
+		orgTXRandList:  orgTXRandList,
+		state:          StatePending,
+	}
+	return nil
+}
+
+// Examine runs the first part of the consistency examination for the pending submissions, then the second part
+// for the pairs of organizations both audited by the auditor. A failed examination does not stop the others.
+// The ledgers are read and written without holding the lock of the daemon, so the status and the submissions
+// are served meanwhile, the results are dropped if the epoch rolls over.
+func (d *Daemon) Examine() {
+	d.examineMu.Lock()
+	defer d.examineMu.Unlock()
+	d.mu.Lock()
+	var pending []*examination
+	for _, key := range d.sortedKeys() {
+		if exam := d.examinations[key]; exam.state == StatePending {
+			exam.running = true
+			pending = append(pending, exam)
+		}
+	}
+	d.mu.Unlock()
+	for _, exam := range pending {
+		audTXID, err := d.examine(exam)
+		d.mu.Lock()
+		exam.running = false
+		if exam.err = err; err != nil {
+			exam.state = StateFailed
+		} else {
+			exam.state, exam.audTXID = StateExamined, audTXID
+		}
+		d.mu.Unlock()
+	}
+	d.checkPairs()
+}
+
+// checkPairs runs the second part of the consistency examination for the pairs examined on both sides.
+func (d *Daemon) checkPairs() {
+	type pair struct {
+		key          [2]organization.TypeID
+		exam1, exam2 *examination
+		audTXID2     string
+	}
+	d.mu.Lock()
+	var pairs []pair
+	for _, key := range d.sortedKeys() {
+		orgID1, orgID2 := key[0], key[1]
+		if orgID1 > orgID2 {
+			continue
+		}
+		if _, ok := d.pairChecks[key]; ok {
+			continue
+		}
+		exam1 := d.examinations[key]
+		exam2, ok := d.examinations[[2]organization.TypeID{orgID2, orgID1}]
+		if !ok || exam1.state != StateExamined || exam2.state != StateExamined {
+			continue
+		}
+		pairs = append(pairs, pair{key: key, exam1: exam1, exam2: exam2, audTXID2: exam2.audTXID})
+	}
+	d.mu.Unlock()
+	for _, p := range pairs {
+		audTX2, err := d.audChain.ReadTX(p.audTXID2)
+		d.mu.Lock()
+		if d.examinations[p.key] != p.exam1 {
+			// the epoch rolled over
+			d.mu.Unlock()
+			continue
+		}
+		if err == nil {
+			var consistent bool
+			consistent, err = d.auditor.ConsistencyExaminationPartTwo(
+				p.key[1], p.key[0], audTX2, p.exam1.pointRes, p.exam1.pointB,
+			)
+			if err == nil {
+				d.pairChecks[p.key] = consistent
+			}
+		}
+		if err != nil {
+			p.exam2.state, p.exam2.err = StateFailed, err
+		}
+		d.mu.Unlock()
+	}
+}
+
+// examine reads the transactions of the submission from the ledgers, rejects them if a range proof does not verify,
+// runs the first part of the consistency examination and submits the encrypted result to the auditor chain.
+// It returns the ID of the result, the lock of the daemon is only held to compose the result.
+func (d *Daemon) examine(exam *examination) (string, error) {
+	d.mu.Lock()
+	org := d.orgMap[exam.orgID]
+	d.mu.Unlock()
+	counterPartyIDHash := organization.IDHashString(exam.counterPartyID)
+	localTXList := make([]*transaction.LocalHidden, len(exam.submission.LocalTXIDs))
+	for idx, txID := range exam.submission.LocalTXIDs {
+		localOnChainTX, err := org.localChain.ReadTX(txID)
+		if err != nil {
+			return "", err
+		}
+		// the signer field alone can be copied, the signature must verify under the key of the organization
+		valid, err := localOnChainTX.VerifySignature(org.signingPublicKey)
+		if err != nil {
+			return "", err
+		}
+		if !valid {
+			return "", fmt.Errorf("transaction %s not signed by %s", txID, exam.orgID)
+		}
+		if localTXList[idx], err = localOnChainTX.ToHidden(); err != nil {
+			return "", err
+		}
+		if hex.EncodeToString(localTXList[idx].CounterParty) != counterPartyIDHash {
+			return "", fmt.Errorf("transaction %s not with %s", txID, exam.counterPartyID)
+		}
+	}
+	// a commitment out of range could wrap around and fake a zero sum
+	if err := d.auditor.VerifyRangeProofs(localTXList); err != nil {
+		return "", err
+	}
+	orgOnChainTX, err := d.orgChain.ReadTX(exam.submission.OrgTXID)
+	if err != nil {
+		return "", err
+	}
+	// the accumulator must be posted by the organization itself
+	valid, err := orgOnChainTX.VerifySignature(org.signingPublicKey)
+	if err != nil {
+		return "", err
+	}
+	if !valid {
+		return "", fmt.Errorf("transaction %s not signed by %s", exam.submission.OrgTXID, exam.orgID)
+	}
+	orgPlainTX, err := orgOnChainTX.ToPlain()
+	if err != nil {
+		return "", err
+	}
+	d.mu.Lock()
+	audOnChainTX, err := d.composeResult(exam, orgPlainTX, localTXList)
+	d.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	return d.audChain.SubmitTX(audOnChainTX)
+}
+
+// composeResult runs the first part of the consistency examination with the epoch parameters of the auditor
+// and signs the result, d.mu must be held.
+func (d *Daemon) composeResult(
+	exam *examination, orgPlainTX *transaction.OrgPlain, localTXList []*transaction.LocalHidden,
+) (*transaction.AudOnChain, error) {
+	if d.examinations[[2]organization.TypeID{exam.orgID, exam.counterPartyID}] != exam {
+		return nil, fmt.Errorf("epoch %d rolled over during the examination", exam.submission.EpochID)
+	}
+	epochOrgID, ok := d.auditor.EpochOrgID(exam.orgID)
+	if !ok {
+		return nil, fmt.Errorf("no epoch ID of organization %s", exam.orgID)
+	}
+	comTXRandList := d.auditor.GetEpochTXRandomness(exam.orgID, exam.counterPartyID, len(exam.orgTXRandList))
+	if comTXRandList == nil {
+		return nil, fmt.Errorf("no randomness for the pair of %s and %s", exam.orgID, exam.counterPartyID)
+	}
+	audPlainTX, err := d.auditor.ConsistencyExaminationPartOne(
+		exam.orgID, exam.counterPartyID, epochOrgID,
+		orgPlainTX, localTXList, exam.orgTXRandList, comTXRandList, d.publicKeyMap,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if exam.pointRes, err = d.auditor.AccumulateCommitments(exam.orgID, localTXList); err != nil {
+		return nil, err
+	}
+	if exam.pointB, err = d.auditor.ComputeB(
+		exam.orgTXRandList, comTXRandList, auditor.BlindingGenerators(localTXList),
+	); err != nil {
+		return nil, err
+	}
+	audOnChainTX := audPlainTX.ToOnChain()
+	if err = d.auditor.SignTX(audOnChainTX); err != nil {
+		return nil, err
+	}
+	return audOnChainTX, nil
+}
+
+// Run syncs with the committee and examines the pending submissions every interval until the context is done.
+// The errors do not stop the daemon, the last one is reported in the status.
+func (d *Daemon) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := d.Sync(ctx)
+		d.mu.Lock()
+		d.lastErr = err
+		d.mu.Unlock()
+		if err == nil {
+			d.Examine()
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status returns the progress of the examination of the current epoch.
+func (d *Daemon) Status() *Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	status := &Status{
+		AuditorID:    string(d.auditor.ID),
+		EpochID:      d.epochID,
+		Synced:       d.synced,
+		Examinations: make([]*ExaminationStatus, 0, len(d.examinations)),
+		PairChecks:   make([]*PairCheckStatus, 0, len(d.pairChecks)),
+	}
+	if d.lastErr != nil {
+		status.LastError = d.lastErr.Error()
+	}
+	for _, key := range d.sortedKeys() {
+		exam := d.examinations[key]
+		examStatus := &ExaminationStatus{
+			OrgID:        string(exam.orgID),
+			CounterParty: string(exam.counterPartyID),
+			NumTXs:       len(exam.submission.LocalTXIDs),
+			State:        exam.state,
+			AudTXID:      exam.audTXID,
+		}
+		if exam.err != nil {
+			examStatus.Error = exam.err.Error()
+		}
+		status.Examinations = append(status.Examinations, examStatus)
+		if consistent, ok := d.pairChecks[key]; ok {
+			status.PairChecks = append(status.PairChecks, &PairCheckStatus{
+				OrgID1:     string(key[0]),
+				OrgID2:     string(key[1]),
+				Consistent: consistent,
+			})
+		}
+	}
+	return status
+}
+
+func (d *Daemon) sortedKeys() [][2]organization.TypeID {
+	keys := make([][2]organization.TypeID, 0, len(d.examinations))
+	for key := range d.examinations {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	return keys
+}
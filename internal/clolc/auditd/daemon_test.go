@@ -0,0 +1,533 @@
+package auditd
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/committee"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/service"
+	"github.com/auti-project/auti/internal/clolc/service/client"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/constants"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/hashing"
+	"github.com/auti-project/auti/internal/ledger"
+	"github.com/auti-project/auti/internal/money"
+)
+
+// harness runs the committee service and the daemon of aud1 on loopback listeners,
+// org1 and org2 are audited by aud1 and org3 by aud2.
+type harness struct {
+	committee     *committee.Committee
+	comURL        string
+	daemon        *Daemon
+	daemonURL     string
+	aud           *auditor.Auditor
+	organizations map[organization.TypeID]*organization.Organization
+	orgChain      ledger.Ledger[*transaction.OrgOnChain]
+	audChain      ledger.Ledger[*transaction.AudOnChain]
+}
+
+func newHarness(t *testing.T) *harness {
+	ctx := context.Background()
+	organizations := []*organization.Organization{
+		organization.New("org1", organization.NewMemoryLocalChain()),
+		organization.New("org2", organization.NewMemoryLocalChain()),
+		organization.New("org3", organization.NewMemoryLocalChain()),
+	}
+	auditors := []*auditor.Auditor{
+		auditor.New("aud1", organizations[:2]),
+		auditor.New("aud2", organizations[2:]),
+	}
+	com := committee.New("com", auditors)
+	enrollments := map[string]*service.Enrollment{
+		string(auditors[0].ID): {Role: service.RoleAuditor, PublicKey: auditors[0].SigningPublicKey},
+	}
+	for _, org := range organizations {
+		enrollments[string(org.ID)] = &service.Enrollment{Role: service.RoleOrganization, PublicKey: org.SigningPublicKey}
+	}
+	server := service.NewServer(com, enrollments)
+	comServer := httptest.NewServer(server)
+	t.Cleanup(comServer.Close)
+	h := &harness{
+		committee:     com,
+		comURL:        comServer.URL,
+		aud:           auditors[0],
+		organizations: make(map[organization.TypeID]*organization.Organization),
+		orgChain:      organization.NewMemoryOrgChain(),
+		audChain:      auditor.NewMemoryAudChain(),
+	}
+	audClient := client.ForAuditor(comServer.URL, h.aud, com.SigningPublicKey)
+	if err := audClient.Register(ctx, service.RoleAuditor); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	for _, org := range organizations {
+		h.organizations[org.ID] = org
+		if err := client.ForOrganization(comServer.URL, org, com.SigningPublicKey).Register(
+			ctx, service.RoleOrganization,
+		); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+	e, _ := epoch.New(1, 0, 100)
+	if err := server.InitializeEpoch(e, nil); err != nil {
+		t.Fatalf("InitializeEpoch() error = %v", err)
+	}
+	for _, org := range organizations {
+		epochOrgID, err := client.ForOrganization(comServer.URL, org, com.SigningPublicKey).OrganizationEpochID(ctx)
+		if err != nil {
+			t.Fatalf("OrganizationEpochID() error = %v", err)
+		}
+		org.SetEpochID(epochOrgID)
+	}
+	h.daemon = New(h.aud, audClient, h.orgChain, h.audChain)
+	for _, org := range organizations[:2] {
+		if err := h.daemon.AddOrganization(org.ID, org.SigningPublicKey, org.LocalChain()); err != nil {
+			t.Fatalf("AddOrganization() error = %v", err)
+		}
+	}
+	if err := h.daemon.AddOrganization("org3", organizations[2].SigningPublicKey, nil); err == nil {
+		t.Fatalf("AddOrganization() of an organization audited by another auditor error = nil, want error")
+	}
+	if err := h.daemon.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	daemonServer := httptest.NewServer(h.daemon)
+	t.Cleanup(daemonServer.Close)
+	h.daemonURL = daemonServer.URL
+	return h
+}
+
+// record records the transactions of the organization with the counterparty, posts the accumulator
+// to the organization chain and returns the submission for the auditor.
+func (h *harness) record(
+	t *testing.T, orgID, counterPartyID organization.TypeID, txList []*transaction.LocalPlain,
+) *Submission {
+	org := h.organizations[orgID]
+	submission := &Submission{EpochID: 1, CounterParty: string(counterPartyID)}
+	for _, tx := range txList {
+		txID, err := org.RecordTransaction(tx)
+		if err != nil {
+			t.Fatalf("RecordTransaction() error = %v", err)
+		}
+		submission.LocalTXIDs = append(submission.LocalTXIDs, txID)
+	}
+	orgPlainTX, err := org.ComposeTXOrgChain(counterPartyID)
+	if err != nil {
+		t.Fatalf("ComposeTXOrgChain() error = %v", err)
+	}
+	orgOnChainTX := orgPlainTX.ToOnChain()
+	if err = org.SignTX(orgOnChainTX); err != nil {
+		t.Fatalf("SignTX() error = %v", err)
+	}
+	if submission.OrgTXID, err = h.orgChain.SubmitTX(orgOnChainTX); err != nil {
+		t.Fatalf("SubmitTX() error = %v", err)
+	}
+	if err = submission.SealRandomness(
+		h.aud.SigningPublicKey, string(orgID), org.EpochTXRandomness(counterPartyID), crypto.RandomStream(),
+	); err != nil {
+		t.Fatalf("SealRandomness() error = %v", err)
+	}
+	return submission
+}
+
+func (h *harness) submit(t *testing.T, orgID organization.TypeID, submission *Submission) {
+	c := NewClient(h.daemonURL, string(orgID), h.organizations[orgID])
+	if err := c.Submit(context.Background(), submission); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+}
+
+func TestDaemon_Examine(t *testing.T) {
+	tests := []struct {
+		name           string
+		amount1        money.Amount
+		amount2        money.Amount
+		wantConsistent bool
+	}{
+		{
+			name:           "test_consistent",
+			amount1:        money.MustParse("10", "USD"),
+			amount2:        money.MustParse("10", "USD"),
+			wantConsistent: true,
+		},
+		{
+			name:           "test_amount_mismatch",
+			amount1:        money.MustParse("10", "USD"),
+			amount2:        money.MustParse("9", "USD"),
+			wantConsistent: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			ctx := context.Background()
+			tx1, _ := transaction.NewPairLocalPlain("org1", "org2", tt.amount1, 1)
+			_, tx2 := transaction.NewPairLocalPlain("org1", "org2", tt.amount2, 1)
+			tx3, _ := transaction.NewPairLocalPlain("org1", "org3", money.MustParse("5", "USD"), 2)
+			h.submit(t, "org1", h.record(t, "org1", "org2", []*transaction.LocalPlain{tx1}))
+			h.submit(t, "org2", h.record(t, "org2", "org1", []*transaction.LocalPlain{tx2}))
+			h.submit(t, "org1", h.record(t, "org1", "org3", []*transaction.LocalPlain{tx3}))
+			h.daemon.Examine()
+
+			status, err := NewClient(h.daemonURL, "org1", h.organizations["org1"]).Status(ctx)
+			if err != nil {
+				t.Fatalf("Status() error = %v", err)
+			}
+			if len(status.Examinations) != 3 {
+				t.Fatalf("Status() got %d examinations, want 3", len(status.Examinations))
+			}
+			for _, exam := range status.Examinations {
+				if exam.State != StateExamined {
+					t.Errorf("examination of %s with %s is %s: %s", exam.OrgID, exam.CounterParty, exam.State, exam.Error)
+				}
+			}
+			if len(status.PairChecks) != 1 || status.PairChecks[0].Consistent != tt.wantConsistent {
+				t.Errorf("Status() pair checks = %v, want one with %v", status.PairChecks, tt.wantConsistent)
+			}
+			// the committee verifies the result the daemon submitted to the auditor chain
+			orgTX, err := h.orgChain.ReadTX(h.daemon.examinations[[2]organization.TypeID{"org1", "org2"}].submission.OrgTXID)
+			if err != nil {
+				t.Fatalf("ReadTX() error = %v", err)
+			}
+			audTX, err := h.audChain.ReadTX(status.Examinations[0].AudTXID)
+			if err != nil {
+				t.Fatalf("ReadTX() error = %v", err)
+			}
+			result, err := h.committee.VerifyOrgAndAudResult("org1", "aud1", orgTX, audTX)
+			if err != nil || !result {
+				t.Errorf("VerifyOrgAndAudResult() = %v, %v, want true", result, err)
+			}
+		})
+	}
+}
+
+func TestDaemon_Submit(t *testing.T) {
+	tests := []struct {
+		name    string
+		signer  organization.TypeID
+		orgID   organization.TypeID
+		modify  func(s *Submission)
+		wantErr bool
+	}{
+		{
+			name:    "test_valid",
+			signer:  "org1",
+			orgID:   "org1",
+			wantErr: false,
+		},
+		{
+			name:    "test_wrong_signer",
+			signer:  "org2",
+			orgID:   "org1",
+			wantErr: true,
+		},
+		{
+			name:    "test_not_audited",
+			signer:  "org3",
+			orgID:   "org3",
+			wantErr: true,
+		},
+		{
+			name:    "test_wrong_epoch",
+			signer:  "org1",
+			orgID:   "org1",
+			modify:  func(s *Submission) { s.EpochID = 2 },
+			wantErr: true,
+		},
+		{
+			name:    "test_missing_transaction",
+			signer:  "org1",
+			orgID:   "org1",
+			modify:  func(s *Submission) { s.LocalTXIDs = s.LocalTXIDs[:1] },
+			wantErr: true,
+		},
+		{
+			name:    "test_swapped_counterparty",
+			signer:  "org1",
+			orgID:   "org1",
+			modify:  func(s *Submission) { s.CounterParty = "org3" },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			tx1, _ := transaction.NewPairLocalPlain("org1", "org2", money.MustParse("10", "USD"), 1)
+			tx2, _ := transaction.NewPairLocalPlain("org1", "org2", money.MustParse("20", "USD"), 2)
+			submission := h.record(t, "org1", "org2", []*transaction.LocalPlain{tx1, tx2})
+			if tt.modify != nil {
+				tt.modify(submission)
+			}
+			c := NewClient(h.daemonURL, string(tt.orgID), h.organizations[tt.signer])
+			err := c.Submit(context.Background(), submission)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Submit() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDaemon_ExamineRejectsMissingRangeProof(t *testing.T) {
+	h := newHarness(t)
+	org := h.organizations["org1"]
+	// the transaction is hidden without a range proof and submitted directly to the local chain
+	tx, _ := transaction.NewPairLocalPlain("org1", "org2", money.MustParse("10", "USD"), 1)
+	hiddenTX, commitment, randScalar, err := tx.Hide(crypto.RandomStream())
+	if err != nil {
+		t.Fatalf("Hide() error = %v", err)
+	}
+	txID, err := org.SubmitTXLocalChain(hiddenTX)
+	if err != nil {
+		t.Fatalf("SubmitTXLocalChain() error = %v", err)
+	}
+	org.Accumulate("org2", commitment)
+	submission := &Submission{EpochID: 1, CounterParty: "org2", LocalTXIDs: []string{txID}}
+	orgPlainTX, err := org.ComposeTXOrgChain("org2")
+	if err != nil {
+		t.Fatalf("ComposeTXOrgChain() error = %v", err)
+	}
+	orgOnChainTX := orgPlainTX.ToOnChain()
+	if err = org.SignTX(orgOnChainTX); err != nil {
+		t.Fatalf("SignTX() error = %v", err)
+	}
+	if submission.OrgTXID, err = h.orgChain.SubmitTX(orgOnChainTX); err != nil {
+		t.Fatalf("SubmitTX() error = %v", err)
+	}
+	if err = submission.SealRandomness(
+		h.aud.SigningPublicKey, "org1", []kyber.Scalar{randScalar}, crypto.RandomStream(),
+	); err != nil {
+		t.Fatalf("SealRandomness() error = %v", err)
+	}
+	h.submit(t, "org1", submission)
+	h.daemon.Examine()
+	status := h.daemon.Status()
+	if len(status.Examinations) != 1 || status.Examinations[0].State != StateFailed {
+		t.Errorf("Status() examinations = %+v, want one failed", status.Examinations)
+	}
+}
+
+func TestDaemon_ExamineRejectsLegacyGenerator(t *testing.T) {
+	tests := []struct {
+		name         string
+		legacyCutoff int64
+		wantState    string
+	}{
+		{
+			name:         "test_without_cutoff",
+			legacyCutoff: 0,
+			wantState:    StateFailed,
+		},
+		{
+			name:         "test_created_after_cutoff",
+			legacyCutoff: 1,
+			wantState:    StateFailed,
+		},
+		{
+			name:         "test_created_before_cutoff",
+			legacyCutoff: 2,
+			wantState:    StateExamined,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			h.aud.LegacyCutoff = tt.legacyCutoff
+			org := h.organizations["org1"]
+			// a new transaction claims the legacy generator, whose discrete log is public, with a valid range proof
+			tx, _ := transaction.NewPairLocalPlain("org1", "org2", money.MustParse("10", "USD"), 1)
+			commitment, randScalar, err := crypto.PedersenCommit(
+				crypto.BlindingGeneratorLegacy, tx.Currency, tx.Amount, crypto.RandomStream(),
+			)
+			if err != nil {
+				t.Fatalf("PedersenCommit() error = %v", err)
+			}
+			rangeProof, err := crypto.NewSignedRangeProof(
+				crypto.BlindingGeneratorLegacy, tx.Currency, []int64{tx.Amount}, []kyber.Scalar{randScalar},
+				constants.RangeProofBitLen, crypto.RandomStream(),
+			)
+			if err != nil {
+				t.Fatalf("NewSignedRangeProof() error = %v", err)
+			}
+			commitmentBytes, err := commitment.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			hiddenTX := transaction.NewLocalHidden(
+				hashing.Sum(hashing.TagOrgID, []byte(tx.CounterParty)), commitmentBytes, tx.Timestamp,
+			)
+			hiddenTX.Asset = tx.Currency
+			hiddenTX.BlindingGenerator = crypto.BlindingGeneratorLegacy
+			if hiddenTX.RangeProof, err = rangeProof.Serialize(); err != nil {
+				t.Fatal(err)
+			}
+			txID, err := org.SubmitTXLocalChain(hiddenTX)
+			if err != nil {
+				t.Fatalf("SubmitTXLocalChain() error = %v", err)
+			}
+			org.Accumulate("org2", commitment)
+			submission := &Submission{EpochID: 1, CounterParty: "org2", LocalTXIDs: []string{txID}}
+			orgPlainTX, err := org.ComposeTXOrgChain("org2")
+			if err != nil {
+				t.Fatalf("ComposeTXOrgChain() error = %v", err)
+			}
+			orgOnChainTX := orgPlainTX.ToOnChain()
+			if err = org.SignTX(orgOnChainTX); err != nil {
+				t.Fatalf("SignTX() error = %v", err)
+			}
+			if submission.OrgTXID, err = h.orgChain.SubmitTX(orgOnChainTX); err != nil {
+				t.Fatalf("SubmitTX() error = %v", err)
+			}
+			if err = submission.SealRandomness(
+				h.aud.SigningPublicKey, "org1", []kyber.Scalar{randScalar}, crypto.RandomStream(),
+			); err != nil {
+				t.Fatalf("SealRandomness() error = %v", err)
+			}
+			h.submit(t, "org1", submission)
+			h.daemon.Examine()
+			status := h.daemon.Status()
+			if len(status.Examinations) != 1 || status.Examinations[0].State != tt.wantState {
+				t.Errorf("Status() examinations = %+v, want one %s", status.Examinations, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestDaemon_ExamineRejectsForgedSignature(t *testing.T) {
+	tests := []struct {
+		name        string
+		forgeLocal  bool
+		forgeOrg    bool
+		wantExamine bool
+	}{
+		{
+			name:        "test_valid",
+			wantExamine: true,
+		},
+		{
+			name:        "test_forged_local_transaction",
+			forgeLocal:  true,
+			wantExamine: false,
+		},
+		{
+			name:        "test_forged_org_transaction",
+			forgeOrg:    true,
+			wantExamine: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			org1, org2 := h.organizations["org1"], h.organizations["org2"]
+			tx, _ := transaction.NewPairLocalPlain("org1", "org2", money.MustParse("10", "USD"), 1)
+			submission := h.record(t, "org1", "org2", []*transaction.LocalPlain{tx})
+			// the ledgers of a misbehaving backend accept the transactions without checking the signatures,
+			// the forged transactions are signed by org2 and claim org1 as the signer
+			localChain := ledger.NewMemory[transaction.LocalOnChain, *transaction.LocalOnChain](nil)
+			for idx, txID := range submission.LocalTXIDs {
+				localTX, err := org1.LocalChain().ReadTX(txID)
+				if err != nil {
+					t.Fatalf("ReadTX() error = %v", err)
+				}
+				if tt.forgeLocal {
+					signer := localTX.Signer
+					if err = org2.SignTX(localTX); err != nil {
+						t.Fatalf("SignTX() error = %v", err)
+					}
+					localTX.Signer = signer
+				}
+				if submission.LocalTXIDs[idx], err = localChain.SubmitTX(localTX); err != nil {
+					t.Fatalf("SubmitTX() error = %v", err)
+				}
+			}
+			orgChain := ledger.NewMemory[transaction.OrgOnChain, *transaction.OrgOnChain](nil)
+			orgTX, err := h.orgChain.ReadTX(submission.OrgTXID)
+			if err != nil {
+				t.Fatalf("ReadTX() error = %v", err)
+			}
+			if tt.forgeOrg {
+				signer := orgTX.Signer
+				if err = org2.SignTX(orgTX); err != nil {
+					t.Fatalf("SignTX() error = %v", err)
+				}
+				orgTX.Signer = signer
+			}
+			if submission.OrgTXID, err = orgChain.SubmitTX(orgTX); err != nil {
+				t.Fatalf("SubmitTX() error = %v", err)
+			}
+			h.daemon.orgChain = orgChain
+			h.daemon.orgMap["org1"].localChain = localChain
+
+			h.submit(t, "org1", submission)
+			h.daemon.Examine()
+			status := h.daemon.Status()
+			if len(status.Examinations) != 1 || (status.Examinations[0].State == StateExamined) != tt.wantExamine {
+				t.Errorf("Status() examinations = %+v, want examined %v", status.Examinations, tt.wantExamine)
+			}
+		})
+	}
+}
+
+// blockingLocalChain holds the reads of the local chain until release is closed, like a slow remote ledger.
+type blockingLocalChain struct {
+	ledger.Ledger[*transaction.LocalOnChain]
+	reading chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingLocalChain) ReadTX(txID string) (*transaction.LocalOnChain, error) {
+	select {
+	case b.reading <- struct{}{}:
+	default:
+	}
+	<-b.release
+	return b.Ledger.ReadTX(txID)
+}
+
+func TestDaemon_ExamineReleasesLock(t *testing.T) {
+	h := newHarness(t)
+	tx, _ := transaction.NewPairLocalPlain("org1", "org2", money.MustParse("10", "USD"), 1)
+	submission := h.record(t, "org1", "org2", []*transaction.LocalPlain{tx})
+	h.submit(t, "org1", submission)
+	localChain := &blockingLocalChain{
+		Ledger:  h.organizations["org1"].LocalChain(),
+		reading: make(chan struct{}),
+		release: make(chan struct{}),
+	}
+	h.daemon.orgMap["org1"].localChain = localChain
+	done := make(chan struct{})
+	go func() {
+		h.daemon.Examine()
+		close(done)
+	}()
+	<-localChain.reading
+	// the status and the submissions are served while the ledger is read
+	statusCh := make(chan *Status, 1)
+	go func() {
+		statusCh <- h.daemon.Status()
+	}()
+	select {
+	case status := <-statusCh:
+		if len(status.Examinations) != 1 || status.Examinations[0].State != StatePending {
+			t.Errorf("Status() examinations = %+v, want one pending", status.Examinations)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Status() blocked by the examination")
+	}
+	if err := h.daemon.Submit("org1", submission); err == nil {
+		t.Errorf("Submit() of a submission being examined error = nil, want error")
+	}
+	close(localChain.release)
+	<-done
+	status := h.daemon.Status()
+	if len(status.Examinations) != 1 || status.Examinations[0].State != StateExamined {
+		t.Errorf("Status() examinations = %+v, want one examined", status.Examinations)
+	}
+}
@@ -0,0 +1,77 @@
+package auditd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/service"
+)
+
+const maxBodyBytes = 1 << 20
+
+// ServeHTTP serves the submissions of the audited organizations and the status of the daemon.
+func (d *Daemon) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case PathSubmissions:
+		d.serveSubmission(w, r)
+	case PathStatus:
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "method "+r.Method+" not allowed")
+			return
+		}
+		writeJSON(w, http.StatusOK, d.Status())
+	default:
+		writeError(w, http.StatusNotFound, "path "+r.URL.Path+" not found")
+	}
+}
+
+func (d *Daemon) serveSubmission(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method "+r.Method+" not allowed")
+		return
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	orgID := organization.TypeID(r.Header.Get(service.HeaderEntity))
+	d.mu.Lock()
+	org, ok := d.orgMap[orgID]
+	d.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "organization "+string(orgID)+" not audited by "+string(d.auditor.ID))
+		return
+	}
+	if err = service.VerifyRequest(r, body, org.signingPublicKey); err != nil {
+		writeError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+	submission := &Submission{}
+	if err = json.Unmarshal(body, submission); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err = d.Submit(orgID, submission); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, struct{}{})
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, &ErrorResponse{Error: msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,14 @@
+package auditor
+
+import (
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/ledger"
+)
+
+// NewMemoryAudChain returns an in-memory stand-in for the auditor chain,
+// it rejects the transactions without a valid signature like the chaincode.
+func NewMemoryAudChain() *ledger.Memory[transaction.AudOnChain, *transaction.AudOnChain] {
+	return ledger.NewMemory(func(tx *transaction.AudOnChain) error {
+		return ledger.VerifySignature(tx.Signer, tx.Signature, tx.SigningMessage())
+	})
+}
@@ -1,7 +1,7 @@
 package auditor
 
 import (
-	"crypto/sha256"
+	"crypto/cipher"
 	"encoding/hex"
 	"fmt"
 
@@ -9,47 +9,110 @@ import (
 
 	clolcorg "github.com/auti-project/auti/internal/clolc/organization"
 	"github.com/auti-project/auti/internal/clolc/transaction"
-	"github.com/auti-project/auti/internal/constants"
 	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/hashing"
+	"github.com/auti-project/auti/internal/keystore"
 )
 
 type TypeID string
 type TypeEpochID []byte
 
+// Decrypter decrypts the aud-chain ciphertexts under the epoch key of an audited organization,
+// it stands in for the epoch secret keys the auditors of a threshold committee do not receive.
+type Decrypter interface {
+	DecryptPoint(orgIDHash string, cipherBytes []byte) (kyber.Point, error)
+}
+
 type Auditor struct {
 	ID                   TypeID
 	AuditedOrgIDs        []clolcorg.TypeID
-	epochTXRandMap       map[[2]string][]kyber.Scalar
+	epochTXSeedMap       map[[2]string][]byte
 	EpochID              TypeEpochID
 	epochOrgSecretKeyMap map[string]crypto.TypePrivateKey
+	epochDecrypter       Decrypter
 	epochOrgIDMap        map[clolcorg.TypeID]clolcorg.TypeEpochID
+	SigningPublicKey     crypto.TypePublicKey
+	// LegacyCutoff admits the transactions committed under the legacy blinding generator before it,
+	// see crypto.CheckBlindingGenerator, it is 0 unless the data committed under the legacy generator is migrated.
+	LegacyCutoff int64
+	signingKey   crypto.TypePrivateKey
+	randStream   cipher.Stream
 }
 
 func New(id string, organizations []*clolcorg.Organization) *Auditor {
+	return NewWithRandStream(id, organizations, crypto.RandomStream())
+}
+
+// NewWithRandStream creates an auditor drawing all its randomness from the given stream,
+// use crypto.NewSeededStream for reproducible runs.
+func NewWithRandStream(id string, organizations []*clolcorg.Organization, rand cipher.Stream) *Auditor {
 	aud := &Auditor{
-		ID: TypeID(id),
+		ID:         TypeID(id),
+		randStream: rand,
 	}
 	aud.AuditedOrgIDs = make([]clolcorg.TypeID, len(organizations))
 	for idx, org := range organizations {
 		aud.AuditedOrgIDs[idx] = org.ID
 	}
+	aud.signingKey, aud.SigningPublicKey = crypto.SigningKeyGen(rand)
 	return aud
 }
 
-func (a *Auditor) SetEpochTXRandomness(txRandMap map[[2]string][]kyber.Scalar) {
-	a.epochTXRandMap = txRandMap
+// SetSigningKey replaces the generated signing key with a long-term key loaded from storage, e.g., by the binaries.
+func (a *Auditor) SetSigningKey(signingKey crypto.TypePrivateKey) {
+	a.signingKey = signingKey
+	a.SigningPublicKey = crypto.KyberSuite.Point().Mul(signingKey, nil)
+}
+
+// SignTX signs the on-chain transaction with the long-term signing key of the auditor.
+func (a *Auditor) SignTX(tx crypto.Signable) error {
+	return tx.Sign(a.signingKey, a.randStream)
+}
+
+// SignMessage signs the message with the long-term signing key of the auditor, e.g., to authenticate to the committee service.
+func (a *Auditor) SignMessage(msg []byte) ([]byte, error) {
+	return crypto.Sign(a.signingKey, msg, a.randStream)
 }
 
-func (a *Auditor) GetEpochTXRandomness(orgID1, orgID2 clolcorg.TypeID) []kyber.Scalar {
+// OpenSealed decrypts a message sealed to the signing public key of the auditor with crypto.Seal,
+// e.g., the commitment randomness the audited organizations disclose.
+func (a *Auditor) OpenSealed(sealed, additionalData []byte) ([]byte, error) {
+	return crypto.Open(a.signingKey, sealed, additionalData)
+}
+
+func (a *Auditor) SetEpochTXSeeds(txSeedMap map[[2]string][]byte) {
+	a.epochTXSeedMap = txSeedMap
+}
+
+// AddEpochTXSeed adds the seed of a pair whose trading relationship is declared in the middle of the epoch.
+func (a *Auditor) AddEpochTXSeed(key [2]string, seed []byte) {
+	if a.epochTXSeedMap == nil {
+		a.epochTXSeedMap = make(map[[2]string][]byte)
+	}
+	a.epochTXSeedMap[key] = seed
+}
+
+// GetEpochTXRandomness derives the committee randomness of the first numTXs transactions of the pair,
+// it returns nil if the auditor has no seed for the pair.
+func (a *Auditor) GetEpochTXRandomness(orgID1, orgID2 clolcorg.TypeID, numTXs int) []kyber.Scalar {
 	key := clolcorg.IDHashKey(clolcorg.IDHashString(orgID1), clolcorg.IDHashString(orgID2))
-	if txRand, ok := a.epochTXRandMap[key]; ok {
-		return txRand
+	if seed, ok := a.epochTXSeedMap[key]; ok {
+		return crypto.PRFScalars(seed, 0, uint64(numTXs))
 	}
 	return nil
 }
 
 func (a *Auditor) SetEpochSecretKey(orgSecretKeyMap map[string]crypto.TypePrivateKey) {
 	a.epochOrgSecretKeyMap = orgSecretKeyMap
+	a.epochDecrypter = nil
+}
+
+// SetEpochDecrypter sets the decrypter of the epoch in place of the secret keys,
+// the auditor requests the decryptions of the ciphertexts of its audited organizations from it.
+func (a *Auditor) SetEpochDecrypter(decrypter Decrypter) {
+	a.epochOrgSecretKeyMap = nil
+	a.epochDecrypter = decrypter
 }
 
 func (a *Auditor) SetEpochID(id []byte) {
@@ -60,19 +123,65 @@ func (a *Auditor) SetEpochOrgIDMap(idMap map[clolcorg.TypeID]clolcorg.TypeEpochI
 	a.epochOrgIDMap = idMap
 }
 
+// EpochOrgID returns the epoch ID of the audited organization forwarded by the committee.
+func (a *Auditor) EpochOrgID(orgID clolcorg.TypeID) (clolcorg.TypeEpochID, bool) {
+	epochOrgID, ok := a.epochOrgIDMap[orgID]
+	return epochOrgID, ok
+}
+
+// ExportEpochSecrets returns the key material the committee forwarded to the auditor for the epoch,
+// the entry of the key store expires at the end of its retention period.
+func (a *Auditor) ExportEpochSecrets(e *epoch.Epoch, expiresAt int64) *keystore.EpochSecrets {
+	secrets := keystore.NewEpochSecrets(e, expiresAt)
+	for idHash, secretKey := range a.epochOrgSecretKeyMap {
+		secrets.SecretKeys[idHash] = secretKey
+	}
+	for key, seed := range a.epochTXSeedMap {
+		secrets.TXSeeds[key] = seed
+	}
+	for orgID, epochOrgID := range a.epochOrgIDMap {
+		secrets.OrgEpochIDs[string(orgID)] = epochOrgID
+	}
+	secrets.AuditorEpochIDs[string(a.ID)] = a.EpochID
+	return secrets
+}
+
+// RestoreEpochSecrets sets the key material of the epoch read from the key store, e.g., after a restart.
+func (a *Auditor) RestoreEpochSecrets(secrets *keystore.EpochSecrets) error {
+	epochID, ok := secrets.AuditorEpochIDs[string(a.ID)]
+	if !ok {
+		return fmt.Errorf("no epoch ID of auditor %s in epoch %d", a.ID, secrets.EpochID)
+	}
+	a.EpochID = epochID
+	a.epochDecrypter = nil
+	a.epochOrgSecretKeyMap = make(map[string]crypto.TypePrivateKey)
+	for idHash, secretKey := range secrets.SecretKeys {
+		a.epochOrgSecretKeyMap[idHash] = secretKey
+	}
+	a.epochTXSeedMap = make(map[[2]string][]byte)
+	for key, seed := range secrets.TXSeeds {
+		a.epochTXSeedMap[key] = seed
+	}
+	a.epochOrgIDMap = make(map[clolcorg.TypeID]clolcorg.TypeEpochID)
+	for orgID, epochOrgID := range secrets.OrgEpochIDs {
+		a.epochOrgIDMap[clolcorg.TypeID(orgID)] = epochOrgID
+	}
+	return nil
+}
+
 func (a *Auditor) AccumulateCommitments(
 	orgID clolcorg.TypeID, txList []*transaction.LocalHidden,
 ) (kyber.Point, error) {
 	if len(txList) == 0 {
 		return nil, fmt.Errorf("empty transaction list")
 	}
-	if constants.MaxNumTXInEpoch < len(txList) {
-		return nil, fmt.Errorf("too many transactions in the epoch: %d", len(txList))
-	}
 	orgIDHashStr := clolcorg.IDHashString(orgID)
 	counterPartyIDHashStr := hex.EncodeToString(txList[0].CounterParty)
 	orgKey := clolcorg.IDHashKey(orgIDHashStr, counterPartyIDHashStr)
-	randomScalars := a.epochTXRandMap[orgKey]
+	seed, ok := a.epochTXSeedMap[orgKey]
+	if !ok {
+		return nil, fmt.Errorf("no randomness for organization %s", orgID)
+	}
 	result := crypto.KyberSuite.Point().Null()
 	for idx, tx := range txList {
 		commitmentBytes := tx.Commitment
@@ -80,12 +189,34 @@ func (a *Auditor) AccumulateCommitments(
 		if err := commitmentPoint.UnmarshalBinary(commitmentBytes); err != nil {
 			return nil, err
 		}
-		commitmentPoint.Mul(randomScalars[idx], commitmentPoint)
+		commitmentPoint.Mul(crypto.PRFScalar(seed, uint64(idx)), commitmentPoint)
 		result.Add(result, commitmentPoint)
 	}
 	return result, nil
 }
 
+// VerifyRangeProofs rejects the transaction list if any of the range proofs does not verify,
+// if any of the transactions has no asset, whose commitments would mix with the base point,
+// or if any of the transactions is committed under the legacy blinding generator after the cutoff.
+func (a *Auditor) VerifyRangeProofs(txList []*transaction.LocalHidden) error {
+	for idx, tx := range txList {
+		if tx.Asset == "" {
+			return fmt.Errorf("transaction %d has no asset", idx)
+		}
+		if err := crypto.CheckBlindingGenerator(tx.BlindingGenerator, tx.Timestamp, a.LegacyCutoff); err != nil {
+			return fmt.Errorf("transaction %d: %v", idx, err)
+		}
+		ok, err := tx.VerifyRangeProof()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("invalid range proof for transaction %d", idx)
+		}
+	}
+	return nil
+}
+
 func (a *Auditor) ComputeA(orgEpochID clolcorg.TypeEpochID, orgChainTX *transaction.OrgPlain) (kyber.Point, error) {
 	orgIDHashPoint := clolcorg.EpochIDHashPoint(orgEpochID)
 	acc := crypto.KyberSuite.Point()
@@ -95,16 +226,31 @@ func (a *Auditor) ComputeA(orgEpochID clolcorg.TypeEpochID, orgChainTX *transact
 	return acc.Sub(acc, orgIDHashPoint), nil
 }
 
-func (a *Auditor) ComputeB(orgTXRandList, comTXRandList []kyber.Scalar) (kyber.Point, error) {
-	if len(orgTXRandList) != len(comTXRandList) {
-		return nil, fmt.Errorf("length of two lists are not equal")
+// BlindingGenerators lists the blinding generators of the transactions, in the order of the transactions.
+func BlindingGenerators(localChainTXList []*transaction.LocalHidden) []crypto.BlindingGeneratorVersion {
+	versions := make([]crypto.BlindingGeneratorVersion, len(localChainTXList))
+	for idx, tx := range localChainTXList {
+		versions[idx] = tx.BlindingGenerator
 	}
-	scalar := crypto.KyberSuite.Scalar().Zero()
+	return versions
+}
+
+// ComputeB computes -sum(r_org * r_com * H), each term with the blinding generator H of its transaction.
+func (a *Auditor) ComputeB(
+	orgTXRandList, comTXRandList []kyber.Scalar, generators []crypto.BlindingGeneratorVersion,
+) (kyber.Point, error) {
+	if len(orgTXRandList) != len(comTXRandList) || len(orgTXRandList) != len(generators) {
+		return nil, fmt.Errorf("length of the lists are not equal")
+	}
+	result := crypto.KyberSuite.Point().Null()
 	for idx := range orgTXRandList {
+		blindingGen, err := crypto.BlindingGenerator(generators[idx])
+		if err != nil {
+			return nil, err
+		}
 		tmp := crypto.KyberSuite.Scalar().Mul(orgTXRandList[idx], comTXRandList[idx])
-		scalar.Sub(scalar, tmp)
+		result.Sub(result, crypto.KyberSuite.Point().Mul(tmp, blindingGen))
 	}
-	result := crypto.KyberSuite.Point().Mul(scalar, crypto.PointH)
 	return result, nil
 }
 
@@ -127,7 +273,7 @@ func (a *Auditor) EncryptConsistencyExamResult(
 	if err != nil {
 		return nil, err
 	}
-	cipherRes, err := crypto.EncryptPoint(publicKey, res)
+	cipherRes, err := crypto.EncryptPoint(publicKey, res, a.randStream)
 	if err != nil {
 		return nil, err
 	}
@@ -135,7 +281,7 @@ func (a *Auditor) EncryptConsistencyExamResult(
 	if err != nil {
 		return nil, err
 	}
-	cipherB, err := crypto.EncryptPoint(publicKey, pointB)
+	cipherB, err := crypto.EncryptPoint(publicKey, pointB, a.randStream)
 	if err != nil {
 		return nil, err
 	}
@@ -143,7 +289,7 @@ func (a *Auditor) EncryptConsistencyExamResult(
 	if err != nil {
 		return nil, err
 	}
-	cipherC, err := crypto.EncryptPoint(publicKey, pointC)
+	cipherC, err := crypto.EncryptPoint(publicKey, pointC, a.randStream)
 	if err != nil {
 		return nil, err
 	}
@@ -153,7 +299,7 @@ func (a *Auditor) EncryptConsistencyExamResult(
 	}
 	epochIDHashPoint := EpochIDHashPoint(a.EpochID)
 	idPointD := crypto.KyberSuite.Point().Add(epochIDHashPoint, pointD)
-	cipherD, err := crypto.EncryptPoint(publicKey, idPointD)
+	cipherD, err := crypto.EncryptPoint(publicKey, idPointD, a.randStream)
 	if err != nil {
 		return nil, err
 	}
@@ -185,7 +331,7 @@ func (a *Auditor) ConsistencyExaminationPartOne(
 		return nil, err
 	}
 	// compute B
-	pointB, err := a.ComputeB(orgTXRandList, comTXRandList)
+	pointB, err := a.ComputeB(orgTXRandList, comTXRandList, BlindingGenerators(localChainTXList))
 	if err != nil {
 		return nil, err
 	}
@@ -227,23 +373,13 @@ func (a *Auditor) ComputeCETransactionID(
 ) ([]byte, error) {
 	orgIDHashStr := clolcorg.IDHashString(orgID)
 	orgKey := clolcorg.IDHashKey(orgIDHashStr, counterPartyIDHash)
-	randomnesses := a.epochTXRandMap[orgKey]
+	// the seed stands in for the whole randomness sequence of the pair
+	seed := a.epochTXSeedMap[orgKey]
 	epochOrgID := a.epochOrgIDMap[orgID]
 	epochOrgIDBytes := make([]byte, len(epochOrgID))
 	copy(epochOrgIDBytes, epochOrgID)
-	randAccumulator := crypto.KyberSuite.Scalar().Zero()
-	for _, randScalar := range randomnesses {
-		randAccumulator.Add(randAccumulator, randScalar)
-	}
-	randAccumulatorBytes, err := randAccumulator.MarshalBinary()
-	if err != nil {
-		return nil, err
-	}
-	concatBytes := append(epochOrgIDBytes, randAccumulatorBytes...)
-	sha256Func := sha256.New()
-	sha256Func.Write(concatBytes)
-	result := sha256Func.Sum(nil)
-	return result, nil
+	concatBytes := append(epochOrgIDBytes, seed...)
+	return hashing.Sum(hashing.TagTXID, concatBytes), nil
 }
 
 func (a *Auditor) DecryptResAndB(
@@ -253,21 +389,31 @@ func (a *Auditor) DecryptResAndB(
 	if err != nil {
 		return nil, nil, err
 	}
-	privateKey, ok := a.epochOrgSecretKeyMap[orgIDHash]
-	if !ok {
-		return nil, nil, fmt.Errorf("no private key for organization %s", orgIDHash)
-	}
-	res, err := crypto.DecryptPoint(privateKey, plainTX.CipherRes)
+	res, err := a.decryptPoint(orgIDHash, plainTX.CipherRes)
 	if err != nil {
 		return nil, nil, err
 	}
-	pointB, err := crypto.DecryptPoint(privateKey, plainTX.CipherB)
+	pointB, err := a.decryptPoint(orgIDHash, plainTX.CipherB)
 	if err != nil {
 		return nil, nil, err
 	}
 	return res, pointB, nil
 }
 
+// decryptPoint decrypts with the epoch secret key of the organization,
+// or requests the decryption from the decrypter of the epoch if the auditor holds no secret key.
+func (a *Auditor) decryptPoint(orgIDHash string, cipherBytes []byte) (kyber.Point, error) {
+	if privateKey, ok := a.epochOrgSecretKeyMap[orgIDHash]; ok {
+		return crypto.DecryptPoint(privateKey, cipherBytes)
+	}
+	if a.epochDecrypter != nil {
+		return a.epochDecrypter.DecryptPoint(orgIDHash, cipherBytes)
+	}
+	return nil, fmt.Errorf("no private key for organization %s", orgIDHash)
+}
+
+// CheckResultConsistency returns true if the results of the two sides of the transactions sum to zero.
+// The amounts are committed with the generators of their assets, so the check holds for each asset separately.
 func (a *Auditor) CheckResultConsistency(res, B, txRes, txB kyber.Point) bool {
 	result := crypto.KyberSuite.Point().Null()
 	result.Add(result, res)
@@ -278,9 +424,7 @@ func (a *Auditor) CheckResultConsistency(res, B, txRes, txB kyber.Point) bool {
 }
 
 func IDHashBytes(id TypeID) []byte {
-	sha256Func := sha256.New()
-	sha256Func.Write([]byte(id))
-	return sha256Func.Sum(nil)
+	return hashing.Sum(hashing.TagAuditorID, []byte(id))
 }
 
 func IDHashString(id TypeID) string {
@@ -296,9 +440,7 @@ func IDHashPoint(id TypeID) kyber.Point {
 }
 
 func EpochIDHashBytes(epochID TypeEpochID) []byte {
-	sha256Func := sha256.New()
-	sha256Func.Write(epochID)
-	return sha256Func.Sum(nil)
+	return hashing.Sum(hashing.TagEpochID, epochID)
 }
 
 func EpochIDHashString(epochID TypeEpochID) string {
@@ -0,0 +1,44 @@
+package auditor
+
+import (
+	"testing"
+
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/money"
+)
+
+func TestAuditor_VerifyRangeProofs(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(tx *transaction.LocalPlain)
+		wantErr bool
+	}{
+		{
+			name:    "test_valid",
+			wantErr: false,
+		},
+		{
+			name:    "test_no_asset",
+			modify:  func(tx *transaction.LocalPlain) { tx.Currency = "" },
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aud := New("aud1", nil)
+			tx := transaction.NewLocalPlain("org2", money.MustParse("10", "USD"), 1)
+			if tt.modify != nil {
+				tt.modify(tx)
+			}
+			hiddenTX, _, _, err := tx.HideWithRangeProof(crypto.RandomStream())
+			if err != nil {
+				t.Fatalf("HideWithRangeProof() error = %v", err)
+			}
+			err = aud.VerifyRangeProofs([]*transaction.LocalHidden{hiddenTX})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyRangeProofs() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package committee
 
 import (
+	"crypto/cipher"
 	"encoding/hex"
 	"errors"
 
@@ -9,29 +10,53 @@ import (
 	"github.com/auti-project/auti/internal/clolc/auditor"
 	"github.com/auti-project/auti/internal/clolc/organization"
 	"github.com/auti-project/auti/internal/clolc/transaction"
-	"github.com/auti-project/auti/internal/constants"
 	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/hashing"
 )
 
 type TypeID string
 
+// errSharedEpochKeys is returned by the methods needing the whole epoch secret keys on the committee of a ThresholdCommittee.
+var errSharedEpochKeys = errors.New("the epoch secret keys are shared among the members of the threshold committee")
+
 type Committee struct {
 	ID                TypeID
 	managedEntityMap  map[auditor.TypeID][]organization.TypeID
 	managedAuditorIDs []auditor.TypeID
 	managedOrgIDs     []organization.TypeID
-	epochTXRandMap    map[[2]string][]kyber.Scalar
+	epochTopology     *Topology
+	epochTXSeedMap    map[[2]string][]byte
 	epochSecretKeyMap map[string]crypto.TypePrivateKey
 	epochPublicKeyMap map[string]crypto.TypePublicKey
 	epochOrgIDMap     map[organization.TypeID]organization.TypeEpochID
 	epochAuditorIDMap map[auditor.TypeID]auditor.TypeEpochID
+	currentEpoch      *epoch.Epoch
+	epochHistory      map[epoch.TypeID]*epochMaterial
+	// sharedEpochKeys is set for the committee of a ThresholdCommittee, whose epoch secret keys exist only as shares
+	sharedEpochKeys  bool
+	SigningPublicKey crypto.TypePublicKey
+	// LegacyCutoff admits the disclosed transactions committed under the legacy blinding generator before it,
+	// see crypto.CheckBlindingGenerator, it is 0 unless the data committed under the legacy generator is migrated.
+	LegacyCutoff int64
+	signingKey   crypto.TypePrivateKey
+	randStream   cipher.Stream
 }
 
 func New(id string, auditors []*auditor.Auditor) *Committee {
+	return NewWithRandStream(id, auditors, crypto.RandomStream())
+}
+
+// NewWithRandStream creates a committee drawing all its randomness from the given stream,
+// use crypto.NewSeededStream for reproducible runs.
+func NewWithRandStream(id string, auditors []*auditor.Auditor, rand cipher.Stream) *Committee {
 	com := &Committee{
 		ID:               TypeID(id),
 		managedEntityMap: make(map[auditor.TypeID][]organization.TypeID),
+		epochHistory:     make(map[epoch.TypeID]*epochMaterial),
+		randStream:       rand,
 	}
+	com.signingKey, com.SigningPublicKey = crypto.SigningKeyGen(rand)
 	com.reinitializeMaps()
 	com.managedAuditorIDs = make([]auditor.TypeID, len(auditors))
 	for idx, aud := range auditors {
@@ -42,21 +67,74 @@ func New(id string, auditors []*auditor.Auditor) *Committee {
 	return com
 }
 
+// SetSigningKey replaces the generated signing key with a long-term key loaded from storage, e.g., by the binaries.
+func (c *Committee) SetSigningKey(signingKey crypto.TypePrivateKey) {
+	c.signingKey = signingKey
+	c.SigningPublicKey = crypto.KyberSuite.Point().Mul(signingKey, nil)
+}
+
+// SignTX signs the on-chain transaction with the long-term signing key of the committee.
+func (c *Committee) SignTX(tx crypto.Signable) error {
+	return tx.Sign(c.signingKey, c.randStream)
+}
+
+// SignMessage signs the message with the long-term signing key of the committee.
+func (c *Committee) SignMessage(msg []byte) ([]byte, error) {
+	return crypto.Sign(c.signingKey, msg, c.randStream)
+}
+
+// ManagesAuditor returns true if the auditor is one of the auditors the committee was created with.
+func (c *Committee) ManagesAuditor(id auditor.TypeID) bool {
+	_, ok := c.managedEntityMap[id]
+	return ok
+}
+
+// Audits returns true if the organization is audited by the managed auditor.
+func (c *Committee) Audits(audID auditor.TypeID, orgID organization.TypeID) bool {
+	for _, id := range c.managedEntityMap[audID] {
+		if id == orgID {
+			return true
+		}
+	}
+	return false
+}
+
+// ManagesOrganization returns true if the organization is audited by one of the managed auditors.
+func (c *Committee) ManagesOrganization(id organization.TypeID) bool {
+	for _, orgID := range c.managedOrgIDs {
+		if orgID == id {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *Committee) reinitializeMaps() {
-	c.epochTXRandMap = make(map[[2]string][]kyber.Scalar)
+	c.epochTXSeedMap = make(map[[2]string][]byte)
 	c.epochSecretKeyMap = make(map[string]crypto.TypePrivateKey)
 	c.epochPublicKeyMap = make(map[string]crypto.TypePublicKey)
 	c.epochOrgIDMap = make(map[organization.TypeID]organization.TypeEpochID)
 	c.epochAuditorIDMap = make(map[auditor.TypeID]auditor.TypeEpochID)
 }
 
-// InitializeEpoch initialize the parameters for an auditing epoch
+// InitializeEpoch initialize the parameters for an auditing epoch in which every pair of organizations can trade
 func (c *Committee) InitializeEpoch(
 	auditors []*auditor.Auditor, organizations []*organization.Organization,
 ) (map[string]crypto.TypePublicKey, error) {
+	c.epochTopology = NewFullTopology(c.managedOrgIDs)
+	return c.initializeEpoch(auditors, organizations, c.generateEpochKeyPairs, c.ForwardEpochAuditorParameters)
+}
+
+func (c *Committee) initializeEpoch(
+	auditors []*auditor.Auditor, organizations []*organization.Organization,
+	generateKeyPairs func() error, forwardAuditorParameters func(*auditor.Auditor) error,
+) (map[string]crypto.TypePublicKey, error) {
+	if err := c.checkEpochOpen(); err != nil {
+		return nil, err
+	}
 	c.reinitializeMaps()
 	// IN.1: generate randomness for the transactions {r_{i, j, k}},
-	// note that r_{i, j, k} = r_{j, i, k}, and R_{i, j} = {r_{i, j, k}}_k
+	// note that r_{i, j, k} = r_{j, i, k}, and R_{i, j} = {r_{i, j, k}}_k is derived on demand from a per-pair seed
 	if err := c.generateEpochTXRandomness(); err != nil {
 		return nil, err
 	}
@@ -71,7 +149,7 @@ func (c *Committee) InitializeEpoch(
 	}
 
 	// IN.3: generate secret-public key pairs for the organizations
-	if err := c.generateEpochKeyPairs(); err != nil {
+	if err := generateKeyPairs(); err != nil {
 		return nil, err
 	}
 
@@ -80,7 +158,7 @@ func (c *Committee) InitializeEpoch(
 	// IN.5: forward the transaction randomnesses, auditor randomnesses and secret keys to the auditors
 	// We need to forward: {r_{i, j, k}}, {r_z}, and {sk_i}
 	for _, aud := range auditors {
-		err := c.ForwardEpochAuditorParameters(aud)
+		err := forwardAuditorParameters(aud)
 		if err != nil {
 			return nil, err
 		}
@@ -93,26 +171,20 @@ func (c *Committee) InitializeEpoch(
 		}
 	}
 
+	if err := c.archiveEpoch(); err != nil {
+		return nil, err
+	}
 	// IN.4
 	return c.epochPublicKeyMap, nil
 }
 
 func (c *Committee) generateEpochTXRandomness() error {
-	// generate randomness for the transactions
-	// distribute randomness to organizations
-	// the complexity is O(n^2) here
-	c.epochTXRandMap = make(map[[2]string][]kyber.Scalar)
-	for i := 0; i < len(c.managedOrgIDs); i++ {
-		for j := i + 1; j < len(c.managedOrgIDs); j++ {
-			orgID1 := c.managedOrgIDs[i]
-			orgID2 := c.managedOrgIDs[j]
-			orgIDHash1 := organization.IDHashString(orgID1)
-			orgIDHash2 := organization.IDHashString(orgID2)
-			key := organization.IDHashKey(orgIDHash1, orgIDHash2)
-			if _, ok := c.epochTXRandMap[key]; ok {
-				continue
-			}
-			c.epochTXRandMap[key] = crypto.RandScalars(constants.MaxNumTXInEpoch)
+	// generate randomness for the transactions of the pairs in the topology,
+	// the complexity is linear in the number of edges, O(n^2) only for the full topology
+	c.epochTXSeedMap = make(map[[2]string][]byte)
+	for _, edge := range c.epochTopology.Edges() {
+		if _, _, err := c.generateEdgeSeed(edge[0], edge[1]); err != nil {
+			return err
 		}
 	}
 	return nil
@@ -120,7 +192,7 @@ func (c *Committee) generateEpochTXRandomness() error {
 
 func (c *Committee) generateEpochKeyPairs() error {
 	for _, id := range c.managedOrgIDs {
-		privateKey, publicKey, err := crypto.KeyGen()
+		privateKey, publicKey, err := crypto.KeyGen(c.randStream)
 		if err != nil {
 			return err
 		}
@@ -140,45 +212,69 @@ func (c *Committee) PublishPublicKeys() map[string]kyber.Point {
 	return publicKeyMap
 }
 
+// PublishEpochIDCommitments returns the commitments to the epoch IDs of the organizations and the auditors,
+// keyed by their ID hashes and published with the public keys (IN.4).
+// The epoch IDs are disclosed in the verdicts, and anyone can check them against the commitments.
+func (c *Committee) PublishEpochIDCommitments() map[string][]byte {
+	commitmentMap := make(map[string][]byte)
+	for orgID, epochID := range c.epochOrgIDMap {
+		commitmentMap[organization.IDHashString(orgID)] = EpochIDCommitment(epochID)
+	}
+	for audID, epochID := range c.epochAuditorIDMap {
+		commitmentMap[auditor.IDHashString(audID)] = EpochIDCommitment(epochID)
+	}
+	return commitmentMap
+}
+
+// EpochIDCommitment returns the commitment to an epoch ID, the epoch IDs are random,
+// so the commitment hides the epoch ID until it is disclosed.
+func EpochIDCommitment(epochID []byte) []byte {
+	return hashing.Sum(hashing.TagEpochIDCommitment, epochID)
+}
+
 func (c *Committee) ForwardEpochAuditorParameters(auditor *auditor.Auditor) error {
-	auditedOrgIDList, ok := c.managedEntityMap[auditor.ID]
-	if !ok {
-		return errors.New(string("auditor not found, id: " + auditor.ID))
-	}
-	// forward transaction randomnesses
-	auditedOrgIDHashList := make([]string, len(auditedOrgIDList))
-	for i, orgID := range auditedOrgIDList {
-		auditedOrgIDHashList[i] = organization.IDHashString(orgID)
-	}
-	managedOrgIDHashList := make([]string, len(c.managedOrgIDs))
-	for i, orgID := range c.managedOrgIDs {
-		managedOrgIDHashList[i] = organization.IDHashString(orgID)
-	}
-	orgTXRandMap := make(map[[2]string][]kyber.Scalar)
-	for _, orgIDHash1 := range auditedOrgIDHashList {
-		for _, orgIDHash2 := range managedOrgIDHashList {
-			if orgIDHash1 == orgIDHash2 {
-				continue
-			}
-			key := organization.IDHashKey(orgIDHash1, orgIDHash2)
-			if _, ok := c.epochTXRandMap[key]; !ok {
-				return errors.New("randomness not found, key: " + key[0] + key[1])
-			}
-			orgTXRandMap[key] = c.epochTXRandMap[key]
-		}
+	if err := c.forwardEpochAuditorPublicParameters(auditor); err != nil {
+		return err
 	}
 	// Forward secret key
+	auditedOrgIDList := c.managedEntityMap[auditor.ID]
 	auditedOrgSecretKeyMap := make(map[string]crypto.TypePrivateKey)
 	for _, orgID := range auditedOrgIDList {
 		orgIDHash := organization.IDHashString(orgID)
-		secretKey, ok := c.epochSecretKeyMap[orgIDHash]
-		if !ok {
-			return errors.New(string("secret key not found, id: " + orgID))
+		secretKey, err := c.epochSecretKey(orgIDHash)
+		if err != nil {
+			return err
 		}
 		auditedOrgSecretKeyMap[orgIDHash] = secretKey
 	}
-	auditor.SetEpochTXRandomness(orgTXRandMap)
 	auditor.SetEpochSecretKey(auditedOrgSecretKeyMap)
+	return nil
+}
+
+// epochSecretKey returns the epoch secret key of the organization, the committee of a ThresholdCommittee
+// refuses explicitly as no one holds the whole key.
+func (c *Committee) epochSecretKey(orgIDHash string) (crypto.TypePrivateKey, error) {
+	if c.sharedEpochKeys {
+		return nil, errSharedEpochKeys
+	}
+	secretKey, ok := c.epochSecretKeyMap[orgIDHash]
+	if !ok {
+		return nil, errors.New("secret key not found, id hash: " + orgIDHash)
+	}
+	return secretKey, nil
+}
+
+// forwardEpochAuditorPublicParameters forwards everything except the secret keys to the auditor.
+func (c *Committee) forwardEpochAuditorPublicParameters(auditor *auditor.Auditor) error {
+	auditedOrgIDList, ok := c.managedEntityMap[auditor.ID]
+	if !ok {
+		return errors.New(string("auditor not found, id: " + auditor.ID))
+	}
+	orgTXSeedMap, err := c.auditedOrgTXSeeds(auditedOrgIDList)
+	if err != nil {
+		return err
+	}
+	auditor.SetEpochTXSeeds(orgTXSeedMap)
 
 	// set the epoch ID
 	epochID, ok := c.epochAuditorIDMap[auditor.ID]
@@ -188,22 +284,48 @@ func (c *Committee) ForwardEpochAuditorParameters(auditor *auditor.Auditor) erro
 	auditor.SetEpochID(epochID)
 
 	// forward organization epoch ID
+	epochOrgIDMap, err := c.auditedOrgEpochIDs(auditedOrgIDList)
+	if err != nil {
+		return err
+	}
+	auditor.SetEpochOrgIDMap(epochOrgIDMap)
+	return nil
+}
+
+// auditedOrgTXSeeds returns the seeds of the transaction randomnesses of the edges of the audited organizations.
+func (c *Committee) auditedOrgTXSeeds(auditedOrgIDList []organization.TypeID) (map[[2]string][]byte, error) {
+	orgTXSeedMap := make(map[[2]string][]byte)
+	for _, orgID1 := range auditedOrgIDList {
+		orgIDHash1 := organization.IDHashString(orgID1)
+		for _, orgID2 := range c.epochTopology.Neighbors(orgID1) {
+			key := organization.IDHashKey(orgIDHash1, organization.IDHashString(orgID2))
+			if _, ok := c.epochTXSeedMap[key]; !ok {
+				return nil, errors.New("randomness not found, key: " + key[0] + key[1])
+			}
+			orgTXSeedMap[key] = c.epochTXSeedMap[key]
+		}
+	}
+	return orgTXSeedMap, nil
+}
+
+func (c *Committee) auditedOrgEpochIDs(
+	auditedOrgIDList []organization.TypeID,
+) (map[organization.TypeID]organization.TypeEpochID, error) {
 	epochOrgIDMap := make(map[organization.TypeID]organization.TypeEpochID)
 	for _, orgID := range auditedOrgIDList {
 		epochID, ok := c.epochOrgIDMap[orgID]
 		if !ok {
-			return errors.New(string("epoch ID not found, id: " + orgID))
+			return nil, errors.New(string("epoch ID not found, id: " + orgID))
 		}
 		epochOrgIDMap[orgID] = epochID
 	}
-	auditor.SetEpochOrgIDMap(epochOrgIDMap)
-	return nil
+	return epochOrgIDMap, nil
 }
 
 func (c *Committee) generateEpochOrgIDs() error {
 	c.epochOrgIDMap = make(map[organization.TypeID]organization.TypeEpochID)
 	for _, id := range c.managedOrgIDs {
-		randBytes, err := crypto.RandBytes()
+		randBytes, err := crypto.RandBytes(c.randStream)
 		if err != nil {
 			return err
 		}
@@ -215,7 +337,7 @@ func (c *Committee) generateEpochOrgIDs() error {
 func (c *Committee) generateEpochAuditorIDs() error {
 	c.epochAuditorIDMap = make(map[auditor.TypeID]auditor.TypeEpochID)
 	for _, id := range c.managedAuditorIDs {
-		randBytes, err := crypto.RandBytes()
+		randBytes, err := crypto.RandBytes(c.randStream)
 		if err != nil {
 			return err
 		}
@@ -250,9 +372,9 @@ func (c *Committee) VerifyOrgAndAudResult(
 	if err != nil {
 		return false, err
 	}
-	privateKey, ok := c.epochSecretKeyMap[organization.IDHashString(orgID)]
-	if !ok {
-		return false, errors.New(string("secret key not found, id: " + orgID))
+	privateKey, err := c.epochSecretKey(organization.IDHashString(orgID))
+	if err != nil {
+		return false, err
 	}
 	pointB, err := crypto.DecryptPoint(privateKey, cipherBBytes)
 	if err != nil {
@@ -283,13 +405,13 @@ func (c *Committee) VerifyAuditPairResult(
 	audChainTX1 *transaction.AudOnChain,
 	audChainTX2 *transaction.AudOnChain,
 ) (bool, error) {
-	privateKey1, ok := c.epochSecretKeyMap[organization.IDHashString(orgID1)]
-	if !ok {
-		return false, errors.New(string("secret key not found, id: " + orgID1))
+	privateKey1, err := c.epochSecretKey(organization.IDHashString(orgID1))
+	if err != nil {
+		return false, err
 	}
-	privateKey2, ok := c.epochSecretKeyMap[organization.IDHashString(orgID2)]
-	if !ok {
-		return false, errors.New(string("secret key not found, id: " + orgID2))
+	privateKey2, err := c.epochSecretKey(organization.IDHashString(orgID2))
+	if err != nil {
+		return false, err
 	}
 	cipherD1Bytes, err := hex.DecodeString(audChainTX1.CipherD)
 	if err != nil {
@@ -0,0 +1,102 @@
+package committee
+
+import (
+	"fmt"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+)
+
+// epochMaterial is the key material of an epoch archived by the committee for re-verifying its results.
+// The maps are shared with the committee until the next epoch is initialized,
+// so the edges requested in the middle of the epoch are archived as well.
+type epochMaterial struct {
+	epoch             *epoch.Epoch
+	topology          *Topology
+	epochTXSeedMap    map[[2]string][]byte
+	epochSecretKeyMap map[string]crypto.TypePrivateKey
+	epochPublicKeyMap map[string]crypto.TypePublicKey
+	epochOrgIDMap     map[organization.TypeID]organization.TypeEpochID
+	epochAuditorIDMap map[auditor.TypeID]auditor.TypeEpochID
+}
+
+// OpenEpoch opens the epoch initialized by the next InitializeEpoch, the epoch IDs must increase.
+// The material of the previous epochs stays archived.
+func (c *Committee) OpenEpoch(e *epoch.Epoch) error {
+	if e.State() != epoch.StateOpen {
+		return fmt.Errorf("epoch %d is %s", e.ID, e.State())
+	}
+	if c.currentEpoch != nil && e.ID <= c.currentEpoch.ID {
+		return fmt.Errorf("epoch %d opened after epoch %d", e.ID, c.currentEpoch.ID)
+	}
+	c.currentEpoch = e
+	return nil
+}
+
+// Epoch returns the current epoch, or nil if no epoch is opened.
+func (c *Committee) Epoch() *epoch.Epoch {
+	return c.currentEpoch
+}
+
+// AdvanceEpoch moves the current epoch to the next state.
+func (c *Committee) AdvanceEpoch(to epoch.State) error {
+	if c.currentEpoch == nil {
+		return fmt.Errorf("no epoch opened")
+	}
+	return c.currentEpoch.Advance(to)
+}
+
+// checkEpochOpen rejects initializing an epoch twice.
+func (c *Committee) checkEpochOpen() error {
+	if c.currentEpoch != nil && c.currentEpoch.State() != epoch.StateOpen {
+		return fmt.Errorf("epoch %d is already initialized", c.currentEpoch.ID)
+	}
+	return nil
+}
+
+// checkEpochRecording rejects changing the material of an epoch once it is closed,
+// or before it is initialized.
+func (c *Committee) checkEpochRecording() error {
+	if c.currentEpoch != nil && c.currentEpoch.State() != epoch.StateRecording {
+		return fmt.Errorf("epoch %d is %s", c.currentEpoch.ID, c.currentEpoch.State())
+	}
+	return nil
+}
+
+// archiveEpoch archives the material of the current epoch and starts its recording.
+func (c *Committee) archiveEpoch() error {
+	if c.currentEpoch == nil {
+		return nil
+	}
+	c.epochHistory[c.currentEpoch.ID] = &epochMaterial{
+		epoch:             c.currentEpoch,
+		topology:          c.epochTopology,
+		epochTXSeedMap:    c.epochTXSeedMap,
+		epochSecretKeyMap: c.epochSecretKeyMap,
+		epochPublicKeyMap: c.epochPublicKeyMap,
+		epochOrgIDMap:     c.epochOrgIDMap,
+		epochAuditorIDMap: c.epochAuditorIDMap,
+	}
+	return c.currentEpoch.Advance(epoch.StateRecording)
+}
+
+// AtEpoch returns a view of the committee holding the archived material of the epoch,
+// the verification methods of the view re-verify the results submitted in that epoch.
+// The epoch secret keys of a threshold committee are held by its members and are not archived.
+func (c *Committee) AtEpoch(epochID epoch.TypeID) (*Committee, error) {
+	material, ok := c.epochHistory[epochID]
+	if !ok {
+		return nil, fmt.Errorf("epoch %d not found", epochID)
+	}
+	view := *c
+	view.currentEpoch = material.epoch
+	view.epochTopology = material.topology
+	view.epochTXSeedMap = material.epochTXSeedMap
+	view.epochSecretKeyMap = material.epochSecretKeyMap
+	view.epochPublicKeyMap = material.epochPublicKeyMap
+	view.epochOrgIDMap = material.epochOrgIDMap
+	view.epochAuditorIDMap = material.epochAuditorIDMap
+	return &view, nil
+}
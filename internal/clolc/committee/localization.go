@@ -0,0 +1,343 @@
+package committee
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+// Disclosure is what an organization discloses of its transactions with a counterparty in a dispute,
+// the transactions read from its local chain with the amounts and the randomness opening their commitments,
+// all in the order the transactions were recorded.
+type Disclosure struct {
+	TXs        []*transaction.LocalOnChain
+	Amounts    []int64
+	Randomness []kyber.Scalar
+}
+
+// Mismatch is a pair of transactions recorded by the two organizations under the same key,
+// whose assets differ or whose amounts do not cancel. The key is the timestamp and the counter
+// of the transaction among the transactions of the organization with the same timestamp.
+// TX1 or TX2 is nil if the organization recorded no transaction under the key.
+// The amounts and the randomness open the commitments of the transactions.
+type Mismatch struct {
+	Index       int
+	Timestamp   int64
+	Counter     int
+	TX1         *transaction.LocalOnChain
+	TX2         *transaction.LocalOnChain
+	Asset1      string
+	Asset2      string
+	Amount1     int64
+	Amount2     int64
+	Randomness1 kyber.Scalar
+	Randomness2 kyber.Scalar
+}
+
+// InconsistencyEvidence is the outcome of LocalizeInconsistency,
+// it can be verified by both organizations with VerifyInconsistencyEvidence.
+type InconsistencyEvidence struct {
+	OrgID1     organization.TypeID
+	OrgID2     organization.TypeID
+	NumTXs1    int
+	NumTXs2    int
+	Mismatches []*Mismatch
+}
+
+// txKey identifies the transaction of a pair on both sides, independent of the order of recording.
+type txKey struct {
+	timestamp int64
+	counter   int
+}
+
+// matchedTX is a key with the positions of its transactions in the two disclosures, -1 for a missing one.
+type matchedTX struct {
+	key        txKey
+	idx1, idx2 int
+}
+
+// SubAccumulator returns sum_k R_k * amount_k * G_asset over the matched transactions in [start, end) of one side,
+// where R_k is the committee randomness of the pair for the k-th matched key. The same R_k is applied to both sides,
+// so the sub-accumulators of the two sides cancel if the amounts of the matched transactions do.
+// positions maps the matched keys to the transactions of the disclosure, -1 if the side has none.
+func (c *Committee) SubAccumulator(
+	orgID, counterPartyID organization.TypeID, disclosure *Disclosure, positions []int, start, end int,
+) (kyber.Point, error) {
+	key := organization.IDHashKey(organization.IDHashString(orgID), organization.IDHashString(counterPartyID))
+	seed, ok := c.epochTXSeedMap[key]
+	if !ok {
+		return nil, errors.New(string("randomness not found, id: " + orgID + ", " + counterPartyID))
+	}
+	if start < 0 || start > end || end > len(positions) {
+		return nil, fmt.Errorf("invalid range [%d, %d)", start, end)
+	}
+	result := crypto.KyberSuite.Point().Null()
+	comTXRandList := crypto.PRFScalars(seed, uint64(start), uint64(end))
+	for idx := start; idx < end; idx++ {
+		pos := positions[idx]
+		if pos < 0 {
+			continue
+		}
+		amountPoint, err := openAmountPoint(
+			disclosure.TXs[pos], disclosure.Amounts[pos], disclosure.Randomness[pos], c.LegacyCutoff,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if amountPoint == nil {
+			return nil, fmt.Errorf("the disclosure of %s does not open the commitment of transaction %d", orgID, pos)
+		}
+		result.Add(result, amountPoint.Mul(comTXRandList[idx-start], amountPoint))
+	}
+	return result, nil
+}
+
+// LocalizeInconsistency bisects the transactions of a pair that failed the consistency examination,
+// the transactions of the two organizations are matched by their keys first, so the order of recording does not matter.
+// A range is split only if the sub-accumulators of the two organizations over it do not cancel,
+// it finds the mismatched keys with O(m log n) sub-accumulator checks for m mismatches.
+func (c *Committee) LocalizeInconsistency(
+	orgID1, orgID2 organization.TypeID, disclosure1, disclosure2 *Disclosure,
+) (*InconsistencyEvidence, error) {
+	for _, side := range []struct {
+		orgID      organization.TypeID
+		disclosure *Disclosure
+	}{{orgID1, disclosure1}, {orgID2, disclosure2}} {
+		if len(side.disclosure.TXs) != len(side.disclosure.Amounts) ||
+			len(side.disclosure.TXs) != len(side.disclosure.Randomness) {
+			return nil, fmt.Errorf("the disclosure of %s has lists of different lengths", side.orgID)
+		}
+	}
+	matched, err := matchTransactions(disclosure1.TXs, disclosure2.TXs)
+	if err != nil {
+		return nil, err
+	}
+	positions1 := make([]int, len(matched))
+	positions2 := make([]int, len(matched))
+	for idx, m := range matched {
+		positions1[idx], positions2[idx] = m.idx1, m.idx2
+	}
+	evidence := &InconsistencyEvidence{
+		OrgID1:  orgID1,
+		OrgID2:  orgID2,
+		NumTXs1: len(disclosure1.TXs),
+		NumTXs2: len(disclosure2.TXs),
+	}
+	var bisect func(start, end int) error
+	bisect = func(start, end int) error {
+		point1, err := c.SubAccumulator(orgID1, orgID2, disclosure1, positions1, start, end)
+		if err != nil {
+			return err
+		}
+		point2, err := c.SubAccumulator(orgID2, orgID1, disclosure2, positions2, start, end)
+		if err != nil {
+			return err
+		}
+		if point1.Add(point1, point2).Equal(crypto.KyberSuite.Point().Null()) {
+			return nil
+		}
+		if end-start == 1 {
+			m := matched[start]
+			mismatch := &Mismatch{Index: start, Timestamp: m.key.timestamp, Counter: m.key.counter}
+			if m.idx1 >= 0 {
+				mismatch.TX1, mismatch.Asset1 = disclosure1.TXs[m.idx1], disclosure1.TXs[m.idx1].Asset
+				mismatch.Amount1, mismatch.Randomness1 = disclosure1.Amounts[m.idx1], disclosure1.Randomness[m.idx1]
+			}
+			if m.idx2 >= 0 {
+				mismatch.TX2, mismatch.Asset2 = disclosure2.TXs[m.idx2], disclosure2.TXs[m.idx2].Asset
+				mismatch.Amount2, mismatch.Randomness2 = disclosure2.Amounts[m.idx2], disclosure2.Randomness[m.idx2]
+			}
+			evidence.Mismatches = append(evidence.Mismatches, mismatch)
+			return nil
+		}
+		mid := (start + end) / 2
+		if err = bisect(start, mid); err != nil {
+			return err
+		}
+		return bisect(mid, end)
+	}
+	if len(matched) > 0 {
+		if err = bisect(0, len(matched)); err != nil {
+			return nil, err
+		}
+	}
+	return evidence, nil
+}
+
+// matchTransactions pairs the transactions of the two lists with the same key,
+// the result is sorted by the key and has the keys of both lists.
+func matchTransactions(txList1, txList2 []*transaction.LocalOnChain) ([]matchedTX, error) {
+	keys1, err := transactionKeys(txList1)
+	if err != nil {
+		return nil, err
+	}
+	keys2, err := transactionKeys(txList2)
+	if err != nil {
+		return nil, err
+	}
+	matchedMap := make(map[txKey]*matchedTX, len(keys1))
+	var matched []*matchedTX
+	for idx, key := range keys1 {
+		m := &matchedTX{key: key, idx1: idx, idx2: -1}
+		matchedMap[key] = m
+		matched = append(matched, m)
+	}
+	for idx, key := range keys2 {
+		if m, ok := matchedMap[key]; ok {
+			m.idx2 = idx
+			continue
+		}
+		matched = append(matched, &matchedTX{key: key, idx1: -1, idx2: idx})
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		if matched[i].key.timestamp != matched[j].key.timestamp {
+			return matched[i].key.timestamp < matched[j].key.timestamp
+		}
+		return matched[i].key.counter < matched[j].key.counter
+	})
+	result := make([]matchedTX, len(matched))
+	for idx, m := range matched {
+		result[idx] = *m
+	}
+	return result, nil
+}
+
+// transactionKeys returns the keys of the transactions, the counter numbers the transactions
+// with the same timestamp in the order they were recorded.
+func transactionKeys(txList []*transaction.LocalOnChain) ([]txKey, error) {
+	counters := make(map[int64]int)
+	keys := make([]txKey, len(txList))
+	for idx, tx := range txList {
+		timestamp, err := strconv.ParseInt(tx.Timestamp, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		keys[idx] = txKey{timestamp: timestamp, counter: counters[timestamp]}
+		counters[timestamp]++
+	}
+	return keys, nil
+}
+
+// VerifyInconsistencyEvidence checks that the transactions in the evidence are signed by the organizations,
+// recorded with the right counterparties under the key of the mismatch, that the disclosed amounts and randomness
+// open their commitments, and that the assets or the amounts of every mismatch differ.
+// The counters and the absence of a transaction follow from the full disclosures seen by the committee,
+// they cannot be checked from the evidence alone. The legacy cutoff is the one of crypto.CheckBlindingGenerator.
+func VerifyInconsistencyEvidence(
+	evidence *InconsistencyEvidence, signingPublicKey1, signingPublicKey2 crypto.TypePublicKey, legacyCutoff int64,
+) (bool, error) {
+	if len(evidence.Mismatches) == 0 {
+		return false, nil
+	}
+	for _, mismatch := range evidence.Mismatches {
+		if mismatch.TX1 == nil && mismatch.TX2 == nil {
+			return false, nil
+		}
+		sum := crypto.KyberSuite.Point().Null()
+		sides := []struct {
+			tx             *transaction.LocalOnChain
+			asset          string
+			amount         int64
+			randomness     kyber.Scalar
+			counterPartyID organization.TypeID
+			publicKey      crypto.TypePublicKey
+		}{
+			{mismatch.TX1, mismatch.Asset1, mismatch.Amount1, mismatch.Randomness1, evidence.OrgID2, signingPublicKey1},
+			{mismatch.TX2, mismatch.Asset2, mismatch.Amount2, mismatch.Randomness2, evidence.OrgID1, signingPublicKey2},
+		}
+		for _, side := range sides {
+			if side.tx == nil {
+				continue
+			}
+			if side.tx.CounterParty != organization.IDHashString(side.counterPartyID) || side.tx.Asset != side.asset {
+				return false, nil
+			}
+			if side.tx.Timestamp != strconv.FormatInt(mismatch.Timestamp, 10) {
+				return false, nil
+			}
+			ok, err := side.tx.VerifySignature(side.publicKey)
+			if err != nil || !ok {
+				return false, err
+			}
+			amountPoint, err := openAmountPoint(side.tx, side.amount, side.randomness, legacyCutoff)
+			if err != nil || amountPoint == nil {
+				return false, err
+			}
+			sum.Add(sum, amountPoint)
+		}
+		if sum.Equal(crypto.KyberSuite.Point().Null()) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// Discrepancy returns the sum of the amounts of the mismatched transactions after checking that they open the commitments,
+// it is zero for a consistent pair and within the bound of the table for realistic amounts.
+// The table must be built for the asset of the transactions, the amounts of different assets do not add up.
+// The legacy cutoff is the one of crypto.CheckBlindingGenerator.
+func (m *Mismatch) Discrepancy(table *crypto.BSGSTable, legacyCutoff int64) (int64, error) {
+	sum := crypto.KyberSuite.Point().Null()
+	sides := []struct {
+		tx         *transaction.LocalOnChain
+		amount     int64
+		randomness kyber.Scalar
+	}{
+		{m.TX1, m.Amount1, m.Randomness1},
+		{m.TX2, m.Amount2, m.Randomness2},
+	}
+	for _, side := range sides {
+		if side.tx == nil {
+			continue
+		}
+		if side.tx.Asset != table.Asset {
+			return 0, fmt.Errorf("transaction in asset %q, table for asset %q", side.tx.Asset, table.Asset)
+		}
+		amountPoint, err := openAmountPoint(side.tx, side.amount, side.randomness, legacyCutoff)
+		if err != nil {
+			return 0, err
+		}
+		if amountPoint == nil {
+			return 0, errors.New("the amount and the randomness do not open the commitment")
+		}
+		sum.Add(sum, amountPoint)
+	}
+	return table.Solve(sum)
+}
+
+// openAmountPoint checks that the amount and the randomness open the commitment of the transaction,
+// i.e., C == amount * G_asset + r * H with the blinding generator H of the transaction,
+// and returns amount * G_asset. It returns nil if they do not.
+// The legacy blinding generator, which could open to any amount, is rejected after the legacy cutoff.
+func openAmountPoint(
+	tx *transaction.LocalOnChain, amount int64, randomness kyber.Scalar, legacyCutoff int64,
+) (kyber.Point, error) {
+	hiddenTX, err := tx.ToHidden()
+	if err != nil {
+		return nil, err
+	}
+	if err = crypto.CheckBlindingGenerator(hiddenTX.BlindingGenerator, hiddenTX.Timestamp, legacyCutoff); err != nil {
+		return nil, err
+	}
+	commitment := crypto.KyberSuite.Point()
+	if err = commitment.UnmarshalBinary(hiddenTX.Commitment); err != nil {
+		return nil, err
+	}
+	ok, err := crypto.VerifyPedersenOpening(commitment, hiddenTX.BlindingGenerator, tx.Asset, amount, randomness)
+	if err != nil || !ok {
+		return nil, err
+	}
+	blindingGen, err := crypto.BlindingGenerator(hiddenTX.BlindingGenerator)
+	if err != nil {
+		return nil, err
+	}
+	randPoint := crypto.KyberSuite.Point().Mul(randomness, blindingGen)
+	return commitment.Sub(commitment, randPoint), nil
+}
@@ -0,0 +1,140 @@
+package committee
+
+import (
+	"fmt"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/keystore"
+)
+
+// ExportEpochSecrets returns the archived key material of the epoch for the key store,
+// the entry expires at the end of its retention period.
+func (c *Committee) ExportEpochSecrets(epochID epoch.TypeID, expiresAt int64) (*keystore.EpochSecrets, error) {
+	material, ok := c.epochHistory[epochID]
+	if !ok {
+		return nil, fmt.Errorf("epoch %d not found", epochID)
+	}
+	secrets := keystore.NewEpochSecrets(material.epoch, expiresAt)
+	for idHash, secretKey := range material.epochSecretKeyMap {
+		secrets.SecretKeys[idHash] = secretKey
+	}
+	for idHash, publicKey := range material.epochPublicKeyMap {
+		secrets.PublicKeys[idHash] = publicKey
+	}
+	for key, seed := range material.epochTXSeedMap {
+		secrets.TXSeeds[key] = seed
+	}
+	for orgID, epochOrgID := range material.epochOrgIDMap {
+		secrets.OrgEpochIDs[string(orgID)] = epochOrgID
+	}
+	for audID, epochAudID := range material.epochAuditorIDMap {
+		secrets.AuditorEpochIDs[string(audID)] = epochAudID
+	}
+	for _, edge := range material.topology.Edges() {
+		secrets.Edges = append(secrets.Edges, [2]string{string(edge[0]), string(edge[1])})
+	}
+	return secrets, nil
+}
+
+// RestoreEpochSecrets archives the key material of an epoch read from the key store, e.g., after a restart.
+// The epoch becomes the current one if it is the latest, so the verification of an in-flight epoch resumes.
+func (c *Committee) RestoreEpochSecrets(secrets *keystore.EpochSecrets) error {
+	e, err := secrets.Epoch()
+	if err != nil {
+		return err
+	}
+	material := &epochMaterial{
+		epoch:             e,
+		topology:          NewTopology(),
+		epochTXSeedMap:    make(map[[2]string][]byte),
+		epochSecretKeyMap: make(map[string]crypto.TypePrivateKey),
+		epochPublicKeyMap: make(map[string]crypto.TypePublicKey),
+		epochOrgIDMap:     make(map[organization.TypeID]organization.TypeEpochID),
+		epochAuditorIDMap: make(map[auditor.TypeID]auditor.TypeEpochID),
+	}
+	for idHash, secretKey := range secrets.SecretKeys {
+		material.epochSecretKeyMap[idHash] = secretKey
+	}
+	for idHash, publicKey := range secrets.PublicKeys {
+		material.epochPublicKeyMap[idHash] = publicKey
+	}
+	for key, seed := range secrets.TXSeeds {
+		material.epochTXSeedMap[key] = seed
+	}
+	for orgID, epochOrgID := range secrets.OrgEpochIDs {
+		material.epochOrgIDMap[organization.TypeID(orgID)] = epochOrgID
+	}
+	for audID, epochAudID := range secrets.AuditorEpochIDs {
+		material.epochAuditorIDMap[auditor.TypeID(audID)] = epochAudID
+	}
+	for _, edge := range secrets.Edges {
+		if err = material.topology.AddEdge(organization.TypeID(edge[0]), organization.TypeID(edge[1])); err != nil {
+			return err
+		}
+	}
+	c.epochHistory[e.ID] = material
+	if c.currentEpoch != nil && e.ID < c.currentEpoch.ID {
+		return nil
+	}
+	c.currentEpoch = e
+	c.epochTopology = material.topology
+	c.epochTXSeedMap = material.epochTXSeedMap
+	c.epochSecretKeyMap = material.epochSecretKeyMap
+	c.epochPublicKeyMap = material.epochPublicKeyMap
+	c.epochOrgIDMap = material.epochOrgIDMap
+	c.epochAuditorIDMap = material.epochAuditorIDMap
+	return nil
+}
+
+// EpochAuditorSecrets returns the parameters of the current epoch the auditor is entitled to,
+// i.e., the seeds of the edges and the secret keys and epoch IDs of its audited organizations, and its epoch ID.
+// It is the pull counterpart of ForwardEpochAuditorParameters for auditors running as separate processes.
+func (c *Committee) EpochAuditorSecrets(audID auditor.TypeID) (*keystore.EpochSecrets, error) {
+	if c.currentEpoch == nil {
+		return nil, fmt.Errorf("no epoch opened")
+	}
+	auditedOrgIDList, ok := c.managedEntityMap[audID]
+	if !ok {
+		return nil, fmt.Errorf("auditor %s not found", audID)
+	}
+	epochAudID, ok := c.epochAuditorIDMap[audID]
+	if !ok {
+		return nil, fmt.Errorf("epoch ID of auditor %s not found", audID)
+	}
+	orgTXSeedMap, err := c.auditedOrgTXSeeds(auditedOrgIDList)
+	if err != nil {
+		return nil, err
+	}
+	epochOrgIDMap, err := c.auditedOrgEpochIDs(auditedOrgIDList)
+	if err != nil {
+		return nil, err
+	}
+	secrets := keystore.NewEpochSecrets(c.currentEpoch, c.currentEpoch.EndTime)
+	secrets.AuditorEpochIDs[string(audID)] = epochAudID
+	secrets.TXSeeds = orgTXSeedMap
+	for orgID, epochOrgID := range epochOrgIDMap {
+		secrets.OrgEpochIDs[string(orgID)] = epochOrgID
+	}
+	for _, orgID := range auditedOrgIDList {
+		orgIDHash := organization.IDHashString(orgID)
+		secretKey, err := c.epochSecretKey(orgIDHash)
+		if err != nil {
+			return nil, err
+		}
+		secrets.SecretKeys[orgIDHash] = secretKey
+		secrets.PublicKeys[orgIDHash] = c.epochPublicKeyMap[orgIDHash]
+	}
+	return secrets, nil
+}
+
+// EpochOrgID returns the epoch ID of the organization in the current epoch.
+func (c *Committee) EpochOrgID(orgID organization.TypeID) (organization.TypeEpochID, error) {
+	epochOrgID, ok := c.epochOrgIDMap[orgID]
+	if !ok {
+		return nil, fmt.Errorf("epoch ID of organization %s not found", orgID)
+	}
+	return epochOrgID, nil
+}
@@ -0,0 +1,328 @@
+package committee
+
+import (
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+// Member is one of the n members of a threshold committee,
+// it holds a share of the epoch secret key of every managed organization.
+type Member struct {
+	Index            int
+	epochKeyShareMap map[string]*crypto.ThresholdKeyShare
+	randStream       cipher.Stream
+	unavailable      atomic.Bool
+}
+
+// SetAvailable marks whether the member answers the decryption requests of the auditors, members are available by default.
+func (m *Member) SetAvailable(available bool) {
+	m.unavailable.Store(!available)
+}
+
+// Available returns true if the member answers the decryption requests of the auditors.
+func (m *Member) Available() bool {
+	return !m.unavailable.Load()
+}
+
+// DecryptShare computes the decryption share of a hex encoded aud-chain ciphertext
+// under the epoch key of the organization.
+func (m *Member) DecryptShare(orgIDHash, cipherHex string) (*crypto.DecryptionShare, error) {
+	keyShare, ok := m.epochKeyShareMap[orgIDHash]
+	if !ok {
+		return nil, fmt.Errorf("key share not found, member: %d, id hash: %s", m.Index, orgIDHash)
+	}
+	cipherBytes, err := hex.DecodeString(cipherHex)
+	if err != nil {
+		return nil, err
+	}
+	return keyShare.DecryptShare(cipherBytes, m.randStream)
+}
+
+// ThresholdCommittee is a CLOLC committee whose epoch key pairs are generated distributively among
+// the members, no member knows the epoch secret keys and any Threshold members are needed to decrypt.
+// The auditors do not receive the epoch secret keys, they request decryptions with ThresholdDecrypt.
+// The promoted methods of Committee needing the whole epoch secret keys fail explicitly.
+type ThresholdCommittee struct {
+	*Committee
+	Threshold               int
+	Members                 []*Member
+	epochVerificationKeyMap map[string][]kyber.Point
+}
+
+// NewThreshold creates a threshold committee whose members each draw their randomness from their own OS-backed source.
+func NewThreshold(id string, auditors []*auditor.Auditor, threshold, numMembers int) (*ThresholdCommittee, error) {
+	memberRands := make([]cipher.Stream, numMembers)
+	for i := range memberRands {
+		memberRands[i] = crypto.RandomStream()
+	}
+	return newThreshold(id, auditors, threshold, crypto.RandomStream(), memberRands)
+}
+
+// NewThresholdWithRandStream creates a threshold committee whose members draw their randomness from streams
+// seeded by the given stream, use crypto.NewSeededStream for reproducible runs.
+// Every member randomness is then known to the holder of the stream, so it is not meant for production runs.
+func NewThresholdWithRandStream(
+	id string, auditors []*auditor.Auditor, threshold, numMembers int, rand cipher.Stream,
+) (*ThresholdCommittee, error) {
+	if numMembers < 0 {
+		return nil, fmt.Errorf("invalid threshold %d for %d members", threshold, numMembers)
+	}
+	memberRands := make([]cipher.Stream, numMembers)
+	for i := range memberRands {
+		seed, err := crypto.RandBytes(rand)
+		if err != nil {
+			return nil, err
+		}
+		memberRands[i] = crypto.NewSeededStream(seed)
+	}
+	return newThreshold(id, auditors, threshold, rand, memberRands)
+}
+
+func newThreshold(
+	id string, auditors []*auditor.Auditor, threshold int, rand cipher.Stream, memberRands []cipher.Stream,
+) (*ThresholdCommittee, error) {
+	numMembers := len(memberRands)
+	if threshold <= 0 || threshold > numMembers {
+		return nil, fmt.Errorf("invalid threshold %d for %d members", threshold, numMembers)
+	}
+	com := &ThresholdCommittee{
+		Committee:               NewWithRandStream(id, auditors, rand),
+		Threshold:               threshold,
+		Members:                 make([]*Member, numMembers),
+		epochVerificationKeyMap: make(map[string][]kyber.Point),
+	}
+	com.sharedEpochKeys = true
+	for i := 0; i < numMembers; i++ {
+		com.Members[i] = &Member{
+			Index:            i,
+			epochKeyShareMap: make(map[string]*crypto.ThresholdKeyShare),
+			randStream:       memberRands[i],
+		}
+	}
+	return com, nil
+}
+
+// InitializeEpoch initialize the parameters for an auditing epoch with distributively generated epoch keys
+func (c *ThresholdCommittee) InitializeEpoch(
+	auditors []*auditor.Auditor, organizations []*organization.Organization,
+) (map[string]crypto.TypePublicKey, error) {
+	c.epochTopology = NewFullTopology(c.managedOrgIDs)
+	return c.initializeEpoch(
+		auditors, organizations, c.generateEpochThresholdKeys, c.ForwardEpochAuditorParameters,
+	)
+}
+
+func (c *ThresholdCommittee) generateEpochThresholdKeys() error {
+	c.epochVerificationKeyMap = make(map[string][]kyber.Point)
+	for _, member := range c.Members {
+		member.epochKeyShareMap = make(map[string]*crypto.ThresholdKeyShare)
+	}
+	memberRands := make([]cipher.Stream, len(c.Members))
+	for idx, member := range c.Members {
+		memberRands[idx] = member.randStream
+	}
+	// one distributed key generation per organization, run in-process among the members
+	for _, id := range c.managedOrgIDs {
+		keyShares, err := crypto.RunDKG(c.Threshold, memberRands)
+		if err != nil {
+			return err
+		}
+		idHash := organization.IDHashString(id)
+		c.epochPublicKeyMap[idHash] = keyShares[0].PublicKey
+		c.epochVerificationKeyMap[idHash] = keyShares[0].VerificationKeys
+		for idx, member := range c.Members {
+			member.epochKeyShareMap[idHash] = keyShares[idx]
+		}
+	}
+	return nil
+}
+
+// ForwardEpochAuditorParameters forwards the public parameters of the epoch to the auditor,
+// in place of the secret keys the auditor requests the decryptions from the members.
+func (c *ThresholdCommittee) ForwardEpochAuditorParameters(aud *auditor.Auditor) error {
+	if err := c.forwardEpochAuditorPublicParameters(aud); err != nil {
+		return err
+	}
+	decrypter := &auditorDecrypter{
+		committee:    c,
+		orgIDHashSet: make(map[string]bool),
+	}
+	for _, orgID := range c.managedEntityMap[aud.ID] {
+		decrypter.orgIDHashSet[organization.IDHashString(orgID)] = true
+	}
+	aud.SetEpochDecrypter(decrypter)
+	return nil
+}
+
+// auditorDecrypter serves the decryption requests of an auditor with the shares of a quorum of the members,
+// restricted to the organizations the auditor audits.
+type auditorDecrypter struct {
+	committee    *ThresholdCommittee
+	orgIDHashSet map[string]bool
+}
+
+func (d *auditorDecrypter) DecryptPoint(orgIDHash string, cipherBytes []byte) (kyber.Point, error) {
+	if !d.orgIDHashSet[orgIDHash] {
+		return nil, errors.New("organization not audited by the auditor, id hash: " + orgIDHash)
+	}
+	quorum, err := d.committee.Quorum()
+	if err != nil {
+		return nil, err
+	}
+	return d.committee.ThresholdDecrypt(orgIDHash, hex.EncodeToString(cipherBytes), quorum)
+}
+
+// Quorum returns the first Threshold available members, the other members are not asked for their shares.
+func (c *ThresholdCommittee) Quorum() ([]*Member, error) {
+	quorum := make([]*Member, 0, c.Threshold)
+	for _, member := range c.Members {
+		if !member.Available() {
+			continue
+		}
+		if quorum = append(quorum, member); len(quorum) == c.Threshold {
+			return quorum, nil
+		}
+	}
+	return nil, fmt.Errorf("%d available members, threshold: %d", len(quorum), c.Threshold)
+}
+
+// ThresholdDecrypt decrypts a hex encoded aud-chain ciphertext with the decryption shares of the given members,
+// it fails if less than Threshold members contribute valid shares.
+func (c *ThresholdCommittee) ThresholdDecrypt(
+	orgIDHash, cipherHex string, members []*Member,
+) (kyber.Point, error) {
+	point, _, err := c.thresholdDecrypt(orgIDHash, cipherHex, members)
+	return point, err
+}
+
+// thresholdDecrypt decrypts with the first Threshold valid decryption shares of the given members,
+// and returns the shares as the proof of the decryption.
+func (c *ThresholdCommittee) thresholdDecrypt(
+	orgIDHash, cipherHex string, members []*Member,
+) (kyber.Point, *ThresholdDecryption, error) {
+	verificationKeys, ok := c.epochVerificationKeyMap[orgIDHash]
+	if !ok {
+		return nil, nil, errors.New("verification keys not found, id hash: " + orgIDHash)
+	}
+	decShares := make([]*crypto.DecryptionShare, 0, len(members))
+	for _, member := range members {
+		decShare, err := member.DecryptShare(orgIDHash, cipherHex)
+		if err != nil {
+			continue
+		}
+		decShares = append(decShares, decShare)
+	}
+	cipherBytes, err := hex.DecodeString(cipherHex)
+	if err != nil {
+		return nil, nil, err
+	}
+	validShares, err := crypto.ValidDecryptionShares(cipherBytes, decShares, verificationKeys)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(validShares) < c.Threshold {
+		return nil, nil, errors.New("not enough valid decryption shares")
+	}
+	validShares = validShares[:c.Threshold]
+	point, err := crypto.CombineDecryptionShares(cipherBytes, validShares, verificationKeys, c.Threshold)
+	if err != nil {
+		return nil, nil, err
+	}
+	return point, &ThresholdDecryption{Shares: validShares, VerificationKeys: verificationKeys}, nil
+}
+
+// thresholdDecryptWithProof is the decryptFunc of the verdicts of the given members.
+func (c *ThresholdCommittee) thresholdDecryptWithProof(members []*Member) decryptFunc {
+	return func(orgIDHash, cipherHex string) (kyber.Point, *crypto.DecryptionProof, *ThresholdDecryption, error) {
+		point, threshold, err := c.thresholdDecrypt(orgIDHash, cipherHex, members)
+		return point, nil, threshold, err
+	}
+}
+
+// ProveOrgAndAudResult is Committee.ProveOrgAndAudResult with threshold decryption by the given members,
+// the verdict carries their decryption shares in place of the proofs of decryption.
+func (c *ThresholdCommittee) ProveOrgAndAudResult(
+	orgID organization.TypeID,
+	audID auditor.TypeID,
+	orgChainTX *transaction.OrgOnChain,
+	audChainTX *transaction.AudOnChain,
+	members []*Member,
+) (*OrgAndAudVerdict, error) {
+	return c.proveOrgAndAudResult(orgID, audID, orgChainTX, audChainTX, c.thresholdDecryptWithProof(members))
+}
+
+// ProveAuditPairResult is Committee.ProveAuditPairResult with threshold decryption by the given members.
+func (c *ThresholdCommittee) ProveAuditPairResult(
+	orgID1 organization.TypeID,
+	orgID2 organization.TypeID,
+	audID1 auditor.TypeID,
+	audID2 auditor.TypeID,
+	audChainTX1 *transaction.AudOnChain,
+	audChainTX2 *transaction.AudOnChain,
+	members []*Member,
+) (*AuditPairVerdict, error) {
+	return c.proveAuditPairResult(
+		orgID1, orgID2, audID1, audID2, audChainTX1, audChainTX2, c.thresholdDecryptWithProof(members),
+	)
+}
+
+// VerifyOrgAndAudResult is Committee.VerifyOrgAndAudResult with threshold decryption by the given members.
+func (c *ThresholdCommittee) VerifyOrgAndAudResult(
+	orgID organization.TypeID,
+	audID auditor.TypeID,
+	orgChainTX *transaction.OrgOnChain,
+	audChainTX *transaction.AudOnChain,
+	members []*Member,
+) (bool, error) {
+	orgIDHash := organization.IDHashString(orgID)
+	pointB, err := c.ThresholdDecrypt(orgIDHash, audChainTX.CipherB, members)
+	if err != nil {
+		return false, err
+	}
+	pointD, err := c.ThresholdDecrypt(orgIDHash, audChainTX.CipherD, members)
+	if err != nil {
+		return false, err
+	}
+	return c.CheckOrgAndAudPair(orgID, audID, orgChainTX, pointB, pointD)
+}
+
+// VerifyAuditPairResult is Committee.VerifyAuditPairResult with threshold decryption by the given members.
+func (c *ThresholdCommittee) VerifyAuditPairResult(
+	orgID1 organization.TypeID,
+	orgID2 organization.TypeID,
+	audID1 auditor.TypeID,
+	audID2 auditor.TypeID,
+	audChainTX1 *transaction.AudOnChain,
+	audChainTX2 *transaction.AudOnChain,
+	members []*Member,
+) (bool, error) {
+	orgIDHash1 := organization.IDHashString(orgID1)
+	orgIDHash2 := organization.IDHashString(orgID2)
+	pointC1, err := c.ThresholdDecrypt(orgIDHash1, audChainTX1.CipherC, members)
+	if err != nil {
+		return false, err
+	}
+	pointD1, err := c.ThresholdDecrypt(orgIDHash1, audChainTX1.CipherD, members)
+	if err != nil {
+		return false, err
+	}
+	pointC2, err := c.ThresholdDecrypt(orgIDHash2, audChainTX2.CipherC, members)
+	if err != nil {
+		return false, err
+	}
+	pointD2, err := c.ThresholdDecrypt(orgIDHash2, audChainTX2.CipherD, members)
+	if err != nil {
+		return false, err
+	}
+	return c.CheckAuditPair(audID1, audID2, pointC1, pointC2, pointD1, pointD2)
+}
@@ -0,0 +1,149 @@
+package committee
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+func TestThresholdCommittee_ThresholdDecrypt(t *testing.T) {
+	com, orgIDHash := newThresholdEpoch(t)
+	point, cipherBytes := encryptRandomPoint(t, com, orgIDHash)
+	tests := []struct {
+		name    string
+		members []*Member
+		wantErr bool
+	}{
+		{
+			name:    "test_threshold",
+			members: com.Members[1:4],
+			wantErr: false,
+		},
+		{
+			name:    "test_all",
+			members: com.Members,
+			wantErr: false,
+		},
+		{
+			name:    "test_below_threshold",
+			members: com.Members[:2],
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := com.ThresholdDecrypt(orgIDHash, hex.EncodeToString(cipherBytes), tt.members)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ThresholdDecrypt() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !got.Equal(point) {
+				t.Errorf("ThresholdDecrypt() = %v, want %v", got, point)
+			}
+		})
+	}
+}
+
+func TestAuditorDecrypter_DecryptPoint(t *testing.T) {
+	tests := []struct {
+		name        string
+		unavailable []int
+		unaudited   bool
+		wantErr     bool
+	}{
+		{
+			name:    "test_all_available",
+			wantErr: false,
+		},
+		{
+			name:        "test_threshold_available",
+			unavailable: []int{0, 2},
+			wantErr:     false,
+		},
+		{
+			name:        "test_below_threshold_available",
+			unavailable: []int{0, 2, 4},
+			wantErr:     true,
+		},
+		{
+			name:      "test_unaudited_organization",
+			unaudited: true,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			com, orgIDHash := newThresholdEpoch(t)
+			point, cipherBytes := encryptRandomPoint(t, com, orgIDHash)
+			for _, idx := range tt.unavailable {
+				com.Members[idx].SetAvailable(false)
+			}
+			decrypter := &auditorDecrypter{committee: com, orgIDHashSet: make(map[string]bool)}
+			if !tt.unaudited {
+				decrypter.orgIDHashSet[orgIDHash] = true
+			}
+			got, err := decrypter.DecryptPoint(orgIDHash, cipherBytes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecryptPoint() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Equal(point) {
+				t.Errorf("DecryptPoint() = %v, want %v", got, point)
+			}
+			quorum, err := com.Quorum()
+			if err != nil {
+				t.Fatalf("Quorum() error = %v", err)
+			}
+			if len(quorum) != com.Threshold {
+				t.Errorf("Quorum() has %d members, want %d", len(quorum), com.Threshold)
+			}
+			for _, member := range quorum {
+				if !member.Available() {
+					t.Errorf("Quorum() contains the unavailable member %d", member.Index)
+				}
+			}
+		})
+	}
+}
+
+// newThresholdEpoch initializes an epoch of a 3-out-of-5 threshold committee,
+// and returns the committee and the id hash of an organization of its auditor.
+func newThresholdEpoch(t *testing.T) (*ThresholdCommittee, string) {
+	t.Helper()
+	organizations := []*organization.Organization{
+		organization.New("org1", organization.NewMemoryLocalChain()),
+		organization.New("org2", organization.NewMemoryLocalChain()),
+	}
+	auditors := []*auditor.Auditor{auditor.New("aud1", organizations)}
+	com, err := NewThreshold("com", auditors, 3, 5)
+	if err != nil {
+		t.Fatalf("NewThreshold() error = %v", err)
+	}
+	if _, err = com.InitializeEpoch(auditors, organizations); err != nil {
+		t.Fatalf("InitializeEpoch() error = %v", err)
+	}
+	return com, organization.IDHashString("org1")
+}
+
+// encryptRandomPoint encrypts a random point under the epoch public key of the organization.
+func encryptRandomPoint(t *testing.T, com *ThresholdCommittee, orgIDHash string) (kyber.Point, []byte) {
+	t.Helper()
+	point := crypto.KyberSuite.Point().Pick(crypto.RandomStream())
+	cipherText, err := crypto.EncryptPoint(com.epochPublicKeyMap[orgIDHash], point, crypto.RandomStream())
+	if err != nil {
+		t.Fatalf("EncryptPoint() error = %v", err)
+	}
+	cipherBytes, err := cipherText.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	return point, cipherBytes
+}
@@ -0,0 +1,180 @@
+package committee
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+// Topology is the graph of the declared trading relationships of the organizations in an epoch,
+// the committee only generates and forwards the transaction randomness for its edges.
+type Topology struct {
+	adjacencyMap map[organization.TypeID]map[organization.TypeID]struct{}
+}
+
+func NewTopology() *Topology {
+	return &Topology{
+		adjacencyMap: make(map[organization.TypeID]map[organization.TypeID]struct{}),
+	}
+}
+
+// NewFullTopology returns the topology in which every pair of the organizations trades.
+func NewFullTopology(orgIDs []organization.TypeID) *Topology {
+	topology := NewTopology()
+	for i := 0; i < len(orgIDs); i++ {
+		for j := i + 1; j < len(orgIDs); j++ {
+			// the self edges of duplicated IDs are skipped
+			_ = topology.AddEdge(orgIDs[i], orgIDs[j])
+		}
+	}
+	return topology
+}
+
+// AddEdge declares that the two organizations trade, adding an existing edge has no effect.
+func (t *Topology) AddEdge(orgID1, orgID2 organization.TypeID) error {
+	if orgID1 == orgID2 {
+		return fmt.Errorf("self edge of organization %s", orgID1)
+	}
+	t.addNeighbor(orgID1, orgID2)
+	t.addNeighbor(orgID2, orgID1)
+	return nil
+}
+
+func (t *Topology) addNeighbor(orgID, neighborID organization.TypeID) {
+	if _, ok := t.adjacencyMap[orgID]; !ok {
+		t.adjacencyMap[orgID] = make(map[organization.TypeID]struct{})
+	}
+	t.adjacencyMap[orgID][neighborID] = struct{}{}
+}
+
+func (t *Topology) clone() *Topology {
+	cloned := NewTopology()
+	for orgID, neighbors := range t.adjacencyMap {
+		for neighborID := range neighbors {
+			cloned.addNeighbor(orgID, neighborID)
+		}
+	}
+	return cloned
+}
+
+func (t *Topology) HasEdge(orgID1, orgID2 organization.TypeID) bool {
+	_, ok := t.adjacencyMap[orgID1][orgID2]
+	return ok
+}
+
+// Neighbors returns the organizations trading with the organization in sorted order.
+func (t *Topology) Neighbors(orgID organization.TypeID) []organization.TypeID {
+	neighbors := make([]organization.TypeID, 0, len(t.adjacencyMap[orgID]))
+	for neighborID := range t.adjacencyMap[orgID] {
+		neighbors = append(neighbors, neighborID)
+	}
+	sort.Slice(neighbors, func(i, j int) bool { return neighbors[i] < neighbors[j] })
+	return neighbors
+}
+
+// Edges returns every edge once in sorted order, the first organization of an edge is the smaller one.
+func (t *Topology) Edges() [][2]organization.TypeID {
+	orgIDs := make([]organization.TypeID, 0, len(t.adjacencyMap))
+	for orgID := range t.adjacencyMap {
+		orgIDs = append(orgIDs, orgID)
+	}
+	sort.Slice(orgIDs, func(i, j int) bool { return orgIDs[i] < orgIDs[j] })
+	var edges [][2]organization.TypeID
+	for _, orgID := range orgIDs {
+		for _, neighborID := range t.Neighbors(orgID) {
+			if orgID < neighborID {
+				edges = append(edges, [2]organization.TypeID{orgID, neighborID})
+			}
+		}
+	}
+	return edges
+}
+
+// InitializeEpochWithTopology initializes an auditing epoch in which only the organizations
+// connected in the topology can trade, the cost of the transaction randomness is linear in the number of edges.
+func (c *Committee) InitializeEpochWithTopology(
+	auditors []*auditor.Auditor, organizations []*organization.Organization, topology *Topology,
+) (map[string]crypto.TypePublicKey, error) {
+	if err := c.setEpochTopology(topology); err != nil {
+		return nil, err
+	}
+	return c.initializeEpoch(auditors, organizations, c.generateEpochKeyPairs, c.ForwardEpochAuditorParameters)
+}
+
+// InitializeEpochWithTopology initializes an auditing epoch in which only the organizations
+// connected in the topology can trade, with distributively generated epoch keys.
+func (c *ThresholdCommittee) InitializeEpochWithTopology(
+	auditors []*auditor.Auditor, organizations []*organization.Organization, topology *Topology,
+) (map[string]crypto.TypePublicKey, error) {
+	if err := c.setEpochTopology(topology); err != nil {
+		return nil, err
+	}
+	return c.initializeEpoch(
+		auditors, organizations, c.generateEpochThresholdKeys, c.ForwardEpochAuditorParameters,
+	)
+}
+
+func (c *Committee) setEpochTopology(topology *Topology) error {
+	if topology == nil {
+		return errors.New("nil topology")
+	}
+	managedOrgIDSet := make(map[organization.TypeID]struct{}, len(c.managedOrgIDs))
+	for _, orgID := range c.managedOrgIDs {
+		managedOrgIDSet[orgID] = struct{}{}
+	}
+	for orgID := range topology.adjacencyMap {
+		if _, ok := managedOrgIDSet[orgID]; !ok {
+			return errors.New(string("organization not managed by the committee, id: " + orgID))
+		}
+	}
+	// the edges requested in the middle of the epoch must not leak into the declared topology
+	c.epochTopology = topology.clone()
+	return nil
+}
+
+// AddEpochEdge handles the request of an organization to trade with another one in the middle of the epoch,
+// it generates the transaction randomness of the new edge and forwards it to the given auditors of the two.
+// The epoch must be recording, the edges of a closed epoch are final.
+func (c *Committee) AddEpochEdge(orgID1, orgID2 organization.TypeID, auditors []*auditor.Auditor) error {
+	if err := c.checkEpochRecording(); err != nil {
+		return err
+	}
+	for _, orgID := range []organization.TypeID{orgID1, orgID2} {
+		if _, ok := c.epochOrgIDMap[orgID]; !ok {
+			return errors.New(string("organization not found, id: " + orgID))
+		}
+	}
+	if c.epochTopology.HasEdge(orgID1, orgID2) {
+		return nil
+	}
+	if err := c.epochTopology.AddEdge(orgID1, orgID2); err != nil {
+		return err
+	}
+	key, seed, err := c.generateEdgeSeed(orgID1, orgID2)
+	if err != nil {
+		return err
+	}
+	for _, aud := range auditors {
+		for _, orgID := range c.managedEntityMap[aud.ID] {
+			if orgID == orgID1 || orgID == orgID2 {
+				aud.AddEpochTXSeed(key, seed)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (c *Committee) generateEdgeSeed(orgID1, orgID2 organization.TypeID) ([2]string, []byte, error) {
+	key := organization.IDHashKey(organization.IDHashString(orgID1), organization.IDHashString(orgID2))
+	seed, err := crypto.RandBytes(c.randStream)
+	if err != nil {
+		return key, nil, err
+	}
+	c.epochTXSeedMap[key] = seed
+	return key, seed, nil
+}
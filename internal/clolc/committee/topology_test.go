@@ -0,0 +1,189 @@
+package committee
+
+import (
+	"testing"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/epoch"
+)
+
+func TestCommittee_InitializeEpochWithTopology(t *testing.T) {
+	organizations := []*organization.Organization{
+		organization.New("org1", organization.NewMemoryLocalChain()),
+		organization.New("org2", organization.NewMemoryLocalChain()),
+		organization.New("org3", organization.NewMemoryLocalChain()),
+		organization.New("org4", organization.NewMemoryLocalChain()),
+	}
+	edges := func(orgIDs ...organization.TypeID) *Topology {
+		topology := NewTopology()
+		for i := 0; i+1 < len(orgIDs); i += 2 {
+			if err := topology.AddEdge(orgIDs[i], orgIDs[i+1]); err != nil {
+				t.Fatalf("AddEdge() error = %v", err)
+			}
+		}
+		return topology
+	}
+	tests := []struct {
+		name      string
+		topology  *Topology
+		wantEdges int
+		wantErr   bool
+	}{
+		{
+			name:      "test_sparse",
+			topology:  edges("org1", "org2", "org3", "org4"),
+			wantEdges: 2,
+			wantErr:   false,
+		},
+		{
+			name:      "test_empty",
+			topology:  NewTopology(),
+			wantEdges: 0,
+			wantErr:   false,
+		},
+		{
+			name:      "test_full",
+			topology:  NewFullTopology([]organization.TypeID{"org1", "org2", "org3", "org4"}),
+			wantEdges: 6,
+			wantErr:   false,
+		},
+		{
+			name:     "test_unmanaged_organization",
+			topology: edges("org1", "org5"),
+			wantErr:  true,
+		},
+		{
+			name:     "test_nil_topology",
+			topology: nil,
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auditors := []*auditor.Auditor{
+				auditor.New("aud1", organizations[:2]),
+				auditor.New("aud2", organizations[2:]),
+			}
+			com := New("com", auditors)
+			_, err := com.InitializeEpochWithTopology(auditors, organizations, tt.topology)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("InitializeEpochWithTopology() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(com.epochTXSeedMap) != tt.wantEdges {
+				t.Errorf("InitializeEpochWithTopology() number of seeds = %d, want %d", len(com.epochTXSeedMap), tt.wantEdges)
+			}
+			for _, pair := range [][2]organization.TypeID{{"org1", "org3"}, {"org2", "org4"}} {
+				want := tt.topology.HasEdge(pair[0], pair[1])
+				for _, aud := range auditors {
+					got := aud.GetEpochTXRandomness(pair[0], pair[1], 1) != nil
+					if got != want {
+						t.Errorf("%s GetEpochTXRandomness(%s, %s) found = %v, want %v", aud.ID, pair[0], pair[1], got, want)
+					}
+				}
+			}
+			// an edge requested in the middle of the epoch reaches the auditors of both organizations
+			if err = com.AddEpochEdge("org1", "org3", auditors); err != nil {
+				t.Fatalf("AddEpochEdge() error = %v", err)
+			}
+			for _, aud := range auditors {
+				if aud.GetEpochTXRandomness("org3", "org1", 1) == nil {
+					t.Errorf("%s GetEpochTXRandomness(org3, org1) = nil after AddEpochEdge()", aud.ID)
+				}
+			}
+			if tt.topology.HasEdge("org1", "org3") != (tt.wantEdges == 6) {
+				t.Errorf("AddEpochEdge() modified the declared topology")
+			}
+			if err = com.AddEpochEdge("org1", "org5", auditors); err == nil {
+				t.Errorf("AddEpochEdge() with unknown organization error = nil, wantErr true")
+			}
+		})
+	}
+}
+
+func TestCommittee_AddEpochEdge(t *testing.T) {
+	tests := []struct {
+		name      string
+		scheduled bool
+		advance   []epoch.State
+		reopen    bool
+		wantErr   bool
+	}{
+		{
+			name:      "test_unscheduled",
+			scheduled: false,
+			wantErr:   false,
+		},
+		{
+			name:      "test_recording",
+			scheduled: true,
+			wantErr:   false,
+		},
+		{
+			name:      "test_closed",
+			scheduled: true,
+			advance:   []epoch.State{epoch.StateClosed},
+			wantErr:   true,
+		},
+		{
+			name:      "test_verified",
+			scheduled: true,
+			advance:   []epoch.State{epoch.StateClosed, epoch.StateExamined, epoch.StateVerified},
+			wantErr:   true,
+		},
+		{
+			name:      "test_next_epoch_not_initialized",
+			scheduled: true,
+			advance:   []epoch.State{epoch.StateClosed, epoch.StateExamined, epoch.StateVerified},
+			reopen:    true,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			organizations := []*organization.Organization{
+				organization.New("org1", organization.NewMemoryLocalChain()),
+				organization.New("org2", organization.NewMemoryLocalChain()),
+			}
+			auditors := []*auditor.Auditor{auditor.New("aud1", organizations)}
+			com := New("com", auditors)
+			if tt.scheduled {
+				openEpoch(t, com, 1)
+			}
+			if _, err := com.InitializeEpochWithTopology(auditors, organizations, NewTopology()); err != nil {
+				t.Fatalf("InitializeEpochWithTopology() error = %v", err)
+			}
+			for _, state := range tt.advance {
+				if err := com.AdvanceEpoch(state); err != nil {
+					t.Fatalf("AdvanceEpoch() error = %v", err)
+				}
+			}
+			if tt.reopen {
+				openEpoch(t, com, 2)
+			}
+			err := com.AddEpochEdge("org1", "org2", auditors)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("AddEpochEdge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := com.epochTopology.HasEdge("org1", "org2"); got == tt.wantErr {
+				t.Errorf("AddEpochEdge() added the edge = %v, want %v", got, !tt.wantErr)
+			}
+		})
+	}
+}
+
+// openEpoch schedules the epoch of the ID for the next initialization of the committee.
+func openEpoch(t *testing.T, com *Committee, id epoch.TypeID) {
+	t.Helper()
+	e, err := epoch.New(id, int64(id)*100, int64(id+1)*100)
+	if err != nil {
+		t.Fatalf("epoch.New() error = %v", err)
+	}
+	if err = com.OpenEpoch(e); err != nil {
+		t.Fatalf("OpenEpoch() error = %v", err)
+	}
+}
@@ -0,0 +1,344 @@
+package committee
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+// ThresholdDecryption proves a decryption by the members of a threshold committee,
+// with the decryption shares of the members and the verification keys of all the members.
+type ThresholdDecryption struct {
+	Shares           []*crypto.DecryptionShare
+	VerificationKeys []kyber.Point
+}
+
+// OrgAndAudVerdict is the publicly verifiable outcome of VerifyOrgAndAudResult.
+// It discloses the epoch IDs of the organization and the auditor, so it is published once the epoch is closed.
+// The verifier checks them against the commitments published with the public keys
+// and recomputes the expected point from them.
+// A threshold committee proves its decryptions with ThresholdB and ThresholdD in place of ProofB and ProofD.
+type OrgAndAudVerdict struct {
+	OrgIDHash  string
+	AudIDHash  string
+	Result     bool
+	PointB     kyber.Point
+	PointD     kyber.Point
+	ProofB     *crypto.DecryptionProof
+	ProofD     *crypto.DecryptionProof
+	ThresholdB *ThresholdDecryption
+	ThresholdD *ThresholdDecryption
+	OrgEpochID organization.TypeEpochID
+	AudEpochID auditor.TypeEpochID
+}
+
+// AuditPairVerdict is the publicly verifiable outcome of VerifyAuditPairResult.
+// It discloses the epoch IDs of the two auditors, which are checked like the ones of OrgAndAudVerdict,
+// and a threshold committee proves its decryptions with the Threshold fields in place of the Proof fields.
+type AuditPairVerdict struct {
+	OrgIDHash1  string
+	OrgIDHash2  string
+	AudIDHash1  string
+	AudIDHash2  string
+	Result      bool
+	PointC1     kyber.Point
+	PointD1     kyber.Point
+	PointC2     kyber.Point
+	PointD2     kyber.Point
+	ProofC1     *crypto.DecryptionProof
+	ProofD1     *crypto.DecryptionProof
+	ProofC2     *crypto.DecryptionProof
+	ProofD2     *crypto.DecryptionProof
+	ThresholdC1 *ThresholdDecryption
+	ThresholdD1 *ThresholdDecryption
+	ThresholdC2 *ThresholdDecryption
+	ThresholdD2 *ThresholdDecryption
+	AudEpochID1 auditor.TypeEpochID
+	AudEpochID2 auditor.TypeEpochID
+}
+
+// decryptFunc decrypts a hex encoded aud-chain ciphertext under the epoch key of an organization,
+// and proves the decryption either with a proof of the whole secret key or with the shares of threshold members.
+type decryptFunc func(orgIDHash, cipherHex string) (kyber.Point, *crypto.DecryptionProof, *ThresholdDecryption, error)
+
+// ProveOrgAndAudResult performs the same check as VerifyOrgAndAudResult,
+// and attaches the proofs of correct decryption to the verdict.
+func (c *Committee) ProveOrgAndAudResult(
+	orgID organization.TypeID,
+	audID auditor.TypeID,
+	orgChainTX *transaction.OrgOnChain,
+	audChainTX *transaction.AudOnChain,
+) (*OrgAndAudVerdict, error) {
+	return c.proveOrgAndAudResult(orgID, audID, orgChainTX, audChainTX, c.decryptWithProof)
+}
+
+func (c *Committee) proveOrgAndAudResult(
+	orgID organization.TypeID,
+	audID auditor.TypeID,
+	orgChainTX *transaction.OrgOnChain,
+	audChainTX *transaction.AudOnChain,
+	decrypt decryptFunc,
+) (*OrgAndAudVerdict, error) {
+	orgIDHash := organization.IDHashString(orgID)
+	orgEpochID, ok := c.epochOrgIDMap[orgID]
+	if !ok {
+		return nil, errors.New(string("epoch ID not found, id: " + orgID))
+	}
+	audEpochID, ok := c.epochAuditorIDMap[audID]
+	if !ok {
+		return nil, errors.New(string("epoch ID not found, id: " + audID))
+	}
+	verdict := &OrgAndAudVerdict{
+		OrgIDHash:  orgIDHash,
+		AudIDHash:  auditor.IDHashString(audID),
+		OrgEpochID: orgEpochID,
+		AudEpochID: audEpochID,
+	}
+	var err error
+	verdict.PointB, verdict.ProofB, verdict.ThresholdB, err = decrypt(orgIDHash, audChainTX.CipherB)
+	if err != nil {
+		return nil, err
+	}
+	verdict.PointD, verdict.ProofD, verdict.ThresholdD, err = decrypt(orgIDHash, audChainTX.CipherD)
+	if err != nil {
+		return nil, err
+	}
+	verdict.Result, err = checkOrgAndAudVerdict(orgChainTX, verdict)
+	if err != nil {
+		return nil, err
+	}
+	return verdict, nil
+}
+
+// ProveAuditPairResult performs the same check as VerifyAuditPairResult,
+// and attaches the proofs of correct decryption to the verdict.
+func (c *Committee) ProveAuditPairResult(
+	orgID1 organization.TypeID,
+	orgID2 organization.TypeID,
+	audID1 auditor.TypeID,
+	audID2 auditor.TypeID,
+	audChainTX1 *transaction.AudOnChain,
+	audChainTX2 *transaction.AudOnChain,
+) (*AuditPairVerdict, error) {
+	return c.proveAuditPairResult(orgID1, orgID2, audID1, audID2, audChainTX1, audChainTX2, c.decryptWithProof)
+}
+
+func (c *Committee) proveAuditPairResult(
+	orgID1 organization.TypeID,
+	orgID2 organization.TypeID,
+	audID1 auditor.TypeID,
+	audID2 auditor.TypeID,
+	audChainTX1 *transaction.AudOnChain,
+	audChainTX2 *transaction.AudOnChain,
+	decrypt decryptFunc,
+) (*AuditPairVerdict, error) {
+	orgIDHash1 := organization.IDHashString(orgID1)
+	orgIDHash2 := organization.IDHashString(orgID2)
+	audEpochID1, ok := c.epochAuditorIDMap[audID1]
+	if !ok {
+		return nil, errors.New(string("epoch ID not found, id: " + audID1))
+	}
+	audEpochID2, ok := c.epochAuditorIDMap[audID2]
+	if !ok {
+		return nil, errors.New(string("epoch ID not found, id: " + audID2))
+	}
+	verdict := &AuditPairVerdict{
+		OrgIDHash1:  orgIDHash1,
+		OrgIDHash2:  orgIDHash2,
+		AudIDHash1:  auditor.IDHashString(audID1),
+		AudIDHash2:  auditor.IDHashString(audID2),
+		AudEpochID1: audEpochID1,
+		AudEpochID2: audEpochID2,
+	}
+	var err error
+	verdict.PointC1, verdict.ProofC1, verdict.ThresholdC1, err = decrypt(orgIDHash1, audChainTX1.CipherC)
+	if err != nil {
+		return nil, err
+	}
+	verdict.PointD1, verdict.ProofD1, verdict.ThresholdD1, err = decrypt(orgIDHash1, audChainTX1.CipherD)
+	if err != nil {
+		return nil, err
+	}
+	verdict.PointC2, verdict.ProofC2, verdict.ThresholdC2, err = decrypt(orgIDHash2, audChainTX2.CipherC)
+	if err != nil {
+		return nil, err
+	}
+	verdict.PointD2, verdict.ProofD2, verdict.ThresholdD2, err = decrypt(orgIDHash2, audChainTX2.CipherD)
+	if err != nil {
+		return nil, err
+	}
+	verdict.Result = checkAuditPairVerdict(verdict)
+	return verdict, nil
+}
+
+// VerifyOrgAndAudVerdict lets anyone check a verdict of the committee from the org-chain and aud-chain
+// transactions, the published public keys and the published commitments to the epoch IDs.
+// It returns true if the disclosed epoch IDs match their commitments, all the decryptions are proven correct,
+// and the verdict result matches the decrypted points.
+func VerifyOrgAndAudVerdict(
+	publicKeyMap map[string]kyber.Point,
+	epochIDCommitmentMap map[string][]byte,
+	orgChainTX *transaction.OrgOnChain,
+	audChainTX *transaction.AudOnChain,
+	verdict *OrgAndAudVerdict,
+) (bool, error) {
+	publicKey, ok := publicKeyMap[verdict.OrgIDHash]
+	if !ok {
+		return false, errors.New("public key not found, id hash: " + verdict.OrgIDHash)
+	}
+	for _, e := range []struct {
+		idHash  string
+		epochID []byte
+	}{
+		{verdict.OrgIDHash, verdict.OrgEpochID},
+		{verdict.AudIDHash, verdict.AudEpochID},
+	} {
+		if ok, err := checkEpochIDCommitment(epochIDCommitmentMap, e.idHash, e.epochID); err != nil || !ok {
+			return false, err
+		}
+	}
+	for _, d := range []struct {
+		cipher    string
+		point     kyber.Point
+		proof     *crypto.DecryptionProof
+		threshold *ThresholdDecryption
+	}{
+		{audChainTX.CipherB, verdict.PointB, verdict.ProofB, verdict.ThresholdB},
+		{audChainTX.CipherD, verdict.PointD, verdict.ProofD, verdict.ThresholdD},
+	} {
+		if ok, err := verifyDecryption(publicKey, d.cipher, d.point, d.proof, d.threshold); err != nil || !ok {
+			return false, err
+		}
+	}
+	result, err := checkOrgAndAudVerdict(orgChainTX, verdict)
+	if err != nil {
+		return false, err
+	}
+	return result == verdict.Result, nil
+}
+
+// VerifyAuditPairVerdict lets anyone check a verdict of the committee from the aud-chain
+// transactions, the published public keys and the published commitments to the epoch IDs.
+func VerifyAuditPairVerdict(
+	publicKeyMap map[string]kyber.Point,
+	epochIDCommitmentMap map[string][]byte,
+	audChainTX1 *transaction.AudOnChain,
+	audChainTX2 *transaction.AudOnChain,
+	verdict *AuditPairVerdict,
+) (bool, error) {
+	publicKey1, ok := publicKeyMap[verdict.OrgIDHash1]
+	if !ok {
+		return false, errors.New("public key not found, id hash: " + verdict.OrgIDHash1)
+	}
+	publicKey2, ok := publicKeyMap[verdict.OrgIDHash2]
+	if !ok {
+		return false, errors.New("public key not found, id hash: " + verdict.OrgIDHash2)
+	}
+	for _, e := range []struct {
+		idHash  string
+		epochID []byte
+	}{
+		{verdict.AudIDHash1, verdict.AudEpochID1},
+		{verdict.AudIDHash2, verdict.AudEpochID2},
+	} {
+		if ok, err := checkEpochIDCommitment(epochIDCommitmentMap, e.idHash, e.epochID); err != nil || !ok {
+			return false, err
+		}
+	}
+	for _, d := range []struct {
+		publicKey kyber.Point
+		cipher    string
+		point     kyber.Point
+		proof     *crypto.DecryptionProof
+		threshold *ThresholdDecryption
+	}{
+		{publicKey1, audChainTX1.CipherC, verdict.PointC1, verdict.ProofC1, verdict.ThresholdC1},
+		{publicKey1, audChainTX1.CipherD, verdict.PointD1, verdict.ProofD1, verdict.ThresholdD1},
+		{publicKey2, audChainTX2.CipherC, verdict.PointC2, verdict.ProofC2, verdict.ThresholdC2},
+		{publicKey2, audChainTX2.CipherD, verdict.PointD2, verdict.ProofD2, verdict.ThresholdD2},
+	} {
+		if ok, err := verifyDecryption(d.publicKey, d.cipher, d.point, d.proof, d.threshold); err != nil || !ok {
+			return false, err
+		}
+	}
+	return checkAuditPairVerdict(verdict) == verdict.Result, nil
+}
+
+func checkOrgAndAudVerdict(orgChainTX *transaction.OrgOnChain, verdict *OrgAndAudVerdict) (bool, error) {
+	accBytes, err := hex.DecodeString(orgChainTX.Accumulator)
+	if err != nil {
+		return false, err
+	}
+	pointAcc := crypto.KyberSuite.Point()
+	if err = pointAcc.UnmarshalBinary(accBytes); err != nil {
+		return false, err
+	}
+	leftPoint := crypto.KyberSuite.Point().Add(pointAcc, verdict.PointB)
+	leftPoint.Add(leftPoint, verdict.PointD)
+	// the expected point is recomputed from the disclosed epoch IDs, never taken from the committee
+	rightPoint := organization.EpochIDHashPoint(verdict.OrgEpochID)
+	rightPoint.Add(rightPoint, auditor.EpochIDHashPoint(verdict.AudEpochID))
+	return leftPoint.Equal(rightPoint), nil
+}
+
+func checkAuditPairVerdict(verdict *AuditPairVerdict) bool {
+	leftPoint := crypto.KyberSuite.Point().Add(verdict.PointD1, verdict.PointC1)
+	leftPoint.Add(leftPoint, verdict.PointD2)
+	leftPoint.Add(leftPoint, verdict.PointC2)
+	rightPoint := auditor.EpochIDHashPoint(verdict.AudEpochID1)
+	rightPoint.Add(rightPoint, auditor.EpochIDHashPoint(verdict.AudEpochID2))
+	return leftPoint.Equal(rightPoint)
+}
+
+// checkEpochIDCommitment returns true if the disclosed epoch ID matches its published commitment.
+func checkEpochIDCommitment(epochIDCommitmentMap map[string][]byte, idHash string, epochID []byte) (bool, error) {
+	commitment, ok := epochIDCommitmentMap[idHash]
+	if !ok {
+		return false, errors.New("epoch ID commitment not found, id hash: " + idHash)
+	}
+	return bytes.Equal(commitment, EpochIDCommitment(epochID)), nil
+}
+
+// decryptWithProof decrypts with the epoch secret key of the organization and proves the decryption.
+func (c *Committee) decryptWithProof(
+	orgIDHash, cipherHex string,
+) (kyber.Point, *crypto.DecryptionProof, *ThresholdDecryption, error) {
+	privateKey, err := c.epochSecretKey(orgIDHash)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	cipherBytes, err := hex.DecodeString(cipherHex)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	point, proof, err := crypto.DecryptPointWithProof(privateKey, cipherBytes, c.randStream)
+	return point, proof, nil, err
+}
+
+// verifyDecryption checks the proof of a decryption with the whole secret key,
+// or the shares of a threshold decryption if the verdict carries no such proof.
+func verifyDecryption(
+	publicKey kyber.Point, cipherHex string, point kyber.Point,
+	proof *crypto.DecryptionProof, threshold *ThresholdDecryption,
+) (bool, error) {
+	cipherBytes, err := hex.DecodeString(cipherHex)
+	if err != nil {
+		return false, err
+	}
+	if proof != nil {
+		return crypto.VerifyDecryptionProof(publicKey, cipherBytes, point, proof)
+	}
+	if threshold != nil {
+		return crypto.VerifyThresholdDecryption(
+			publicKey, cipherBytes, point, threshold.Shares, threshold.VerificationKeys,
+		)
+	}
+	return false, errors.New("no proof of decryption")
+}
@@ -0,0 +1,229 @@
+// Package config loads the configuration files and the signing keys of the CLOLC binaries,
+// i.e., the committee service, the auditor daemon and the organization agent.
+package config
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	fabricconfig "github.com/hyperledger/fabric-sdk-go/pkg/core/config"
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/ledger"
+)
+
+const (
+	// BackendFabric is a chaincode on a Hyperledger Fabric network.
+	BackendFabric = "fabric"
+	// BackendMemory is a ledger kept in the memory of the process, it is only for trying out a binary alone.
+	BackendMemory = "memory"
+)
+
+// Ledger is the backend of one of the chains.
+type Ledger struct {
+	Backend string `json:"backend"`
+	// WalletPath, Identity and ConnectionProfile connect to the Fabric network, the identity must be in the wallet.
+	WalletPath        string `json:"wallet_path,omitempty"`
+	Identity          string `json:"identity,omitempty"`
+	ConnectionProfile string `json:"connection_profile,omitempty"`
+	Contract          string `json:"contract,omitempty"`
+}
+
+// Open connects to the ledger, newMemory creates the ledger of BackendMemory.
+func Open[T ledger.Transaction](l *Ledger, newMemory func() ledger.Ledger[T]) (ledger.Ledger[T], error) {
+	if l == nil {
+		return nil, errors.New("no ledger configured")
+	}
+	switch l.Backend {
+	case BackendMemory:
+		return newMemory(), nil
+	case BackendFabric:
+		wallet, err := gateway.NewFileSystemWallet(l.WalletPath)
+		if err != nil {
+			return nil, err
+		}
+		if !wallet.Exists(l.Identity) {
+			return nil, fmt.Errorf("identity %q not in the wallet %s", l.Identity, l.WalletPath)
+		}
+		gw, err := gateway.Connect(
+			gateway.WithConfig(fabricconfig.FromFile(filepath.Clean(l.ConnectionProfile))),
+			gateway.WithIdentity(wallet, l.Identity),
+		)
+		if err != nil {
+			return nil, err
+		}
+		fabric, err := ledger.NewFabric[T](gw, l.Contract)
+		if err != nil {
+			gw.Close()
+			return nil, err
+		}
+		return fabric, nil
+	default:
+		return nil, fmt.Errorf("unknown ledger backend %q", l.Backend)
+	}
+}
+
+// Peer is another entity of the deployment, identified by the ID and the hex encoded signing public key
+// distributed out of band.
+type Peer struct {
+	ID        string `json:"id"`
+	PublicKey string `json:"public_key"`
+	// URL is the base URL of the service of the peer, if it runs one.
+	URL string `json:"url,omitempty"`
+}
+
+// SigningPublicKey decodes the signing public key of the peer.
+func (p *Peer) SigningPublicKey() (crypto.TypePublicKey, error) {
+	publicKeyBytes, err := hex.DecodeString(p.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("public key of %s: %v", p.ID, err)
+	}
+	publicKey := crypto.KyberSuite.Point()
+	if err = publicKey.UnmarshalBinary(publicKeyBytes); err != nil {
+		return nil, fmt.Errorf("public key of %s: %v", p.ID, err)
+	}
+	return publicKey, nil
+}
+
+// AuditorPeer is an auditor managed by the committee with the organizations it audits.
+type AuditorPeer struct {
+	Peer
+	Organizations []Peer `json:"organizations"`
+}
+
+// Committee is the configuration of the committee service.
+type Committee struct {
+	ID             string `json:"id"`
+	Listen         string `json:"listen"`
+	SigningKeyFile string `json:"signing_key_file"`
+	// KeyStoreDir keeps the epoch secrets, the passphrase is read from the environment variable KeyStorePassphraseEnv.
+	KeyStoreDir           string `json:"keystore_dir"`
+	KeyStorePassphraseEnv string `json:"keystore_passphrase_env"`
+	// Genesis and EpochDuration define the epoch schedule in Unix seconds,
+	// the secrets of an epoch are kept for Retention after its end.
+	Genesis       int64         `json:"genesis"`
+	EpochDuration int64         `json:"epoch_duration"`
+	Retention     Duration      `json:"retention"`
+	Interval      Duration      `json:"interval"`
+	Auditors      []AuditorPeer `json:"auditors"`
+	// LegacyCutoff is the Unix second before which the disclosed transactions may use the legacy blinding generator,
+	// it is only set while the data committed under the legacy generator is migrated.
+	LegacyCutoff int64 `json:"legacy_cutoff,omitempty"`
+}
+
+// AuditorDaemon is the configuration of the daemon of an auditor.
+type AuditorDaemon struct {
+	ID             string   `json:"id"`
+	Listen         string   `json:"listen"`
+	SigningKeyFile string   `json:"signing_key_file"`
+	Committee      Peer     `json:"committee"`
+	OrgChain       *Ledger  `json:"org_chain"`
+	AudChain       *Ledger  `json:"aud_chain"`
+	Interval       Duration `json:"interval"`
+	// Organizations are the audited organizations with the ledgers of their local chains.
+	Organizations []AuditedOrganization `json:"organizations"`
+	// LegacyCutoff is the Unix second before which the examined transactions may use the legacy blinding generator,
+	// it is only set while the data committed under the legacy generator is migrated.
+	LegacyCutoff int64 `json:"legacy_cutoff,omitempty"`
+}
+
+// AuditedOrganization is an organization audited by the daemon.
+type AuditedOrganization struct {
+	Peer
+	LocalChain *Ledger `json:"local_chain"`
+}
+
+// OrgAgent is the configuration of the agent of an organization, it listens to the feed files of FeedDir.
+type OrgAgent struct {
+	ID             string   `json:"id"`
+	SigningKeyFile string   `json:"signing_key_file"`
+	FeedDir        string   `json:"feed_dir"`
+	Committee      Peer     `json:"committee"`
+	Auditor        Peer     `json:"auditor"`
+	LocalChain     *Ledger  `json:"local_chain"`
+	OrgChain       *Ledger  `json:"org_chain"`
+	Interval       Duration `json:"interval"`
+}
+
+// Duration is a time.Duration written as a string in the configuration files, e.g., "30s".
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = duration
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// Load reads the JSON configuration file, the unknown fields are rejected.
+func Load(path string, cfg any) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	dec := json.NewDecoder(file)
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(cfg); err != nil {
+		return fmt.Errorf("config %s: %v", path, err)
+	}
+	return nil
+}
+
+// GenerateSigningKey writes a new signing key to the file, which must not exist,
+// and returns the hex encoded public key to distribute to the other entities.
+func GenerateSigningKey(path string) (string, error) {
+	signingKey, publicKey := crypto.SigningKeyGen(crypto.RandomStream())
+	signingKeyBytes, err := signingKey.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return "", err
+	}
+	if _, err = file.WriteString(hex.EncodeToString(signingKeyBytes) + "\n"); err != nil {
+		file.Close()
+		return "", err
+	}
+	if err = file.Close(); err != nil {
+		return "", err
+	}
+	return crypto.PublicKeyHex(publicKey)
+}
+
+// LoadSigningKey reads the hex encoded signing key written by GenerateSigningKey.
+func LoadSigningKey(path string) (crypto.TypePrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	signingKeyBytes, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %v", path, err)
+	}
+	signingKey := crypto.KyberSuite.Scalar()
+	if err = signingKey.UnmarshalBinary(signingKeyBytes); err != nil {
+		return nil, fmt.Errorf("signing key %s: %v", path, err)
+	}
+	return signingKey, nil
+}
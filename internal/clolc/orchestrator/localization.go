@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/auti-project/auti/internal/clolc/committee"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/epoch"
+)
+
+// Localize runs the dispute resolution for a pair of the last epoch, the organizations
+// disclose the openings of their commitments to the committee, which bisects the transactions of the pair.
+// The evidence is empty if the pair is consistent.
+func (o *Orchestrator) Localize(orgID1, orgID2 organization.TypeID) (*committee.InconsistencyEvidence, error) {
+	return o.LocalizeEpoch(o.lastEpochID, orgID1, orgID2)
+}
+
+// LocalizeEpoch runs the dispute resolution for a pair of any past epoch with the archived key material.
+func (o *Orchestrator) LocalizeEpoch(
+	epochID epoch.TypeID, orgID1, orgID2 organization.TypeID,
+) (*committee.InconsistencyEvidence, error) {
+	record, ok := o.history[epochID]
+	if !ok {
+		return nil, fmt.Errorf("epoch %d not found", epochID)
+	}
+	side1 := record.sideMap[[2]organization.TypeID{orgID1, orgID2}]
+	side2 := record.sideMap[[2]organization.TypeID{orgID2, orgID1}]
+	if side1 == nil && side2 == nil {
+		return nil, fmt.Errorf("no transactions between %s and %s in epoch %d", orgID1, orgID2, epochID)
+	}
+	disclosure1, err := o.disclose(epochID, orgID2, side1)
+	if err != nil {
+		return nil, err
+	}
+	disclosure2, err := o.disclose(epochID, orgID1, side2)
+	if err != nil {
+		return nil, err
+	}
+	com, err := o.committee.AtEpoch(epochID)
+	if err != nil {
+		return nil, err
+	}
+	return com.LocalizeInconsistency(orgID1, orgID2, disclosure1, disclosure2)
+}
+
+// disclose reads the transactions of the side from the local chain along with the amounts and the randomness
+// opening the commitments, those of a rolled over epoch come from the archive of the organization.
+func (o *Orchestrator) disclose(
+	epochID epoch.TypeID, counterPartyID organization.TypeID, side *pairSide,
+) (*committee.Disclosure, error) {
+	disclosure := new(committee.Disclosure)
+	if side == nil {
+		return disclosure, nil
+	}
+	disclosure.TXs = make([]*transaction.LocalOnChain, len(side.localTXIDs))
+	for idx, txID := range side.localTXIDs {
+		tx, err := side.org.LocalChain().ReadTX(txID)
+		if err != nil {
+			return nil, err
+		}
+		disclosure.TXs[idx] = tx
+	}
+	if epochID == o.lastEpochID {
+		disclosure.Amounts = side.org.EpochTXAmounts(counterPartyID)
+		disclosure.Randomness = side.org.EpochTXRandomness(counterPartyID)
+		return disclosure, nil
+	}
+	var err error
+	if disclosure.Amounts, err = side.org.ArchivedEpochTXAmounts(epochID, counterPartyID); err != nil {
+		return nil, err
+	}
+	if disclosure.Randomness, err = side.org.ArchivedEpochTXRandomness(epochID, counterPartyID); err != nil {
+		return nil, err
+	}
+	return disclosure, nil
+}
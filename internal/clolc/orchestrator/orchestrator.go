@@ -0,0 +1,537 @@
+package orchestrator
+
+import (
+	"fmt"
+	"sort"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/committee"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/ledger"
+	"github.com/auti-project/auti/internal/money"
+)
+
+// Workload is the local transactions of the organizations in an epoch, in the order they are recorded.
+type Workload map[organization.TypeID][]*transaction.LocalPlain
+
+// AddTransfer appends the two local transactions of a transfer from one organization to another.
+func (w Workload) AddTransfer(fromID, toID organization.TypeID, amount money.Amount, timestamp int64) {
+	fromTX, toTX := transaction.NewPairLocalPlain(string(fromID), string(toID), amount, timestamp)
+	w[fromID] = append(w[fromID], fromTX)
+	w[toID] = append(w[toID], toTX)
+}
+
+// timeRange returns the range of the timestamps of the workload, [0, 1) for an empty workload.
+func (w Workload) timeRange() (int64, int64) {
+	var (
+		startTime, endTime int64
+		found              bool
+	)
+	for _, txList := range w {
+		for _, tx := range txList {
+			if !found || tx.Timestamp < startTime {
+				startTime = tx.Timestamp
+			}
+			if !found || tx.Timestamp >= endTime {
+				endTime = tx.Timestamp + 1
+			}
+			found = true
+		}
+	}
+	if !found {
+		return 0, 1
+	}
+	return startTime, endTime
+}
+
+// Orchestrator runs the four phases of CLOLC, i.e., initialization, transaction record,
+// consistency examination and result verification, for the organizations and auditors managed by the committee.
+// The local chains are the ones the organizations were created with.
+type Orchestrator struct {
+	committee *committee.Committee
+	// threshold is the threshold committee the committee belongs to, nil for a committee holding the epoch secret keys
+	threshold     *committee.ThresholdCommittee
+	auditors      []*auditor.Auditor
+	organizations []*organization.Organization
+	orgChain      ledger.Ledger[*transaction.OrgOnChain]
+	audChain      ledger.Ledger[*transaction.AudOnChain]
+	orgMap        map[organization.TypeID]*organization.Organization
+	orgAuditorMap map[organization.TypeID]*auditor.Auditor
+	// topology is the declared trading relationships, nil means every pair of organizations can trade
+	topology *committee.Topology
+	// history keeps the sides and the report of every epoch for the localization of inconsistencies
+	history     map[epoch.TypeID]*epochRecord
+	lastEpochID epoch.TypeID
+	nextEpochID epoch.TypeID
+}
+
+// epochRecord is what the orchestrator keeps of an epoch.
+type epochRecord struct {
+	sideMap map[[2]organization.TypeID]*pairSide
+	report  *Report
+}
+
+// pairSide is what one organization of a pair contributes to the epoch.
+type pairSide struct {
+	org            *organization.Organization
+	aud            *auditor.Auditor
+	counterPartyID organization.TypeID
+	localTXIDs     []string
+	orgTXID        string
+	audTXID        string
+	pointRes       kyber.Point
+	pointB         kyber.Point
+}
+
+func New(
+	com *committee.Committee,
+	auditors []*auditor.Auditor,
+	organizations []*organization.Organization,
+	orgChain ledger.Ledger[*transaction.OrgOnChain],
+	audChain ledger.Ledger[*transaction.AudOnChain],
+) (*Orchestrator, error) {
+	o := &Orchestrator{
+		committee:     com,
+		auditors:      auditors,
+		organizations: organizations,
+		orgChain:      orgChain,
+		audChain:      audChain,
+		orgMap:        make(map[organization.TypeID]*organization.Organization),
+		orgAuditorMap: make(map[organization.TypeID]*auditor.Auditor),
+	}
+	for _, org := range organizations {
+		if _, ok := o.orgMap[org.ID]; ok {
+			return nil, fmt.Errorf("duplicated organization: %s", org.ID)
+		}
+		if org.LocalChain() == nil {
+			return nil, fmt.Errorf("organization %s has no local chain", org.ID)
+		}
+		o.orgMap[org.ID] = org
+	}
+	for _, aud := range auditors {
+		for _, orgID := range aud.AuditedOrgIDs {
+			if _, ok := o.orgAuditorMap[orgID]; ok {
+				return nil, fmt.Errorf("organization %s is audited by more than one auditor", orgID)
+			}
+			o.orgAuditorMap[orgID] = aud
+		}
+	}
+	for _, org := range organizations {
+		if _, ok := o.orgAuditorMap[org.ID]; !ok {
+			return nil, fmt.Errorf("organization %s has no auditor", org.ID)
+		}
+	}
+	return o, nil
+}
+
+// NewThreshold creates an orchestrator whose committee is a threshold committee,
+// a quorum of Threshold available members contributes its decryption shares to the examinations and the verifications.
+func NewThreshold(
+	com *committee.ThresholdCommittee,
+	auditors []*auditor.Auditor,
+	organizations []*organization.Organization,
+	orgChain ledger.Ledger[*transaction.OrgOnChain],
+	audChain ledger.Ledger[*transaction.AudOnChain],
+) (*Orchestrator, error) {
+	o, err := New(com.Committee, auditors, organizations, orgChain, audChain)
+	if err != nil {
+		return nil, err
+	}
+	o.threshold = com
+	return o, nil
+}
+
+// resultVerifier verifies the results of the organizations and the auditors, with the epoch secret keys
+// of a committee or with the decryption shares of the members of a threshold committee.
+type resultVerifier interface {
+	VerifyOrgAndAudResult(
+		orgID organization.TypeID,
+		audID auditor.TypeID,
+		orgChainTX *transaction.OrgOnChain,
+		audChainTX *transaction.AudOnChain,
+	) (bool, error)
+	VerifyAuditPairResult(
+		orgID1 organization.TypeID,
+		orgID2 organization.TypeID,
+		audID1 auditor.TypeID,
+		audID2 auditor.TypeID,
+		audChainTX1 *transaction.AudOnChain,
+		audChainTX2 *transaction.AudOnChain,
+	) (bool, error)
+}
+
+// thresholdVerifier is the resultVerifier of a threshold committee, a quorum of its members contributes shares.
+type thresholdVerifier struct {
+	com *committee.ThresholdCommittee
+}
+
+func (v thresholdVerifier) VerifyOrgAndAudResult(
+	orgID organization.TypeID,
+	audID auditor.TypeID,
+	orgChainTX *transaction.OrgOnChain,
+	audChainTX *transaction.AudOnChain,
+) (bool, error) {
+	quorum, err := v.com.Quorum()
+	if err != nil {
+		return false, err
+	}
+	return v.com.VerifyOrgAndAudResult(orgID, audID, orgChainTX, audChainTX, quorum)
+}
+
+func (v thresholdVerifier) VerifyAuditPairResult(
+	orgID1 organization.TypeID,
+	orgID2 organization.TypeID,
+	audID1 auditor.TypeID,
+	audID2 auditor.TypeID,
+	audChainTX1 *transaction.AudOnChain,
+	audChainTX2 *transaction.AudOnChain,
+) (bool, error) {
+	quorum, err := v.com.Quorum()
+	if err != nil {
+		return false, err
+	}
+	return v.com.VerifyAuditPairResult(orgID1, orgID2, audID1, audID2, audChainTX1, audChainTX2, quorum)
+}
+
+// SetTopology declares the trading relationships of the following epochs,
+// a transfer between two unconnected organizations requests a new edge from the committee in the middle of the epoch.
+func (o *Orchestrator) SetTopology(topology *committee.Topology) {
+	o.topology = topology
+}
+
+func (o *Orchestrator) initializeEpoch() (map[string]crypto.TypePublicKey, error) {
+	if o.threshold != nil {
+		if o.topology == nil {
+			return o.threshold.InitializeEpoch(o.auditors, o.organizations)
+		}
+		return o.threshold.InitializeEpochWithTopology(o.auditors, o.organizations, o.topology)
+	}
+	if o.topology == nil {
+		return o.committee.InitializeEpoch(o.auditors, o.organizations)
+	}
+	return o.committee.InitializeEpochWithTopology(o.auditors, o.organizations, o.topology)
+}
+
+// RunEpoch runs a full epoch over the workload and returns the verdicts of all the pairs of organizations.
+// The epoch follows the last one and spans the timestamps of the workload, the organizations roll over
+// the accumulators of the last epoch before recording.
+func (o *Orchestrator) RunEpoch(workload Workload) (*Report, error) {
+	startTime, endTime := workload.timeRange()
+	e, err := epoch.New(o.nextEpochID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	return o.runEpoch(e, workload)
+}
+
+// RunEpochs buckets the transactions of the workload into the epochs of the schedule by their timestamps
+// and runs the epochs with transactions in order, the epochs must follow the last one.
+func (o *Orchestrator) RunEpochs(schedule *epoch.Schedule, workload Workload) ([]*Report, error) {
+	bucketMap := make(map[epoch.TypeID]Workload)
+	for orgID, txList := range workload {
+		for _, tx := range txList {
+			epochID, err := schedule.EpochID(tx.Timestamp)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := bucketMap[epochID]; !ok {
+				bucketMap[epochID] = make(Workload)
+			}
+			bucketMap[epochID][orgID] = append(bucketMap[epochID][orgID], tx)
+		}
+	}
+	epochIDs := make([]epoch.TypeID, 0, len(bucketMap))
+	for epochID := range bucketMap {
+		epochIDs = append(epochIDs, epochID)
+	}
+	sort.Slice(epochIDs, func(i, j int) bool { return epochIDs[i] < epochIDs[j] })
+	reports := make([]*Report, len(epochIDs))
+	for idx, epochID := range epochIDs {
+		report, err := o.runEpoch(schedule.Epoch(epochID), bucketMap[epochID])
+		if err != nil {
+			return nil, err
+		}
+		reports[idx] = report
+	}
+	return reports, nil
+}
+
+func (o *Orchestrator) runEpoch(e *epoch.Epoch, workload Workload) (*Report, error) {
+	for orgID, txList := range workload {
+		if _, ok := o.orgMap[orgID]; !ok {
+			return nil, fmt.Errorf("unknown organization in the workload: %s", orgID)
+		}
+		for _, tx := range txList {
+			if !e.Contains(tx.Timestamp) {
+				return nil, fmt.Errorf("timestamp %d of organization %s out of epoch %d", tx.Timestamp, orgID, e.ID)
+			}
+		}
+	}
+	if o.history != nil {
+		if e.ID < o.nextEpochID {
+			return nil, fmt.Errorf("epoch %d does not follow epoch %d", e.ID, o.lastEpochID)
+		}
+		// roll over the accumulators of the last epoch
+		for _, org := range o.organizations {
+			if err := org.RolloverEpoch(o.lastEpochID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := o.committee.OpenEpoch(e); err != nil {
+		return nil, err
+	}
+	// IN
+	publicKeyMap, err := o.initializeEpoch()
+	if err != nil {
+		return nil, err
+	}
+	if o.history == nil {
+		o.history = make(map[epoch.TypeID]*epochRecord)
+	}
+	record := &epochRecord{sideMap: make(map[[2]organization.TypeID]*pairSide)}
+	o.history[e.ID] = record
+	o.lastEpochID = e.ID
+	o.nextEpochID = e.ID + 1
+	// TR: record the local transactions
+	var sides []*pairSide
+	sideMap := record.sideMap
+	for _, org := range o.organizations {
+		for _, tx := range workload[org.ID] {
+			counterPartyID := organization.TypeID(tx.CounterParty)
+			if _, ok := o.orgMap[counterPartyID]; !ok || counterPartyID == org.ID {
+				return nil, fmt.Errorf("invalid counterparty %s of organization %s", counterPartyID, org.ID)
+			}
+			key := [2]organization.TypeID{org.ID, counterPartyID}
+			side, ok := sideMap[key]
+			if !ok {
+				if err := o.committee.AddEpochEdge(org.ID, counterPartyID, o.auditors); err != nil {
+					return nil, err
+				}
+				side = &pairSide{org: org, aud: o.orgAuditorMap[org.ID], counterPartyID: counterPartyID}
+				sideMap[key] = side
+				sides = append(sides, side)
+			}
+			txID, err := org.RecordTransaction(tx)
+			if err != nil {
+				return nil, err
+			}
+			side.localTXIDs = append(side.localTXIDs, txID)
+		}
+	}
+	// TR: submit the accumulators to the organization chain
+	for _, side := range sides {
+		if side.orgTXID, err = o.submitOrgTX(side.org, side.counterPartyID); err != nil {
+			return nil, err
+		}
+	}
+	if err = o.committee.AdvanceEpoch(epoch.StateClosed); err != nil {
+		return nil, err
+	}
+	// CE
+	for _, side := range sides {
+		if err = o.examine(side, publicKeyMap); err != nil {
+			return nil, err
+		}
+	}
+	if err = o.committee.AdvanceEpoch(epoch.StateExamined); err != nil {
+		return nil, err
+	}
+	// RV
+	var verifier resultVerifier = o.committee
+	if o.threshold != nil {
+		verifier = thresholdVerifier{com: o.threshold}
+	}
+	if record.report, err = o.verify(e.ID, verifier, sideMap, true); err != nil {
+		return nil, err
+	}
+	if err = o.committee.AdvanceEpoch(epoch.StateVerified); err != nil {
+		return nil, err
+	}
+	return record.report, nil
+}
+
+// Reverify has the committee verify the results of a past epoch again with the archived key material.
+// The auditors do not keep the keys of past epochs, so their own checks are not repeated.
+// The key shares of the members of a threshold committee are not archived, its epochs cannot be re-verified.
+func (o *Orchestrator) Reverify(epochID epoch.TypeID) (*Report, error) {
+	record, ok := o.history[epochID]
+	if !ok || record.report == nil {
+		return nil, fmt.Errorf("epoch %d not verified", epochID)
+	}
+	if o.threshold != nil {
+		return nil, fmt.Errorf("key shares of epoch %d not archived by the threshold committee", epochID)
+	}
+	com, err := o.committee.AtEpoch(epochID)
+	if err != nil {
+		return nil, err
+	}
+	return o.verify(epochID, com, record.sideMap, false)
+}
+
+// EpochReport returns the report of a verified epoch, or nil if the epoch is not found.
+func (o *Orchestrator) EpochReport(epochID epoch.TypeID) *Report {
+	if record, ok := o.history[epochID]; ok {
+		return record.report
+	}
+	return nil
+}
+
+func (o *Orchestrator) verify(
+	epochID epoch.TypeID, com resultVerifier, sideMap map[[2]organization.TypeID]*pairSide, withAuditor bool,
+) (*Report, error) {
+	report := &Report{EpochID: epochID}
+	for i := 0; i < len(o.organizations); i++ {
+		for j := i + 1; j < len(o.organizations); j++ {
+			orgID1, orgID2 := o.organizations[i].ID, o.organizations[j].ID
+			side1 := sideMap[[2]organization.TypeID{orgID1, orgID2}]
+			side2 := sideMap[[2]organization.TypeID{orgID2, orgID1}]
+			if side1 == nil && side2 == nil {
+				continue
+			}
+			verdict, err := o.verifyPair(com, orgID1, orgID2, side1, side2, withAuditor)
+			if err != nil {
+				return nil, err
+			}
+			report.Pairs = append(report.Pairs, verdict)
+		}
+	}
+	return report, nil
+}
+
+func (o *Orchestrator) submitOrgTX(org *organization.Organization, counterPartyID organization.TypeID) (string, error) {
+	orgPlainTX, err := org.ComposeTXOrgChain(counterPartyID)
+	if err != nil {
+		return "", err
+	}
+	orgOnChainTX := orgPlainTX.ToOnChain()
+	if err = org.SignTX(orgOnChainTX); err != nil {
+		return "", err
+	}
+	return o.orgChain.SubmitTX(orgOnChainTX)
+}
+
+// examine reads the transactions of the side from the ledgers, rejects them if a range proof does not verify,
+// runs the first part of the consistency examination and submits the encrypted result to the auditor chain.
+func (o *Orchestrator) examine(side *pairSide, publicKeyMap map[string]crypto.TypePublicKey) error {
+	counterPartyID := side.counterPartyID
+	localTXList := make([]*transaction.LocalHidden, len(side.localTXIDs))
+	for idx, txID := range side.localTXIDs {
+		localOnChainTX, err := side.org.LocalChain().ReadTX(txID)
+		if err != nil {
+			return err
+		}
+		if localTXList[idx], err = localOnChainTX.ToHidden(); err != nil {
+			return err
+		}
+	}
+	// a commitment out of range could wrap around and fake a zero sum
+	if err := side.aud.VerifyRangeProofs(localTXList); err != nil {
+		return fmt.Errorf("transactions of %s with %s: %v", side.org.ID, counterPartyID, err)
+	}
+	orgOnChainTX, err := o.orgChain.ReadTX(side.orgTXID)
+	if err != nil {
+		return err
+	}
+	orgPlainTX, err := orgOnChainTX.ToPlain()
+	if err != nil {
+		return err
+	}
+	orgTXRandList := side.org.EpochTXRandomness(counterPartyID)
+	comTXRandList := side.aud.GetEpochTXRandomness(side.org.ID, counterPartyID, len(orgTXRandList))
+	audPlainTX, err := side.aud.ConsistencyExaminationPartOne(
+		side.org.ID, counterPartyID, side.org.EpochID,
+		orgPlainTX, localTXList, orgTXRandList, comTXRandList, publicKeyMap,
+	)
+	if err != nil {
+		return err
+	}
+	// kept for the second part of the examination
+	if side.pointRes, err = side.aud.AccumulateCommitments(side.org.ID, localTXList); err != nil {
+		return err
+	}
+	if side.pointB, err = side.aud.ComputeB(
+		orgTXRandList, comTXRandList, auditor.BlindingGenerators(localTXList),
+	); err != nil {
+		return err
+	}
+	audOnChainTX := audPlainTX.ToOnChain()
+	if err = side.aud.SignTX(audOnChainTX); err != nil {
+		return err
+	}
+	side.audTXID, err = o.audChain.SubmitTX(audOnChainTX)
+	return err
+}
+
+func (o *Orchestrator) verifyPair(
+	com resultVerifier, orgID1, orgID2 organization.TypeID, side1, side2 *pairSide, withAuditor bool,
+) (*PairVerdict, error) {
+	verdict := &PairVerdict{
+		OrgID1: orgID1,
+		OrgID2: orgID2,
+		AudID1: o.orgAuditorMap[orgID1].ID,
+		AudID2: o.orgAuditorMap[orgID2].ID,
+	}
+	if side1 == nil {
+		verdict.NumTXs2 = len(side2.localTXIDs)
+		verdict.Reason = fmt.Sprintf("organization %s recorded no transactions with %s", orgID1, orgID2)
+		return verdict, nil
+	}
+	if side2 == nil {
+		verdict.NumTXs1 = len(side1.localTXIDs)
+		verdict.Reason = fmt.Sprintf("organization %s recorded no transactions with %s", orgID2, orgID1)
+		return verdict, nil
+	}
+	verdict.NumTXs1 = len(side1.localTXIDs)
+	verdict.NumTXs2 = len(side2.localTXIDs)
+	orgTX1, audTX1, err := o.readResultTXs(side1)
+	if err != nil {
+		return nil, err
+	}
+	orgTX2, audTX2, err := o.readResultTXs(side2)
+	if err != nil {
+		return nil, err
+	}
+	if verdict.OrgAndAudResult1, err = com.VerifyOrgAndAudResult(
+		orgID1, verdict.AudID1, orgTX1, audTX1,
+	); err != nil {
+		return nil, err
+	}
+	if verdict.OrgAndAudResult2, err = com.VerifyOrgAndAudResult(
+		orgID2, verdict.AudID2, orgTX2, audTX2,
+	); err != nil {
+		return nil, err
+	}
+	if verdict.AuditPairResult, err = com.VerifyAuditPairResult(
+		orgID1, orgID2, verdict.AudID1, verdict.AudID2, audTX1, audTX2,
+	); err != nil {
+		return nil, err
+	}
+	// the auditor decrypts under the epoch keys of both organizations only if it audits both
+	if withAuditor && side1.aud == side2.aud {
+		verdict.AuditorChecked = true
+		if verdict.AuditorResult, err = side1.aud.ConsistencyExaminationPartTwo(
+			orgID2, orgID1, audTX2, side1.pointRes, side1.pointB,
+		); err != nil {
+			return nil, err
+		}
+	}
+	return verdict, nil
+}
+
+func (o *Orchestrator) readResultTXs(side *pairSide) (*transaction.OrgOnChain, *transaction.AudOnChain, error) {
+	orgTX, err := o.orgChain.ReadTX(side.orgTXID)
+	if err != nil {
+		return nil, nil, err
+	}
+	audTX, err := o.audChain.ReadTX(side.audTXID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return orgTX, audTX, nil
+}
@@ -0,0 +1,521 @@
+package orchestrator
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/committee"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/constants"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/keystore"
+	"github.com/auti-project/auti/internal/money"
+)
+
+// newOrchestrator sets up three organizations on in-memory ledgers,
+// aud1 audits org1 and org2, and aud2 audits org3.
+func newOrchestrator(t *testing.T) *Orchestrator {
+	organizations := []*organization.Organization{
+		organization.New("org1", organization.NewMemoryLocalChain()),
+		organization.New("org2", organization.NewMemoryLocalChain()),
+		organization.New("org3", organization.NewMemoryLocalChain()),
+	}
+	auditors := []*auditor.Auditor{
+		auditor.New("aud1", organizations[:2]),
+		auditor.New("aud2", organizations[2:]),
+	}
+	com := committee.New("com", auditors)
+	o, err := New(com, auditors, organizations, organization.NewMemoryOrgChain(), auditor.NewMemoryAudChain())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return o
+}
+
+func TestOrchestrator_RunEpoch(t *testing.T) {
+	consistentWorkload := func() Workload {
+		workload := make(Workload)
+		workload.AddTransfer("org1", "org2", money.MustParse("100.25", "USD"), 1)
+		workload.AddTransfer("org2", "org1", money.MustParse("20", "USD"), 2)
+		workload.AddTransfer("org1", "org3", money.MustParse("3.5", "USD"), 3)
+		workload.AddTransfer("org3", "org2", money.MustParse("42", "USD"), 4)
+		return workload
+	}
+	tests := []struct {
+		name     string
+		workload func() Workload
+		// wantConsistent is keyed by the pairs that have transactions
+		wantConsistent map[[2]organization.TypeID]bool
+		wantErr        bool
+	}{
+		{
+			name:     "test_consistent",
+			workload: consistentWorkload,
+			wantConsistent: map[[2]organization.TypeID]bool{
+				{"org1", "org2"}: true,
+				{"org1", "org3"}: true,
+				{"org2", "org3"}: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "test_amount_mismatch",
+			workload: func() Workload {
+				workload := consistentWorkload()
+				workload["org1"] = append(workload["org1"], transaction.NewLocalPlain("org2", money.MustParse("10", "USD"), 5))
+				workload["org2"] = append(workload["org2"], transaction.NewLocalPlain("org1", money.MustParse("-9", "USD"), 5))
+				return workload
+			},
+			wantConsistent: map[[2]organization.TypeID]bool{
+				{"org1", "org2"}: false,
+				{"org1", "org3"}: true,
+				{"org2", "org3"}: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "test_currency_mismatch",
+			workload: func() Workload {
+				workload := consistentWorkload()
+				workload["org1"] = append(workload["org1"], transaction.NewLocalPlain("org2", money.MustParse("10", "USD"), 5))
+				workload["org2"] = append(workload["org2"], transaction.NewLocalPlain("org1", money.MustParse("-10", "EUR"), 5))
+				return workload
+			},
+			wantConsistent: map[[2]organization.TypeID]bool{
+				{"org1", "org2"}: false,
+				{"org1", "org3"}: true,
+				{"org2", "org3"}: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "test_missing_transaction",
+			workload: func() Workload {
+				workload := consistentWorkload()
+				workload["org3"] = append(workload["org3"], transaction.NewLocalPlain("org1", money.MustParse("10", "USD"), 5))
+				return workload
+			},
+			wantConsistent: map[[2]organization.TypeID]bool{
+				{"org1", "org2"}: true,
+				{"org1", "org3"}: false,
+				{"org2", "org3"}: true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "test_one_sided_pair",
+			workload: func() Workload {
+				workload := consistentWorkload()
+				workload["org2"] = append(workload["org2"], transaction.NewLocalPlain("org1", money.MustParse("10", "USD"), 5))
+				workload["org3"] = nil
+				workload["org1"] = workload["org1"][:2]
+				return workload
+			},
+			wantConsistent: map[[2]organization.TypeID]bool{
+				{"org1", "org2"}: false,
+				{"org2", "org3"}: false,
+			},
+			wantErr: false,
+		},
+		{
+			name: "test_unknown_counterparty",
+			workload: func() Workload {
+				workload := consistentWorkload()
+				workload["org1"] = append(workload["org1"], transaction.NewLocalPlain("org4", money.MustParse("10", "USD"), 5))
+				return workload
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOrchestrator(t)
+			report, err := o.RunEpoch(tt.workload())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RunEpoch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(report.Pairs) != len(tt.wantConsistent) {
+				t.Errorf("RunEpoch() number of pairs = %d, want %d", len(report.Pairs), len(tt.wantConsistent))
+			}
+			for pair, want := range tt.wantConsistent {
+				verdict := report.Pair(pair[0], pair[1])
+				if verdict == nil {
+					t.Errorf("Pair(%s, %s) = nil", pair[0], pair[1])
+					continue
+				}
+				if got := verdict.Consistent(); got != want {
+					t.Errorf("Pair(%s, %s).Consistent() = %v, want %v, verdict %+v", pair[0], pair[1], got, want, verdict)
+				}
+			}
+		})
+	}
+}
+
+func TestOrchestrator_RunEpochMoreThanMaxNumTXInEpoch(t *testing.T) {
+	// every transaction carries a range proof, so the epoch takes minutes to record and examine
+	if testing.Short() {
+		t.Skip("skipping the large epoch in short mode")
+	}
+	workload := make(Workload)
+	for i := 0; i <= constants.MaxNumTXInEpoch; i++ {
+		workload.AddTransfer("org1", "org3", money.MustParse("1", "USD"), int64(i))
+	}
+	o := newOrchestrator(t)
+	report, err := o.RunEpoch(workload)
+	if err != nil {
+		t.Fatalf("RunEpoch() error = %v", err)
+	}
+	verdict := report.Pair("org1", "org3")
+	if len(report.Pairs) != 1 || verdict == nil || !verdict.Consistent() {
+		t.Errorf("RunEpoch() pairs = %+v, want org1 and org3 consistent", report.Pairs)
+	}
+}
+
+func TestOrchestrator_RunEpochThreshold(t *testing.T) {
+	tests := []struct {
+		name           string
+		mismatch       bool
+		wantConsistent bool
+	}{
+		{
+			name:           "test_consistent",
+			mismatch:       false,
+			wantConsistent: true,
+		},
+		{
+			name:           "test_amount_mismatch",
+			mismatch:       true,
+			wantConsistent: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			organizations := []*organization.Organization{
+				organization.New("org1", organization.NewMemoryLocalChain()),
+				organization.New("org2", organization.NewMemoryLocalChain()),
+				organization.New("org3", organization.NewMemoryLocalChain()),
+			}
+			auditors := []*auditor.Auditor{
+				auditor.New("aud1", organizations[:2]),
+				auditor.New("aud2", organizations[2:]),
+			}
+			com, err := committee.NewThreshold("com", auditors, 2, 3)
+			if err != nil {
+				t.Fatalf("NewThreshold() error = %v", err)
+			}
+			o, err := NewThreshold(
+				com, auditors, organizations, organization.NewMemoryOrgChain(), auditor.NewMemoryAudChain(),
+			)
+			if err != nil {
+				t.Fatalf("NewThreshold() error = %v", err)
+			}
+			workload := make(Workload)
+			workload.AddTransfer("org1", "org2", money.MustParse("100.25", "USD"), 1)
+			workload.AddTransfer("org1", "org3", money.MustParse("3.5", "USD"), 2)
+			if tt.mismatch {
+				workload["org1"] = append(workload["org1"], transaction.NewLocalPlain("org2", money.MustParse("10", "USD"), 3))
+				workload["org2"] = append(workload["org2"], transaction.NewLocalPlain("org1", money.MustParse("-9", "USD"), 3))
+			}
+			report, err := o.RunEpoch(workload)
+			if err != nil {
+				t.Fatalf("RunEpoch() error = %v", err)
+			}
+			// aud1 audits both org1 and org2, it requests the decryptions of its second part from the members
+			verdict := report.Pair("org1", "org2")
+			if verdict == nil || !verdict.AuditorChecked {
+				t.Fatalf("Pair(org1, org2) = %+v, want checked by the auditor", verdict)
+			}
+			if got := verdict.Consistent(); got != tt.wantConsistent {
+				t.Errorf("Pair(org1, org2).Consistent() = %v, want %v, verdict %+v", got, tt.wantConsistent, verdict)
+			}
+			if verdict := report.Pair("org1", "org3"); verdict == nil || !verdict.Consistent() {
+				t.Errorf("Pair(org1, org3) = %+v, want consistent", verdict)
+			}
+			if _, err = o.Reverify(report.EpochID); err == nil {
+				t.Errorf("Reverify() error = nil, want the key shares not archived")
+			}
+		})
+	}
+}
+
+func TestOrchestrator_RunEpochWithTopology(t *testing.T) {
+	// only org1 and org2 declare a trading relationship, org1 and org3 request theirs in the middle of the epoch
+	topology := committee.NewTopology()
+	if err := topology.AddEdge("org1", "org2"); err != nil {
+		t.Fatalf("AddEdge() error = %v", err)
+	}
+	o := newOrchestrator(t)
+	o.SetTopology(topology)
+	workload := make(Workload)
+	workload.AddTransfer("org1", "org2", money.MustParse("100.25", "USD"), 1)
+	workload.AddTransfer("org3", "org1", money.MustParse("3.5", "USD"), 2)
+	report, err := o.RunEpoch(workload)
+	if err != nil {
+		t.Fatalf("RunEpoch() error = %v", err)
+	}
+	if len(report.Pairs) != 2 || !report.Consistent() {
+		t.Errorf("RunEpoch() number of pairs = %d, consistent = %v, want %d, %v",
+			len(report.Pairs), report.Consistent(), 2, true)
+	}
+	if topology.HasEdge("org1", "org3") {
+		t.Errorf("RunEpoch() modified the declared topology")
+	}
+}
+
+func TestOrchestrator_RunEpochs(t *testing.T) {
+	schedule, err := epoch.NewSchedule(0, 10)
+	if err != nil {
+		t.Fatalf("NewSchedule() error = %v", err)
+	}
+	// epoch 0 has an amount mismatch between org1 and org2, epoch 1 has no transactions
+	workload := make(Workload)
+	workload.AddTransfer("org1", "org2", money.MustParse("100.25", "USD"), 1)
+	workload.AddTransfer("org1", "org2", money.MustParse("7", "USD"), 3)
+	workload["org2"][1].Amount++
+	workload.AddTransfer("org1", "org3", money.MustParse("3.5", "USD"), 25)
+	workload.AddTransfer("org1", "org2", money.MustParse("5", "USD"), 27)
+	o := newOrchestrator(t)
+	reports, err := o.RunEpochs(schedule, workload)
+	if err != nil {
+		t.Fatalf("RunEpochs() error = %v", err)
+	}
+	wantConsistent := []map[[2]organization.TypeID]bool{
+		{{"org1", "org2"}: false},
+		{{"org1", "org2"}: true, {"org1", "org3"}: true},
+	}
+	wantEpochIDs := []epoch.TypeID{0, 2}
+	if len(reports) != len(wantEpochIDs) {
+		t.Fatalf("RunEpochs() number of reports = %d, want %d", len(reports), len(wantEpochIDs))
+	}
+	for idx, report := range reports {
+		if report.EpochID != wantEpochIDs[idx] {
+			t.Errorf("RunEpochs() epoch ID = %d, want %d", report.EpochID, wantEpochIDs[idx])
+		}
+		// the committee re-verifies the past epochs with the archived key material
+		reverified, err := o.Reverify(report.EpochID)
+		if err != nil {
+			t.Fatalf("Reverify() error = %v", err)
+		}
+		for pair, want := range wantConsistent[idx] {
+			for _, r := range []*Report{report, reverified} {
+				verdict := r.Pair(pair[0], pair[1])
+				if verdict == nil || verdict.Consistent() != want {
+					t.Errorf("epoch %d Pair(%s, %s) = %+v, want consistent %v", r.EpochID, pair[0], pair[1], verdict, want)
+				}
+			}
+		}
+	}
+	evidence, err := o.LocalizeEpoch(0, "org1", "org2")
+	if err != nil {
+		t.Fatalf("LocalizeEpoch() error = %v", err)
+	}
+	if len(evidence.Mismatches) != 1 || evidence.Mismatches[0].Index != 1 {
+		t.Errorf("LocalizeEpoch() mismatches = %+v, want index %d", evidence.Mismatches, 1)
+	}
+	// the epochs must follow the last one
+	workload = make(Workload)
+	workload.AddTransfer("org1", "org2", money.MustParse("1", "USD"), 15)
+	if _, err = o.RunEpochs(schedule, workload); err == nil {
+		t.Errorf("RunEpochs() of a past epoch error = nil, wantErr true")
+	}
+	workload = make(Workload)
+	workload.AddTransfer("org2", "org3", money.MustParse("1", "USD"), 31)
+	reports, err = o.RunEpochs(schedule, workload)
+	if err != nil || len(reports) != 1 || !reports[0].Consistent() {
+		t.Errorf("RunEpochs() of the next epoch = %v, %v, want one consistent report", reports, err)
+	}
+}
+
+func TestOrchestrator_RestartCommittee(t *testing.T) {
+	workload := make(Workload)
+	workload.AddTransfer("org1", "org2", money.MustParse("100.25", "USD"), 1)
+	workload.AddTransfer("org1", "org2", money.MustParse("7", "USD"), 2)
+	workload["org2"][1].Amount++
+	workload.AddTransfer("org2", "org3", money.MustParse("42", "USD"), 3)
+	o := newOrchestrator(t)
+	report, err := o.RunEpoch(workload)
+	if err != nil {
+		t.Fatalf("RunEpoch() error = %v", err)
+	}
+	store, err := keystore.OpenWithParams(t.TempDir(), []byte("passphrase"),
+		keystore.ScryptParams{N: 1 << 4, R: 8, P: 1}, crypto.RandomStream())
+	if err != nil {
+		t.Fatalf("OpenWithParams() error = %v", err)
+	}
+	secrets, err := o.committee.ExportEpochSecrets(report.EpochID, 100)
+	if err != nil {
+		t.Fatalf("ExportEpochSecrets() error = %v", err)
+	}
+	if err = store.Put("com", secrets); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	aud1 := o.auditors[0]
+	if err = store.Put("aud1", aud1.ExportEpochSecrets(o.committee.Epoch(), 100)); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	// the restarted committee only has the key material read from the store
+	restarted := committee.New("com", o.auditors)
+	if secrets, err = store.Get("com", report.EpochID); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if err = restarted.RestoreEpochSecrets(secrets); err != nil {
+		t.Fatalf("RestoreEpochSecrets() error = %v", err)
+	}
+	if got := restarted.Epoch(); got == nil || got.ID != report.EpochID || got.State() != epoch.StateVerified {
+		t.Errorf("Epoch() after RestoreEpochSecrets() = %+v", got)
+	}
+	o.committee = restarted
+	reverified, err := o.Reverify(report.EpochID)
+	if err != nil {
+		t.Fatalf("Reverify() error = %v", err)
+	}
+	for _, verdict := range report.Pairs {
+		got := reverified.Pair(verdict.OrgID1, verdict.OrgID2)
+		if got == nil || got.Consistent() != verdict.Consistent() {
+			t.Errorf("Reverify() Pair(%s, %s) = %+v, want consistent %v",
+				verdict.OrgID1, verdict.OrgID2, got, verdict.Consistent())
+		}
+	}
+	evidence, err := o.Localize("org1", "org2")
+	if err != nil || len(evidence.Mismatches) != 1 {
+		t.Errorf("Localize() after restart = %+v, %v, want one mismatch", evidence, err)
+	}
+	// the restarted auditor derives the same randomness
+	audSecrets, err := store.Get("aud1", report.EpochID)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	restartedAud := auditor.New("aud1", nil)
+	if err = restartedAud.RestoreEpochSecrets(audSecrets); err != nil {
+		t.Fatalf("RestoreEpochSecrets() error = %v", err)
+	}
+	want := aud1.GetEpochTXRandomness("org1", "org2", 2)
+	got := restartedAud.GetEpochTXRandomness("org1", "org2", 2)
+	if len(got) != len(want) || !got[1].Equal(want[1]) {
+		t.Errorf("GetEpochTXRandomness() after restart does not match")
+	}
+}
+
+func TestOrchestrator_Localize(t *testing.T) {
+	table, err := crypto.NewAssetBSGSTable("USD", 1<<20)
+	if err != nil {
+		t.Fatalf("NewAssetBSGSTable() error = %v", err)
+	}
+	transfers := func(numTXs int) Workload {
+		workload := make(Workload)
+		for i := 0; i < numTXs; i++ {
+			workload.AddTransfer("org1", "org2", money.MustParse(strconv.Itoa(i+1), "USD"), int64(i))
+		}
+		workload.AddTransfer("org1", "org3", money.MustParse("5", "USD"), int64(numTXs))
+		return workload
+	}
+	tests := []struct {
+		name              string
+		workload          func() Workload
+		wantIndexes       []int
+		wantDiscrepancies []int64
+	}{
+		{
+			name:     "test_consistent",
+			workload: func() Workload { return transfers(8) },
+		},
+		{
+			name: "test_one_mismatch",
+			workload: func() Workload {
+				workload := transfers(8)
+				workload["org2"][5].Amount += 150
+				return workload
+			},
+			wantIndexes:       []int{5},
+			wantDiscrepancies: []int64{150},
+		},
+		{
+			name: "test_two_mismatches",
+			workload: func() Workload {
+				workload := transfers(7)
+				workload["org1"][0].Amount -= 1
+				workload["org2"][6].Amount += 2500
+				return workload
+			},
+			wantIndexes:       []int{0, 6},
+			wantDiscrepancies: []int64{-1, 2500},
+		},
+		{
+			name: "test_reordered_mismatch",
+			workload: func() Workload {
+				// the transactions are matched by their keys, not by the order of recording
+				workload := transfers(8)
+				workload["org2"][1], workload["org2"][2] = workload["org2"][2], workload["org2"][1]
+				workload["org2"][5].Amount += 150
+				return workload
+			},
+			wantIndexes:       []int{5},
+			wantDiscrepancies: []int64{150},
+		},
+		{
+			name: "test_missing_transaction",
+			workload: func() Workload {
+				workload := transfers(5)
+				workload["org1"] = append(workload["org1"], transaction.NewLocalPlain("org2", money.MustParse("12", "USD"), 5))
+				return workload
+			},
+			wantIndexes:       []int{5},
+			wantDiscrepancies: []int64{1200},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOrchestrator(t)
+			if _, err := o.RunEpoch(tt.workload()); err != nil {
+				t.Fatalf("RunEpoch() error = %v", err)
+			}
+			evidence, err := o.Localize("org1", "org2")
+			if err != nil {
+				t.Fatalf("Localize() error = %v", err)
+			}
+			if len(evidence.Mismatches) != len(tt.wantIndexes) {
+				t.Fatalf("Localize() number of mismatches = %d, want %d", len(evidence.Mismatches), len(tt.wantIndexes))
+			}
+			for idx, mismatch := range evidence.Mismatches {
+				if mismatch.Index != tt.wantIndexes[idx] {
+					t.Errorf("Localize() mismatch index = %d, want %d", mismatch.Index, tt.wantIndexes[idx])
+				}
+				discrepancy, err := mismatch.Discrepancy(table, 0)
+				if err != nil || discrepancy != tt.wantDiscrepancies[idx] {
+					t.Errorf("Discrepancy() = %d, %v, want %d", discrepancy, err, tt.wantDiscrepancies[idx])
+				}
+			}
+			ok, err := committee.VerifyInconsistencyEvidence(evidence,
+				o.orgMap["org1"].SigningPublicKey, o.orgMap["org2"].SigningPublicKey, 0)
+			if err != nil || ok != (len(tt.wantIndexes) > 0) {
+				t.Errorf("VerifyInconsistencyEvidence() = %v, %v, want %v", ok, err, len(tt.wantIndexes) > 0)
+			}
+			// the evidence does not verify against the keys of other organizations
+			if ok, _ = committee.VerifyInconsistencyEvidence(evidence,
+				o.orgMap["org3"].SigningPublicKey, o.orgMap["org2"].SigningPublicKey, 0); ok {
+				t.Errorf("VerifyInconsistencyEvidence() with wrong keys = %v, want %v", ok, false)
+			}
+			// the disclosed amounts must open the commitments
+			for _, mismatch := range evidence.Mismatches {
+				if mismatch.TX1 == nil {
+					continue
+				}
+				mismatch.Amount1 = -mismatch.Amount2
+				if ok, _ = committee.VerifyInconsistencyEvidence(evidence,
+					o.orgMap["org1"].SigningPublicKey, o.orgMap["org2"].SigningPublicKey, 0); ok {
+					t.Errorf("VerifyInconsistencyEvidence() with a tampered amount = %v, want %v", ok, false)
+				}
+				if _, err = mismatch.Discrepancy(table, 0); err == nil {
+					t.Errorf("Discrepancy() with a tampered amount error = nil, wantErr true")
+				}
+			}
+		})
+	}
+}
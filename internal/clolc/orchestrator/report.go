@@ -0,0 +1,76 @@
+package orchestrator
+
+import (
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/epoch"
+)
+
+// PairVerdict is the outcome of the consistency examination of the transactions between two organizations.
+// OrgAndAudResult1 and OrgAndAudResult2 are the results of Committee.VerifyOrgAndAudResult for the two sides,
+// AuditPairResult is the result of Committee.VerifyAuditPairResult.
+// If one auditor audits both organizations, it also checks the pair itself with Auditor.ConsistencyExaminationPartTwo.
+type PairVerdict struct {
+	OrgID1           organization.TypeID
+	OrgID2           organization.TypeID
+	AudID1           auditor.TypeID
+	AudID2           auditor.TypeID
+	NumTXs1          int
+	NumTXs2          int
+	OrgAndAudResult1 bool
+	OrgAndAudResult2 bool
+	AuditPairResult  bool
+	AuditorChecked   bool
+	AuditorResult    bool
+	// Reason explains why the pair could not be examined, e.g., one side recorded no transactions.
+	Reason string
+}
+
+// Consistent returns true if all the checks on the pair passed.
+func (v *PairVerdict) Consistent() bool {
+	if v.Reason != "" {
+		return false
+	}
+	if v.AuditorChecked && !v.AuditorResult {
+		return false
+	}
+	return v.OrgAndAudResult1 && v.OrgAndAudResult2 && v.AuditPairResult
+}
+
+// Report has a verdict for every pair of organizations that recorded transactions with each other in the epoch.
+type Report struct {
+	EpochID epoch.TypeID
+	Pairs   []*PairVerdict
+}
+
+// Consistent returns true if the verdicts of all the pairs are consistent.
+func (r *Report) Consistent() bool {
+	for _, verdict := range r.Pairs {
+		if !verdict.Consistent() {
+			return false
+		}
+	}
+	return true
+}
+
+// Inconsistent returns the verdicts of the inconsistent pairs.
+func (r *Report) Inconsistent() []*PairVerdict {
+	var result []*PairVerdict
+	for _, verdict := range r.Pairs {
+		if !verdict.Consistent() {
+			result = append(result, verdict)
+		}
+	}
+	return result
+}
+
+// Pair returns the verdict of the two organizations in any order, or nil if they have no transactions.
+func (r *Report) Pair(orgID1, orgID2 organization.TypeID) *PairVerdict {
+	for _, verdict := range r.Pairs {
+		if (verdict.OrgID1 == orgID1 && verdict.OrgID2 == orgID2) ||
+			(verdict.OrgID1 == orgID2 && verdict.OrgID2 == orgID1) {
+			return verdict
+		}
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+package orgagent
+
+import (
+	"context"
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/auti-project/auti/internal/clolc/auditd"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/service/client"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/ledger"
+)
+
+// The subdirectories of a watched directory the feed files are moved to once ingested.
+const (
+	ProcessedDir = "processed"
+	FailedDir    = "failed"
+)
+
+// Agent runs an organization as a separate process. It ingests the bookkeeping transactions of the organization,
+// hides and submits them to the local chain, and at the close of the epoch posts the accumulators
+// to the organization chain and hands the submissions to the daemon of its auditor.
+type Agent struct {
+	// mu guards the fields below and the organization, it is released around the remote calls,
+	// and closeMu serializes the attempts to close the epoch
+	mu               sync.Mutex
+	closeMu          sync.Mutex
+	org              *organization.Organization
+	committee        *client.Client
+	auditor          *auditd.Client
+	auditorPublicKey crypto.TypePublicKey
+	orgChain         ledger.Ledger[*transaction.OrgOnChain]
+	epoch            *epoch.Epoch
+	// closing is set by the first attempt to close the epoch, the accumulators are fixed from then on,
+	// and closed once the submissions with every counterparty are handed to the auditor
+	closing bool
+	closed  bool
+	// localTXIDMap keeps the local transaction IDs with every counterparty in the order they were recorded
+	localTXIDMap map[organization.TypeID][]string
+	// orgTXIDMap and submittedMap keep the counterparties whose accumulators are posted and whose submissions
+	// are handed to the auditor, so a retry of CloseEpoch does not post or submit them again
+	orgTXIDMap   map[organization.TypeID]string
+	submittedMap map[organization.TypeID]bool
+	// backlog keeps the transfers after the end of the current epoch until the next epoch is opened
+	backlog    []*Transfer
+	lastErr    error
+	now        func() time.Time
+	randStream cipher.Stream
+}
+
+// New creates an agent for the organization, the committee client must act as the organization,
+// and the auditor client must point to the daemon of the auditor holding the public key.
+func New(
+	org *organization.Organization,
+	committee *client.Client,
+	auditor *auditd.Client,
+	auditorPublicKey crypto.TypePublicKey,
+	orgChain ledger.Ledger[*transaction.OrgOnChain],
+) *Agent {
+	return NewWithRandStream(org, committee, auditor, auditorPublicKey, orgChain, crypto.RandomStream())
+}
+
+// NewWithRandStream creates an agent sealing the randomness for the auditor with randomness from the given stream.
+func NewWithRandStream(
+	org *organization.Organization,
+	committee *client.Client,
+	auditor *auditd.Client,
+	auditorPublicKey crypto.TypePublicKey,
+	orgChain ledger.Ledger[*transaction.OrgOnChain],
+	rand cipher.Stream,
+) *Agent {
+	return &Agent{
+		org:              org,
+		committee:        committee,
+		auditor:          auditor,
+		auditorPublicKey: auditorPublicKey,
+		orgChain:         orgChain,
+		localTXIDMap:     make(map[organization.TypeID][]string),
+		orgTXIDMap:       make(map[organization.TypeID]string),
+		submittedMap:     make(map[organization.TypeID]bool),
+		now:              time.Now,
+		randStream:       rand,
+	}
+}
+
+// Sync follows the epoch of the committee. Once a new epoch is opened, the organization rolls over the previous one,
+// fetches its epoch ID, and records the transfers held back for the new epoch.
+func (a *Agent) Sync(ctx context.Context) error {
+	epochResp, err := a.committee.Epoch(ctx)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	current := a.epoch
+	a.mu.Unlock()
+	if current != nil && uint64(current.ID) == epochResp.ID {
+		return nil
+	}
+	e, err := epoch.New(epoch.TypeID(epochResp.ID), epochResp.StartTime, epochResp.EndTime)
+	if err != nil {
+		return err
+	}
+	epochOrgID, err := a.committee.OrganizationEpochID(ctx)
+	if err != nil {
+		return err
+	}
+	a.mu.Lock()
+	if a.epoch != nil {
+		if err = a.org.RolloverEpoch(a.epoch.ID); err != nil {
+			a.mu.Unlock()
+			return err
+		}
+	}
+	a.org.SetEpochID(epochOrgID)
+	a.epoch = e
+	a.closing, a.closed = false, false
+	a.localTXIDMap = make(map[organization.TypeID][]string)
+	a.orgTXIDMap = make(map[organization.TypeID]string)
+	a.submittedMap = make(map[organization.TypeID]bool)
+	backlog := a.backlog
+	a.backlog = nil
+	a.mu.Unlock()
+	_, err = a.Ingest(ctx, backlog)
+	return err
+}
+
+// Ingest records the side of the organization of the transfers in the current epoch and holds back the ones
+// after its end, it returns the number of recorded transfers. The transfers before the start of the epoch,
+// or not involving the organization, are rejected before anything is recorded.
+func (a *Agent) Ingest(ctx context.Context, transfers []*Transfer) (int, error) {
+	a.mu.Lock()
+	e := a.epoch
+	current, err := a.currentTransfers(transfers)
+	if err != nil {
+		a.mu.Unlock()
+		return 0, err
+	}
+	var newCounterPartyIDs []organization.TypeID
+	for _, counterPartyID := range a.counterParties(current) {
+		if _, ok := a.localTXIDMap[counterPartyID]; !ok {
+			newCounterPartyIDs = append(newCounterPartyIDs, counterPartyID)
+		}
+	}
+	a.mu.Unlock()
+	// declare the trading relationships, a no-op for the pairs in the topology of the epoch
+	for _, counterPartyID := range newCounterPartyIDs {
+		if err = a.committee.RequestEdge(ctx, counterPartyID); err != nil {
+			return 0, err
+		}
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.epoch != e {
+		return 0, fmt.Errorf("epoch %d rolled over while ingesting", e.ID)
+	}
+	// the epoch may be closed meanwhile
+	if current, err = a.currentTransfers(transfers); err != nil {
+		return 0, err
+	}
+	for _, transfer := range transfers {
+		if !a.epoch.Contains(transfer.Timestamp) {
+			a.backlog = append(a.backlog, transfer)
+		}
+	}
+	orgID := string(a.org.ID)
+	for idx, transfer := range current {
+		fromTX, toTX := transaction.NewPairLocalPlain(transfer.From, transfer.To, transfer.Amount, transfer.Timestamp)
+		tx := fromTX
+		if transfer.To == orgID {
+			tx = toTX
+		}
+		txID, err := a.org.RecordTransaction(tx)
+		if err != nil {
+			return idx, err
+		}
+		counterPartyID := organization.TypeID(tx.CounterParty)
+		a.localTXIDMap[counterPartyID] = append(a.localTXIDMap[counterPartyID], txID)
+	}
+	return len(current), nil
+}
+
+// currentTransfers validates the transfers and returns the ones in the current epoch, the caller must hold mu.
+func (a *Agent) currentTransfers(transfers []*Transfer) ([]*Transfer, error) {
+	if a.epoch == nil {
+		return nil, errors.New("agent not synced with the committee")
+	}
+	orgID := string(a.org.ID)
+	var current []*Transfer
+	for _, transfer := range transfers {
+		if err := transfer.validate(); err != nil {
+			return nil, err
+		}
+		if transfer.From != orgID && transfer.To != orgID {
+			return nil, fmt.Errorf("transfer from %s to %s not involving %s", transfer.From, transfer.To, orgID)
+		}
+		if transfer.Timestamp < a.epoch.StartTime {
+			return nil, fmt.Errorf("transfer at %d before epoch %d", transfer.Timestamp, a.epoch.ID)
+		}
+		if a.epoch.Contains(transfer.Timestamp) {
+			current = append(current, transfer)
+		}
+	}
+	if len(current) > 0 && (a.closing || a.closed) {
+		return nil, fmt.Errorf("epoch %d is closed", a.epoch.ID)
+	}
+	return current, nil
+}
+
+// counterParties returns the distinct counterparties of the organization in the transfers in their order.
+func (a *Agent) counterParties(transfers []*Transfer) []organization.TypeID {
+	var counterPartyIDs []organization.TypeID
+	seen := make(map[organization.TypeID]bool)
+	for _, transfer := range transfers {
+		counterPartyID := organization.TypeID(transfer.To)
+		if transfer.To == string(a.org.ID) {
+			counterPartyID = organization.TypeID(transfer.From)
+		}
+		if !seen[counterPartyID] {
+			seen[counterPartyID] = true
+			counterPartyIDs = append(counterPartyIDs, counterPartyID)
+		}
+	}
+	return counterPartyIDs
+}
+
+// IngestDir ingests the feed files in the directory in the order of their names, the ingested files are moved to
+// the processed subdirectory and the failed ones to the failed subdirectory with the error next to them.
+// A file is never ingested twice, so the transfers of a failed file recorded before the error are not recorded again.
+func (a *Agent) IngestDir(ctx context.Context, dir string) (int, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return 0, err
+	}
+	numRecorded := 0
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if _, ok := feedParser(entry.Name()); !ok {
+			continue
+		}
+		n, err := a.ingestFile(ctx, filepath.Join(dir, entry.Name()))
+		numRecorded += n
+		if err == nil {
+			if err = moveFile(dir, ProcessedDir, entry.Name()); err != nil {
+				return numRecorded, err
+			}
+			continue
+		}
+		if moveErr := moveFile(dir, FailedDir, entry.Name()); moveErr != nil {
+			return numRecorded, moveErr
+		}
+		errMsg := fmt.Sprintf("recorded %d transfers before the error: %v\n", n, err)
+		errPath := filepath.Join(dir, FailedDir, entry.Name()+".error")
+		if err = os.WriteFile(errPath, []byte(errMsg), 0o600); err != nil {
+			return numRecorded, err
+		}
+	}
+	return numRecorded, nil
+}
+
+func (a *Agent) ingestFile(ctx context.Context, path string) (int, error) {
+	transfers, err := ParseFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return a.Ingest(ctx, transfers)
+}
+
+// CloseEpoch posts the accumulators with every counterparty to the organization chain and hands the submissions
+// to the auditor, the transfers of the epoch ingested afterwards are rejected. If an attempt fails, the next one
+// skips the accumulators already posted and the submissions already handed to the auditor.
+func (a *Agent) CloseEpoch(ctx context.Context) error {
+	a.closeMu.Lock()
+	defer a.closeMu.Unlock()
+	a.mu.Lock()
+	if a.epoch == nil {
+		a.mu.Unlock()
+		return errors.New("agent not synced with the committee")
+	}
+	if a.closed {
+		a.mu.Unlock()
+		return fmt.Errorf("epoch %d is closed", a.epoch.ID)
+	}
+	e := a.epoch
+	a.closing = true
+	counterPartyIDs := make([]organization.TypeID, 0, len(a.localTXIDMap))
+	for counterPartyID := range a.localTXIDMap {
+		counterPartyIDs = append(counterPartyIDs, counterPartyID)
+	}
+	a.mu.Unlock()
+	sort.Slice(counterPartyIDs, func(i, j int) bool { return counterPartyIDs[i] < counterPartyIDs[j] })
+	for _, counterPartyID := range counterPartyIDs {
+		if err := a.closeWith(ctx, e, counterPartyID); err != nil {
+			return err
+		}
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.epoch != e {
+		return fmt.Errorf("epoch %d rolled over while closing", e.ID)
+	}
+	a.closed = true
+	return nil
+}
+
+// closeWith posts the accumulator with the counterparty unless it is already posted,
+// and hands the submission to the auditor, mu is released around the remote calls.
+func (a *Agent) closeWith(ctx context.Context, e *epoch.Epoch, counterPartyID organization.TypeID) error {
+	a.mu.Lock()
+	if a.epoch != e {
+		a.mu.Unlock()
+		return fmt.Errorf("epoch %d rolled over while closing", e.ID)
+	}
+	if a.submittedMap[counterPartyID] {
+		a.mu.Unlock()
+		return nil
+	}
+	orgTXID, posted := a.orgTXIDMap[counterPartyID]
+	var orgOnChainTX *transaction.OrgOnChain
+	if !posted {
+		orgPlainTX, err := a.org.ComposeTXOrgChain(counterPartyID)
+		if err != nil {
+			a.mu.Unlock()
+			return err
+		}
+		orgOnChainTX = orgPlainTX.ToOnChain()
+		if err = a.org.SignTX(orgOnChainTX); err != nil {
+			a.mu.Unlock()
+			return err
+		}
+	}
+	a.mu.Unlock()
+	if !posted {
+		var err error
+		if orgTXID, err = a.orgChain.SubmitTX(orgOnChainTX); err != nil {
+			return err
+		}
+	}
+
+	a.mu.Lock()
+	if a.epoch != e {
+		a.mu.Unlock()
+		return fmt.Errorf("epoch %d rolled over while closing", e.ID)
+	}
+	a.orgTXIDMap[counterPartyID] = orgTXID
+	submission := &auditd.Submission{
+		EpochID:      uint64(e.ID),
+		CounterParty: string(counterPartyID),
+		OrgTXID:      orgTXID,
+		LocalTXIDs:   a.localTXIDMap[counterPartyID],
+	}
+	err := submission.SealRandomness(
+		a.auditorPublicKey, string(a.org.ID), a.org.EpochTXRandomness(counterPartyID), a.randStream,
+	)
+	a.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err = a.auditor.Submit(ctx, submission); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.epoch == e {
+		a.submittedMap[counterPartyID] = true
+	}
+	return nil
+}
+
+// Run follows the epochs of the committee, ingests the feed files of the directory,
+// and closes the epoch once the clock passes its end, every interval until the context is done.
+// The errors do not stop the agent, the failed steps are retried and the last error is kept.
+func (a *Agent) Run(ctx context.Context, dir string, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		err := a.step(ctx, dir)
+		a.mu.Lock()
+		a.lastErr = err
+		a.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// LastError returns the error of the last step of Run, or nil if it succeeded.
+func (a *Agent) LastError() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.lastErr
+}
+
+func (a *Agent) step(ctx context.Context, dir string) error {
+	if err := a.Sync(ctx); err != nil {
+		return err
+	}
+	if _, err := a.IngestDir(ctx, dir); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	due := !a.closed && a.now().Unix() >= a.epoch.EndTime
+	a.mu.Unlock()
+	if due {
+		return a.CloseEpoch(ctx)
+	}
+	return nil
+}
+
+func moveFile(dir, subDir, name string) error {
+	if err := os.MkdirAll(filepath.Join(dir, subDir), 0o700); err != nil {
+		return err
+	}
+	return os.Rename(filepath.Join(dir, name), filepath.Join(dir, subDir, name))
+}
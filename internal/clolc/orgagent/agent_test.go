@@ -0,0 +1,261 @@
+package orgagent
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/auti-project/auti/internal/clolc/auditd"
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/committee"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/service"
+	"github.com/auti-project/auti/internal/clolc/service/client"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/ledger"
+	"github.com/auti-project/auti/internal/money"
+)
+
+// harness runs the committee service and the daemon of aud1 on loopback listeners,
+// with an agent for each of org1 and org2 audited by aud1. The daemon answers 503 while auditorDown is set.
+type harness struct {
+	server      *service.Server
+	daemon      *auditd.Daemon
+	agents      map[organization.TypeID]*Agent
+	orgChain    ledger.Ledger[*transaction.OrgOnChain]
+	auditorDown atomic.Bool
+}
+
+func newHarness(t *testing.T) *harness {
+	ctx := context.Background()
+	organizations := []*organization.Organization{
+		organization.New("org1", organization.NewMemoryLocalChain()),
+		organization.New("org2", organization.NewMemoryLocalChain()),
+	}
+	aud := auditor.New("aud1", organizations)
+	com := committee.New("com", []*auditor.Auditor{aud})
+	enrollments := map[string]*service.Enrollment{
+		string(aud.ID): {Role: service.RoleAuditor, PublicKey: aud.SigningPublicKey},
+	}
+	for _, org := range organizations {
+		enrollments[string(org.ID)] = &service.Enrollment{Role: service.RoleOrganization, PublicKey: org.SigningPublicKey}
+	}
+	h := &harness{
+		server: service.NewServer(com, enrollments),
+		agents: make(map[organization.TypeID]*Agent),
+	}
+	comServer := httptest.NewServer(h.server)
+	t.Cleanup(comServer.Close)
+	h.orgChain = organization.NewMemoryOrgChain()
+
+	audClient := client.ForAuditor(comServer.URL, aud, com.SigningPublicKey)
+	if err := audClient.Register(ctx, service.RoleAuditor); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	h.daemon = auditd.New(aud, audClient, h.orgChain, auditor.NewMemoryAudChain())
+	daemonServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if h.auditorDown.Load() {
+			http.Error(w, "auditor down", http.StatusServiceUnavailable)
+			return
+		}
+		h.daemon.ServeHTTP(w, r)
+	}))
+	t.Cleanup(daemonServer.Close)
+	for _, org := range organizations {
+		if err := h.daemon.AddOrganization(org.ID, org.SigningPublicKey, org.LocalChain()); err != nil {
+			t.Fatalf("AddOrganization() error = %v", err)
+		}
+		orgClient := client.ForOrganization(comServer.URL, org, com.SigningPublicKey)
+		if err := orgClient.Register(ctx, service.RoleOrganization); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		audDaemonClient := auditd.NewClient(daemonServer.URL, string(org.ID), org)
+		h.agents[org.ID] = New(org, orgClient, audDaemonClient, aud.SigningPublicKey, h.orgChain)
+	}
+	h.openEpoch(t, 1, 0, 100)
+	return h
+}
+
+// openEpoch initializes the epoch and syncs the daemon and the agents.
+func (h *harness) openEpoch(t *testing.T, id epoch.TypeID, startTime, endTime int64) {
+	ctx := context.Background()
+	e, _ := epoch.New(id, startTime, endTime)
+	if err := h.server.InitializeEpoch(e, nil); err != nil {
+		t.Fatalf("InitializeEpoch() error = %v", err)
+	}
+	if err := h.daemon.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	for _, agent := range h.agents {
+		if err := agent.Sync(ctx); err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+	}
+}
+
+func writeFeed(t *testing.T, dir, name, content string) {
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+}
+
+func TestAgent_IngestDir(t *testing.T) {
+	tests := []struct {
+		name           string
+		feed2          string
+		wantConsistent bool
+	}{
+		{
+			name:           "test_consistent",
+			feed2:          `{"from": "org1", "to": "org2", "amount": 10.50, "currency": "USD", "timestamp": 1}`,
+			wantConsistent: true,
+		},
+		{
+			name:           "test_amount_mismatch",
+			feed2:          `{"from": "org1", "to": "org2", "amount": 10.49, "currency": "USD", "timestamp": 1}`,
+			wantConsistent: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			ctx := context.Background()
+			dir1, dir2 := t.TempDir(), t.TempDir()
+			writeFeed(t, dir1, "01.csv", "from,to,amount,currency,timestamp\norg1,org2,10.5,USD,1\n")
+			writeFeed(t, dir1, "02.csv", "from,to,amount,timestamp\norg1,org2,1,1\n")
+			writeFeed(t, dir1, "notes.txt", "not a feed")
+			writeFeed(t, dir2, "01.jsonl", tt.feed2)
+
+			n, err := h.agents["org1"].IngestDir(ctx, dir1)
+			if err != nil || n != 1 {
+				t.Fatalf("IngestDir() = %d, %v, want 1", n, err)
+			}
+			if _, err = os.Stat(filepath.Join(dir1, ProcessedDir, "01.csv")); err != nil {
+				t.Errorf("IngestDir() did not move the ingested file: %v", err)
+			}
+			if _, err = os.Stat(filepath.Join(dir1, FailedDir, "02.csv.error")); err != nil {
+				t.Errorf("IngestDir() did not report the failed file: %v", err)
+			}
+			if _, err = os.Stat(filepath.Join(dir1, "notes.txt")); err != nil {
+				t.Errorf("IngestDir() touched the file of unknown format: %v", err)
+			}
+			if n, err = h.agents["org2"].IngestDir(ctx, dir2); err != nil || n != 1 {
+				t.Fatalf("IngestDir() = %d, %v, want 1", n, err)
+			}
+			for _, agent := range h.agents {
+				if err = agent.CloseEpoch(ctx); err != nil {
+					t.Fatalf("CloseEpoch() error = %v", err)
+				}
+			}
+			h.daemon.Examine()
+			status := h.daemon.Status()
+			for _, exam := range status.Examinations {
+				if exam.State != auditd.StateExamined {
+					t.Errorf("examination of %s with %s is %s: %s", exam.OrgID, exam.CounterParty, exam.State, exam.Error)
+				}
+			}
+			if len(status.PairChecks) != 1 || status.PairChecks[0].Consistent != tt.wantConsistent {
+				t.Errorf("Status() pair checks = %v, want one with %v", status.PairChecks, tt.wantConsistent)
+			}
+		})
+	}
+}
+
+func TestAgent_Ingest(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	agent := h.agents["org1"]
+	tests := []struct {
+		name      string
+		transfers []*Transfer
+		want      int
+		wantErr   bool
+	}{
+		{
+			name:      "test_current_epoch",
+			transfers: []*Transfer{{From: "org1", To: "org2", Amount: money.MustParse("1", "USD"), Timestamp: 5}},
+			want:      1,
+			wantErr:   false,
+		},
+		{
+			name:      "test_next_epoch_held_back",
+			transfers: []*Transfer{{From: "org2", To: "org1", Amount: money.MustParse("2", "USD"), Timestamp: 150}},
+			want:      0,
+			wantErr:   false,
+		},
+		{
+			name:      "test_other_organizations",
+			transfers: []*Transfer{{From: "org2", To: "org3", Amount: money.MustParse("2", "USD"), Timestamp: 5}},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := agent.Ingest(ctx, tt.transfers)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Ingest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Ingest() got = %d, want %d", got, tt.want)
+			}
+		})
+	}
+	if err := agent.CloseEpoch(ctx); err != nil {
+		t.Fatalf("CloseEpoch() error = %v", err)
+	}
+	if _, err := agent.Ingest(ctx, tests[0].transfers); err == nil {
+		t.Errorf("Ingest() after CloseEpoch() error = nil, want error")
+	}
+	// the held back transfer is recorded once the next epoch is opened
+	h.openEpoch(t, 2, 100, 200)
+	if got := len(agent.localTXIDMap["org2"]); got != 1 {
+		t.Errorf("Sync() recorded %d held back transfers, want 1", got)
+	}
+	if _, err := agent.org.ArchivedEpochTXRandomness(1, "org2"); err != nil {
+		t.Errorf("ArchivedEpochTXRandomness() error = %v", err)
+	}
+}
+
+func TestAgent_CloseEpochRetry(t *testing.T) {
+	h := newHarness(t)
+	ctx := context.Background()
+	agent := h.agents["org1"]
+	transfers := []*Transfer{
+		{From: "org1", To: "org2", Amount: money.MustParse("1", "USD"), Timestamp: 5},
+		{From: "org2", To: "org1", Amount: money.MustParse("2", "USD"), Timestamp: 6},
+	}
+	if _, err := agent.Ingest(ctx, transfers); err != nil {
+		t.Fatalf("Ingest() error = %v", err)
+	}
+	h.auditorDown.Store(true)
+	if err := agent.CloseEpoch(ctx); err == nil {
+		t.Fatalf("CloseEpoch() with the auditor down error = nil, want error")
+	}
+	// the accumulator is posted, so the transfers of the epoch are rejected from the first attempt on
+	if _, err := agent.Ingest(ctx, transfers); err == nil {
+		t.Errorf("Ingest() after a failed CloseEpoch() error = nil, want error")
+	}
+	h.auditorDown.Store(false)
+	if err := agent.CloseEpoch(ctx); err != nil {
+		t.Fatalf("CloseEpoch() error = %v", err)
+	}
+	orgTXList, _, err := h.orgChain.ReadAllTXsByPage("")
+	if err != nil {
+		t.Fatalf("ReadAllTXsByPage() error = %v", err)
+	}
+	if len(orgTXList) != 1 {
+		t.Errorf("CloseEpoch() posted %d accumulators, want 1", len(orgTXList))
+	}
+	if status := h.daemon.Status(); len(status.Examinations) != 1 {
+		t.Errorf("Status() got %d examinations, want 1", len(status.Examinations))
+	}
+	if err = agent.CloseEpoch(ctx); err == nil {
+		t.Errorf("CloseEpoch() of a closed epoch error = nil, want error")
+	}
+}
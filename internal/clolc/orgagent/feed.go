@@ -0,0 +1,191 @@
+package orgagent
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/auti-project/auti/internal/money"
+)
+
+// Transfer is a record of the bookkeeping feed, a transfer of the amount from one organization to another.
+// The agent keeps the side of its own organization.
+type Transfer struct {
+	From      string
+	To        string
+	Amount    money.Amount
+	Timestamp int64
+}
+
+// transferRecord is the form of a transfer in the feeds, the amount is a decimal string
+// or number in the ISO 4217 currency, e.g., 100.25 with currency USD.
+type transferRecord struct {
+	From      string      `json:"from"`
+	To        string      `json:"to"`
+	Amount    json.Number `json:"amount"`
+	Currency  string      `json:"currency"`
+	Timestamp int64       `json:"timestamp"`
+}
+
+// csvColumns are the columns a CSV feed must have, in any order after the header.
+var csvColumns = []string{"from", "to", "amount", "currency", "timestamp"}
+
+// ParseCSV reads the transfers of a CSV feed with a header row naming the columns, other columns are ignored.
+func ParseCSV(r io.Reader) ([]*Transfer, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("read header: %v", err)
+	}
+	columnIdx := make(map[string]int, len(header))
+	for idx, name := range header {
+		columnIdx[strings.ToLower(strings.TrimSpace(name))] = idx
+	}
+	for _, name := range csvColumns {
+		if _, ok := columnIdx[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+	var transfers []*Transfer
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return transfers, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		timestamp, err := strconv.ParseInt(record[columnIdx["timestamp"]], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid timestamp: %v", line, err)
+		}
+		transfer, err := (&transferRecord{
+			From:      record[columnIdx["from"]],
+			To:        record[columnIdx["to"]],
+			Amount:    json.Number(record[columnIdx["amount"]]),
+			Currency:  record[columnIdx["currency"]],
+			Timestamp: timestamp,
+		}).toTransfer()
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		transfers = append(transfers, transfer)
+	}
+}
+
+// ParseJSON reads the transfers of a JSON feed, either an array of transfers or one transfer per line.
+func ParseJSON(r io.Reader) ([]*Transfer, error) {
+	reader := bufio.NewReader(r)
+	first, err := firstNonSpace(reader)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(reader)
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+	var records []*transferRecord
+	if first == '[' {
+		if err = dec.Decode(&records); err != nil {
+			return nil, err
+		}
+	} else {
+		for {
+			record := &transferRecord{}
+			if err = dec.Decode(record); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, err
+			}
+			records = append(records, record)
+		}
+	}
+	transfers := make([]*Transfer, len(records))
+	for idx, record := range records {
+		if transfers[idx], err = record.toTransfer(); err != nil {
+			return nil, fmt.Errorf("transfer %d: %v", idx, err)
+		}
+	}
+	return transfers, nil
+}
+
+// ParseFile reads the transfers of a feed file, the format follows the extension: .csv, .json or .jsonl.
+func ParseFile(path string) ([]*Transfer, error) {
+	parse, ok := feedParser(path)
+	if !ok {
+		return nil, fmt.Errorf("unknown feed format of %s", path)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parse(file)
+}
+
+func feedParser(path string) (func(io.Reader) ([]*Transfer, error), bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ParseCSV, true
+	case ".json", ".jsonl":
+		return ParseJSON, true
+	}
+	return nil, false
+}
+
+func (t *Transfer) validate() error {
+	if t.From == "" || t.To == "" {
+		return errors.New("empty organization ID")
+	}
+	if t.From == t.To {
+		return fmt.Errorf("transfer from %s to itself", t.From)
+	}
+	if t.Amount.Currency().Code == "" {
+		return errors.New("amount without a currency")
+	}
+	if t.Amount.MinorUnits() < 0 {
+		return fmt.Errorf("negative amount %s", t.Amount)
+	}
+	return nil
+}
+
+func (r *transferRecord) toTransfer() (*Transfer, error) {
+	amount, err := money.Parse(string(r.Amount), r.Currency)
+	if err != nil {
+		return nil, err
+	}
+	transfer := &Transfer{
+		From:      r.From,
+		To:        r.To,
+		Amount:    amount,
+		Timestamp: r.Timestamp,
+	}
+	if err = transfer.validate(); err != nil {
+		return nil, err
+	}
+	return transfer, nil
+}
+
+// firstNonSpace peeks the first byte of the JSON value without consuming it.
+func firstNonSpace(reader *bufio.Reader) (byte, error) {
+	for {
+		b, err := reader.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\n' && b != '\r' {
+			return b, reader.UnreadByte()
+		}
+	}
+}
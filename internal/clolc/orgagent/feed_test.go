@@ -0,0 +1,122 @@
+package orgagent
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCSV(t *testing.T) {
+	tests := []struct {
+		name    string
+		feed    string
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name:    "test_valid",
+			feed:    "from,to,amount,currency,timestamp\norg1,org2,10.5,USD,1\norg2,org1,300,JPY,2\n",
+			wantLen: 2,
+			wantErr: false,
+		},
+		{
+			name:    "test_reordered_and_extra_columns",
+			feed:    "timestamp, memo, currency, amount, to, from\n1, invoice 42, EUR, 10, org2, org1\n",
+			wantLen: 1,
+			wantErr: false,
+		},
+		{
+			name:    "test_missing_column",
+			feed:    "from,to,amount,timestamp\norg1,org2,10,1\n",
+			wantErr: true,
+		},
+		{
+			name:    "test_invalid_amount",
+			feed:    "from,to,amount,currency,timestamp\norg1,org2,ten,USD,1\n",
+			wantErr: true,
+		},
+		{
+			name:    "test_negative_amount",
+			feed:    "from,to,amount,currency,timestamp\norg1,org2,-10,USD,1\n",
+			wantErr: true,
+		},
+		{
+			name:    "test_excess_precision",
+			feed:    "from,to,amount,currency,timestamp\norg1,org2,0.005,USD,1\n",
+			wantErr: true,
+		},
+		{
+			name:    "test_self_transfer",
+			feed:    "from,to,amount,currency,timestamp\norg1,org1,10,USD,1\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCSV(strings.NewReader(tt.feed))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCSV() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != tt.wantLen {
+				t.Errorf("ParseCSV() got %d transfers, want %d", len(got), tt.wantLen)
+			}
+		})
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		feed    string
+		wantLen int
+		wantErr bool
+	}{
+		{
+			name:    "test_array",
+			feed:    ` [{"from": "org1", "to": "org2", "amount": 10.5, "currency": "USD", "timestamp": 1}]`,
+			wantLen: 1,
+			wantErr: false,
+		},
+		{
+			name: "test_lines",
+			feed: `{"from": "org1", "to": "org2", "amount": 10.5, "currency": "USD", "timestamp": 1}
+{"from": "org2", "to": "org1", "amount": "3", "currency": "KWD", "timestamp": 2}
+`,
+			wantLen: 2,
+			wantErr: false,
+		},
+		{
+			name:    "test_empty",
+			feed:    "\n",
+			wantLen: 0,
+			wantErr: false,
+		},
+		{
+			name:    "test_unknown_field",
+			feed:    `{"from": "org1", "to": "org2", "amount": 10, "currency": "EUR", "timestamp": 1, "memo": "invoice 42"}`,
+			wantErr: true,
+		},
+		{
+			name:    "test_unknown_currency",
+			feed:    `{"from": "org1", "to": "org2", "amount": 10, "currency": "XYZ", "timestamp": 1}`,
+			wantErr: true,
+		},
+		{
+			name:    "test_missing_organization",
+			feed:    `{"from": "org1", "amount": 10, "currency": "USD", "timestamp": 1}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseJSON(strings.NewReader(tt.feed))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if len(got) != tt.wantLen {
+				t.Errorf("ParseJSON() got %d transfers, want %d", len(got), tt.wantLen)
+			}
+		})
+	}
+}
@@ -0,0 +1,14 @@
+package organization
+
+import (
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/ledger"
+)
+
+// NewMemoryLocalChain returns an in-memory stand-in for the local chain,
+// it rejects the transactions without a valid signature like the chaincode.
+func NewMemoryLocalChain() *ledger.Memory[transaction.LocalOnChain, *transaction.LocalOnChain] {
+	return ledger.NewMemory(func(tx *transaction.LocalOnChain) error {
+		return ledger.VerifySignature(tx.Signer, tx.Signature, tx.SigningMessage())
+	})
+}
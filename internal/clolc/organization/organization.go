@@ -1,14 +1,18 @@
 package organization
 
 import (
-	"crypto/sha256"
+	"crypto/cipher"
 	"encoding/hex"
+	"errors"
 	"fmt"
 
 	"go.dedis.ch/kyber/v3"
 
 	"github.com/auti-project/auti/internal/clolc/transaction"
 	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/hashing"
+	"github.com/auti-project/auti/internal/ledger"
 )
 
 type TypeID string
@@ -19,48 +23,140 @@ type Organization struct {
 	IDHash              string
 	EpochID             TypeEpochID
 	epochAccumulatorMap map[[2]string]kyber.Point
-	epochTXRandomness   map[[2]string]kyber.Scalar
+	epochTXRandomness   map[[2]string][]kyber.Scalar
+	epochTXAmounts      map[[2]string][]int64
+	epochHistory        map[epoch.TypeID]*epochAccumulators
+	SigningPublicKey    crypto.TypePublicKey
+	signingKey          crypto.TypePrivateKey
+	randStream          cipher.Stream
+	localChain          ledger.Ledger[*transaction.LocalOnChain]
 }
 
-func New(id string) *Organization {
-	sha256Func := sha256.New()
-	sha256Func.Write([]byte(id))
-	idHash := hex.EncodeToString(sha256Func.Sum(nil))
+// New creates an organization recording its transactions on the given local chain,
+// e.g., a ledger.Fabric client or NewMemoryLocalChain.
+func New(id string, localChain ledger.Ledger[*transaction.LocalOnChain]) *Organization {
+	return NewWithRandStream(id, localChain, crypto.RandomStream())
+}
+
+// NewWithRandStream creates an organization drawing all its randomness from the given stream,
+// use crypto.NewSeededStream for reproducible runs.
+func NewWithRandStream(id string, localChain ledger.Ledger[*transaction.LocalOnChain], rand cipher.Stream) *Organization {
 	org := &Organization{
 		ID:                  TypeID(id),
-		IDHash:              idHash,
+		IDHash:              IDHashString(TypeID(id)),
 		epochAccumulatorMap: make(map[[2]string]kyber.Point),
-		epochTXRandomness:   make(map[[2]string]kyber.Scalar),
+		epochTXRandomness:   make(map[[2]string][]kyber.Scalar),
+		epochTXAmounts:      make(map[[2]string][]int64),
+		epochHistory:        make(map[epoch.TypeID]*epochAccumulators),
+		randStream:          rand,
+		localChain:          localChain,
 	}
+	org.signingKey, org.SigningPublicKey = crypto.SigningKeyGen(rand)
 	return org
 }
 
+// SetSigningKey replaces the generated signing key with a long-term key loaded from storage, e.g., by the binaries.
+func (c *Organization) SetSigningKey(signingKey crypto.TypePrivateKey) {
+	c.signingKey = signingKey
+	c.SigningPublicKey = crypto.KyberSuite.Point().Mul(signingKey, nil)
+}
+
+// SignTX signs the on-chain transaction with the long-term signing key of the organization.
+func (c *Organization) SignTX(tx crypto.Signable) error {
+	return tx.Sign(c.signingKey, c.randStream)
+}
+
+// SignMessage signs the message with the long-term signing key of the organization, e.g., to authenticate to the committee service.
+func (c *Organization) SignMessage(msg []byte) ([]byte, error) {
+	return crypto.Sign(c.signingKey, msg, c.randStream)
+}
+
+// LocalChain returns the local chain the organization records its transactions on.
+func (c *Organization) LocalChain() ledger.Ledger[*transaction.LocalOnChain] {
+	return c.localChain
+}
+
+// EpochTXRandomness returns the randomness of the commitments of the recorded transactions
+// with the counterparty, in the order the transactions were recorded.
+func (c *Organization) EpochTXRandomness(counterParty TypeID) []kyber.Scalar {
+	orgMapKey := IDHashKey(c.IDHash, IDHashString(counterParty))
+	return c.epochTXRandomness[orgMapKey]
+}
+
+// EpochTXAmounts returns the amounts of the recorded transactions with the counterparty,
+// in the same order as EpochTXRandomness, together they open the commitments in a dispute.
+func (c *Organization) EpochTXAmounts(counterParty TypeID) []int64 {
+	orgMapKey := IDHashKey(c.IDHash, IDHashString(counterParty))
+	return c.epochTXAmounts[orgMapKey]
+}
+
 func (c *Organization) SetEpochID(randID []byte) {
 	c.EpochID = randID
 }
 
-func (c *Organization) RecordTransaction(tx *transaction.LocalPlain) error {
+// epochAccumulators is what the organization keeps of an epoch after rolling over,
+// the amounts and the randomness are needed to disclose the commitments in a dispute over the epoch.
+type epochAccumulators struct {
+	epochID           TypeEpochID
+	accumulatorMap    map[[2]string]kyber.Point
+	epochTXRandomness map[[2]string][]kyber.Scalar
+	epochTXAmounts    map[[2]string][]int64
+}
+
+// RolloverEpoch archives the accumulators and the commitment randomness of the closed epoch,
+// the transactions recorded afterwards are accumulated from scratch for the next epoch.
+func (c *Organization) RolloverEpoch(closedEpochID epoch.TypeID) error {
+	if _, ok := c.epochHistory[closedEpochID]; ok {
+		return fmt.Errorf("epoch %d is already rolled over", closedEpochID)
+	}
+	c.epochHistory[closedEpochID] = &epochAccumulators{
+		epochID:           c.EpochID,
+		accumulatorMap:    c.epochAccumulatorMap,
+		epochTXRandomness: c.epochTXRandomness,
+		epochTXAmounts:    c.epochTXAmounts,
+	}
+	c.EpochID = nil
+	c.epochAccumulatorMap = make(map[[2]string]kyber.Point)
+	c.epochTXRandomness = make(map[[2]string][]kyber.Scalar)
+	c.epochTXAmounts = make(map[[2]string][]int64)
+	return nil
+}
+
+// ArchivedEpochTXRandomness returns the randomness of the commitments of the transactions
+// with the counterparty in a rolled over epoch, in the order the transactions were recorded.
+func (c *Organization) ArchivedEpochTXRandomness(epochID epoch.TypeID, counterParty TypeID) ([]kyber.Scalar, error) {
+	archive, ok := c.epochHistory[epochID]
+	if !ok {
+		return nil, fmt.Errorf("epoch %d not rolled over", epochID)
+	}
+	orgMapKey := IDHashKey(c.IDHash, IDHashString(counterParty))
+	return archive.epochTXRandomness[orgMapKey], nil
+}
+
+// ArchivedEpochTXAmounts returns the amounts of the transactions with the counterparty in a rolled over epoch,
+// in the same order as ArchivedEpochTXRandomness.
+func (c *Organization) ArchivedEpochTXAmounts(epochID epoch.TypeID, counterParty TypeID) ([]int64, error) {
+	archive, ok := c.epochHistory[epochID]
+	if !ok {
+		return nil, fmt.Errorf("epoch %d not rolled over", epochID)
+	}
+	orgMapKey := IDHashKey(c.IDHash, IDHashString(counterParty))
+	return archive.epochTXAmounts[orgMapKey], nil
+}
+
+// RecordTransaction submits the hidden transaction with its range proof to the local chain and returns its key on the ledger,
+// the commitment is accumulated only if the submission succeeds.
+func (c *Organization) RecordTransaction(tx *transaction.LocalPlain) (string, error) {
 	// Submit the transaction to the local chain
-	sha256Func := sha256.New()
-	sha256Func.Write([]byte(tx.CounterParty))
-	counterPartyHash := sha256Func.Sum(nil)
-	commitment, randScalar, err := crypto.PedersenCommit(tx.Amount)
+	clolcHidden, commitment, randScalar, err := tx.HideWithRangeProof(c.randStream)
 	if err != nil {
-		return err
+		return "", err
 	}
-	commitmentBytes, err := commitment.MarshalBinary()
+	txID, err := c.SubmitTXLocalChain(clolcHidden)
 	if err != nil {
-		return err
-	}
-	clolcHidden := &transaction.LocalHidden{
-		CounterParty: counterPartyHash,
-		Commitment:   commitmentBytes,
-		Timestamp:    tx.Timestamp,
+		return "", err
 	}
-	if err = c.SubmitTXLocalChain(clolcHidden); err != nil {
-		return err
-	}
-	counterPartyHashStr := hex.EncodeToString(counterPartyHash)
+	counterPartyHashStr := hex.EncodeToString(clolcHidden.CounterParty)
 	orgMapKey := IDHashKey(c.IDHash, counterPartyHashStr)
 	// Accumulate the commitment to the corresponding accumulator
 	if _, ok := c.epochAccumulatorMap[orgMapKey]; !ok {
@@ -71,9 +167,10 @@ func (c *Organization) RecordTransaction(tx *transaction.LocalPlain) error {
 			commitment,
 		)
 	}
-	// Record the randomness used in the commitment
-	c.epochTXRandomness[orgMapKey] = randScalar
-	return nil
+	// Record the amount and the randomness opening the commitment
+	c.epochTXRandomness[orgMapKey] = append(c.epochTXRandomness[orgMapKey], randScalar)
+	c.epochTXAmounts[orgMapKey] = append(c.epochTXAmounts[orgMapKey], tx.Amount)
+	return txID, nil
 }
 
 func (c *Organization) Accumulate(counterParty TypeID, commitment kyber.Point) {
@@ -90,8 +187,16 @@ func (c *Organization) Accumulate(counterParty TypeID, commitment kyber.Point) {
 	}
 }
 
-func (c *Organization) SubmitTXLocalChain(tx *transaction.LocalHidden) error {
-	panic("not implemented")
+// SubmitTXLocalChain signs the transaction and submits it to the local chain of the organization.
+func (c *Organization) SubmitTXLocalChain(tx *transaction.LocalHidden) (string, error) {
+	if c.localChain == nil {
+		return "", errors.New("the organization has no local chain")
+	}
+	onChainTX := tx.ToOnChain()
+	if err := c.SignTX(onChainTX); err != nil {
+		return "", err
+	}
+	return c.localChain.SubmitTX(onChainTX)
 }
 
 func (c *Organization) ComposeTXOrgChain(counterParty TypeID) (*transaction.OrgPlain, error) {
@@ -111,9 +216,7 @@ func (c *Organization) ComposeTXOrgChain(counterParty TypeID) (*transaction.OrgP
 }
 
 func IDHashBytes(id TypeID) []byte {
-	sha256Func := sha256.New()
-	sha256Func.Write([]byte(id))
-	return sha256Func.Sum(nil)
+	return hashing.Sum(hashing.TagOrgID, []byte(id))
 }
 
 func IDHashString(id TypeID) string {
@@ -136,9 +239,7 @@ func IDHashKey(orgIDHash1, orgIDHash2 string) [2]string {
 }
 
 func EpochIDHashBytes(epochID TypeEpochID) []byte {
-	sha256Func := sha256.New()
-	sha256Func.Write(epochID)
-	return sha256Func.Sum(nil)
+	return hashing.Sum(hashing.TagEpochID, epochID)
 }
 
 func EpochIDHashString(epochID TypeEpochID) string {
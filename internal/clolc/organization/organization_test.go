@@ -0,0 +1,96 @@
+package organization
+
+import (
+	"testing"
+
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/ledger"
+	"github.com/auti-project/auti/internal/money"
+)
+
+func TestOrganization_RecordTransaction(t *testing.T) {
+	tests := []struct {
+		name       string
+		localChain ledger.Ledger[*transaction.LocalOnChain]
+		wantErr    bool
+	}{
+		{
+			name:       "test_memory_local_chain",
+			localChain: NewMemoryLocalChain(),
+			wantErr:    false,
+		},
+		{
+			name:       "test_no_local_chain",
+			localChain: nil,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := New("org1", tt.localChain)
+			org.SetEpochID([]byte("epoch"))
+			txID, err := org.RecordTransaction(transaction.NewLocalPlain("org2", money.MustParse("100", "USD"), 1))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RecordTransaction() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			// the commitment is accumulated only if the transaction is on the local chain
+			if _, err = org.ComposeTXOrgChain("org2"); (err != nil) != tt.wantErr {
+				t.Errorf("ComposeTXOrgChain() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			onChainTX, err := tt.localChain.ReadTX(txID)
+			if err != nil {
+				t.Errorf("ReadTX() error = %v", err)
+				return
+			}
+			if ok, err := onChainTX.VerifySignature(org.SigningPublicKey); err != nil || !ok {
+				t.Errorf("VerifySignature() = %v, %v, want %v", ok, err, true)
+			}
+		})
+	}
+}
+
+func TestOrganization_RolloverEpoch(t *testing.T) {
+	org := New("org1", NewMemoryLocalChain())
+	org.SetEpochID([]byte("epoch0"))
+	if _, err := org.RecordTransaction(transaction.NewLocalPlain("org2", money.MustParse("100", "USD"), 1)); err != nil {
+		t.Fatalf("RecordTransaction() error = %v", err)
+	}
+	if err := org.RolloverEpoch(0); err != nil {
+		t.Fatalf("RolloverEpoch() error = %v", err)
+	}
+	// the accumulators of the next epoch start empty
+	if _, err := org.ComposeTXOrgChain("org2"); err == nil {
+		t.Errorf("ComposeTXOrgChain() after RolloverEpoch() error = nil, wantErr true")
+	}
+	if got := len(org.EpochTXRandomness("org2")); got != 0 {
+		t.Errorf("EpochTXRandomness() length = %d, want %d", got, 0)
+	}
+	randList, err := org.ArchivedEpochTXRandomness(0, "org2")
+	if err != nil || len(randList) != 1 {
+		t.Errorf("ArchivedEpochTXRandomness() length = %d, %v, want %d", len(randList), err, 1)
+	}
+	amountList, err := org.ArchivedEpochTXAmounts(0, "org2")
+	if err != nil || len(amountList) != 1 || amountList[0] != 10000 {
+		t.Errorf("ArchivedEpochTXAmounts() = %v, %v, want %v", amountList, err, []int64{10000})
+	}
+	if err = org.RolloverEpoch(0); err == nil {
+		t.Errorf("RolloverEpoch() twice error = nil, wantErr true")
+	}
+	if _, err = org.ArchivedEpochTXRandomness(1, "org2"); err == nil {
+		t.Errorf("ArchivedEpochTXRandomness() of unknown epoch error = nil, wantErr true")
+	}
+}
+
+func TestIDHashPoint(t *testing.T) {
+	// the ID and the epoch ID hashes are domain separated, even over the same bytes
+	if IDHashPoint("org1").Equal(EpochIDHashPoint(TypeEpochID("org1"))) {
+		t.Errorf("IDHashPoint() = EpochIDHashPoint() over the same bytes")
+	}
+	if got, want := IDHashString("org1"), "d9e927f1c287c32cb439002f51532c5e13d820b8cea7a4024488786585d2962e"; got != want {
+		t.Errorf("IDHashString() = %v, want %v", got, want)
+	}
+}
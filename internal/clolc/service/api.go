@@ -0,0 +1,101 @@
+package service
+
+import (
+	"github.com/auti-project/auti/internal/clolc/transaction"
+)
+
+// The endpoints of the committee service, the bodies are JSON.
+const (
+	// PathRegister registers the signing public key of an auditor or organization managed by the committee.
+	PathRegister = "/v1/register"
+	// PathEpoch returns the current epoch, it needs no authentication.
+	PathEpoch = "/v1/epoch"
+	// PathPublicKeys returns the epoch public keys of the organizations and the commitments to the epoch IDs (IN.4),
+	// it needs no authentication.
+	PathPublicKeys = "/v1/public-keys"
+	// PathAuditorParameters returns the epoch parameters of the auditor sealed to the ephemeral key of the request.
+	PathAuditorParameters = "/v1/auditor-parameters"
+	// PathOrgParameters returns the epoch ID of the organization sealed to the ephemeral key of the request.
+	PathOrgParameters = "/v1/organization-parameters"
+	// PathEdges declares a trading relationship of the organization in the middle of the epoch.
+	PathEdges = "/v1/edges"
+	// PathVerifyOrgAndAud verifies the result of an organization and its auditor (VR.1), open to that auditor only.
+	PathVerifyOrgAndAud = "/v1/verify/org-and-aud"
+	// PathVerifyAuditPair verifies the results of the auditors of a pair of organizations (VR.2), open to those auditors only.
+	PathVerifyAuditPair = "/v1/verify/audit-pair"
+)
+
+// The headers authenticating the requests and the responses.
+const (
+	HeaderEntity    = "Auti-Entity"
+	HeaderTimestamp = "Auti-Timestamp"
+	HeaderSignature = "Auti-Signature"
+)
+
+type Role string
+
+const (
+	RoleAuditor      Role = "auditor"
+	RoleOrganization Role = "organization"
+)
+
+// RegisterRequest is signed with the key it registers as the proof of possession.
+type RegisterRequest struct {
+	Role      Role   `json:"role"`
+	PublicKey string `json:"public_key"`
+}
+
+type EpochResponse struct {
+	ID        uint64 `json:"id"`
+	StartTime int64  `json:"start_time"`
+	EndTime   int64  `json:"end_time"`
+	State     string `json:"state"`
+}
+
+// PublicKeysResponse maps the organization ID hashes to the hex encoded epoch public keys,
+// and the organization and auditor ID hashes to the hex encoded commitments to their epoch IDs.
+type PublicKeysResponse struct {
+	PublicKeys         map[string]string `json:"public_keys"`
+	EpochIDCommitments map[string]string `json:"epoch_id_commitments"`
+}
+
+// ParametersRequest carries a fresh ephemeral public key per request,
+// the parameters are sealed to it so only the signer of the request can open them.
+type ParametersRequest struct {
+	EphemeralKey string `json:"ephemeral_key"`
+}
+
+// ParametersResponse carries the hex encoded keystore.EpochSecrets sealed to the ephemeral key,
+// the epoch ID is part of the additional data of the sealed box.
+type ParametersResponse struct {
+	EpochID uint64 `json:"epoch_id"`
+	Sealed  string `json:"sealed"`
+}
+
+type EdgeRequest struct {
+	CounterParty string `json:"counter_party"`
+}
+
+type VerifyOrgAndAudRequest struct {
+	OrgID string                  `json:"org_id"`
+	AudID string                  `json:"aud_id"`
+	OrgTX *transaction.OrgOnChain `json:"org_tx"`
+	AudTX *transaction.AudOnChain `json:"aud_tx"`
+}
+
+type VerifyAuditPairRequest struct {
+	OrgID1 string                  `json:"org_id_1"`
+	OrgID2 string                  `json:"org_id_2"`
+	AudID1 string                  `json:"aud_id_1"`
+	AudID2 string                  `json:"aud_id_2"`
+	AudTX1 *transaction.AudOnChain `json:"aud_tx_1"`
+	AudTX2 *transaction.AudOnChain `json:"aud_tx_2"`
+}
+
+type VerifyResponse struct {
+	Result bool `json:"result"`
+}
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+const (
+	requestSigningTag  = "auti-service-request"
+	responseSigningTag = "auti-service-response"
+	sealTag            = "auti-service-parameters"
+	// MaxClockSkew bounds the age of the timestamp of a signed request.
+	MaxClockSkew = 5 * time.Minute
+)
+
+// Signer signs the requests with the long-term signing key of the entity,
+// the auditors and the organizations implement it.
+type Signer interface {
+	SignMessage(msg []byte) ([]byte, error)
+}
+
+// RequestSigningMessage is the message an entity signs to authenticate a request,
+// the digest of the body binds the signature to the payload.
+func RequestSigningMessage(method, path, entityID string, timestamp int64, body []byte) []byte {
+	digest := sha256.Sum256(body)
+	return crypto.SigningMessage(
+		requestSigningTag, method, path, entityID, strconv.FormatInt(timestamp, 10), hex.EncodeToString(digest[:]),
+	)
+}
+
+// SignRequest sets the headers authenticating the request of the entity with the body,
+// it returns the hex encoded signature the response is bound to.
+func SignRequest(httpReq *http.Request, entityID string, body []byte, signer Signer) (string, error) {
+	timestamp := time.Now().Unix()
+	msg := RequestSigningMessage(httpReq.Method, httpReq.URL.Path, entityID, timestamp, body)
+	signature, err := signer.SignMessage(msg)
+	if err != nil {
+		return "", err
+	}
+	signatureHex := hex.EncodeToString(signature)
+	httpReq.Header.Set(HeaderEntity, entityID)
+	httpReq.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	httpReq.Header.Set(HeaderSignature, signatureHex)
+	return signatureHex, nil
+}
+
+// VerifyRequest checks the timestamp and the signature of the request with the body against the public key
+// of the entity. Replaying a request within the clock skew is possible, so the handlers must be idempotent.
+func VerifyRequest(httpReq *http.Request, body []byte, publicKey crypto.TypePublicKey) error {
+	entityID := httpReq.Header.Get(HeaderEntity)
+	timestamp, err := strconv.ParseInt(httpReq.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid timestamp: %v", err)
+	}
+	if skew := time.Since(time.Unix(timestamp, 0)); skew > MaxClockSkew || skew < -MaxClockSkew {
+		return fmt.Errorf("timestamp %d out of the allowed clock skew", timestamp)
+	}
+	signature, err := hex.DecodeString(httpReq.Header.Get(HeaderSignature))
+	if err != nil {
+		return fmt.Errorf("invalid signature: %v", err)
+	}
+	msg := RequestSigningMessage(httpReq.Method, httpReq.URL.Path, entityID, timestamp, body)
+	if ok, err := crypto.VerifySignature(publicKey, msg, signature); err != nil || !ok {
+		return fmt.Errorf("invalid signature of entity %q", entityID)
+	}
+	return nil
+}
+
+// ResponseSigningMessage is the message the committee signs to authenticate a response,
+// the signature of the request binds the response to it, the signature is empty for unauthenticated requests.
+func ResponseSigningMessage(path, requestSignature string, status int, body []byte) []byte {
+	digest := sha256.Sum256(body)
+	return crypto.SigningMessage(
+		responseSigningTag, path, requestSignature, strconv.Itoa(status), hex.EncodeToString(digest[:]),
+	)
+}
+
+// SealAdditionalData binds the sealed parameters to the entity and the epoch they are issued for.
+func SealAdditionalData(entityID string, epochID uint64) []byte {
+	return crypto.SigningMessage(sealTag, entityID, strconv.FormatUint(epochID, 10))
+}
@@ -0,0 +1,304 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/service"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/keystore"
+)
+
+const maxResponseBytes = 1 << 20
+
+// Error is a request rejected by the committee service.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("committee service: %s (status %d)", e.Message, e.StatusCode)
+}
+
+// Client talks to the committee service on behalf of an auditor or organization,
+// it signs every request and checks the signature of the committee on every response.
+type Client struct {
+	// HTTPClient sends the requests, http.DefaultClient if nil.
+	HTTPClient         *http.Client
+	baseURL            string
+	entityID           string
+	signer             service.Signer
+	signingPublicKey   crypto.TypePublicKey
+	committeePublicKey crypto.TypePublicKey
+	randStream         cipher.Stream
+}
+
+func New(
+	baseURL, entityID string, signer service.Signer, signingPublicKey, committeePublicKey crypto.TypePublicKey,
+) *Client {
+	return NewWithRandStream(baseURL, entityID, signer, signingPublicKey, committeePublicKey, crypto.RandomStream())
+}
+
+// NewWithRandStream creates a client drawing the ephemeral keys from the given stream.
+func NewWithRandStream(
+	baseURL, entityID string, signer service.Signer, signingPublicKey, committeePublicKey crypto.TypePublicKey,
+	rand cipher.Stream,
+) *Client {
+	return &Client{
+		baseURL:            baseURL,
+		entityID:           entityID,
+		signer:             signer,
+		signingPublicKey:   signingPublicKey,
+		committeePublicKey: committeePublicKey,
+		randStream:         rand,
+	}
+}
+
+// ForAuditor returns a client acting as the auditor.
+func ForAuditor(baseURL string, aud *auditor.Auditor, committeePublicKey crypto.TypePublicKey) *Client {
+	return New(baseURL, string(aud.ID), aud, aud.SigningPublicKey, committeePublicKey)
+}
+
+// ForOrganization returns a client acting as the organization.
+func ForOrganization(
+	baseURL string, org *organization.Organization, committeePublicKey crypto.TypePublicKey,
+) *Client {
+	return New(baseURL, string(org.ID), org, org.SigningPublicKey, committeePublicKey)
+}
+
+// Register registers the signing public key of the entity with the role.
+func (c *Client) Register(ctx context.Context, role service.Role) error {
+	publicKeyHex, err := crypto.PublicKeyHex(c.signingPublicKey)
+	if err != nil {
+		return err
+	}
+	req := &service.RegisterRequest{Role: role, PublicKey: publicKeyHex}
+	return c.do(ctx, http.MethodPost, service.PathRegister, req, true, nil)
+}
+
+func (c *Client) Epoch(ctx context.Context) (*service.EpochResponse, error) {
+	resp := &service.EpochResponse{}
+	if err := c.do(ctx, http.MethodGet, service.PathEpoch, nil, false, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PublicKeys returns the epoch public keys of the organizations keyed by their ID hashes.
+func (c *Client) PublicKeys(ctx context.Context) (map[string]crypto.TypePublicKey, error) {
+	resp := &service.PublicKeysResponse{}
+	if err := c.do(ctx, http.MethodGet, service.PathPublicKeys, nil, false, resp); err != nil {
+		return nil, err
+	}
+	publicKeyMap := make(map[string]crypto.TypePublicKey, len(resp.PublicKeys))
+	for idHash, publicKeyHex := range resp.PublicKeys {
+		publicKey, err := decodePoint(publicKeyHex)
+		if err != nil {
+			return nil, err
+		}
+		publicKeyMap[idHash] = publicKey
+	}
+	return publicKeyMap, nil
+}
+
+// EpochIDCommitments returns the commitments to the epoch IDs of the organizations and the auditors,
+// check the verdicts of the committee against them with committee.VerifyOrgAndAudVerdict and committee.VerifyAuditPairVerdict.
+func (c *Client) EpochIDCommitments(ctx context.Context) (map[string][]byte, error) {
+	resp := &service.PublicKeysResponse{}
+	if err := c.do(ctx, http.MethodGet, service.PathPublicKeys, nil, false, resp); err != nil {
+		return nil, err
+	}
+	commitmentMap := make(map[string][]byte, len(resp.EpochIDCommitments))
+	for idHash, commitmentHex := range resp.EpochIDCommitments {
+		commitment, err := hex.DecodeString(commitmentHex)
+		if err != nil {
+			return nil, err
+		}
+		commitmentMap[idHash] = commitment
+	}
+	return commitmentMap, nil
+}
+
+// AuditorParameters returns the epoch parameters of the auditor,
+// apply them with auditor.RestoreEpochSecrets.
+func (c *Client) AuditorParameters(ctx context.Context) (*keystore.EpochSecrets, error) {
+	return c.parameters(ctx, service.PathAuditorParameters)
+}
+
+// OrganizationEpochID returns the epoch ID of the organization in the current epoch.
+func (c *Client) OrganizationEpochID(ctx context.Context) (organization.TypeEpochID, error) {
+	secrets, err := c.parameters(ctx, service.PathOrgParameters)
+	if err != nil {
+		return nil, err
+	}
+	epochOrgID, ok := secrets.OrgEpochIDs[c.entityID]
+	if !ok {
+		return nil, errors.New("no epoch ID in the parameters")
+	}
+	return epochOrgID, nil
+}
+
+// parameters requests the parameters sealed to a fresh ephemeral key and opens them.
+func (c *Client) parameters(ctx context.Context, path string) (*keystore.EpochSecrets, error) {
+	ephemeralKey, ephemeralPublicKey, err := crypto.KeyGen(c.randStream)
+	if err != nil {
+		return nil, err
+	}
+	ephemeralPublicKeyHex, err := crypto.PublicKeyHex(ephemeralPublicKey)
+	if err != nil {
+		return nil, err
+	}
+	req := &service.ParametersRequest{EphemeralKey: ephemeralPublicKeyHex}
+	resp := &service.ParametersResponse{}
+	if err = c.do(ctx, http.MethodPost, path, req, true, resp); err != nil {
+		return nil, err
+	}
+	sealed, err := hex.DecodeString(resp.Sealed)
+	if err != nil {
+		return nil, err
+	}
+	data, err := crypto.Open(ephemeralKey, sealed, service.SealAdditionalData(c.entityID, resp.EpochID))
+	if err != nil {
+		return nil, err
+	}
+	return keystore.DeserializeEpochSecrets(data)
+}
+
+// RequestEdge declares the trading relationship of the organization with the counterparty in the current epoch.
+func (c *Client) RequestEdge(ctx context.Context, counterParty organization.TypeID) error {
+	req := &service.EdgeRequest{CounterParty: string(counterParty)}
+	return c.do(ctx, http.MethodPost, service.PathEdges, req, true, nil)
+}
+
+// VerifyOrgAndAudResult asks the committee to verify the result of the organization and its auditor.
+func (c *Client) VerifyOrgAndAudResult(
+	ctx context.Context,
+	orgID organization.TypeID,
+	audID auditor.TypeID,
+	orgChainTX *transaction.OrgOnChain,
+	audChainTX *transaction.AudOnChain,
+) (bool, error) {
+	req := &service.VerifyOrgAndAudRequest{
+		OrgID: string(orgID),
+		AudID: string(audID),
+		OrgTX: orgChainTX,
+		AudTX: audChainTX,
+	}
+	resp := &service.VerifyResponse{}
+	if err := c.do(ctx, http.MethodPost, service.PathVerifyOrgAndAud, req, true, resp); err != nil {
+		return false, err
+	}
+	return resp.Result, nil
+}
+
+// VerifyAuditPairResult asks the committee to verify the results of the auditors of the pair of organizations.
+func (c *Client) VerifyAuditPairResult(
+	ctx context.Context,
+	orgID1, orgID2 organization.TypeID,
+	audID1, audID2 auditor.TypeID,
+	audChainTX1, audChainTX2 *transaction.AudOnChain,
+) (bool, error) {
+	req := &service.VerifyAuditPairRequest{
+		OrgID1: string(orgID1),
+		OrgID2: string(orgID2),
+		AudID1: string(audID1),
+		AudID2: string(audID2),
+		AudTX1: audChainTX1,
+		AudTX2: audChainTX2,
+	}
+	resp := &service.VerifyResponse{}
+	if err := c.do(ctx, http.MethodPost, service.PathVerifyAuditPair, req, true, resp); err != nil {
+		return false, err
+	}
+	return resp.Result, nil
+}
+
+// do sends the request, signed if needed, checks the signature of the response and decodes it into resp.
+func (c *Client) do(ctx context.Context, method, path string, reqBody any, sign bool, resp any) error {
+	var body []byte
+	if reqBody != nil {
+		var err error
+		if body, err = json.Marshal(reqBody); err != nil {
+			return err
+		}
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	var requestSignature string
+	if sign {
+		if requestSignature, err = service.SignRequest(httpReq, c.entityID, body, c.signer); err != nil {
+			return err
+		}
+	}
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	httpResp, err := httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseBytes))
+	if err != nil {
+		return err
+	}
+	if err = c.verifyResponse(path, requestSignature, httpResp, respBody); err != nil {
+		return err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		errResp := &service.ErrorResponse{}
+		if err = json.Unmarshal(respBody, errResp); err != nil {
+			return &Error{StatusCode: httpResp.StatusCode, Message: string(respBody)}
+		}
+		return &Error{StatusCode: httpResp.StatusCode, Message: errResp.Error}
+	}
+	if resp == nil {
+		return nil
+	}
+	return json.Unmarshal(respBody, resp)
+}
+
+// verifyResponse rejects the responses not signed by the committee,
+// including the signed responses replayed for another request.
+func (c *Client) verifyResponse(path, requestSignature string, httpResp *http.Response, body []byte) error {
+	signature, err := hex.DecodeString(httpResp.Header.Get(service.HeaderSignature))
+	if err != nil {
+		return fmt.Errorf("invalid response signature: %v", err)
+	}
+	msg := service.ResponseSigningMessage(path, requestSignature, httpResp.StatusCode, body)
+	ok, err := crypto.VerifySignature(c.committeePublicKey, msg, signature)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("response of %s not signed by the committee (status %d)", path, httpResp.StatusCode)
+	}
+	return nil
+}
+
+func decodePoint(pointHex string) (crypto.TypePublicKey, error) {
+	pointBytes, err := hex.DecodeString(pointHex)
+	if err != nil {
+		return nil, err
+	}
+	point := crypto.KyberSuite.Point()
+	if err = point.UnmarshalBinary(pointBytes); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
@@ -0,0 +1,454 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/committee"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/clolc/service"
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/money"
+)
+
+// harness runs the committee service on a loopback listener,
+// org1 and org2 are audited by aud1 and org3 by aud2.
+type harness struct {
+	server        *service.Server
+	httpServer    *httptest.Server
+	committee     *committee.Committee
+	organizations map[organization.TypeID]*organization.Organization
+	auditors      map[auditor.TypeID]*auditor.Auditor
+}
+
+func newHarness(t *testing.T) *harness {
+	organizations := []*organization.Organization{
+		organization.New("org1", organization.NewMemoryLocalChain()),
+		organization.New("org2", organization.NewMemoryLocalChain()),
+		organization.New("org3", organization.NewMemoryLocalChain()),
+	}
+	auditors := []*auditor.Auditor{
+		auditor.New("aud1", organizations[:2]),
+		auditor.New("aud2", organizations[2:]),
+	}
+	com := committee.New("com", auditors)
+	enrollments := make(map[string]*service.Enrollment)
+	for _, aud := range auditors {
+		enrollments[string(aud.ID)] = &service.Enrollment{Role: service.RoleAuditor, PublicKey: aud.SigningPublicKey}
+	}
+	for _, org := range organizations {
+		enrollments[string(org.ID)] = &service.Enrollment{Role: service.RoleOrganization, PublicKey: org.SigningPublicKey}
+	}
+	h := &harness{
+		server:        service.NewServer(com, enrollments),
+		committee:     com,
+		organizations: make(map[organization.TypeID]*organization.Organization),
+		auditors:      make(map[auditor.TypeID]*auditor.Auditor),
+	}
+	for _, org := range organizations {
+		h.organizations[org.ID] = org
+	}
+	for _, aud := range auditors {
+		h.auditors[aud.ID] = aud
+	}
+	h.httpServer = httptest.NewServer(h.server)
+	t.Cleanup(h.httpServer.Close)
+	return h
+}
+
+func (h *harness) auditorClient(id auditor.TypeID) *Client {
+	return ForAuditor(h.httpServer.URL, h.auditors[id], h.committee.SigningPublicKey)
+}
+
+func (h *harness) orgClient(id organization.TypeID) *Client {
+	return ForOrganization(h.httpServer.URL, h.organizations[id], h.committee.SigningPublicKey)
+}
+
+// registerAll registers every entity and initializes the first epoch.
+func (h *harness) registerAll(t *testing.T, topology *committee.Topology) {
+	ctx := context.Background()
+	for id := range h.auditors {
+		if err := h.auditorClient(id).Register(ctx, service.RoleAuditor); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+	for id := range h.organizations {
+		if err := h.orgClient(id).Register(ctx, service.RoleOrganization); err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+	}
+	e, _ := epoch.New(1, 0, 100)
+	if err := h.server.InitializeEpoch(e, topology); err != nil {
+		t.Fatalf("InitializeEpoch() error = %v", err)
+	}
+}
+
+func wantStatus(err error, status int) bool {
+	var cErr *Error
+	return errors.As(err, &cErr) && cErr.StatusCode == status
+}
+
+func TestClient_Register(t *testing.T) {
+	tests := []struct {
+		name       string
+		register   func(h *harness) error
+		wantStatus int
+	}{
+		{
+			name: "test_register_auditor",
+			register: func(h *harness) error {
+				return h.auditorClient("aud1").Register(context.Background(), service.RoleAuditor)
+			},
+		},
+		{
+			name: "test_register_twice",
+			register: func(h *harness) error {
+				if err := h.orgClient("org1").Register(context.Background(), service.RoleOrganization); err != nil {
+					return err
+				}
+				return h.orgClient("org1").Register(context.Background(), service.RoleOrganization)
+			},
+		},
+		{
+			name: "test_wrong_role",
+			register: func(h *harness) error {
+				return h.orgClient("org1").Register(context.Background(), service.RoleAuditor)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "test_unmanaged_entity",
+			register: func(h *harness) error {
+				aud := auditor.New("aud9", nil)
+				c := ForAuditor(h.httpServer.URL, aud, h.committee.SigningPublicKey)
+				return c.Register(context.Background(), service.RoleAuditor)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "test_another_key",
+			register: func(h *harness) error {
+				impostor := auditor.New("aud1", nil)
+				c := ForAuditor(h.httpServer.URL, impostor, h.committee.SigningPublicKey)
+				return c.Register(context.Background(), service.RoleAuditor)
+			},
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name: "test_not_enrolled",
+			register: func(h *harness) error {
+				httpServer := httptest.NewServer(service.NewServer(h.committee, nil))
+				defer httpServer.Close()
+				c := ForAuditor(httpServer.URL, h.auditors["aud1"], h.committee.SigningPublicKey)
+				return c.Register(context.Background(), service.RoleAuditor)
+			},
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name: "test_pinned_after_start",
+			register: func(h *harness) error {
+				server := service.NewServer(h.committee, nil)
+				server.PinEntity(service.RoleAuditor, "aud1", h.auditors["aud1"].SigningPublicKey)
+				httpServer := httptest.NewServer(server)
+				defer httpServer.Close()
+				c := ForAuditor(httpServer.URL, h.auditors["aud1"], h.committee.SigningPublicKey)
+				return c.Register(context.Background(), service.RoleAuditor)
+			},
+		},
+		{
+			name: "test_not_registered",
+			register: func(h *harness) error {
+				_, err := h.auditorClient("aud1").AuditorParameters(context.Background())
+				return err
+			},
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			err := tt.register(h)
+			if tt.wantStatus == 0 && err != nil {
+				t.Errorf("Register() error = %v, want nil", err)
+			}
+			if tt.wantStatus != 0 && !wantStatus(err, tt.wantStatus) {
+				t.Errorf("Register() error = %v, want status %d", err, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestClient_AuditorParameters(t *testing.T) {
+	h := newHarness(t)
+	topology := committee.NewTopology()
+	_ = topology.AddEdge("org1", "org2")
+	_ = topology.AddEdge("org1", "org3")
+	h.registerAll(t, topology)
+	ctx := context.Background()
+	key := func(orgID1, orgID2 organization.TypeID) [2]string {
+		return organization.IDHashKey(organization.IDHashString(orgID1), organization.IDHashString(orgID2))
+	}
+	tests := []struct {
+		name          string
+		audID         auditor.TypeID
+		wantOrgIDs    []organization.TypeID
+		wantSeedPairs [][2]string
+	}{
+		{
+			name:          "test_aud1",
+			audID:         "aud1",
+			wantOrgIDs:    []organization.TypeID{"org1", "org2"},
+			wantSeedPairs: [][2]string{key("org1", "org2"), key("org1", "org3")},
+		},
+		{
+			name:          "test_aud2",
+			audID:         "aud2",
+			wantOrgIDs:    []organization.TypeID{"org3"},
+			wantSeedPairs: [][2]string{key("org1", "org3")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secrets, err := h.auditorClient(tt.audID).AuditorParameters(ctx)
+			if err != nil {
+				t.Fatalf("AuditorParameters() error = %v", err)
+			}
+			if len(secrets.SecretKeys) != len(tt.wantOrgIDs) || len(secrets.OrgEpochIDs) != len(tt.wantOrgIDs) {
+				t.Errorf("AuditorParameters() got %d secret keys and %d epoch IDs, want %d",
+					len(secrets.SecretKeys), len(secrets.OrgEpochIDs), len(tt.wantOrgIDs))
+			}
+			for _, orgID := range tt.wantOrgIDs {
+				if _, ok := secrets.SecretKeys[organization.IDHashString(orgID)]; !ok {
+					t.Errorf("AuditorParameters() missing the secret key of %s", orgID)
+				}
+			}
+			if len(secrets.TXSeeds) != len(tt.wantSeedPairs) {
+				t.Errorf("AuditorParameters() got %d seeds, want %d", len(secrets.TXSeeds), len(tt.wantSeedPairs))
+			}
+			for _, pair := range tt.wantSeedPairs {
+				if _, ok := secrets.TXSeeds[pair]; !ok {
+					t.Errorf("AuditorParameters() missing the seed of %v", pair)
+				}
+			}
+			if err = h.auditors[tt.audID].RestoreEpochSecrets(secrets); err != nil {
+				t.Errorf("RestoreEpochSecrets() error = %v", err)
+			}
+		})
+	}
+	if _, err := h.orgClient("org1").AuditorParameters(ctx); !wantStatus(err, http.StatusForbidden) {
+		t.Errorf("AuditorParameters() of an organization error = %v, want status %d", err, http.StatusForbidden)
+	}
+	// a client expecting another committee rejects the responses
+	_, otherKey := crypto.SigningKeyGen(crypto.RandomStream())
+	c := ForAuditor(h.httpServer.URL, h.auditors["aud1"], otherKey)
+	if _, err := c.AuditorParameters(ctx); err == nil {
+		t.Errorf("AuditorParameters() with another committee key error = nil, want error")
+	}
+	// the edge requested in the middle of the epoch reaches the auditors with the next fetch
+	if err := h.orgClient("org2").RequestEdge(ctx, "org3"); err != nil {
+		t.Fatalf("RequestEdge() error = %v", err)
+	}
+	secrets, err := h.auditorClient("aud2").AuditorParameters(ctx)
+	if err != nil {
+		t.Fatalf("AuditorParameters() error = %v", err)
+	}
+	if _, ok := secrets.TXSeeds[key("org2", "org3")]; !ok {
+		t.Errorf("AuditorParameters() missing the seed of the requested edge")
+	}
+	// the edges of a closed epoch are final
+	if err = h.server.AdvanceEpoch(epoch.StateClosed); err != nil {
+		t.Fatalf("AdvanceEpoch() error = %v", err)
+	}
+	if err = h.orgClient("org2").RequestEdge(ctx, "org1"); !wantStatus(err, http.StatusConflict) {
+		t.Errorf("RequestEdge() after the epoch closed error = %v, want status %d", err, http.StatusConflict)
+	}
+}
+
+func TestClient_Verify(t *testing.T) {
+	tests := []struct {
+		name           string
+		amount1        money.Amount
+		amount2        money.Amount
+		wantConsistent bool
+	}{
+		{
+			name:           "test_consistent",
+			amount1:        money.MustParse("10", "USD"),
+			amount2:        money.MustParse("10", "USD"),
+			wantConsistent: true,
+		},
+		{
+			name:           "test_amount_mismatch",
+			amount1:        money.MustParse("10", "USD"),
+			amount2:        money.MustParse("9", "USD"),
+			wantConsistent: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			h.registerAll(t, nil)
+			ctx := context.Background()
+			for audID, aud := range h.auditors {
+				secrets, err := h.auditorClient(audID).AuditorParameters(ctx)
+				if err != nil {
+					t.Fatalf("AuditorParameters() error = %v", err)
+				}
+				if err = aud.RestoreEpochSecrets(secrets); err != nil {
+					t.Fatalf("RestoreEpochSecrets() error = %v", err)
+				}
+			}
+			for orgID, org := range h.organizations {
+				epochOrgID, err := h.orgClient(orgID).OrganizationEpochID(ctx)
+				if err != nil {
+					t.Fatalf("OrganizationEpochID() error = %v", err)
+				}
+				org.SetEpochID(epochOrgID)
+			}
+			publicKeyMap, err := h.orgClient("org1").PublicKeys(ctx)
+			if err != nil {
+				t.Fatalf("PublicKeys() error = %v", err)
+			}
+			commitmentMap, err := h.orgClient("org1").EpochIDCommitments(ctx)
+			if err != nil {
+				t.Fatalf("EpochIDCommitments() error = %v", err)
+			}
+			for orgID, org := range h.organizations {
+				commitment := commitmentMap[organization.IDHashString(orgID)]
+				if !bytes.Equal(commitment, committee.EpochIDCommitment(org.EpochID)) {
+					t.Errorf("EpochIDCommitments() of %s = %x, want the commitment to its epoch ID", orgID, commitment)
+				}
+			}
+			tx1, _ := transaction.NewPairLocalPlain("org1", "org3", tt.amount1, 1)
+			_, tx2 := transaction.NewPairLocalPlain("org1", "org3", tt.amount2, 1)
+			orgTX1, audTX1 := examine(t, h.organizations["org1"], h.auditors["aud1"], "org3", tx1, publicKeyMap)
+			orgTX2, audTX2 := examine(t, h.organizations["org3"], h.auditors["aud2"], "org1", tx2, publicKeyMap)
+
+			c := h.auditorClient("aud1")
+			result1, err := c.VerifyOrgAndAudResult(ctx, "org1", "aud1", orgTX1, audTX1)
+			if err != nil || !result1 {
+				t.Errorf("VerifyOrgAndAudResult() = %v, %v, want true", result1, err)
+			}
+			result2, err := h.auditorClient("aud2").VerifyOrgAndAudResult(ctx, "org3", "aud2", orgTX2, audTX2)
+			if err != nil || !result2 {
+				t.Errorf("VerifyOrgAndAudResult() = %v, %v, want true", result2, err)
+			}
+			got, err := c.VerifyAuditPairResult(ctx, "org1", "org3", "aud1", "aud2", audTX1, audTX2)
+			if err != nil {
+				t.Fatalf("VerifyAuditPairResult() error = %v", err)
+			}
+			if got != tt.wantConsistent {
+				t.Errorf("VerifyAuditPairResult() got = %v, want %v", got, tt.wantConsistent)
+			}
+		})
+	}
+}
+
+func TestClient_VerifyForbidden(t *testing.T) {
+	tests := []struct {
+		name   string
+		verify func(h *harness) error
+	}{
+		{
+			name: "test_org_and_aud_by_organization",
+			verify: func(h *harness) error {
+				_, err := h.orgClient("org1").VerifyOrgAndAudResult(context.Background(), "org1", "aud1", nil, nil)
+				return err
+			},
+		},
+		{
+			name: "test_org_and_aud_by_other_auditor",
+			verify: func(h *harness) error {
+				_, err := h.auditorClient("aud1").VerifyOrgAndAudResult(context.Background(), "org3", "aud2", nil, nil)
+				return err
+			},
+		},
+		{
+			name: "test_org_and_aud_not_audited",
+			verify: func(h *harness) error {
+				_, err := h.auditorClient("aud1").VerifyOrgAndAudResult(context.Background(), "org3", "aud1", nil, nil)
+				return err
+			},
+		},
+		{
+			name: "test_audit_pair_by_organization",
+			verify: func(h *harness) error {
+				_, err := h.orgClient("org1").VerifyAuditPairResult(
+					context.Background(), "org1", "org3", "aud1", "aud2", nil, nil,
+				)
+				return err
+			},
+		},
+		{
+			name: "test_audit_pair_by_other_auditor",
+			verify: func(h *harness) error {
+				_, err := h.auditorClient("aud2").VerifyAuditPairResult(
+					context.Background(), "org1", "org2", "aud1", "aud1", nil, nil,
+				)
+				return err
+			},
+		},
+		{
+			name: "test_audit_pair_not_audited",
+			verify: func(h *harness) error {
+				_, err := h.auditorClient("aud1").VerifyAuditPairResult(
+					context.Background(), "org1", "org2", "aud1", "aud2", nil, nil,
+				)
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHarness(t)
+			h.registerAll(t, nil)
+			if err := tt.verify(h); !wantStatus(err, http.StatusForbidden) {
+				t.Errorf("verify error = %v, want status %d", err, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+// examine records the transaction of the organization and runs the first part of the consistency examination.
+func examine(
+	t *testing.T,
+	org *organization.Organization,
+	aud *auditor.Auditor,
+	counterPartyID organization.TypeID,
+	tx *transaction.LocalPlain,
+	publicKeyMap map[string]crypto.TypePublicKey,
+) (*transaction.OrgOnChain, *transaction.AudOnChain) {
+	txID, err := org.RecordTransaction(tx)
+	if err != nil {
+		t.Fatalf("RecordTransaction() error = %v", err)
+	}
+	localOnChainTX, err := org.LocalChain().ReadTX(txID)
+	if err != nil {
+		t.Fatalf("ReadTX() error = %v", err)
+	}
+	localTX, err := localOnChainTX.ToHidden()
+	if err != nil {
+		t.Fatalf("ToHidden() error = %v", err)
+	}
+	orgPlainTX, err := org.ComposeTXOrgChain(counterPartyID)
+	if err != nil {
+		t.Fatalf("ComposeTXOrgChain() error = %v", err)
+	}
+	orgTXRandList := org.EpochTXRandomness(counterPartyID)
+	comTXRandList := aud.GetEpochTXRandomness(org.ID, counterPartyID, len(orgTXRandList))
+	audPlainTX, err := aud.ConsistencyExaminationPartOne(
+		org.ID, counterPartyID, org.EpochID, orgPlainTX,
+		[]*transaction.LocalHidden{localTX}, orgTXRandList, comTXRandList, publicKeyMap,
+	)
+	if err != nil {
+		t.Fatalf("ConsistencyExaminationPartOne() error = %v", err)
+	}
+	return orgPlainTX.ToOnChain(), audPlainTX.ToOnChain()
+}
@@ -0,0 +1,469 @@
+package service
+
+import (
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/auti-project/auti/internal/clolc/auditor"
+	"github.com/auti-project/auti/internal/clolc/committee"
+	"github.com/auti-project/auti/internal/clolc/organization"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/keystore"
+)
+
+const maxBodyBytes = 1 << 20
+
+// entity is a registered auditor or organization.
+type entity struct {
+	role      Role
+	publicKey crypto.TypePublicKey
+}
+
+// Enrollment is the role and the signing public key of an entity distributed out of band,
+// the server only registers the entities enrolled with it.
+type Enrollment struct {
+	Role      Role
+	PublicKey crypto.TypePublicKey
+}
+
+// request is the request passed to the handlers, the role is set once authenticated.
+type request struct {
+	httpReq      *http.Request
+	entityID     string
+	signatureHex string
+	body         []byte
+	role         Role
+}
+
+// statusError is an error reported to the client with the status code.
+type statusError struct {
+	status int
+	err    error
+}
+
+func (e *statusError) Error() string {
+	return e.err.Error()
+}
+
+func errorf(status int, format string, args ...any) error {
+	return &statusError{status: status, err: fmt.Errorf(format, args...)}
+}
+
+// Server exposes a committee to the auditors and organizations over HTTP.
+// The requests are signed with the long-term signing keys of the entities and the responses with the one
+// of the committee, the epoch parameters are sealed to a key of the requester, so no secret travels in the clear
+// even without TLS. The committee is not safe for concurrent use, the server serializes the calls to it.
+// The keys of the entities are pinned when the server is created, a registration proves the possession
+// of the pinned key and the registrations of the entities not pinned are rejected.
+type Server struct {
+	mu          sync.Mutex
+	committee   *committee.Committee
+	enrollments map[string]*Enrollment
+	entityMap   map[string]*entity
+	mux         *http.ServeMux
+	randStream  cipher.Stream
+}
+
+// NewServer creates a server for the committee registering the entities of the enrollments keyed by their IDs.
+func NewServer(com *committee.Committee, enrollments map[string]*Enrollment) *Server {
+	return NewServerWithRandStream(com, enrollments, crypto.RandomStream())
+}
+
+// NewServerWithRandStream creates a server sealing the parameters with randomness from the given stream.
+func NewServerWithRandStream(com *committee.Committee, enrollments map[string]*Enrollment, rand cipher.Stream) *Server {
+	s := &Server{
+		committee:   com,
+		enrollments: make(map[string]*Enrollment, len(enrollments)),
+		entityMap:   make(map[string]*entity),
+		mux:         http.NewServeMux(),
+		randStream:  rand,
+	}
+	for id, enrollment := range enrollments {
+		s.enrollments[id] = enrollment
+	}
+	s.mux.HandleFunc(PathRegister, s.wrap(http.MethodPost, false, s.register))
+	s.mux.HandleFunc(PathEpoch, s.wrap(http.MethodGet, false, s.epoch))
+	s.mux.HandleFunc(PathPublicKeys, s.wrap(http.MethodGet, false, s.publicKeys))
+	s.mux.HandleFunc(PathAuditorParameters, s.wrap(http.MethodPost, true, s.auditorParameters))
+	s.mux.HandleFunc(PathOrgParameters, s.wrap(http.MethodPost, true, s.orgParameters))
+	s.mux.HandleFunc(PathEdges, s.wrap(http.MethodPost, true, s.addEdge))
+	s.mux.HandleFunc(PathVerifyOrgAndAud, s.wrap(http.MethodPost, true, s.verifyOrgAndAud))
+	s.mux.HandleFunc(PathVerifyAuditPair, s.wrap(http.MethodPost, true, s.verifyAuditPair))
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// InitializeEpoch opens and initializes the epoch, the topology is the full one if nil.
+// The auditors and organizations fetch their parameters afterwards.
+func (s *Server) InitializeEpoch(e *epoch.Epoch, topology *committee.Topology) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.initializeEpoch(e, topology)
+}
+
+// OpenScheduledEpoch initializes the epoch of the schedule covering the time with the full topology
+// unless it or a later epoch is already opened, and returns the current epoch.
+func (s *Server) OpenScheduledEpoch(schedule *epoch.Schedule, now int64) (*epoch.Epoch, error) {
+	epochID, err := schedule.EpochID(now)
+	if err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e := s.committee.Epoch(); e != nil && e.ID >= epochID {
+		return e, nil
+	}
+	e := schedule.Epoch(epochID)
+	if err = s.initializeEpoch(e, nil); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (s *Server) initializeEpoch(e *epoch.Epoch, topology *committee.Topology) error {
+	if err := s.committee.OpenEpoch(e); err != nil {
+		return err
+	}
+	var err error
+	if topology == nil {
+		_, err = s.committee.InitializeEpoch(nil, nil)
+	} else {
+		_, err = s.committee.InitializeEpochWithTopology(nil, nil, topology)
+	}
+	return err
+}
+
+// PinEntity enrolls the signing public key of an entity distributed out of band after the server is created,
+// replacing the enrolled key drops the registration made with the previous one.
+func (s *Server) PinEntity(role Role, id string, publicKey crypto.TypePublicKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enrollments[id] = &Enrollment{Role: role, PublicKey: publicKey}
+	if ent, ok := s.entityMap[id]; ok && (ent.role != role || !ent.publicKey.Equal(publicKey)) {
+		delete(s.entityMap, id)
+	}
+}
+
+// ExportEpochSecrets returns the archived key material of the epoch for the key store.
+func (s *Server) ExportEpochSecrets(epochID epoch.TypeID, expiresAt int64) (*keystore.EpochSecrets, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.committee.ExportEpochSecrets(epochID, expiresAt)
+}
+
+// AdvanceEpoch moves the current epoch to the next state.
+func (s *Server) AdvanceEpoch(to epoch.State) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.committee.AdvanceEpoch(to)
+}
+
+// wrap checks the method, reads the body, authenticates the request if needed,
+// and writes the result of the handler as a signed JSON response.
+func (s *Server) wrap(method string, authenticate bool, handler func(*request) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req := &request{
+			httpReq:      r,
+			entityID:     r.Header.Get(HeaderEntity),
+			signatureHex: r.Header.Get(HeaderSignature),
+		}
+		result, err := s.serve(r, method, authenticate, req, handler)
+		status := http.StatusOK
+		if err != nil {
+			status = http.StatusBadRequest
+			var sErr *statusError
+			if errors.As(err, &sErr) {
+				status = sErr.status
+			}
+			result = &ErrorResponse{Error: err.Error()}
+		}
+		body, err := json.Marshal(result)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		s.mu.Lock()
+		signature, err := s.committee.SignMessage(ResponseSigningMessage(r.URL.Path, req.signatureHex, status, body))
+		s.mu.Unlock()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(HeaderSignature, hex.EncodeToString(signature))
+		w.WriteHeader(status)
+		_, _ = w.Write(body)
+	}
+}
+
+func (s *Server) serve(
+	r *http.Request, method string, authenticate bool, req *request, handler func(*request) (any, error),
+) (any, error) {
+	if r.Method != method {
+		return nil, errorf(http.StatusMethodNotAllowed, "method %s not allowed", r.Method)
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBodyBytes))
+	if err != nil {
+		return nil, errorf(http.StatusRequestEntityTooLarge, "read body: %v", err)
+	}
+	req.body = body
+	if authenticate {
+		if err = s.authenticate(req); err != nil {
+			return nil, err
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return handler(req)
+}
+
+// authenticate checks the signature of the request against the registered key of the entity.
+func (s *Server) authenticate(req *request) error {
+	s.mu.Lock()
+	ent, ok := s.entityMap[req.entityID]
+	s.mu.Unlock()
+	if !ok {
+		return errorf(http.StatusUnauthorized, "entity %q not registered", req.entityID)
+	}
+	if err := verifyRequest(req, ent.publicKey); err != nil {
+		return err
+	}
+	req.role = ent.role
+	return nil
+}
+
+// verifyRequest reports the requests failing VerifyRequest as unauthorized.
+func verifyRequest(req *request, publicKey crypto.TypePublicKey) error {
+	if err := VerifyRequest(req.httpReq, req.body, publicKey); err != nil {
+		return errorf(http.StatusUnauthorized, "%v", err)
+	}
+	return nil
+}
+
+// register binds the signing public key to a managed entity, the request is signed with the key itself.
+// The key and the role must be the enrolled ones, so no one can claim the ID of an entity with another key.
+func (s *Server) register(req *request) (any, error) {
+	var regReq RegisterRequest
+	if err := json.Unmarshal(req.body, &regReq); err != nil {
+		return nil, err
+	}
+	switch regReq.Role {
+	case RoleAuditor:
+		if !s.committee.ManagesAuditor(auditor.TypeID(req.entityID)) {
+			return nil, errorf(http.StatusForbidden, "auditor %q not managed by the committee", req.entityID)
+		}
+	case RoleOrganization:
+		if !s.committee.ManagesOrganization(organization.TypeID(req.entityID)) {
+			return nil, errorf(http.StatusForbidden, "organization %q not managed by the committee", req.entityID)
+		}
+	default:
+		return nil, fmt.Errorf("invalid role %q", regReq.Role)
+	}
+	publicKey, err := decodePoint(regReq.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key: %v", err)
+	}
+	if err = verifyRequest(req, publicKey); err != nil {
+		return nil, err
+	}
+	enrollment, ok := s.enrollments[req.entityID]
+	if !ok {
+		return nil, errorf(http.StatusForbidden, "entity %q not enrolled", req.entityID)
+	}
+	if enrollment.Role != regReq.Role || !enrollment.PublicKey.Equal(publicKey) {
+		return nil, errorf(http.StatusConflict, "entity %q enrolled with another key", req.entityID)
+	}
+	s.entityMap[req.entityID] = &entity{role: regReq.Role, publicKey: publicKey}
+	return struct{}{}, nil
+}
+
+func (s *Server) epoch(*request) (any, error) {
+	e := s.committee.Epoch()
+	if e == nil {
+		return nil, errorf(http.StatusNotFound, "no epoch opened")
+	}
+	return &EpochResponse{
+		ID:        uint64(e.ID),
+		StartTime: e.StartTime,
+		EndTime:   e.EndTime,
+		State:     e.State().String(),
+	}, nil
+}
+
+func (s *Server) publicKeys(*request) (any, error) {
+	resp := &PublicKeysResponse{
+		PublicKeys:         make(map[string]string),
+		EpochIDCommitments: make(map[string]string),
+	}
+	for idHash, publicKey := range s.committee.PublishPublicKeys() {
+		if publicKey == nil {
+			continue
+		}
+		publicKeyHex, err := crypto.PublicKeyHex(publicKey)
+		if err != nil {
+			return nil, err
+		}
+		resp.PublicKeys[idHash] = publicKeyHex
+	}
+	for idHash, commitment := range s.committee.PublishEpochIDCommitments() {
+		resp.EpochIDCommitments[idHash] = hex.EncodeToString(commitment)
+	}
+	return resp, nil
+}
+
+// auditorParameters returns the parameters of the auditor only, i.e., the material of its audited organizations.
+func (s *Server) auditorParameters(req *request) (any, error) {
+	if req.role != RoleAuditor {
+		return nil, errorf(http.StatusForbidden, "entity %q is not an auditor", req.entityID)
+	}
+	secrets, err := s.committee.EpochAuditorSecrets(auditor.TypeID(req.entityID))
+	if err != nil {
+		return nil, errorf(http.StatusConflict, "%v", err)
+	}
+	return s.sealParameters(req, secrets)
+}
+
+func (s *Server) orgParameters(req *request) (any, error) {
+	if req.role != RoleOrganization {
+		return nil, errorf(http.StatusForbidden, "entity %q is not an organization", req.entityID)
+	}
+	e := s.committee.Epoch()
+	if e == nil {
+		return nil, errorf(http.StatusConflict, "no epoch opened")
+	}
+	epochOrgID, err := s.committee.EpochOrgID(organization.TypeID(req.entityID))
+	if err != nil {
+		return nil, errorf(http.StatusConflict, "%v", err)
+	}
+	secrets := keystore.NewEpochSecrets(e, e.EndTime)
+	secrets.OrgEpochIDs[req.entityID] = epochOrgID
+	return s.sealParameters(req, secrets)
+}
+
+func (s *Server) sealParameters(req *request, secrets *keystore.EpochSecrets) (any, error) {
+	var paramReq ParametersRequest
+	if err := json.Unmarshal(req.body, &paramReq); err != nil {
+		return nil, err
+	}
+	ephemeralKey, err := decodePoint(paramReq.EphemeralKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ephemeral key: %v", err)
+	}
+	data, err := secrets.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := crypto.Seal(
+		ephemeralKey, data, SealAdditionalData(req.entityID, uint64(secrets.EpochID)), s.randStream,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &ParametersResponse{EpochID: uint64(secrets.EpochID), Sealed: hex.EncodeToString(sealed)}, nil
+}
+
+// addEdge declares the trading relationship of the organization with the counterparty,
+// the auditors of the two fetch the seed of the edge with their parameters.
+func (s *Server) addEdge(req *request) (any, error) {
+	if req.role != RoleOrganization {
+		return nil, errorf(http.StatusForbidden, "entity %q is not an organization", req.entityID)
+	}
+	var edgeReq EdgeRequest
+	if err := json.Unmarshal(req.body, &edgeReq); err != nil {
+		return nil, err
+	}
+	if e := s.committee.Epoch(); e != nil && e.State() != epoch.StateRecording {
+		return nil, errorf(http.StatusConflict, "epoch %d is %s", e.ID, e.State())
+	}
+	err := s.committee.AddEpochEdge(
+		organization.TypeID(req.entityID), organization.TypeID(edgeReq.CounterParty), nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return struct{}{}, nil
+}
+
+// verifyOrgAndAud is only open to the auditor of the result, for an organization it audits.
+func (s *Server) verifyOrgAndAud(req *request) (any, error) {
+	var verifyReq VerifyOrgAndAudRequest
+	if err := json.Unmarshal(req.body, &verifyReq); err != nil {
+		return nil, err
+	}
+	if req.role != RoleAuditor || req.entityID != verifyReq.AudID {
+		return nil, errorf(http.StatusForbidden, "entity %q is not the auditor %q", req.entityID, verifyReq.AudID)
+	}
+	if err := s.checkAudits(verifyReq.AudID, verifyReq.OrgID); err != nil {
+		return nil, err
+	}
+	if verifyReq.OrgTX == nil || verifyReq.AudTX == nil {
+		return nil, errors.New("missing transaction")
+	}
+	result, err := s.committee.VerifyOrgAndAudResult(
+		organization.TypeID(verifyReq.OrgID), auditor.TypeID(verifyReq.AudID), verifyReq.OrgTX, verifyReq.AudTX,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyResponse{Result: result}, nil
+}
+
+// verifyAuditPair is only open to the auditors of the two results, each for an organization it audits.
+func (s *Server) verifyAuditPair(req *request) (any, error) {
+	var verifyReq VerifyAuditPairRequest
+	if err := json.Unmarshal(req.body, &verifyReq); err != nil {
+		return nil, err
+	}
+	if req.role != RoleAuditor || (req.entityID != verifyReq.AudID1 && req.entityID != verifyReq.AudID2) {
+		return nil, errorf(
+			http.StatusForbidden, "entity %q is not the auditor %q or %q", req.entityID, verifyReq.AudID1, verifyReq.AudID2,
+		)
+	}
+	if err := s.checkAudits(verifyReq.AudID1, verifyReq.OrgID1); err != nil {
+		return nil, err
+	}
+	if err := s.checkAudits(verifyReq.AudID2, verifyReq.OrgID2); err != nil {
+		return nil, err
+	}
+	if verifyReq.AudTX1 == nil || verifyReq.AudTX2 == nil {
+		return nil, errors.New("missing transaction")
+	}
+	result, err := s.committee.VerifyAuditPairResult(
+		organization.TypeID(verifyReq.OrgID1), organization.TypeID(verifyReq.OrgID2),
+		auditor.TypeID(verifyReq.AudID1), auditor.TypeID(verifyReq.AudID2),
+		verifyReq.AudTX1, verifyReq.AudTX2,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &VerifyResponse{Result: result}, nil
+}
+
+func (s *Server) checkAudits(audID, orgID string) error {
+	if !s.committee.Audits(auditor.TypeID(audID), organization.TypeID(orgID)) {
+		return errorf(http.StatusForbidden, "organization %q not audited by %q", orgID, audID)
+	}
+	return nil
+}
+
+func decodePoint(pointHex string) (crypto.TypePublicKey, error) {
+	pointBytes, err := hex.DecodeString(pointHex)
+	if err != nil {
+		return nil, err
+	}
+	point := crypto.KyberSuite.Point()
+	if err = point.UnmarshalBinary(pointBytes); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
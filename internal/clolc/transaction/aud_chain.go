@@ -1,8 +1,13 @@
 package transaction
 
 import (
+	"crypto/cipher"
 	"encoding/hex"
-	"encoding/json"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/codec"
+	"github.com/auti-project/auti/internal/crypto"
 )
 
 type AudPlain struct {
@@ -23,6 +28,45 @@ func NewAudPlain(id, cipherRes, cipherB, cipherC, cipherD []byte) *AudPlain {
 	}
 }
 
+// MarshalBinary returns the canonical binary encoding of the transaction.
+func (a *AudPlain) MarshalBinary() ([]byte, error) {
+	return codec.NewEncoder(codec.TypeCLOLCAudPlain).
+		WriteBytes(a.ID).
+		WriteBytes(a.CipherRes).
+		WriteBytes(a.CipherB).
+		WriteBytes(a.CipherC).
+		WriteBytes(a.CipherD).
+		Bytes()
+}
+
+func (a *AudPlain) UnmarshalBinary(data []byte) error {
+	dec, err := codec.NewDecoder(data, codec.TypeCLOLCAudPlain)
+	if err != nil {
+		return err
+	}
+	decoded := AudPlain{
+		ID:        dec.ReadBytes(),
+		CipherRes: dec.ReadBytes(),
+		CipherB:   dec.ReadBytes(),
+		CipherC:   dec.ReadBytes(),
+		CipherD:   dec.ReadBytes(),
+	}
+	if err = dec.Finish(); err != nil {
+		return err
+	}
+	*a = decoded
+	return nil
+}
+
+// ContentID is the hash of the canonical binary encoding, it does not depend on the JSON form.
+func (a *AudPlain) ContentID() (string, error) {
+	encoding, err := a.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return codec.ContentID(encoding), nil
+}
+
 func (a *AudPlain) ToOnChain() *AudOnChain {
 	return &AudOnChain{
 		ID:        hex.EncodeToString(a.ID),
@@ -39,6 +83,8 @@ type AudOnChain struct {
 	CipherB   string `json:"cipher_b"`
 	CipherC   string `json:"cipher_c"`
 	CipherD   string `json:"cipher_d"`
+	Signer    string `json:"signer,omitempty"`
+	Signature string `json:"signature,omitempty"`
 }
 
 func NewAudOnChain(id, cipherRes, cipherB, cipherC, cipherD string) *AudOnChain {
@@ -75,10 +121,66 @@ func (a *AudOnChain) ToPlain() (*AudPlain, error) {
 	return NewAudPlain(id, cipherRes, cipherB, cipherC, cipherD), nil
 }
 
+// MarshalBinary returns the canonical binary encoding of the transaction with the signature,
+// the JSON form is only for reading.
+func (a *AudOnChain) MarshalBinary() ([]byte, error) {
+	return codec.NewEncoder(codec.TypeCLOLCAudOnChain).
+		WriteBytes([]byte(a.ID)).
+		WriteBytes([]byte(a.CipherRes)).
+		WriteBytes([]byte(a.CipherB)).
+		WriteBytes([]byte(a.CipherC)).
+		WriteBytes([]byte(a.CipherD)).
+		WriteBytes([]byte(a.Signer)).
+		WriteBytes([]byte(a.Signature)).
+		Bytes()
+}
+
+func (a *AudOnChain) UnmarshalBinary(data []byte) error {
+	dec, err := codec.NewDecoder(data, codec.TypeCLOLCAudOnChain)
+	if err != nil {
+		return err
+	}
+	decoded := AudOnChain{
+		ID:        string(dec.ReadBytes()),
+		CipherRes: string(dec.ReadBytes()),
+		CipherB:   string(dec.ReadBytes()),
+		CipherC:   string(dec.ReadBytes()),
+		CipherD:   string(dec.ReadBytes()),
+		Signer:    string(dec.ReadBytes()),
+		Signature: string(dec.ReadBytes()),
+	}
+	if err = dec.Finish(); err != nil {
+		return err
+	}
+	*a = decoded
+	return nil
+}
+
+// KeyVal returns the ID of the transaction and the canonical binary encoding submitted to the chaincode.
 func (a *AudOnChain) KeyVal() (string, []byte, error) {
-	txJSON, err := json.Marshal(a)
+	val, err := a.MarshalBinary()
 	if err != nil {
 		return "", nil, err
 	}
-	return a.ID, txJSON, nil
+	return a.ID, val, nil
+}
+
+// SigningMessage returns the canonical encoding of the transaction covered by the signature.
+func (a *AudOnChain) SigningMessage() []byte {
+	return crypto.SigningMessage(audSigningTag, a.ID, a.CipherRes, a.CipherB, a.CipherC, a.CipherD, a.Signer)
+}
+
+// Sign sets the signer to the public key of the signing key and signs the transaction.
+func (a *AudOnChain) Sign(signingKey kyber.Scalar, rand cipher.Stream) error {
+	var err error
+	if a.Signer, err = crypto.SignerHex(signingKey); err != nil {
+		return err
+	}
+	a.Signature, err = crypto.SignHex(signingKey, a.SigningMessage(), rand)
+	return err
+}
+
+// VerifySignature returns false if the transaction is unsigned, forged, or not signed by the public key.
+func (a *AudOnChain) VerifySignature(publicKey kyber.Point) (bool, error) {
+	return verifySignedBy(publicKey, a.Signer, a.Signature, a.SigningMessage())
 }
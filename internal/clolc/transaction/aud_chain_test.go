@@ -0,0 +1,50 @@
+package transaction
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAudPlain_MarshalBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   *AudPlain
+	}{
+		{
+			name: "test_full",
+			tx: NewAudPlain(
+				[]byte("id"), []byte("cipher_res"), []byte("cipher_b"), []byte("cipher_c"), []byte("cipher_d"),
+			),
+		},
+		{
+			name: "test_empty_fields",
+			tx:   NewAudPlain([]byte("id"), nil, nil, nil, []byte("cipher_d")),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, err := tt.tx.MarshalBinary()
+			if err != nil {
+				t.Errorf("MarshalBinary() error = %v", err)
+				return
+			}
+			decoded := new(AudPlain)
+			if err = decoded.UnmarshalBinary(encoding); err != nil {
+				t.Errorf("UnmarshalBinary() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(decoded, tt.tx) {
+				t.Errorf("UnmarshalBinary() = %v, want %v", decoded, tt.tx)
+			}
+			// an encoding of another type is rejected
+			orgEncoding, err := NewOrgPlain(tt.tx.ID).MarshalBinary()
+			if err != nil {
+				t.Errorf("MarshalBinary() error = %v", err)
+				return
+			}
+			if err = decoded.UnmarshalBinary(orgEncoding); err == nil {
+				t.Errorf("UnmarshalBinary() error = %v, wantErr %v", err, true)
+			}
+		})
+	}
+}
@@ -1,56 +1,49 @@
 package transaction
 
 import (
-	"crypto/sha256"
+	"crypto/cipher"
 	"encoding/hex"
-	"encoding/json"
 	"strconv"
 
 	"go.dedis.ch/kyber/v3"
 
+	"github.com/auti-project/auti/internal/codec"
+	"github.com/auti-project/auti/internal/constants"
 	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/hashing"
+	"github.com/auti-project/auti/internal/money"
 )
 
-const amountAmplifier = 100
-
+// LocalPlain is a local transaction of an organization with the counterparty,
+// the amount is in minor units of the currency, negative for the receiving side.
 type LocalPlain struct {
 	CounterParty string
 	Amount       int64
+	Currency     string
 	Timestamp    int64
 }
 
-func NewLocalPlain(counterParty string, amount float64, timestamp int64) *LocalPlain {
-	amountInt := int64(amount * amountAmplifier)
+func NewLocalPlain(counterParty string, amount money.Amount, timestamp int64) *LocalPlain {
 	return &LocalPlain{
 		CounterParty: counterParty,
-		Amount:       amountInt,
+		Amount:       amount.MinorUnits(),
+		Currency:     amount.Currency().Code,
 		Timestamp:    timestamp,
 	}
 }
 
 func NewPairLocalPlain(
 	fromID, toID string,
-	amount float64,
+	amount money.Amount,
 	timestamp int64,
 ) (*LocalPlain, *LocalPlain) {
-	amountInt := int64(amount * amountAmplifier)
-	return &LocalPlain{
-			CounterParty: toID,
-			Amount:       amountInt,
-			Timestamp:    timestamp,
-		}, &LocalPlain{
-			CounterParty: fromID,
-			Amount:       -amountInt,
-			Timestamp:    timestamp,
-		}
+	return NewLocalPlain(toID, amount, timestamp), NewLocalPlain(fromID, amount.Neg(), timestamp)
 }
 
-func (l *LocalPlain) Hide() (hiddenTX *LocalHidden,
+func (l *LocalPlain) Hide(rand cipher.Stream) (hiddenTX *LocalHidden,
 	commitment kyber.Point, randScalar kyber.Scalar, err error) {
-	sha256Func := sha256.New()
-	sha256Func.Write([]byte(l.CounterParty))
-	counterPartyHash := sha256Func.Sum(nil)
-	commitment, randScalar, err = crypto.PedersenCommit(l.Amount)
+	counterPartyHash := hashing.Sum(hashing.TagOrgID, []byte(l.CounterParty))
+	commitment, randScalar, err = crypto.PedersenCommit(crypto.DefaultBlindingGenerator, l.Currency, l.Amount, rand)
 	if err != nil {
 		return nil, nil, nil, err
 	}
@@ -63,13 +56,42 @@ func (l *LocalPlain) Hide() (hiddenTX *LocalHidden,
 		commitmentBytes,
 		l.Timestamp,
 	)
+	hiddenTX.Asset = l.Currency
+	hiddenTX.BlindingGenerator = crypto.DefaultBlindingGenerator
+	return
+}
+
+// HideWithRangeProof hides the transaction and attaches a range proof showing that
+// the commitment opens to a signed amount of constants.RangeProofBitLen bits.
+func (l *LocalPlain) HideWithRangeProof(rand cipher.Stream) (hiddenTX *LocalHidden,
+	commitment kyber.Point, randScalar kyber.Scalar, err error) {
+	hiddenTX, commitment, randScalar, err = l.Hide(rand)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rangeProof, err := crypto.NewSignedRangeProof(
+		hiddenTX.BlindingGenerator, l.Currency, []int64{l.Amount}, []kyber.Scalar{randScalar},
+		constants.RangeProofBitLen, rand,
+	)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	hiddenTX.RangeProof, err = rangeProof.Serialize()
+	if err != nil {
+		return nil, nil, nil, err
+	}
 	return
 }
 
+// LocalHidden is a local transaction with the amount hidden in a commitment of the asset,
+// the asset code and the blinding generator are public so that the commitment and its range proof can be verified.
 type LocalHidden struct {
-	CounterParty []byte
-	Commitment   []byte
-	Timestamp    int64
+	CounterParty      []byte
+	Commitment        []byte
+	Asset             string
+	BlindingGenerator crypto.BlindingGeneratorVersion
+	Timestamp         int64
+	RangeProof        []byte
 }
 
 func NewLocalHidden(counterParty, commitment []byte, timestamp int64) *LocalHidden {
@@ -80,19 +102,92 @@ func NewLocalHidden(counterParty, commitment []byte, timestamp int64) *LocalHidd
 	}
 }
 
+// MarshalBinary returns the canonical binary encoding of the transaction.
+func (h *LocalHidden) MarshalBinary() ([]byte, error) {
+	return codec.NewEncoder(codec.TypeCLOLCLocalHidden).
+		WriteBytes(h.CounterParty).
+		WriteBytes(h.Commitment).
+		WriteBytes([]byte(h.Asset)).
+		WriteInt64(int64(h.BlindingGenerator)).
+		WriteInt64(h.Timestamp).
+		WriteBytes(h.RangeProof).
+		Bytes()
+}
+
+func (h *LocalHidden) UnmarshalBinary(data []byte) error {
+	dec, err := codec.NewDecoder(data, codec.TypeCLOLCLocalHidden)
+	if err != nil {
+		return err
+	}
+	decoded := LocalHidden{
+		CounterParty:      dec.ReadBytes(),
+		Commitment:        dec.ReadBytes(),
+		Asset:             string(dec.ReadBytes()),
+		BlindingGenerator: crypto.BlindingGeneratorVersion(dec.ReadInt64()),
+		Timestamp:         dec.ReadInt64(),
+		RangeProof:        dec.ReadBytes(),
+	}
+	if err = dec.Finish(); err != nil {
+		return err
+	}
+	*h = decoded
+	return nil
+}
+
+// ContentID is the hash of the canonical binary encoding, it does not depend on the JSON form.
+func (h *LocalHidden) ContentID() (string, error) {
+	encoding, err := h.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return codec.ContentID(encoding), nil
+}
+
 func (h *LocalHidden) ToOnChain() *LocalOnChain {
 	timestampStr := strconv.FormatInt(h.Timestamp, 10)
-	return NewLocalOnChain(
+	onChainTX := NewLocalOnChain(
 		hex.EncodeToString(h.CounterParty),
 		hex.EncodeToString(h.Commitment),
 		timestampStr,
 	)
+	onChainTX.Asset = h.Asset
+	if h.BlindingGenerator != crypto.BlindingGeneratorLegacy {
+		onChainTX.BlindingGenerator = strconv.Itoa(int(h.BlindingGenerator))
+	}
+	onChainTX.RangeProof = hex.EncodeToString(h.RangeProof)
+	return onChainTX
+}
+
+// VerifyRangeProof checks the attached range proof against the commitment,
+// a transaction without a range proof does not verify.
+func (h *LocalHidden) VerifyRangeProof() (bool, error) {
+	if len(h.RangeProof) == 0 {
+		return false, nil
+	}
+	commitment := crypto.KyberSuite.Point()
+	if err := commitment.UnmarshalBinary(h.Commitment); err != nil {
+		return false, err
+	}
+	rangeProof, err := crypto.DeserializeRangeProof(h.RangeProof)
+	if err != nil {
+		return false, err
+	}
+	if rangeProof.BitLen != constants.RangeProofBitLen {
+		return false, nil
+	}
+	return crypto.VerifySignedRangeProof(h.BlindingGenerator, h.Asset, []kyber.Point{commitment}, rangeProof)
 }
 
 type LocalOnChain struct {
 	CounterParty string `json:"counter_party"`
 	Commitment   string `json:"commitment"`
-	Timestamp    string `json:"timestamp"`
+	Asset        string `json:"asset,omitempty"`
+	// BlindingGenerator is the decimal crypto.BlindingGeneratorVersion, empty for crypto.BlindingGeneratorLegacy
+	BlindingGenerator string `json:"blinding_generator,omitempty"`
+	Timestamp         string `json:"timestamp"`
+	RangeProof        string `json:"range_proof,omitempty"`
+	Signer            string `json:"signer,omitempty"`
+	Signature         string `json:"signature,omitempty"`
 }
 
 func NewLocalOnChain(counterParty, commitment, timestamp string) *LocalOnChain {
@@ -103,14 +198,88 @@ func NewLocalOnChain(counterParty, commitment, timestamp string) *LocalOnChain {
 	}
 }
 
+// MarshalBinary returns the canonical binary encoding of the transaction with the signature,
+// the JSON form is only for reading.
+func (l *LocalOnChain) MarshalBinary() ([]byte, error) {
+	return codec.NewEncoder(codec.TypeCLOLCLocalOnChain).
+		WriteBytes([]byte(l.CounterParty)).
+		WriteBytes([]byte(l.Commitment)).
+		WriteBytes([]byte(l.Asset)).
+		WriteBytes([]byte(l.BlindingGenerator)).
+		WriteBytes([]byte(l.Timestamp)).
+		WriteBytes([]byte(l.RangeProof)).
+		WriteBytes([]byte(l.Signer)).
+		WriteBytes([]byte(l.Signature)).
+		Bytes()
+}
+
+func (l *LocalOnChain) UnmarshalBinary(data []byte) error {
+	dec, err := codec.NewDecoder(data, codec.TypeCLOLCLocalOnChain)
+	if err != nil {
+		return err
+	}
+	decoded := LocalOnChain{
+		CounterParty:      string(dec.ReadBytes()),
+		Commitment:        string(dec.ReadBytes()),
+		Asset:             string(dec.ReadBytes()),
+		BlindingGenerator: string(dec.ReadBytes()),
+		Timestamp:         string(dec.ReadBytes()),
+		RangeProof:        string(dec.ReadBytes()),
+		Signer:            string(dec.ReadBytes()),
+		Signature:         string(dec.ReadBytes()),
+	}
+	if err = dec.Finish(); err != nil {
+		return err
+	}
+	*l = decoded
+	return nil
+}
+
+// ContentID is the hash of the canonical binary encoding of the transaction without the signature,
+// it is the key of the transaction on the ledger and does not change if the transaction is signed again.
+func (l *LocalOnChain) ContentID() (string, error) {
+	unsignedTX := *l
+	unsignedTX.Signature = ""
+	encoding, err := unsignedTX.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return codec.ContentID(encoding), nil
+}
+
+// KeyVal returns the content ID and the canonical binary encoding submitted to the chaincode.
 func (l *LocalOnChain) KeyVal() (string, []byte, error) {
-	txJSON, err := json.Marshal(l)
+	key, err := l.ContentID()
 	if err != nil {
 		return "", nil, err
 	}
-	sha256Func := sha256.New()
-	sha256Func.Write(txJSON)
-	return hex.EncodeToString(sha256Func.Sum(nil)), txJSON, nil
+	val, err := l.MarshalBinary()
+	if err != nil {
+		return "", nil, err
+	}
+	return key, val, nil
+}
+
+// SigningMessage returns the canonical encoding of the transaction covered by the signature.
+func (l *LocalOnChain) SigningMessage() []byte {
+	return crypto.SigningMessage(
+		localSigningTag, l.CounterParty, l.Commitment, l.Asset, l.BlindingGenerator, l.Timestamp, l.RangeProof, l.Signer,
+	)
+}
+
+// Sign sets the signer to the public key of the signing key and signs the transaction.
+func (l *LocalOnChain) Sign(signingKey kyber.Scalar, rand cipher.Stream) error {
+	var err error
+	if l.Signer, err = crypto.SignerHex(signingKey); err != nil {
+		return err
+	}
+	l.Signature, err = crypto.SignHex(signingKey, l.SigningMessage(), rand)
+	return err
+}
+
+// VerifySignature returns false if the transaction is unsigned, forged, or not signed by the public key.
+func (l *LocalOnChain) VerifySignature(publicKey kyber.Point) (bool, error) {
+	return verifySignedBy(publicKey, l.Signer, l.Signature, l.SigningMessage())
 }
 
 func (l *LocalOnChain) ToHidden() (*LocalHidden, error) {
@@ -126,5 +295,21 @@ func (l *LocalOnChain) ToHidden() (*LocalHidden, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewLocalHidden(counterParty, commitment, timestamp), nil
+	rangeProof, err := hex.DecodeString(l.RangeProof)
+	if err != nil {
+		return nil, err
+	}
+	hiddenTX := NewLocalHidden(counterParty, commitment, timestamp)
+	hiddenTX.Asset = l.Asset
+	if l.BlindingGenerator != "" {
+		version, err := strconv.ParseUint(l.BlindingGenerator, 10, 8)
+		if err != nil {
+			return nil, err
+		}
+		hiddenTX.BlindingGenerator = crypto.BlindingGeneratorVersion(version)
+	}
+	if len(rangeProof) > 0 {
+		hiddenTX.RangeProof = rangeProof
+	}
+	return hiddenTX, nil
 }
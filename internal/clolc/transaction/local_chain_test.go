@@ -1,9 +1,14 @@
 package transaction
 
 import (
+	"reflect"
 	"testing"
 
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/constants"
 	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/money"
 )
 
 func TestLocalPlain_Hide(t *testing.T) {
@@ -57,12 +62,12 @@ func TestLocalPlain_Hide(t *testing.T) {
 				Amount:       -tt.fields.Amount,
 				Timestamp:    tt.fields.Timestamp,
 			}
-			_, com1, randScalar1, err := c1.Hide()
+			_, com1, randScalar1, err := c1.Hide(crypto.RandomStream())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Hide() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			_, com2, randScalar2, err := c2.Hide()
+			_, com2, randScalar2, err := c2.Hide(crypto.RandomStream())
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Hide() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -79,3 +84,279 @@ func TestLocalPlain_Hide(t *testing.T) {
 		})
 	}
 }
+
+func TestLocalPlain_HideWithRangeProof(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  int64
+		wantErr bool
+	}{
+		{
+			name:    "test_positive",
+			amount:  123123123123,
+			wantErr: false,
+		},
+		{
+			name:    "test_negative",
+			amount:  -123123123123,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &LocalPlain{
+				CounterParty: tt.name,
+				Amount:       tt.amount,
+				Timestamp:    1,
+			}
+			hiddenTX, _, _, err := l.HideWithRangeProof(crypto.RandomStream())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("HideWithRangeProof() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			hiddenTX, err = hiddenTX.ToOnChain().ToHidden()
+			if err != nil {
+				t.Errorf("ToHidden() error = %v", err)
+				return
+			}
+			ok, err := hiddenTX.VerifyRangeProof()
+			if err != nil {
+				t.Errorf("VerifyRangeProof() error = %v", err)
+				return
+			}
+			if !ok {
+				t.Errorf("VerifyRangeProof() = %v, want %v", ok, true)
+			}
+			// a transaction without a range proof is rejected
+			hiddenTX.RangeProof = nil
+			if ok, _ = hiddenTX.VerifyRangeProof(); ok {
+				t.Errorf("VerifyRangeProof() = %v, want %v", ok, false)
+			}
+		})
+	}
+}
+
+func TestLocalHidden_BlindingGenerator(t *testing.T) {
+	// the entries committed before the hashed generator keep verifying under the legacy one
+	tests := []struct {
+		name          string
+		generator     crypto.BlindingGeneratorVersion
+		wantOnChainBG string
+	}{
+		{
+			name:          "test_legacy",
+			generator:     crypto.BlindingGeneratorLegacy,
+			wantOnChainBG: "",
+		},
+		{
+			name:          "test_hashed",
+			generator:     crypto.BlindingGeneratorHashed,
+			wantOnChainBG: "1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plainTX := NewLocalPlain(tt.name, money.MustParse("-2.5", "USD"), 1)
+			commitment, randScalar, err := crypto.PedersenCommit(
+				tt.generator, plainTX.Currency, plainTX.Amount, crypto.RandomStream(),
+			)
+			if err != nil {
+				t.Fatalf("PedersenCommit() error = %v", err)
+			}
+			rangeProof, err := crypto.NewSignedRangeProof(
+				tt.generator, plainTX.Currency, []int64{plainTX.Amount}, []kyber.Scalar{randScalar},
+				constants.RangeProofBitLen, crypto.RandomStream(),
+			)
+			if err != nil {
+				t.Fatalf("NewSignedRangeProof() error = %v", err)
+			}
+			commitmentBytes, err := commitment.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			hiddenTX := NewLocalHidden([]byte(tt.name), commitmentBytes, plainTX.Timestamp)
+			hiddenTX.Asset = plainTX.Currency
+			hiddenTX.BlindingGenerator = tt.generator
+			if hiddenTX.RangeProof, err = rangeProof.Serialize(); err != nil {
+				t.Fatal(err)
+			}
+			onChainTX := hiddenTX.ToOnChain()
+			if onChainTX.BlindingGenerator != tt.wantOnChainBG {
+				t.Errorf("ToOnChain() BlindingGenerator = %q, want %q", onChainTX.BlindingGenerator, tt.wantOnChainBG)
+			}
+			decoded, err := onChainTX.ToHidden()
+			if err != nil {
+				t.Fatalf("ToHidden() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, hiddenTX) {
+				t.Errorf("ToHidden() = %v, want %v", decoded, hiddenTX)
+			}
+			ok, err := decoded.VerifyRangeProof()
+			if err != nil || !ok {
+				t.Errorf("VerifyRangeProof() = %v, %v, want %v", ok, err, true)
+			}
+			// the proof does not verify under the other generator
+			decoded.BlindingGenerator = crypto.BlindingGeneratorHashed - tt.generator
+			if ok, _ = decoded.VerifyRangeProof(); ok {
+				t.Errorf("VerifyRangeProof() = %v, want %v", ok, false)
+			}
+		})
+	}
+}
+
+func TestLocalOnChain_Sign(t *testing.T) {
+	signingKey, publicKey := crypto.SigningKeyGen(crypto.RandomStream())
+	_, otherPublicKey := crypto.SigningKeyGen(crypto.RandomStream())
+	tests := []struct {
+		name      string
+		publicKey crypto.TypePublicKey
+		tamper    func(tx *LocalOnChain)
+		want      bool
+	}{
+		{
+			name:      "test_valid",
+			publicKey: publicKey,
+			tamper:    func(tx *LocalOnChain) {},
+			want:      true,
+		},
+		{
+			name:      "test_other_signer",
+			publicKey: otherPublicKey,
+			tamper:    func(tx *LocalOnChain) {},
+			want:      false,
+		},
+		{
+			name:      "test_forged",
+			publicKey: publicKey,
+			tamper:    func(tx *LocalOnChain) { tx.Commitment = "00" },
+			want:      false,
+		},
+		{
+			name:      "test_unsigned",
+			publicKey: publicKey,
+			tamper:    func(tx *LocalOnChain) { tx.Signature = "" },
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hiddenTX, _, _, err := NewLocalPlain(tt.name, money.MustParse("1", "USD"), 1).Hide(crypto.RandomStream())
+			if err != nil {
+				t.Errorf("Hide() error = %v", err)
+				return
+			}
+			tx := hiddenTX.ToOnChain()
+			if err = tx.Sign(signingKey, crypto.RandomStream()); err != nil {
+				t.Errorf("Sign() error = %v", err)
+				return
+			}
+			tt.tamper(tx)
+			got, err := tx.VerifySignature(tt.publicKey)
+			if err != nil {
+				t.Errorf("VerifySignature() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalHidden_MarshalBinary(t *testing.T) {
+	tests := []struct {
+		name           string
+		withRangeProof bool
+	}{
+		{
+			name:           "test_without_range_proof",
+			withRangeProof: false,
+		},
+		{
+			name:           "test_with_range_proof",
+			withRangeProof: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plainTX := NewLocalPlain(tt.name, money.MustParse("1.5", "USD"), 1)
+			hide := plainTX.Hide
+			if tt.withRangeProof {
+				hide = plainTX.HideWithRangeProof
+			}
+			hiddenTX, _, _, err := hide(crypto.RandomStream())
+			if err != nil {
+				t.Errorf("Hide() error = %v", err)
+				return
+			}
+			encoding, err := hiddenTX.MarshalBinary()
+			if err != nil {
+				t.Errorf("MarshalBinary() error = %v", err)
+				return
+			}
+			decoded := new(LocalHidden)
+			if err = decoded.UnmarshalBinary(encoding); err != nil {
+				t.Errorf("UnmarshalBinary() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(decoded, hiddenTX) {
+				t.Errorf("UnmarshalBinary() = %v, want %v", decoded, hiddenTX)
+			}
+			id, err := hiddenTX.ContentID()
+			if err != nil {
+				t.Errorf("ContentID() error = %v", err)
+				return
+			}
+			decodedID, err := decoded.ContentID()
+			if err != nil {
+				t.Errorf("ContentID() error = %v", err)
+				return
+			}
+			if id != decodedID {
+				t.Errorf("ContentID() = %s, want %s", decodedID, id)
+			}
+			decoded.Timestamp++
+			if decodedID, _ = decoded.ContentID(); id == decodedID {
+				t.Errorf("ContentID() = %s, want a different ID", decodedID)
+			}
+		})
+	}
+}
+
+func TestLocalOnChain_KeyVal(t *testing.T) {
+	signingKey, _ := crypto.SigningKeyGen(crypto.RandomStream())
+	hiddenTX, _, _, err := NewLocalPlain("org2", money.MustParse("1.5", "USD"), 1).Hide(crypto.RandomStream())
+	if err != nil {
+		t.Fatalf("Hide() error = %v", err)
+	}
+	onChainTX := hiddenTX.ToOnChain()
+	if err = onChainTX.Sign(signingKey, crypto.RandomStream()); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	key, val, err := onChainTX.KeyVal()
+	if err != nil {
+		t.Fatalf("KeyVal() error = %v", err)
+	}
+	decoded := new(LocalOnChain)
+	if err = decoded.UnmarshalBinary(val); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, onChainTX) {
+		t.Errorf("UnmarshalBinary() = %v, want %v", decoded, onChainTX)
+	}
+	// the key does not depend on the randomness of the signature
+	resignedTX := *onChainTX
+	if err = resignedTX.Sign(signingKey, crypto.RandomStream()); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if resignedKey, _, _ := resignedTX.KeyVal(); resignedKey != key {
+		t.Errorf("KeyVal() key = %s, want %s", resignedKey, key)
+	}
+	otherSigningKey, _ := crypto.SigningKeyGen(crypto.RandomStream())
+	if err = resignedTX.Sign(otherSigningKey, crypto.RandomStream()); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if otherKey, _, _ := resignedTX.KeyVal(); otherKey == key {
+		t.Errorf("KeyVal() key = %s, want a different key for another signer", otherKey)
+	}
+}
@@ -0,0 +1,26 @@
+package transaction
+
+import (
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+// the tags separate the signing messages of the on-chain transaction types
+const (
+	localSigningTag = "auti-clolc-local"
+	orgSigningTag   = "auti-clolc-org"
+	audSigningTag   = "auti-clolc-aud"
+)
+
+// verifySignedBy checks that the transaction is signed by the given public key.
+func verifySignedBy(publicKey kyber.Point, signer, signature string, msg []byte) (bool, error) {
+	publicKeyHex, err := crypto.PublicKeyHex(publicKey)
+	if err != nil {
+		return false, err
+	}
+	if signer != publicKeyHex {
+		return false, nil
+	}
+	return crypto.VerifySignatureHex(signer, signature, msg)
+}
@@ -1,6 +1,9 @@
 package auditor
 
 import (
+	"crypto/cipher"
+	"errors"
+
 	mt "github.com/txaty/go-merkletree"
 	"go.dedis.ch/kyber/v3"
 
@@ -14,22 +17,41 @@ type TypeID string
 type TypeEpochID kyber.Point
 
 type Auditor struct {
-	ID            TypeID
-	AuditedOrgIDs []organization.TypeID
-	EpochID       TypeEpochID
+	ID               TypeID
+	AuditedOrgIDs    []organization.TypeID
+	EpochID          TypeEpochID
+	SigningPublicKey crypto.TypePublicKey
+	// AllowLegacyScheme admits the transactions of the legacy commitment scheme, see crypto.CheckCommitmentScheme,
+	// it is only set while the data committed under the legacy scheme is migrated.
+	AllowLegacyScheme bool
+	signingKey        crypto.TypePrivateKey
+	randStream        cipher.Stream
 }
 
 func New(id string, organizations []*organization.Organization) *Auditor {
+	return NewWithRandStream(id, organizations, crypto.RandomStream())
+}
+
+// NewWithRandStream creates an auditor drawing all its randomness from the given stream,
+// use crypto.NewSeededStream for reproducible runs.
+func NewWithRandStream(id string, organizations []*organization.Organization, rand cipher.Stream) *Auditor {
 	aud := &Auditor{
-		ID: TypeID(id),
+		ID:         TypeID(id),
+		randStream: rand,
 	}
 	aud.AuditedOrgIDs = make([]organization.TypeID, len(organizations))
 	for idx, org := range organizations {
 		aud.AuditedOrgIDs[idx] = org.ID
 	}
+	aud.signingKey, aud.SigningPublicKey = crypto.SigningKeyGen(rand)
 	return aud
 }
 
+// SignTX signs the on-chain transaction with the long-term signing key of the auditor.
+func (a *Auditor) SignTX(tx crypto.Signable) error {
+	return tx.Sign(a.signingKey, a.randStream)
+}
+
 func (a *Auditor) SetEpochID(id kyber.Point) {
 	a.EpochID = crypto.KyberSuite.Point().Set(id)
 }
@@ -53,6 +75,32 @@ func (a *Auditor) VerifyMerkleProof(tx transaction.LocalOnChain) (uint, error) {
 	return 1, nil
 }
 
+// VerifyRangeProof returns 0 for the local chain transactions whose range proofs do not verify,
+// the results can be summarized with SummarizeMerkleProofVerificationResults.
+// The hash point from the organization is needed if the transaction uses crypto.CommitmentSchemeHashToCurve.
+// The transactions without an asset, whose commitments would mix with the base point, are rejected,
+// and so are the transactions of the legacy scheme unless AllowLegacyScheme is set.
+func (a *Auditor) VerifyRangeProof(tx transaction.LocalOnChain, hashPoint kyber.Point) (uint, error) {
+	txPlain, err := tx.ToPlain()
+	if err != nil {
+		return 0, err
+	}
+	if txPlain.Asset == "" {
+		return 0, errors.New("transaction has no asset")
+	}
+	if err = crypto.CheckCommitmentScheme(txPlain.Scheme, a.AllowLegacyScheme); err != nil {
+		return 0, err
+	}
+	ok, err := txPlain.VerifyRangeProof(hashPoint)
+	if err != nil {
+		return 0, err
+	}
+	if !ok {
+		return 0, nil
+	}
+	return 1, nil
+}
+
 func (a *Auditor) SummarizeMerkleProofVerificationResults(verificationResults []uint) bool {
 	if len(verificationResults) == 0 {
 		return false
@@ -67,6 +115,8 @@ func (a *Auditor) SummarizeMerkleProofVerificationResults(verificationResults []
 	return true
 }
 
+// VerifyCommitments returns true if the paired commitments without their hash points sum to zero,
+// amounts of different assets never cancel as they are committed with independent generators.
 func (a *Auditor) VerifyCommitments(commitmentList1, commitmentList2 [][]byte,
 	hashPoints1, hashPoints2 []kyber.Point) (bool, error) {
 	if len(commitmentList1) != len(commitmentList2) {
@@ -97,7 +147,6 @@ func (a *Auditor) VerifyCommitments(commitmentList1, commitmentList2 [][]byte,
 		// hash point 2
 		sum = sum.Sub(sum, hashPoints2[i])
 	}
-	// TODO: test this
 	return sum.Equal(crypto.KyberSuite.Point().Null()), nil
 }
 
@@ -0,0 +1,198 @@
+package auditor
+
+import (
+	"testing"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/closc/transaction"
+	"github.com/auti-project/auti/internal/constants"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/money"
+)
+
+func TestAuditor_VerifyRangeProof(t *testing.T) {
+	tests := []struct {
+		name              string
+		scheme            crypto.CommitmentScheme
+		asset             string
+		allowLegacyScheme bool
+		want              uint
+		wantErr           bool
+	}{
+		{
+			name:    "test_hash_to_curve",
+			scheme:  crypto.CommitmentSchemeHashToCurve,
+			asset:   "EUR",
+			want:    1,
+			wantErr: false,
+		},
+		{
+			name:    "test_no_asset",
+			scheme:  crypto.CommitmentSchemeHashToCurve,
+			asset:   "",
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:    "test_new_legacy_entry",
+			scheme:  crypto.CommitmentSchemeHashScalar,
+			asset:   "EUR",
+			want:    0,
+			wantErr: true,
+		},
+		{
+			name:              "test_legacy_entry_migration",
+			scheme:            crypto.CommitmentSchemeHashScalar,
+			asset:             "EUR",
+			allowLegacyScheme: true,
+			want:              1,
+			wantErr:           false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aud := New("aud1", nil)
+			aud.AllowLegacyScheme = tt.allowLegacyScheme
+			tx, hashPoint := newLocalTX(t, tt.scheme, tt.asset)
+			got, err := aud.VerifyRangeProof(*tx, hashPoint)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyRangeProof() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("VerifyRangeProof() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditor_VerifyCommitments(t *testing.T) {
+	type pair struct {
+		amount1 money.Amount
+		amount2 money.Amount
+	}
+	tests := []struct {
+		name       string
+		pairs      []pair
+		dropHash2  bool
+		badCommit1 bool
+		want       bool
+		wantErr    bool
+	}{
+		{
+			name: "test_consistent",
+			pairs: []pair{
+				{money.MustParse("100.25", "USD"), money.MustParse("-100.25", "USD")},
+				{money.MustParse("-3.5", "EUR"), money.MustParse("3.5", "EUR")},
+			},
+			want:    true,
+			wantErr: false,
+		},
+		{
+			name: "test_amount_mismatch",
+			pairs: []pair{
+				{money.MustParse("100.25", "USD"), money.MustParse("-100.25", "USD")},
+				{money.MustParse("-3.5", "EUR"), money.MustParse("3.4", "EUR")},
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "test_asset_mismatch",
+			pairs: []pair{
+				{money.MustParse("100", "USD"), money.MustParse("-100", "EUR")},
+			},
+			want:    false,
+			wantErr: false,
+		},
+		{
+			name: "test_missing_hash_point",
+			pairs: []pair{
+				{money.MustParse("100.25", "USD"), money.MustParse("-100.25", "USD")},
+			},
+			dropHash2: true,
+			want:      false,
+			wantErr:   false,
+		},
+		{
+			name: "test_invalid_commitment",
+			pairs: []pair{
+				{money.MustParse("100.25", "USD"), money.MustParse("-100.25", "USD")},
+			},
+			badCommit1: true,
+			want:       false,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				commitments1, commitments2 [][]byte
+				hashPoints1, hashPoints2   []kyber.Point
+			)
+			for idx, p := range tt.pairs {
+				counter := uint64(idx + 1)
+				hidden1, hashPoint1, err := transaction.NewPlain("org1", "org2", p.amount1, counter, 1).Hide()
+				if err != nil {
+					t.Fatalf("Hide() error = %v", err)
+				}
+				hidden2, hashPoint2, err := transaction.NewPlain("org2", "org1", p.amount2, counter, 1).Hide()
+				if err != nil {
+					t.Fatalf("Hide() error = %v", err)
+				}
+				commitments1 = append(commitments1, hidden1.Commitment)
+				commitments2 = append(commitments2, hidden2.Commitment)
+				hashPoints1 = append(hashPoints1, hashPoint1)
+				hashPoints2 = append(hashPoints2, hashPoint2)
+			}
+			if tt.dropHash2 {
+				hashPoints2 = hashPoints2[1:]
+			}
+			if tt.badCommit1 {
+				commitments1[0] = []byte("commitment")
+			}
+			got, err := New("aud1", nil).VerifyCommitments(commitments1, commitments2, hashPoints1, hashPoints2)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyCommitments() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("VerifyCommitments() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newLocalTX commits to an amount of the asset under the scheme with a range proof,
+// and returns the on-chain transaction and its hash point.
+func newLocalTX(t *testing.T, scheme crypto.CommitmentScheme, asset string) (*transaction.LocalOnChain, kyber.Point) {
+	t.Helper()
+	const (
+		amount    = -1250
+		timestamp = 1
+		counter   = 1
+	)
+	receiverHash := []byte("receiver")
+	commitment, hashPoint, err := crypto.PedersonCommitWithHash(scheme, asset, amount, timestamp, receiverHash, counter)
+	if err != nil {
+		t.Fatalf("PedersonCommitWithHash() error = %v", err)
+	}
+	rangeProof, err := crypto.PedersonCommitWithHashRangeProof(
+		scheme, asset, amount, timestamp, receiverHash, counter, constants.RangeProofBitLen, crypto.RandomStream(),
+	)
+	if err != nil {
+		t.Fatalf("PedersonCommitWithHashRangeProof() error = %v", err)
+	}
+	commitmentBytes, err := commitment.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plainTX := transaction.NewLocalPlain(commitmentBytes, []byte("merkle_root"), []byte("merkle_proof"))
+	plainTX.Asset = asset
+	plainTX.Scheme = scheme
+	if plainTX.RangeProof, err = rangeProof.Serialize(); err != nil {
+		t.Fatal(err)
+	}
+	return plainTX.ToOnChain(), hashPoint
+}
@@ -1,12 +1,16 @@
 package committee
 
 import (
+	"crypto/cipher"
+	"fmt"
+
 	mt "github.com/txaty/go-merkletree"
 	"go.dedis.ch/kyber/v3"
 
 	"github.com/auti-project/auti/internal/closc/auditor"
 	closcorg "github.com/auti-project/auti/internal/closc/organization"
 	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
 )
 
 type TypeID string
@@ -17,12 +21,25 @@ type Committee struct {
 	managedAuditorIDs []auditor.TypeID
 	managedOrgIDs     []closcorg.TypeID
 	epochAuditorIDMap map[auditor.TypeID]auditor.TypeEpochID
+	currentEpoch      *epoch.Epoch
+	epochHistory      map[epoch.TypeID]*epochMaterial
+	SigningPublicKey  crypto.TypePublicKey
+	signingKey        crypto.TypePrivateKey
+	randStream        cipher.Stream
 }
 
 func New(id string, auditors []*auditor.Auditor) *Committee {
+	return NewWithRandStream(id, auditors, crypto.RandomStream())
+}
+
+// NewWithRandStream creates a committee drawing all its randomness from the given stream,
+// use crypto.NewSeededStream for reproducible runs.
+func NewWithRandStream(id string, auditors []*auditor.Auditor, rand cipher.Stream) *Committee {
 	com := &Committee{
 		ID:               TypeID(id),
 		managedEntityMap: make(map[auditor.TypeID][]closcorg.TypeID),
+		epochHistory:     make(map[epoch.TypeID]*epochMaterial),
+		randStream:       rand,
 	}
 	com.managedAuditorIDs = make([]auditor.TypeID, len(auditors))
 	for idx, aud := range auditors {
@@ -30,29 +47,45 @@ func New(id string, auditors []*auditor.Auditor) *Committee {
 		com.managedAuditorIDs[idx] = aud.ID
 		com.managedOrgIDs = append(com.managedOrgIDs, aud.AuditedOrgIDs...)
 	}
+	com.signingKey, com.SigningPublicKey = crypto.SigningKeyGen(rand)
 	return com
 }
 
+// SignTX signs the on-chain transaction with the long-term signing key of the committee.
+func (c *Committee) SignTX(tx crypto.Signable) error {
+	return tx.Sign(c.signingKey, c.randStream)
+}
+
 func (c *Committee) reinitializeMaps() {
 	c.epochAuditorIDMap = make(map[auditor.TypeID]auditor.TypeEpochID)
 }
 
-func GenerateAuditorEpochID() kyber.Point {
-	randScalar := crypto.KyberSuite.Scalar().Pick(crypto.KyberSuite.RandomStream())
+func GenerateAuditorEpochID(rand cipher.Stream) kyber.Point {
+	randScalar := crypto.KyberSuite.Scalar().Pick(rand)
 	randPoint := crypto.KyberSuite.Point().Mul(randScalar, crypto.PointG)
 	return randPoint
 }
 
 func (c *Committee) InitializeEpoch(auditors []*auditor.Auditor) error {
+	if c.currentEpoch != nil && c.currentEpoch.State() != epoch.StateOpen {
+		return fmt.Errorf("epoch %d is already initialized", c.currentEpoch.ID)
+	}
 	c.reinitializeMaps()
 	for _, aud := range auditors {
 		// Generate epoch ID for each auditor
-		epochID := GenerateAuditorEpochID()
+		epochID := GenerateAuditorEpochID(c.randStream)
 		c.epochAuditorIDMap[aud.ID] = epochID
 		// Distribute epoch auditor IDs
 		aud.SetEpochID(epochID)
 	}
-	return nil
+	if c.currentEpoch == nil {
+		return nil
+	}
+	c.epochHistory[c.currentEpoch.ID] = &epochMaterial{
+		epoch:             c.currentEpoch,
+		epochAuditorIDMap: c.epochAuditorIDMap,
+	}
+	return c.currentEpoch.Advance(epoch.StateRecording)
 }
 
 func (c *Committee) VerifyMerkleBatchProof(commitments []mt.DataBlock,
@@ -81,6 +114,8 @@ func (c *Committee) SummarizeMerkleBatchProofVerificationResults(verificationRes
 	return true
 }
 
+// VerifyCommitment returns true if the accumulated commitments of the auditors cancel out,
+// which requires the amounts of every asset to cancel out on their own.
 func (c *Committee) VerifyCommitment(commitments []kyber.Point) bool {
 	sum := crypto.KyberSuite.Point().Null()
 	for _, commitment := range commitments {
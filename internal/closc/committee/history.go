@@ -0,0 +1,53 @@
+package committee
+
+import (
+	"fmt"
+
+	"github.com/auti-project/auti/internal/closc/auditor"
+	"github.com/auti-project/auti/internal/epoch"
+)
+
+// epochMaterial is the key material of an epoch archived by the committee for re-verifying its results.
+type epochMaterial struct {
+	epoch             *epoch.Epoch
+	epochAuditorIDMap map[auditor.TypeID]auditor.TypeEpochID
+}
+
+// OpenEpoch opens the epoch initialized by the next InitializeEpoch, the epoch IDs must increase.
+// The material of the previous epochs stays archived.
+func (c *Committee) OpenEpoch(e *epoch.Epoch) error {
+	if e.State() != epoch.StateOpen {
+		return fmt.Errorf("epoch %d is %s", e.ID, e.State())
+	}
+	if c.currentEpoch != nil && e.ID <= c.currentEpoch.ID {
+		return fmt.Errorf("epoch %d opened after epoch %d", e.ID, c.currentEpoch.ID)
+	}
+	c.currentEpoch = e
+	return nil
+}
+
+// Epoch returns the current epoch, or nil if no epoch is opened.
+func (c *Committee) Epoch() *epoch.Epoch {
+	return c.currentEpoch
+}
+
+// AdvanceEpoch moves the current epoch to the next state.
+func (c *Committee) AdvanceEpoch(to epoch.State) error {
+	if c.currentEpoch == nil {
+		return fmt.Errorf("no epoch opened")
+	}
+	return c.currentEpoch.Advance(to)
+}
+
+// AtEpoch returns a view of the committee holding the archived auditor epoch IDs of the epoch,
+// VerifyCommitment of the view re-verifies the commitments submitted in that epoch.
+func (c *Committee) AtEpoch(epochID epoch.TypeID) (*Committee, error) {
+	material, ok := c.epochHistory[epochID]
+	if !ok {
+		return nil, fmt.Errorf("epoch %d not found", epochID)
+	}
+	view := *c
+	view.currentEpoch = material.epoch
+	view.epochAuditorIDMap = material.epochAuditorIDMap
+	return &view, nil
+}
@@ -0,0 +1,309 @@
+package committee
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"fmt"
+	"sort"
+	"strconv"
+
+	mt "github.com/txaty/go-merkletree"
+	"go.dedis.ch/kyber/v3"
+
+	closcorg "github.com/auti-project/auti/internal/closc/organization"
+	"github.com/auti-project/auti/internal/closc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/hashing"
+)
+
+// disclosureSigningTag separates the signatures of the disclosures from those of the transactions.
+const disclosureSigningTag = "auti-closc-disclosure"
+
+// DisclosedTX is a local chain transaction disclosed by its sender
+// with the inputs of the hash point of its commitment.
+// The sender signs the inputs together with the ID of the transaction on its local chain,
+// so that the committee cannot attach other inputs to the transaction.
+type DisclosedTX struct {
+	Sender    closcorg.TypeID
+	Receiver  closcorg.TypeID
+	Counter   uint64
+	Timestamp int64
+	TX        *transaction.LocalOnChain
+	Signature []byte
+}
+
+// SigningMessage returns the canonical encoding of the disclosure covered by the signature of the sender.
+func (d *DisclosedTX) SigningMessage() ([]byte, error) {
+	localTXID, err := d.TX.ContentID()
+	if err != nil {
+		return nil, err
+	}
+	return crypto.SigningMessage(disclosureSigningTag, localTXID, string(d.Sender), string(d.Receiver),
+		strconv.FormatUint(d.Counter, 10), strconv.FormatInt(d.Timestamp, 10)), nil
+}
+
+// Sign signs the disclosure with the signing key of the sender.
+func (d *DisclosedTX) Sign(signingKey kyber.Scalar, rand cipher.Stream) error {
+	msg, err := d.SigningMessage()
+	if err != nil {
+		return err
+	}
+	d.Signature, err = crypto.Sign(signingKey, msg, rand)
+	return err
+}
+
+// VerifySignature returns false if the disclosure is unsigned or not signed by the public key of the sender.
+func (d *DisclosedTX) VerifySignature(publicKey kyber.Point) (bool, error) {
+	if len(d.Signature) == 0 {
+		return false, nil
+	}
+	msg, err := d.SigningMessage()
+	if err != nil {
+		return false, err
+	}
+	return crypto.VerifySignature(publicKey, msg, d.Signature)
+}
+
+// Divergence is a counter of a pair of organizations whose transactions do not cancel,
+// TX1 is sent by OrgID1 and TX2 by OrgID2, either is nil if the organization did not record it.
+type Divergence struct {
+	OrgID1  closcorg.TypeID
+	OrgID2  closcorg.TypeID
+	Counter uint64
+	TX1     *DisclosedTX
+	TX2     *DisclosedTX
+}
+
+// PinpointEvidence is the outcome of PinpointInconsistency,
+// it can be verified with VerifyPinpointEvidence against the Merkle roots on the organization chain.
+// Ledgers prove the absence of the missing sides of the divergences, it holds the complete disclosure
+// of every organization missing a side, in the order of the leaves of its Merkle tree.
+type PinpointEvidence struct {
+	Divergences []*Divergence
+	Ledgers     map[closcorg.TypeID][]*DisclosedTX
+}
+
+type divergenceKey struct {
+	orgID1  closcorg.TypeID
+	orgID2  closcorg.TypeID
+	counter uint64
+}
+
+// PinpointInconsistency groups the disclosed transactions by the pair of organizations and the counter,
+// and reports the groups whose commitments do not cancel after removing the hash points.
+// The transactions of every organization are disclosed in the order of the leaves of its Merkle tree.
+func (c *Committee) PinpointInconsistency(txList []*DisclosedTX) (*PinpointEvidence, error) {
+	divergenceMap := make(map[divergenceKey]*Divergence)
+	ledgerMap := make(map[closcorg.TypeID][]*DisclosedTX)
+	var keys []divergenceKey
+	for _, tx := range txList {
+		ledgerMap[tx.Sender] = append(ledgerMap[tx.Sender], tx)
+		key := divergenceKey{orgID1: tx.Sender, orgID2: tx.Receiver, counter: tx.Counter}
+		if key.orgID1 > key.orgID2 {
+			key.orgID1, key.orgID2 = key.orgID2, key.orgID1
+		}
+		divergence, ok := divergenceMap[key]
+		if !ok {
+			divergence = &Divergence{OrgID1: key.orgID1, OrgID2: key.orgID2, Counter: key.counter}
+			divergenceMap[key] = divergence
+			keys = append(keys, key)
+		}
+		side := &divergence.TX1
+		if tx.Sender == key.orgID2 {
+			side = &divergence.TX2
+		}
+		if *side != nil {
+			return nil, fmt.Errorf("duplicated counter %d from %s to %s", tx.Counter, tx.Sender, tx.Receiver)
+		}
+		*side = tx
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].orgID1 != keys[j].orgID1 {
+			return keys[i].orgID1 < keys[j].orgID1
+		}
+		if keys[i].orgID2 != keys[j].orgID2 {
+			return keys[i].orgID2 < keys[j].orgID2
+		}
+		return keys[i].counter < keys[j].counter
+	})
+	evidence := &PinpointEvidence{Ledgers: make(map[closcorg.TypeID][]*DisclosedTX)}
+	for _, key := range keys {
+		divergence := divergenceMap[key]
+		ok, err := divergence.cancels()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			continue
+		}
+		evidence.Divergences = append(evidence.Divergences, divergence)
+		if divergence.TX1 == nil {
+			evidence.Ledgers[divergence.OrgID1] = ledgerMap[divergence.OrgID1]
+		}
+		if divergence.TX2 == nil {
+			evidence.Ledgers[divergence.OrgID2] = ledgerMap[divergence.OrgID2]
+		}
+	}
+	return evidence, nil
+}
+
+// VerifyPinpointEvidence checks that every transaction in the evidence and its disclosure are signed by its sender,
+// that the transaction is included in the Merkle tree anchored by its sender,
+// and that the transactions of every divergence do not cancel.
+// A missing side of a divergence must be proven absent by the ledger of its sender,
+// so merkleRoots must hold the roots of all the organizations anchoring one in the epoch.
+func VerifyPinpointEvidence(
+	evidence *PinpointEvidence,
+	merkleRoots map[closcorg.TypeID][]byte,
+	signingPublicKeys map[closcorg.TypeID]crypto.TypePublicKey,
+) (bool, error) {
+	if len(evidence.Divergences) == 0 {
+		return false, nil
+	}
+	for _, divergence := range evidence.Divergences {
+		if divergence.TX1 == nil && divergence.TX2 == nil {
+			return false, nil
+		}
+		sides := []struct {
+			tx       *DisclosedTX
+			sender   closcorg.TypeID
+			receiver closcorg.TypeID
+		}{
+			{divergence.TX1, divergence.OrgID1, divergence.OrgID2},
+			{divergence.TX2, divergence.OrgID2, divergence.OrgID1},
+		}
+		for _, side := range sides {
+			publicKey, ok := signingPublicKeys[side.sender]
+			if !ok {
+				return false, fmt.Errorf("no signing public key for organization %s", side.sender)
+			}
+			if side.tx == nil {
+				ledger, ok := evidence.Ledgers[side.sender]
+				if !ok {
+					return false, nil
+				}
+				ok, err := verifyAbsence(
+					ledger, side.sender, side.receiver, divergence.Counter, merkleRoots[side.sender], publicKey,
+				)
+				if err != nil || !ok {
+					return false, err
+				}
+				continue
+			}
+			if side.tx.Sender != side.sender || side.tx.Receiver != side.receiver ||
+				side.tx.Counter != divergence.Counter {
+				return false, nil
+			}
+			if ok, err := side.tx.verifySignatures(publicKey); err != nil || !ok {
+				return false, err
+			}
+			if ok, err := verifyInclusion(side.tx.TX, merkleRoots[side.sender]); err != nil || !ok {
+				return false, err
+			}
+		}
+		ok, err := divergence.cancels()
+		if err != nil || ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// verifyAbsence checks that the ledger is the complete disclosure of the sender, i.e., its signed transactions
+// rebuild the anchored Merkle root, and that it holds no transaction to the receiver with the counter.
+// An empty ledger is only complete for a sender anchoring no root.
+func verifyAbsence(
+	ledger []*DisclosedTX,
+	sender, receiver closcorg.TypeID,
+	counter uint64,
+	merkleRoot []byte,
+	publicKey crypto.TypePublicKey,
+) (bool, error) {
+	if len(ledger) == 0 {
+		return len(merkleRoot) == 0, nil
+	}
+	dataBlocks := make([]mt.DataBlock, len(ledger))
+	for idx, tx := range ledger {
+		if tx.Sender != sender || (tx.Receiver == receiver && tx.Counter == counter) {
+			return false, nil
+		}
+		if ok, err := tx.verifySignatures(publicKey); err != nil || !ok {
+			return false, err
+		}
+		localPlainTX, err := tx.TX.ToPlain()
+		if err != nil {
+			return false, err
+		}
+		dataBlocks[idx] = transaction.NewLocalCommitmentPlain(localPlainTX.Commitment)
+	}
+	// a Merkle tree needs at least two leaves, the orchestrator duplicates a single commitment
+	if len(dataBlocks) == 1 {
+		dataBlocks = append(dataBlocks, dataBlocks[0])
+	}
+	_, root, err := crypto.GenerateMerkleProofs(dataBlocks)
+	if err != nil {
+		return false, err
+	}
+	return len(merkleRoot) != 0 && bytes.Equal(root, merkleRoot), nil
+}
+
+// verifySignatures checks the signatures of the sender on the local chain transaction and on its disclosure.
+func (d *DisclosedTX) verifySignatures(publicKey kyber.Point) (bool, error) {
+	if ok, err := d.TX.VerifySignature(publicKey); err != nil || !ok {
+		return false, err
+	}
+	return d.VerifySignature(publicKey)
+}
+
+// cancels returns true if the commitments of the divergence sum up to the sum of their hash points,
+// the commitments of different assets never cancel.
+func (d *Divergence) cancels() (bool, error) {
+	sum := crypto.KyberSuite.Point().Null()
+	for _, tx := range []*DisclosedTX{d.TX1, d.TX2} {
+		if tx == nil {
+			continue
+		}
+		amountPoint, err := tx.amountPoint()
+		if err != nil {
+			return false, err
+		}
+		sum.Add(sum, amountPoint)
+	}
+	return sum.Equal(crypto.KyberSuite.Point().Null()), nil
+}
+
+// amountPoint removes the hash point from the commitment of the transaction, i.e., returns amount * G_asset.
+func (d *DisclosedTX) amountPoint() (kyber.Point, error) {
+	receiverHash := hashing.Sum(hashing.TagOrgID, []byte(d.Receiver))
+	localPlainTX, err := d.TX.ToPlain()
+	if err != nil {
+		return nil, err
+	}
+	// the commitment to a zero amount is the hash point itself, derived with the scheme of the entry
+	_, hashPoint, err := crypto.PedersonCommitWithHash(
+		localPlainTX.Scheme, "", 0, d.Timestamp, receiverHash, d.Counter,
+	)
+	if err != nil {
+		return nil, err
+	}
+	commitment := crypto.KyberSuite.Point()
+	if err = commitment.UnmarshalBinary(localPlainTX.Commitment); err != nil {
+		return nil, err
+	}
+	return commitment.Sub(commitment, hashPoint), nil
+}
+
+func verifyInclusion(tx *transaction.LocalOnChain, merkleRoot []byte) (bool, error) {
+	localPlainTX, err := tx.ToPlain()
+	if err != nil {
+		return false, err
+	}
+	if len(merkleRoot) == 0 || !bytes.Equal(localPlainTX.MerkleRoot, merkleRoot) {
+		return false, nil
+	}
+	merkleProof, err := crypto.MerkleProofUnmarshal(localPlainTX.MerkleProof)
+	if err != nil {
+		return false, err
+	}
+	return crypto.VerifyMerkleProof(localPlainTX, merkleProof, merkleRoot)
+}
@@ -0,0 +1,191 @@
+package committee
+
+import (
+	"testing"
+
+	mt "github.com/txaty/go-merkletree"
+
+	closcorg "github.com/auti-project/auti/internal/closc/organization"
+	"github.com/auti-project/auti/internal/closc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/money"
+)
+
+func TestVerifyPinpointEvidence(t *testing.T) {
+	tests := []struct {
+		name string
+		// txList are the transactions of the organizations, those between org2 and org3 are consistent
+		txList          []*transaction.Plain
+		tamper          func(evidence *PinpointEvidence)
+		wantDivergences int
+		want            bool
+	}{
+		{
+			name: "test_consistent",
+			txList: []*transaction.Plain{
+				transaction.NewPlain("org1", "org2", money.MustParse("10", "USD"), 1, 1),
+				transaction.NewPlain("org2", "org1", money.MustParse("-10", "USD"), 1, 1),
+			},
+			wantDivergences: 0,
+			want:            false,
+		},
+		{
+			name: "test_amount_mismatch",
+			txList: []*transaction.Plain{
+				transaction.NewPlain("org1", "org2", money.MustParse("10", "USD"), 1, 1),
+				transaction.NewPlain("org2", "org1", money.MustParse("-9", "USD"), 1, 1),
+			},
+			wantDivergences: 1,
+			want:            true,
+		},
+		{
+			name: "test_missing_side",
+			txList: []*transaction.Plain{
+				transaction.NewPlain("org1", "org2", money.MustParse("10", "USD"), 1, 1),
+				transaction.NewPlain("org2", "org3", money.MustParse("5", "USD"), 1, 1),
+				transaction.NewPlain("org3", "org2", money.MustParse("-5", "USD"), 1, 1),
+			},
+			wantDivergences: 1,
+			want:            true,
+		},
+		{
+			name: "test_missing_side_no_transactions",
+			txList: []*transaction.Plain{
+				transaction.NewPlain("org1", "org2", money.MustParse("10", "USD"), 1, 1),
+			},
+			wantDivergences: 1,
+			want:            true,
+		},
+		{
+			name: "test_missing_side_without_ledger",
+			txList: []*transaction.Plain{
+				transaction.NewPlain("org1", "org2", money.MustParse("10", "USD"), 1, 1),
+				transaction.NewPlain("org2", "org3", money.MustParse("5", "USD"), 1, 1),
+				transaction.NewPlain("org3", "org2", money.MustParse("-5", "USD"), 1, 1),
+			},
+			tamper: func(evidence *PinpointEvidence) {
+				delete(evidence.Ledgers, "org2")
+			},
+			wantDivergences: 1,
+			want:            false,
+		},
+		{
+			name: "test_missing_side_incomplete_ledger",
+			txList: []*transaction.Plain{
+				transaction.NewPlain("org1", "org2", money.MustParse("10", "USD"), 1, 1),
+				transaction.NewPlain("org2", "org3", money.MustParse("5", "USD"), 1, 1),
+				transaction.NewPlain("org3", "org2", money.MustParse("-5", "USD"), 1, 1),
+				transaction.NewPlain("org2", "org3", money.MustParse("6", "USD"), 2, 2),
+				transaction.NewPlain("org3", "org2", money.MustParse("-6", "USD"), 2, 2),
+			},
+			tamper: func(evidence *PinpointEvidence) {
+				evidence.Ledgers["org2"] = evidence.Ledgers["org2"][1:]
+			},
+			wantDivergences: 1,
+			want:            false,
+		},
+		{
+			name: "test_no_side",
+			txList: []*transaction.Plain{
+				transaction.NewPlain("org1", "org2", money.MustParse("10", "USD"), 1, 1),
+				transaction.NewPlain("org2", "org3", money.MustParse("5", "USD"), 1, 1),
+				transaction.NewPlain("org3", "org2", money.MustParse("-5", "USD"), 1, 1),
+			},
+			tamper: func(evidence *PinpointEvidence) {
+				evidence.Divergences[0].TX1 = nil
+			},
+			wantDivergences: 1,
+			want:            false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			organizations := []*closcorg.Organization{closcorg.New("org1"), closcorg.New("org2"), closcorg.New("org3")}
+			merkleRoots := make(map[closcorg.TypeID][]byte)
+			signingPublicKeys := make(map[closcorg.TypeID]crypto.TypePublicKey)
+			var disclosedTXs []*DisclosedTX
+			for _, org := range organizations {
+				var orgTXList []*transaction.Plain
+				for _, tx := range tt.txList {
+					if closcorg.TypeID(tx.Sender) == org.ID {
+						orgTXList = append(orgTXList, tx)
+					}
+				}
+				signingPublicKeys[org.ID] = org.SigningPublicKey
+				// an organization without transactions anchors no root
+				if len(orgTXList) == 0 {
+					continue
+				}
+				ledger, merkleRoot := disclose(t, org, orgTXList)
+				disclosedTXs = append(disclosedTXs, ledger...)
+				merkleRoots[org.ID] = merkleRoot
+			}
+			evidence, err := New("com", nil).PinpointInconsistency(disclosedTXs)
+			if err != nil {
+				t.Fatalf("PinpointInconsistency() error = %v", err)
+			}
+			if len(evidence.Divergences) != tt.wantDivergences {
+				t.Fatalf("PinpointInconsistency() number of divergences = %d, want %d",
+					len(evidence.Divergences), tt.wantDivergences)
+			}
+			if tt.tamper != nil {
+				tt.tamper(evidence)
+			}
+			got, err := VerifyPinpointEvidence(evidence, merkleRoots, signingPublicKeys)
+			if err != nil {
+				t.Fatalf("VerifyPinpointEvidence() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("VerifyPinpointEvidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// disclose records the transactions of the organization as the orchestrator does,
+// and returns their signed disclosures in the order of the Merkle leaves with the Merkle root.
+func disclose(t *testing.T, org *closcorg.Organization, txList []*transaction.Plain) ([]*DisclosedTX, []byte) {
+	t.Helper()
+	hiddenTXs := make([]*transaction.Hidden, len(txList))
+	dataBlocks := make([]mt.DataBlock, len(txList))
+	for idx, tx := range txList {
+		hiddenTX, _, err := tx.Hide()
+		if err != nil {
+			t.Fatalf("Hide() error = %v", err)
+		}
+		hiddenTXs[idx] = hiddenTX
+		dataBlocks[idx] = transaction.NewLocalCommitmentPlain(hiddenTX.Commitment)
+	}
+	treeBlocks := dataBlocks
+	if len(treeBlocks) == 1 {
+		treeBlocks = []mt.DataBlock{dataBlocks[0], dataBlocks[0]}
+	}
+	merkleProofs, merkleRoot, err := crypto.GenerateMerkleProofs(treeBlocks)
+	if err != nil {
+		t.Fatalf("GenerateMerkleProofs() error = %v", err)
+	}
+	ledger := make([]*DisclosedTX, len(txList))
+	for idx, tx := range txList {
+		localPlainTX, err := transaction.NewLocalPlainFromProof(hiddenTXs[idx].Commitment, merkleRoot, merkleProofs[idx])
+		if err != nil {
+			t.Fatalf("NewLocalPlainFromProof() error = %v", err)
+		}
+		localPlainTX.Asset = hiddenTXs[idx].Asset
+		localPlainTX.Scheme = hiddenTXs[idx].Scheme
+		localOnChainTX := localPlainTX.ToOnChain()
+		if err = org.SignTX(localOnChainTX); err != nil {
+			t.Fatalf("SignTX() error = %v", err)
+		}
+		ledger[idx] = &DisclosedTX{
+			Sender:    org.ID,
+			Receiver:  closcorg.TypeID(tx.Receiver),
+			Counter:   tx.Counter,
+			Timestamp: tx.Timestamp,
+			TX:        localOnChainTX,
+		}
+		if err = org.SignTX(ledger[idx]); err != nil {
+			t.Fatalf("SignTX() error = %v", err)
+		}
+	}
+	return ledger, merkleRoot
+}
@@ -0,0 +1,490 @@
+package orchestrator
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+
+	mt "github.com/txaty/go-merkletree"
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/closc/auditor"
+	"github.com/auti-project/auti/internal/closc/committee"
+	"github.com/auti-project/auti/internal/closc/organization"
+	"github.com/auti-project/auti/internal/closc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/hashing"
+	"github.com/auti-project/auti/internal/ledger"
+	"github.com/auti-project/auti/internal/money"
+)
+
+// Workload is the plaintext transactions of the organizations in an epoch,
+// the sender of every transaction is the organization holding it.
+type Workload map[organization.TypeID][]*transaction.Plain
+
+// AddTransfer appends the two transactions of a transfer from one organization to another.
+func (w Workload) AddTransfer(fromID, toID organization.TypeID, amount money.Amount, counter uint64, timestamp int64) {
+	fromTX, toTX := transaction.NewPairPlain(string(fromID), string(toID), amount, counter, timestamp)
+	w[fromID] = append(w[fromID], fromTX)
+	w[toID] = append(w[toID], toTX)
+}
+
+// timeRange returns the range of the timestamps of the workload, [0, 1) for an empty workload.
+func (w Workload) timeRange() (int64, int64) {
+	var (
+		startTime, endTime int64
+		found              bool
+	)
+	for _, txList := range w {
+		for _, tx := range txList {
+			if !found || tx.Timestamp < startTime {
+				startTime = tx.Timestamp
+			}
+			if !found || tx.Timestamp >= endTime {
+				endTime = tx.Timestamp + 1
+			}
+			found = true
+		}
+	}
+	if !found {
+		return 0, 1
+	}
+	return startTime, endTime
+}
+
+// Orchestrator runs an epoch of CLOSC for the organizations and auditors managed by the committee.
+// The organizations anchor the Merkle roots of their commitments on the organization chain,
+// the auditors verify, merge and accumulate, and the committee produces the verdict.
+type Orchestrator struct {
+	committee     *committee.Committee
+	auditors      []*auditor.Auditor
+	organizations []*organization.Organization
+	localChains   map[organization.TypeID]ledger.Ledger[*transaction.LocalOnChain]
+	orgChain      ledger.Ledger[*transaction.OrgOnChain]
+	audChain      ledger.Ledger[*transaction.AudOnChain]
+	orgMap        map[organization.TypeID]*organization.Organization
+	orgAuditorMap map[organization.TypeID]*auditor.Auditor
+	// recordMap keeps the records of the last epoch for pinpointing inconsistencies
+	recordMap map[organization.TypeID]*orgRecord
+	// history keeps the records, the evidence and the report of every epoch for re-verification
+	history     map[epoch.TypeID]*epochRecord
+	lastEpochID epoch.TypeID
+	nextEpochID epoch.TypeID
+}
+
+// epochRecord is what the orchestrator keeps of an epoch.
+type epochRecord struct {
+	recordMap    map[organization.TypeID]*orgRecord
+	evidenceList []*orgEvidence
+	audTXIDs     []string
+	report       *Report
+}
+
+// orgRecord is what an organization records in the epoch, the hash points are
+// handed to its auditor off-chain in the order of the local chain transactions.
+type orgRecord struct {
+	org        *organization.Organization
+	txList     []*transaction.Plain
+	localTXIDs []string
+	hashPoints []kyber.Point
+	orgTXID    string
+}
+
+// orgEvidence is what an auditor hands to the committee for an organization.
+type orgEvidence struct {
+	verdict    *OrgVerdict
+	orgTXID    string
+	dataBlocks []mt.DataBlock
+	batchProof []byte
+}
+
+func New(
+	com *committee.Committee,
+	auditors []*auditor.Auditor,
+	organizations []*organization.Organization,
+	localChains map[organization.TypeID]ledger.Ledger[*transaction.LocalOnChain],
+	orgChain ledger.Ledger[*transaction.OrgOnChain],
+	audChain ledger.Ledger[*transaction.AudOnChain],
+) (*Orchestrator, error) {
+	o := &Orchestrator{
+		committee:     com,
+		auditors:      auditors,
+		organizations: organizations,
+		localChains:   localChains,
+		orgChain:      orgChain,
+		audChain:      audChain,
+		orgMap:        make(map[organization.TypeID]*organization.Organization),
+		orgAuditorMap: make(map[organization.TypeID]*auditor.Auditor),
+	}
+	for _, org := range organizations {
+		if _, ok := o.orgMap[org.ID]; ok {
+			return nil, fmt.Errorf("duplicated organization: %s", org.ID)
+		}
+		if localChains[org.ID] == nil {
+			return nil, fmt.Errorf("organization %s has no local chain", org.ID)
+		}
+		o.orgMap[org.ID] = org
+	}
+	for _, aud := range auditors {
+		for _, orgID := range aud.AuditedOrgIDs {
+			if _, ok := o.orgAuditorMap[orgID]; ok {
+				return nil, fmt.Errorf("organization %s is audited by more than one auditor", orgID)
+			}
+			o.orgAuditorMap[orgID] = aud
+		}
+	}
+	for _, org := range organizations {
+		if _, ok := o.orgAuditorMap[org.ID]; !ok {
+			return nil, fmt.Errorf("organization %s has no auditor", org.ID)
+		}
+	}
+	return o, nil
+}
+
+// RunEpoch runs a full epoch over the workload and returns the verdict of the committee,
+// the epoch follows the last one and spans the timestamps of the workload.
+func (o *Orchestrator) RunEpoch(workload Workload) (*Report, error) {
+	startTime, endTime := workload.timeRange()
+	e, err := epoch.New(o.nextEpochID, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	return o.runEpoch(e, workload)
+}
+
+// RunEpochs buckets the transactions of the workload into the epochs of the schedule by their timestamps
+// and runs the epochs with transactions in order, the epochs must follow the last one.
+func (o *Orchestrator) RunEpochs(schedule *epoch.Schedule, workload Workload) ([]*Report, error) {
+	bucketMap := make(map[epoch.TypeID]Workload)
+	for orgID, txList := range workload {
+		for _, tx := range txList {
+			epochID, err := schedule.EpochID(tx.Timestamp)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := bucketMap[epochID]; !ok {
+				bucketMap[epochID] = make(Workload)
+			}
+			bucketMap[epochID][orgID] = append(bucketMap[epochID][orgID], tx)
+		}
+	}
+	epochIDs := make([]epoch.TypeID, 0, len(bucketMap))
+	for epochID := range bucketMap {
+		epochIDs = append(epochIDs, epochID)
+	}
+	sort.Slice(epochIDs, func(i, j int) bool { return epochIDs[i] < epochIDs[j] })
+	reports := make([]*Report, len(epochIDs))
+	for idx, epochID := range epochIDs {
+		report, err := o.runEpoch(schedule.Epoch(epochID), bucketMap[epochID])
+		if err != nil {
+			return nil, err
+		}
+		reports[idx] = report
+	}
+	return reports, nil
+}
+
+func (o *Orchestrator) runEpoch(e *epoch.Epoch, workload Workload) (*Report, error) {
+	for orgID, txList := range workload {
+		if _, ok := o.orgMap[orgID]; !ok {
+			return nil, fmt.Errorf("unknown organization in the workload: %s", orgID)
+		}
+		for _, tx := range txList {
+			receiverID := organization.TypeID(tx.Receiver)
+			if tx.Sender != string(orgID) {
+				return nil, fmt.Errorf("transaction of organization %s is sent by %s", orgID, tx.Sender)
+			}
+			if _, ok := o.orgMap[receiverID]; !ok || receiverID == orgID {
+				return nil, fmt.Errorf("invalid receiver %s of organization %s", receiverID, orgID)
+			}
+			if !e.Contains(tx.Timestamp) {
+				return nil, fmt.Errorf("timestamp %d of organization %s out of epoch %d", tx.Timestamp, orgID, e.ID)
+			}
+		}
+	}
+	if o.history != nil && e.ID < o.nextEpochID {
+		return nil, fmt.Errorf("epoch %d does not follow epoch %d", e.ID, o.lastEpochID)
+	}
+	if err := o.committee.OpenEpoch(e); err != nil {
+		return nil, err
+	}
+	// IN
+	if err := o.committee.InitializeEpoch(o.auditors); err != nil {
+		return nil, err
+	}
+	if o.history == nil {
+		o.history = make(map[epoch.TypeID]*epochRecord)
+	}
+	epochRec := &epochRecord{recordMap: make(map[organization.TypeID]*orgRecord)}
+	o.history[e.ID] = epochRec
+	o.lastEpochID = e.ID
+	o.nextEpochID = e.ID + 1
+	// TR
+	recordMap := epochRec.recordMap
+	o.recordMap = recordMap
+	for _, org := range o.organizations {
+		if len(workload[org.ID]) == 0 {
+			continue
+		}
+		record, err := o.record(org, workload[org.ID])
+		if err != nil {
+			return nil, err
+		}
+		recordMap[org.ID] = record
+	}
+	if err := o.committee.AdvanceEpoch(epoch.StateClosed); err != nil {
+		return nil, err
+	}
+	// CE
+	for _, aud := range o.auditors {
+		audEvidenceList, audTXID, err := o.examine(aud, recordMap)
+		if err != nil {
+			return nil, err
+		}
+		epochRec.evidenceList = append(epochRec.evidenceList, audEvidenceList...)
+		epochRec.audTXIDs = append(epochRec.audTXIDs, audTXID)
+	}
+	if err := o.committee.AdvanceEpoch(epoch.StateExamined); err != nil {
+		return nil, err
+	}
+	// RV
+	report, err := o.verify(e.ID, o.committee, epochRec)
+	if err != nil {
+		return nil, err
+	}
+	epochRec.report = report
+	if err = o.committee.AdvanceEpoch(epoch.StateVerified); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// Reverify has the committee verify the merged proofs and the accumulated commitments of a past epoch
+// again with the archived auditor epoch IDs, the Merkle proof results of the auditors are carried over.
+func (o *Orchestrator) Reverify(epochID epoch.TypeID) (*Report, error) {
+	epochRec, ok := o.history[epochID]
+	if !ok || epochRec.report == nil {
+		return nil, fmt.Errorf("epoch %d not verified", epochID)
+	}
+	com, err := o.committee.AtEpoch(epochID)
+	if err != nil {
+		return nil, err
+	}
+	return o.verify(epochID, com, epochRec)
+}
+
+// EpochReport returns the report of a verified epoch, or nil if the epoch is not found.
+func (o *Orchestrator) EpochReport(epochID epoch.TypeID) *Report {
+	if epochRec, ok := o.history[epochID]; ok {
+		return epochRec.report
+	}
+	return nil
+}
+
+func (o *Orchestrator) verify(epochID epoch.TypeID, com *committee.Committee, epochRec *epochRecord) (*Report, error) {
+	report := &Report{EpochID: epochID}
+	for _, evidence := range epochRec.evidenceList {
+		verdict := *evidence.verdict
+		result, err := o.verifyBatchProof(com, evidence)
+		if err != nil {
+			return nil, err
+		}
+		verdict.BatchProofResult = result
+		report.Orgs = append(report.Orgs, &verdict)
+	}
+	accumulatedCommitments := make([]kyber.Point, len(epochRec.audTXIDs))
+	for idx, audTXID := range epochRec.audTXIDs {
+		audOnChainTX, err := o.audChain.ReadTX(audTXID)
+		if err != nil {
+			return nil, err
+		}
+		audPlainTX, err := audOnChainTX.ToPlain()
+		if err != nil {
+			return nil, err
+		}
+		accumulatedCommitments[idx] = crypto.KyberSuite.Point()
+		if err = accumulatedCommitments[idx].UnmarshalBinary(audPlainTX.Commitment); err != nil {
+			return nil, err
+		}
+	}
+	report.CommitmentResult = com.VerifyCommitment(accumulatedCommitments)
+	return report, nil
+}
+
+// record hides the transactions of the organization, submits them with their Merkle proofs
+// to its local chain and anchors the Merkle root on the organization chain.
+func (o *Orchestrator) record(org *organization.Organization, txList []*transaction.Plain) (*orgRecord, error) {
+	record := &orgRecord{
+		org:        org,
+		txList:     txList,
+		hashPoints: make([]kyber.Point, len(txList)),
+	}
+	dataBlocks := make([]mt.DataBlock, len(txList))
+	assets := make([]string, len(txList))
+	schemes := make([]crypto.CommitmentScheme, len(txList))
+	rangeProofs := make([][]byte, len(txList))
+	for idx, tx := range txList {
+		hiddenTX, hashPoint, err := org.HideTX(tx)
+		if err != nil {
+			return nil, err
+		}
+		dataBlocks[idx] = transaction.NewLocalCommitmentPlain(hiddenTX.Commitment)
+		assets[idx] = hiddenTX.Asset
+		schemes[idx] = hiddenTX.Scheme
+		rangeProofs[idx] = hiddenTX.RangeProof
+		record.hashPoints[idx] = hashPoint
+	}
+	// a Merkle tree needs at least two leaves, a single commitment is duplicated
+	treeBlocks := dataBlocks
+	if len(treeBlocks) == 1 {
+		treeBlocks = []mt.DataBlock{dataBlocks[0], dataBlocks[0]}
+	}
+	merkleProofs, merkleRoot, err := crypto.GenerateMerkleProofs(treeBlocks)
+	if err != nil {
+		return nil, err
+	}
+	localOnChainTXList := make([]*transaction.LocalOnChain, len(dataBlocks))
+	for idx, dataBlock := range dataBlocks {
+		commitment, err := dataBlock.Serialize()
+		if err != nil {
+			return nil, err
+		}
+		localPlainTX, err := transaction.NewLocalPlainFromProof(commitment, merkleRoot, merkleProofs[idx])
+		if err != nil {
+			return nil, err
+		}
+		localPlainTX.Asset = assets[idx]
+		localPlainTX.Scheme = schemes[idx]
+		localPlainTX.RangeProof = rangeProofs[idx]
+		localOnChainTXList[idx] = localPlainTX.ToOnChain()
+		if err = org.SignTX(localOnChainTXList[idx]); err != nil {
+			return nil, err
+		}
+	}
+	if record.localTXIDs, err = o.localChains[org.ID].SubmitBatchTXs(localOnChainTXList); err != nil {
+		return nil, err
+	}
+	orgOnChainTX := transaction.NewOrgPlain(merkleRoot).ToOnChain()
+	if err = org.SignTX(orgOnChainTX); err != nil {
+		return nil, err
+	}
+	if record.orgTXID, err = o.orgChain.SubmitTX(orgOnChainTX); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// examine verifies the range proofs and the Merkle proofs of the organizations audited by the auditor against the anchored roots,
+// merges the proofs for the committee, and submits the accumulated commitments to the auditor chain.
+// The hash points are removed from the commitments, so the accumulated amounts of all the auditors cancel out.
+func (o *Orchestrator) examine(aud *auditor.Auditor, recordMap map[organization.TypeID]*orgRecord) (
+	[]*orgEvidence, string, error,
+) {
+	var (
+		evidenceList []*orgEvidence
+		amountPoints []kyber.Point
+	)
+	rootDigest := hashing.New(hashing.TagRootDigest)
+	for _, orgID := range aud.AuditedOrgIDs {
+		record, ok := recordMap[orgID]
+		if !ok {
+			continue
+		}
+		orgOnChainTX, err := o.orgChain.ReadTX(record.orgTXID)
+		if err != nil {
+			return nil, "", err
+		}
+		orgPlainTX, err := orgOnChainTX.ToPlain()
+		if err != nil {
+			return nil, "", err
+		}
+		rootDigest.Write(orgPlainTX.MerkleRoot)
+		evidence := &orgEvidence{
+			verdict: &OrgVerdict{
+				OrgID:  orgID,
+				AudID:  aud.ID,
+				NumTXs: len(record.localTXIDs),
+			},
+			orgTXID:    record.orgTXID,
+			dataBlocks: make([]mt.DataBlock, len(record.localTXIDs)),
+		}
+		merkleProofs := make([]*mt.Proof, len(record.localTXIDs))
+		verificationResults := make([]uint, len(record.localTXIDs))
+		for idx, txID := range record.localTXIDs {
+			localOnChainTX, err := o.localChains[orgID].ReadTX(txID)
+			if err != nil {
+				return nil, "", err
+			}
+			localPlainTX, err := localOnChainTX.ToPlain()
+			if err != nil {
+				return nil, "", err
+			}
+			// a commitment out of range could wrap around and fake a zero sum
+			rangeProofResult, err := aud.VerifyRangeProof(*localOnChainTX, record.hashPoints[idx])
+			if err != nil {
+				return nil, "", err
+			}
+			if rangeProofResult == 0 {
+				return nil, "", fmt.Errorf("invalid range proof for transaction %s of organization %s", txID, orgID)
+			}
+			if bytes.Equal(localPlainTX.MerkleRoot, orgPlainTX.MerkleRoot) {
+				if verificationResults[idx], err = aud.VerifyMerkleProof(*localOnChainTX); err != nil {
+					return nil, "", err
+				}
+			}
+			if merkleProofs[idx], err = crypto.MerkleProofUnmarshal(localPlainTX.MerkleProof); err != nil {
+				return nil, "", err
+			}
+			evidence.dataBlocks[idx] = localPlainTX
+			commitment := crypto.KyberSuite.Point()
+			if err = commitment.UnmarshalBinary(localPlainTX.Commitment); err != nil {
+				return nil, "", err
+			}
+			amountPoints = append(amountPoints, commitment.Sub(commitment, record.hashPoints[idx]))
+		}
+		evidence.verdict.MerkleProofResult = aud.SummarizeMerkleProofVerificationResults(verificationResults)
+		if evidence.batchProof, err = aud.MergeProof(evidence.dataBlocks, merkleProofs); err != nil {
+			return nil, "", err
+		}
+		evidenceList = append(evidenceList, evidence)
+	}
+	accumulatedCommitment, err := aud.AccumulateCommitments(amountPoints)
+	if err != nil {
+		return nil, "", err
+	}
+	audPlainTX, err := transaction.NewAudPlainFromPoint(accumulatedCommitment, rootDigest.Sum(nil))
+	if err != nil {
+		return nil, "", err
+	}
+	audOnChainTX := audPlainTX.ToOnChain()
+	if err = aud.SignTX(audOnChainTX); err != nil {
+		return nil, "", err
+	}
+	audTXID, err := o.audChain.SubmitTX(audOnChainTX)
+	if err != nil {
+		return nil, "", err
+	}
+	return evidenceList, audTXID, nil
+}
+
+// verifyBatchProof has the committee verify the merged proof against the root anchored on the organization chain.
+func (o *Orchestrator) verifyBatchProof(com *committee.Committee, evidence *orgEvidence) (bool, error) {
+	orgOnChainTX, err := o.orgChain.ReadTX(evidence.orgTXID)
+	if err != nil {
+		return false, err
+	}
+	orgPlainTX, err := orgOnChainTX.ToPlain()
+	if err != nil {
+		return false, err
+	}
+	batchProof, err := crypto.MerkleBatchProofUnmarshal(evidence.batchProof)
+	if err != nil {
+		return false, err
+	}
+	result, err := com.VerifyMerkleBatchProof(evidence.dataBlocks, batchProof, orgPlainTX.MerkleRoot)
+	if err != nil {
+		return false, err
+	}
+	return com.SummarizeMerkleBatchProofVerificationResults([]uint{result}), nil
+}
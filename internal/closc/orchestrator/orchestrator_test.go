@@ -0,0 +1,387 @@
+package orchestrator
+
+import (
+	"encoding/hex"
+	"testing"
+
+	mt "github.com/txaty/go-merkletree"
+
+	"github.com/auti-project/auti/internal/closc/auditor"
+	"github.com/auti-project/auti/internal/closc/committee"
+	"github.com/auti-project/auti/internal/closc/organization"
+	"github.com/auti-project/auti/internal/closc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+	"github.com/auti-project/auti/internal/ledger"
+	"github.com/auti-project/auti/internal/money"
+)
+
+// tamperedLocalChain returns the transactions modified by the tamper function.
+type tamperedLocalChain struct {
+	ledger.Ledger[*transaction.LocalOnChain]
+	tamper func(tx *transaction.LocalOnChain) error
+}
+
+func (t *tamperedLocalChain) ReadTX(txID string) (*transaction.LocalOnChain, error) {
+	tx, err := t.Ledger.ReadTX(txID)
+	if err != nil {
+		return nil, err
+	}
+	tamperedTX := *tx
+	if err = t.tamper(&tamperedTX); err != nil {
+		return nil, err
+	}
+	return &tamperedTX, nil
+}
+
+// replaceCommitment replaces the commitment by the generator, which has no valid range proof.
+func replaceCommitment(tx *transaction.LocalOnChain) error {
+	commitment, err := crypto.PointG.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	tx.Commitment = hex.EncodeToString(commitment)
+	return nil
+}
+
+// replaceMerkleRoot replaces the Merkle root by the one of a tree of the generator.
+func replaceMerkleRoot(tx *transaction.LocalOnChain) error {
+	commitment, err := crypto.PointG.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	block := transaction.NewLocalCommitmentPlain(commitment)
+	_, merkleRoot, err := crypto.GenerateMerkleProofs([]mt.DataBlock{block, block})
+	if err != nil {
+		return err
+	}
+	tx.MerkleRoot = hex.EncodeToString(merkleRoot)
+	return nil
+}
+
+// newOrchestrator sets up three organizations on in-memory ledgers,
+// aud1 audits org1 and org2, and aud2 audits org3.
+func newOrchestrator(
+	t *testing.T, tamperedOrgID organization.TypeID, tamper func(tx *transaction.LocalOnChain) error,
+) *Orchestrator {
+	organizations := []*organization.Organization{
+		organization.New("org1"),
+		organization.New("org2"),
+		organization.New("org3"),
+	}
+	localChains := make(map[organization.TypeID]ledger.Ledger[*transaction.LocalOnChain])
+	for _, org := range organizations {
+		localChains[org.ID] = organization.NewMemoryLocalChain()
+		if org.ID == tamperedOrgID {
+			localChains[org.ID] = &tamperedLocalChain{Ledger: localChains[org.ID], tamper: tamper}
+		}
+	}
+	auditors := []*auditor.Auditor{
+		auditor.New("aud1", organizations[:2]),
+		auditor.New("aud2", organizations[2:]),
+	}
+	com := committee.New("com", auditors)
+	o, err := New(com, auditors, organizations, localChains,
+		organization.NewMemoryOrgChain(), auditor.NewMemoryAudChain())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return o
+}
+
+func TestOrchestrator_RunEpoch(t *testing.T) {
+	consistentWorkload := func() Workload {
+		workload := make(Workload)
+		workload.AddTransfer("org1", "org2", money.MustParse("100.25", "USD"), 1, 1)
+		workload.AddTransfer("org2", "org1", money.MustParse("20", "USD"), 2, 2)
+		workload.AddTransfer("org1", "org3", money.MustParse("3.5", "USD"), 3, 3)
+		workload.AddTransfer("org3", "org2", money.MustParse("42", "USD"), 4, 4)
+		workload.AddTransfer("org1", "org2", money.MustParse("7", "USD"), 5, 5)
+		return workload
+	}
+	tests := []struct {
+		name                 string
+		workload             func() Workload
+		tamperedOrgID        organization.TypeID
+		tamper               func(tx *transaction.LocalOnChain) error
+		wantConsistent       bool
+		wantCommitmentResult bool
+		// wantOrgConsistent is keyed by the organizations that have transactions
+		wantOrgConsistent map[organization.TypeID]bool
+		wantErr           bool
+	}{
+		{
+			name:                 "test_consistent",
+			workload:             consistentWorkload,
+			wantConsistent:       true,
+			wantCommitmentResult: true,
+			wantOrgConsistent:    map[organization.TypeID]bool{"org1": true, "org2": true, "org3": true},
+			wantErr:              false,
+		},
+		{
+			name: "test_single_transaction",
+			workload: func() Workload {
+				workload := make(Workload)
+				workload.AddTransfer("org1", "org3", money.MustParse("10", "USD"), 1, 1)
+				return workload
+			},
+			wantConsistent:       true,
+			wantCommitmentResult: true,
+			wantOrgConsistent:    map[organization.TypeID]bool{"org1": true, "org3": true},
+			wantErr:              false,
+		},
+		{
+			name: "test_amount_mismatch",
+			workload: func() Workload {
+				workload := consistentWorkload()
+				workload["org3"][0].Amount++
+				return workload
+			},
+			wantConsistent:       false,
+			wantCommitmentResult: false,
+			wantOrgConsistent:    map[organization.TypeID]bool{"org1": true, "org2": true, "org3": true},
+			wantErr:              false,
+		},
+		{
+			name: "test_currency_mismatch",
+			workload: func() Workload {
+				workload := consistentWorkload()
+				workload["org3"][0].Currency = "EUR"
+				return workload
+			},
+			wantConsistent:       false,
+			wantCommitmentResult: false,
+			wantOrgConsistent:    map[organization.TypeID]bool{"org1": true, "org2": true, "org3": true},
+			wantErr:              false,
+		},
+		{
+			name:                 "test_tampered_merkle_root",
+			workload:             consistentWorkload,
+			tamperedOrgID:        "org2",
+			tamper:               replaceMerkleRoot,
+			wantConsistent:       false,
+			wantCommitmentResult: true,
+			wantOrgConsistent:    map[organization.TypeID]bool{"org1": true, "org2": false, "org3": true},
+			wantErr:              false,
+		},
+		{
+			name:          "test_tampered_commitment",
+			workload:      consistentWorkload,
+			tamperedOrgID: "org2",
+			tamper:        replaceCommitment,
+			wantErr:       true,
+		},
+		{
+			name: "test_wrong_sender",
+			workload: func() Workload {
+				workload := consistentWorkload()
+				workload["org1"] = append(workload["org1"], transaction.NewPlain("org2", "org3", money.MustParse("1", "USD"), 6, 6))
+				return workload
+			},
+			wantErr: true,
+		},
+		{
+			name: "test_unknown_receiver",
+			workload: func() Workload {
+				workload := consistentWorkload()
+				workload["org1"] = append(workload["org1"], transaction.NewPlain("org1", "org4", money.MustParse("1", "USD"), 6, 6))
+				return workload
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOrchestrator(t, tt.tamperedOrgID, tt.tamper)
+			report, err := o.RunEpoch(tt.workload())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RunEpoch() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := report.Consistent(); got != tt.wantConsistent {
+				t.Errorf("Consistent() = %v, want %v", got, tt.wantConsistent)
+			}
+			if report.CommitmentResult != tt.wantCommitmentResult {
+				t.Errorf("CommitmentResult = %v, want %v", report.CommitmentResult, tt.wantCommitmentResult)
+			}
+			if len(report.Orgs) != len(tt.wantOrgConsistent) {
+				t.Errorf("RunEpoch() number of organizations = %d, want %d", len(report.Orgs), len(tt.wantOrgConsistent))
+			}
+			for orgID, want := range tt.wantOrgConsistent {
+				verdict := report.Org(orgID)
+				if verdict == nil {
+					t.Errorf("Org(%s) = nil", orgID)
+					continue
+				}
+				if got := verdict.Consistent(); got != want {
+					t.Errorf("Org(%s).Consistent() = %v, want %v, verdict %+v", orgID, got, want, verdict)
+				}
+			}
+		})
+	}
+}
+
+func TestOrchestrator_RunEpochs(t *testing.T) {
+	schedule, err := epoch.NewSchedule(0, 10)
+	if err != nil {
+		t.Fatalf("NewSchedule() error = %v", err)
+	}
+	// epoch 0 has an amount mismatch between org2 and org3
+	workload := make(Workload)
+	workload.AddTransfer("org1", "org2", money.MustParse("100.25", "USD"), 1, 1)
+	workload.AddTransfer("org2", "org3", money.MustParse("42", "USD"), 2, 2)
+	workload["org3"][0].Amount++
+	workload.AddTransfer("org1", "org3", money.MustParse("3.5", "USD"), 3, 12)
+	o := newOrchestrator(t, "", nil)
+	reports, err := o.RunEpochs(schedule, workload)
+	if err != nil {
+		t.Fatalf("RunEpochs() error = %v", err)
+	}
+	wantCommitmentResults := []bool{false, true}
+	if len(reports) != len(wantCommitmentResults) {
+		t.Fatalf("RunEpochs() number of reports = %d, want %d", len(reports), len(wantCommitmentResults))
+	}
+	for idx, report := range reports {
+		if report.EpochID != epoch.TypeID(idx) {
+			t.Errorf("RunEpochs() epoch ID = %d, want %d", report.EpochID, idx)
+		}
+		// the committee re-verifies the past epochs with the archived auditor epoch IDs
+		reverified, err := o.Reverify(report.EpochID)
+		if err != nil {
+			t.Fatalf("Reverify() error = %v", err)
+		}
+		for _, r := range []*Report{report, reverified} {
+			if r.CommitmentResult != wantCommitmentResults[idx] || r.Consistent() != wantCommitmentResults[idx] {
+				t.Errorf("epoch %d CommitmentResult = %v, Consistent() = %v, want %v",
+					r.EpochID, r.CommitmentResult, r.Consistent(), wantCommitmentResults[idx])
+			}
+		}
+		if o.EpochReport(report.EpochID) != report {
+			t.Errorf("EpochReport(%d) does not return the report of the epoch", report.EpochID)
+		}
+	}
+	// the epochs must follow the last one
+	workload = make(Workload)
+	workload.AddTransfer("org1", "org2", money.MustParse("1", "USD"), 4, 5)
+	if _, err = o.RunEpochs(schedule, workload); err == nil {
+		t.Errorf("RunEpochs() of a past epoch error = nil, wantErr true")
+	}
+}
+
+func TestOrchestrator_Pinpoint(t *testing.T) {
+	tests := []struct {
+		name     string
+		workload func() Workload
+		// wantDivergences are the pairs of organizations and the counters,
+		// a counter mismatch passes the epoch check as the amounts still cancel
+		wantDivergences []divergence
+	}{
+		{
+			name: "test_consistent",
+			workload: func() Workload {
+				workload := make(Workload)
+				workload.AddTransfer("org1", "org2", money.MustParse("100.25", "USD"), 1, 1)
+				workload.AddTransfer("org3", "org1", money.MustParse("3.5", "USD"), 2, 2)
+				return workload
+			},
+		},
+		{
+			name: "test_amount_mismatch",
+			workload: func() Workload {
+				workload := make(Workload)
+				workload.AddTransfer("org1", "org2", money.MustParse("100.25", "USD"), 1, 1)
+				workload.AddTransfer("org2", "org3", money.MustParse("42", "USD"), 2, 2)
+				workload.AddTransfer("org3", "org1", money.MustParse("3.5", "USD"), 3, 3)
+				workload["org3"][0].Amount++
+				return workload
+			},
+			wantDivergences: []divergence{{"org2", "org3", 2}},
+		},
+		{
+			name: "test_missing_transaction",
+			workload: func() Workload {
+				workload := make(Workload)
+				workload.AddTransfer("org1", "org2", money.MustParse("100.25", "USD"), 1, 1)
+				workload.AddTransfer("org2", "org3", money.MustParse("42", "USD"), 2, 2)
+				workload["org1"] = append(workload["org1"], transaction.NewPlain("org1", "org3", money.MustParse("7", "USD"), 3, 3))
+				return workload
+			},
+			wantDivergences: []divergence{{"org1", "org3", 3}},
+		},
+		{
+			name: "test_counter_mismatch",
+			workload: func() Workload {
+				workload := make(Workload)
+				workload.AddTransfer("org1", "org2", money.MustParse("100.25", "USD"), 1, 1)
+				workload.AddTransfer("org1", "org2", money.MustParse("5", "USD"), 2, 2)
+				workload["org2"][1].Counter = 3
+				return workload
+			},
+			wantDivergences: []divergence{{"org1", "org2", 2}, {"org1", "org2", 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOrchestrator(t, "", nil)
+			if _, err := o.RunEpoch(tt.workload()); err != nil {
+				t.Fatalf("RunEpoch() error = %v", err)
+			}
+			evidence, err := o.Pinpoint()
+			if err != nil {
+				t.Fatalf("Pinpoint() error = %v", err)
+			}
+			if len(evidence.Divergences) != len(tt.wantDivergences) {
+				t.Fatalf("Pinpoint() number of divergences = %d, want %d",
+					len(evidence.Divergences), len(tt.wantDivergences))
+			}
+			for idx, got := range evidence.Divergences {
+				if (divergence{got.OrgID1, got.OrgID2, got.Counter}) != tt.wantDivergences[idx] {
+					t.Errorf("Pinpoint() divergence = %s, %s, %d, want %v",
+						got.OrgID1, got.OrgID2, got.Counter, tt.wantDivergences[idx])
+				}
+			}
+			merkleRoots, err := o.AnchoredRoots()
+			if err != nil {
+				t.Fatalf("AnchoredRoots() error = %v", err)
+			}
+			signingPublicKeys := make(map[organization.TypeID]crypto.TypePublicKey)
+			for _, org := range o.organizations {
+				signingPublicKeys[org.ID] = org.SigningPublicKey
+			}
+			ok, err := committee.VerifyPinpointEvidence(evidence, merkleRoots, signingPublicKeys)
+			if err != nil || ok != (len(tt.wantDivergences) > 0) {
+				t.Errorf("VerifyPinpointEvidence() = %v, %v, want %v", ok, err, len(tt.wantDivergences) > 0)
+			}
+			// the disclosed inputs of the hash points are signed by the senders
+			if len(tt.wantDivergences) > 0 {
+				disclosedTX := evidence.Divergences[0].TX1
+				if disclosedTX == nil {
+					disclosedTX = evidence.Divergences[0].TX2
+				}
+				disclosedTX.Timestamp++
+				if ok, _ = committee.VerifyPinpointEvidence(evidence, merkleRoots, signingPublicKeys); ok {
+					t.Errorf("VerifyPinpointEvidence() with a tampered timestamp = %v, want %v", ok, false)
+				}
+				disclosedTX.Timestamp--
+			}
+			// the evidence does not verify against the roots of another epoch
+			if len(tt.wantDivergences) > 0 {
+				for orgID := range merkleRoots {
+					merkleRoots[orgID] = make([]byte, len(merkleRoots[orgID]))
+				}
+				if ok, _ = committee.VerifyPinpointEvidence(evidence, merkleRoots, signingPublicKeys); ok {
+					t.Errorf("VerifyPinpointEvidence() with wrong roots = %v, want %v", ok, false)
+				}
+			}
+		})
+	}
+}
+
+type divergence struct {
+	orgID1  organization.TypeID
+	orgID2  organization.TypeID
+	counter uint64
+}
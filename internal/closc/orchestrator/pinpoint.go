@@ -0,0 +1,60 @@
+package orchestrator
+
+import (
+	"errors"
+
+	"github.com/auti-project/auti/internal/closc/committee"
+	"github.com/auti-project/auti/internal/closc/organization"
+)
+
+// Pinpoint runs the dispute resolution of the last epoch, the organizations disclose and sign the receivers,
+// counters and timestamps of their transactions to the committee, which reports the diverging counters.
+// The evidence is empty if the commitments of the epoch sum up.
+func (o *Orchestrator) Pinpoint() (*committee.PinpointEvidence, error) {
+	if o.recordMap == nil {
+		return nil, errors.New("no epoch to pinpoint")
+	}
+	var txList []*committee.DisclosedTX
+	for _, org := range o.organizations {
+		record, ok := o.recordMap[org.ID]
+		if !ok {
+			continue
+		}
+		for idx, txID := range record.localTXIDs {
+			localOnChainTX, err := o.localChains[org.ID].ReadTX(txID)
+			if err != nil {
+				return nil, err
+			}
+			plainTX := record.txList[idx]
+			disclosedTX := &committee.DisclosedTX{
+				Sender:    org.ID,
+				Receiver:  organization.TypeID(plainTX.Receiver),
+				Counter:   plainTX.Counter,
+				Timestamp: plainTX.Timestamp,
+				TX:        localOnChainTX,
+			}
+			if err = org.SignTX(disclosedTX); err != nil {
+				return nil, err
+			}
+			txList = append(txList, disclosedTX)
+		}
+	}
+	return o.committee.PinpointInconsistency(txList)
+}
+
+// AnchoredRoots returns the Merkle roots the organizations anchored on the organization chain in the last epoch.
+func (o *Orchestrator) AnchoredRoots() (map[organization.TypeID][]byte, error) {
+	merkleRoots := make(map[organization.TypeID][]byte)
+	for orgID, record := range o.recordMap {
+		orgOnChainTX, err := o.orgChain.ReadTX(record.orgTXID)
+		if err != nil {
+			return nil, err
+		}
+		orgPlainTX, err := orgOnChainTX.ToPlain()
+		if err != nil {
+			return nil, err
+		}
+		merkleRoots[orgID] = orgPlainTX.MerkleRoot
+	}
+	return merkleRoots, nil
+}
@@ -0,0 +1,55 @@
+package orchestrator
+
+import (
+	"github.com/auti-project/auti/internal/closc/auditor"
+	"github.com/auti-project/auti/internal/closc/organization"
+	"github.com/auti-project/auti/internal/epoch"
+)
+
+// OrgVerdict is the outcome of the Merkle proof checks on the transactions of an organization in an epoch.
+// MerkleProofResult is the result of the auditor verifying every local chain transaction against
+// the Merkle root anchored on the organization chain, BatchProofResult is the result of the committee
+// verifying the proof merged by the auditor against the same root.
+type OrgVerdict struct {
+	OrgID             organization.TypeID
+	AudID             auditor.TypeID
+	NumTXs            int
+	MerkleProofResult bool
+	BatchProofResult  bool
+}
+
+// Consistent returns true if both the auditor and the committee accepted the transactions of the organization.
+func (v *OrgVerdict) Consistent() bool {
+	return v.MerkleProofResult && v.BatchProofResult
+}
+
+// Report has a verdict for every organization that recorded transactions in the epoch
+// and the result of Committee.VerifyCommitment on the accumulated commitments of all the auditors.
+type Report struct {
+	EpochID          epoch.TypeID
+	Orgs             []*OrgVerdict
+	CommitmentResult bool
+}
+
+// Consistent returns true if the commitments sum up and the verdicts of all the organizations are consistent.
+func (r *Report) Consistent() bool {
+	if !r.CommitmentResult {
+		return false
+	}
+	for _, verdict := range r.Orgs {
+		if !verdict.Consistent() {
+			return false
+		}
+	}
+	return true
+}
+
+// Org returns the verdict of the organization, or nil if it has no transactions.
+func (r *Report) Org(orgID organization.TypeID) *OrgVerdict {
+	for _, verdict := range r.Orgs {
+		if verdict.OrgID == orgID {
+			return verdict
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package organization
+
+import (
+	"testing"
+
+	"github.com/auti-project/auti/internal/closc/transaction"
+	"github.com/auti-project/auti/internal/money"
+)
+
+func TestNewMemoryLocalChain(t *testing.T) {
+	tests := []struct {
+		name    string
+		sign    bool
+		tamper  func(tx *transaction.LocalOnChain)
+		wantErr bool
+	}{
+		{
+			name:    "test_signed",
+			sign:    true,
+			wantErr: false,
+		},
+		{
+			name:    "test_unsigned",
+			sign:    false,
+			wantErr: true,
+		},
+		{
+			name: "test_tampered_after_signing",
+			sign: true,
+			tamper: func(tx *transaction.LocalOnChain) {
+				tx.MerkleRoot = "00"
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := New("org1")
+			hiddenTX, _, err := transaction.NewPlain("org1", "org2", money.MustParse("5", "USD"), 1, 1).Hide()
+			if err != nil {
+				t.Fatalf("Hide() error = %v", err)
+			}
+			tx := transaction.NewLocalPlain(hiddenTX.Commitment, []byte("merkle_root"), []byte("merkle_proof")).ToOnChain()
+			if tt.sign {
+				if err = org.SignTX(tx); err != nil {
+					t.Fatalf("SignTX() error = %v", err)
+				}
+			}
+			if tt.tamper != nil {
+				tt.tamper(tx)
+			}
+			_, err = NewMemoryLocalChain().SubmitTX(tx)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SubmitTX() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
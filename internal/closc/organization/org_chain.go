@@ -0,0 +1,14 @@
+package organization
+
+import (
+	"github.com/auti-project/auti/internal/closc/transaction"
+	"github.com/auti-project/auti/internal/ledger"
+)
+
+// NewMemoryOrgChain returns an in-memory stand-in for the organization chain,
+// it rejects the transactions without a valid signature like the chaincode.
+func NewMemoryOrgChain() *ledger.Memory[transaction.OrgOnChain, *transaction.OrgOnChain] {
+	return ledger.NewMemory(func(tx *transaction.OrgOnChain) error {
+		return ledger.VerifySignature(tx.Signer, tx.Signature, tx.SigningMessage())
+	})
+}
@@ -1,26 +1,48 @@
 package organization
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
-)
+	"crypto/cipher"
+
+	"go.dedis.ch/kyber/v3"
 
-var sha256Func = sha256.New()
+	"github.com/auti-project/auti/internal/closc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/hashing"
+)
 
 type TypeID string
 
 type Organization struct {
-	ID     TypeID
-	IDHash string
+	ID               TypeID
+	IDHash           string
+	SigningPublicKey crypto.TypePublicKey
+	signingKey       crypto.TypePrivateKey
+	randStream       cipher.Stream
 }
 
 func New(id string) *Organization {
-	defer sha256Func.Reset()
-	sha256Func.Write([]byte(id))
-	idHash := hex.EncodeToString(sha256Func.Sum(nil))
+	return NewWithRandStream(id, crypto.RandomStream())
+}
+
+// NewWithRandStream creates an organization drawing all its randomness from the given stream,
+// use crypto.NewSeededStream for reproducible runs.
+func NewWithRandStream(id string, rand cipher.Stream) *Organization {
 	org := &Organization{
-		ID:     TypeID(id),
-		IDHash: idHash,
+		ID:         TypeID(id),
+		IDHash:     hashing.SumHex(hashing.TagOrgID, []byte(id)),
+		randStream: rand,
 	}
+	org.signingKey, org.SigningPublicKey = crypto.SigningKeyGen(rand)
 	return org
 }
+
+// SignTX signs the on-chain transaction with the long-term signing key of the organization.
+func (o *Organization) SignTX(tx crypto.Signable) error {
+	return tx.Sign(o.signingKey, o.randStream)
+}
+
+// HideTX hides the transaction with a range proof drawn from the randomness of the organization,
+// the hash point is handed to the auditor off-chain.
+func (o *Organization) HideTX(tx *transaction.Plain) (*transaction.Hidden, kyber.Point, error) {
+	return tx.HideWithRangeProof(o.randStream)
+}
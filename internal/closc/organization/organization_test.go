@@ -0,0 +1,59 @@
+package organization
+
+import (
+	"testing"
+
+	"github.com/auti-project/auti/internal/closc/transaction"
+	"github.com/auti-project/auti/internal/money"
+)
+
+func TestOrganization_HideTX(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(localPlainTX *transaction.LocalPlain)
+		want   bool
+	}{
+		{
+			name: "test_valid",
+			want: true,
+		},
+		{
+			name: "test_other_asset",
+			tamper: func(localPlainTX *transaction.LocalPlain) {
+				localPlainTX.Asset = "EUR"
+			},
+			want: false,
+		},
+		{
+			name: "test_no_range_proof",
+			tamper: func(localPlainTX *transaction.LocalPlain) {
+				localPlainTX.RangeProof = nil
+			},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			org := New("org1")
+			tx := transaction.NewPlain("org1", "org2", money.MustParse("-100.25", "USD"), 1, 1)
+			hiddenTX, hashPoint, err := org.HideTX(tx)
+			if err != nil {
+				t.Fatalf("HideTX() error = %v", err)
+			}
+			localPlainTX := transaction.NewLocalPlain(hiddenTX.Commitment, []byte("merkle_root"), []byte("merkle_proof"))
+			localPlainTX.Asset = hiddenTX.Asset
+			localPlainTX.Scheme = hiddenTX.Scheme
+			localPlainTX.RangeProof = hiddenTX.RangeProof
+			if tt.tamper != nil {
+				tt.tamper(localPlainTX)
+			}
+			got, err := localPlainTX.VerifyRangeProof(hashPoint)
+			if err != nil {
+				t.Fatalf("VerifyRangeProof() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("VerifyRangeProof() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
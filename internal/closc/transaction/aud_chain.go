@@ -1,11 +1,13 @@
 package transaction
 
 import (
-	"crypto/sha256"
+	"crypto/cipher"
 	"encoding/hex"
-	"encoding/json"
 
 	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/codec"
+	"github.com/auti-project/auti/internal/crypto"
 )
 
 type AudPlain struct {
@@ -20,6 +22,39 @@ func NewAudPlain(commitment, hash []byte) *AudPlain {
 	}
 }
 
+// MarshalBinary returns the canonical binary encoding of the transaction.
+func (a *AudPlain) MarshalBinary() ([]byte, error) {
+	return codec.NewEncoder(codec.TypeCLOSCAudPlain).
+		WriteBytes(a.Commitment).
+		WriteBytes(a.Hash).
+		Bytes()
+}
+
+func (a *AudPlain) UnmarshalBinary(data []byte) error {
+	dec, err := codec.NewDecoder(data, codec.TypeCLOSCAudPlain)
+	if err != nil {
+		return err
+	}
+	decoded := AudPlain{
+		Commitment: dec.ReadBytes(),
+		Hash:       dec.ReadBytes(),
+	}
+	if err = dec.Finish(); err != nil {
+		return err
+	}
+	*a = decoded
+	return nil
+}
+
+// ContentID is the hash of the canonical binary encoding, it does not depend on the JSON form.
+func (a *AudPlain) ContentID() (string, error) {
+	encoding, err := a.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return codec.ContentID(encoding), nil
+}
+
 func NewAudPlainFromPoint(commitment kyber.Point, hash []byte) (*AudPlain, error) {
 	commitmentBytes, err := commitment.MarshalBinary()
 	if err != nil {
@@ -35,6 +70,8 @@ func (a *AudPlain) ToOnChain() *AudOnChain {
 type AudOnChain struct {
 	Commitment string `json:"commitment"`
 	Hash       string `json:"hash"`
+	Signer     string `json:"signer,omitempty"`
+	Signature  string `json:"signature,omitempty"`
 }
 
 func NewAudOnChain(commitment, hash string) *AudOnChain {
@@ -56,12 +93,76 @@ func (a *AudOnChain) ToPlain() (*AudPlain, error) {
 	return NewAudPlain(commitment, hash), nil
 }
 
+// MarshalBinary returns the canonical binary encoding of the transaction with the signature,
+// the JSON form is only for reading.
+func (a *AudOnChain) MarshalBinary() ([]byte, error) {
+	return codec.NewEncoder(codec.TypeCLOSCAudOnChain).
+		WriteBytes([]byte(a.Commitment)).
+		WriteBytes([]byte(a.Hash)).
+		WriteBytes([]byte(a.Signer)).
+		WriteBytes([]byte(a.Signature)).
+		Bytes()
+}
+
+func (a *AudOnChain) UnmarshalBinary(data []byte) error {
+	dec, err := codec.NewDecoder(data, codec.TypeCLOSCAudOnChain)
+	if err != nil {
+		return err
+	}
+	decoded := AudOnChain{
+		Commitment: string(dec.ReadBytes()),
+		Hash:       string(dec.ReadBytes()),
+		Signer:     string(dec.ReadBytes()),
+		Signature:  string(dec.ReadBytes()),
+	}
+	if err = dec.Finish(); err != nil {
+		return err
+	}
+	*a = decoded
+	return nil
+}
+
+// ContentID is the hash of the canonical binary encoding of the transaction without the signature,
+// it is the key of the transaction on the ledger and does not change if the transaction is signed again.
+func (a *AudOnChain) ContentID() (string, error) {
+	unsignedTX := *a
+	unsignedTX.Signature = ""
+	encoding, err := unsignedTX.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return codec.ContentID(encoding), nil
+}
+
+// KeyVal returns the content ID and the canonical binary encoding submitted to the chaincode.
 func (a *AudOnChain) KeyVal() (string, []byte, error) {
-	txJSON, err := json.Marshal(a)
+	key, err := a.ContentID()
 	if err != nil {
 		return "", nil, err
 	}
-	sha256Func := sha256.New()
-	sha256Func.Write(txJSON)
-	return hex.EncodeToString(sha256Func.Sum(nil)), txJSON, nil
+	val, err := a.MarshalBinary()
+	if err != nil {
+		return "", nil, err
+	}
+	return key, val, nil
+}
+
+// SigningMessage returns the canonical encoding of the transaction covered by the signature.
+func (a *AudOnChain) SigningMessage() []byte {
+	return crypto.SigningMessage(audSigningTag, a.Commitment, a.Hash, a.Signer)
+}
+
+// Sign sets the signer to the public key of the signing key and signs the transaction.
+func (a *AudOnChain) Sign(signingKey kyber.Scalar, rand cipher.Stream) error {
+	var err error
+	if a.Signer, err = crypto.SignerHex(signingKey); err != nil {
+		return err
+	}
+	a.Signature, err = crypto.SignHex(signingKey, a.SigningMessage(), rand)
+	return err
+}
+
+// VerifySignature returns false if the transaction is unsigned, forged, or not signed by the public key.
+func (a *AudOnChain) VerifySignature(publicKey kyber.Point) (bool, error) {
+	return verifySignedBy(publicKey, a.Signer, a.Signature, a.SigningMessage())
 }
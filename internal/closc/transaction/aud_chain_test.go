@@ -0,0 +1,24 @@
+package transaction
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAudPlain_MarshalBinary(t *testing.T) {
+	tx := NewAudPlain([]byte("commitment"), []byte("hash"))
+	encoding, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() error = %v", err)
+	}
+	decoded := new(AudPlain)
+	if err = decoded.UnmarshalBinary(encoding); err != nil {
+		t.Fatalf("UnmarshalBinary() error = %v", err)
+	}
+	if !reflect.DeepEqual(decoded, tx) {
+		t.Errorf("UnmarshalBinary() = %v, want %v", decoded, tx)
+	}
+	if err = new(LocalPlain).UnmarshalBinary(encoding); err == nil {
+		t.Errorf("UnmarshalBinary() error = %v, wantErr %v", err, true)
+	}
+}
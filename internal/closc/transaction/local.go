@@ -1,72 +1,59 @@
 package transaction
 
 import (
-	"crypto/sha256"
+	"crypto/cipher"
 
 	"go.dedis.ch/kyber/v3"
 
+	"github.com/auti-project/auti/internal/constants"
 	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/hashing"
+	"github.com/auti-project/auti/internal/money"
 )
 
-const amountAmplifier = 100
-
-// Plain is the struct for plaintext transaction
+// Plain is the struct for plaintext transaction, the amount is in minor units of the currency
 type Plain struct {
 	Sender    string
 	Receiver  string
 	Amount    int64
+	Currency  string
 	Counter   uint64
 	Timestamp int64
 }
 
 // NewPlain creates a new plaintext transaction
-func NewPlain(sender, receiver string, amount float64, counter uint64, timestamp int64) *Plain {
-	amountInt := int64(amount * amountAmplifier)
+func NewPlain(sender, receiver string, amount money.Amount, counter uint64, timestamp int64) *Plain {
 	return &Plain{
 		Sender:    sender,
 		Receiver:  receiver,
-		Amount:    amountInt,
+		Amount:    amount.MinorUnits(),
+		Currency:  amount.Currency().Code,
 		Counter:   counter,
 		Timestamp: timestamp,
 	}
 }
 
-func NewPairPlain(sender, receiver string, amount float64, counter uint64, timestamp int64) (*Plain, *Plain) {
-	amountInt := int64(amount * amountAmplifier)
-	return &Plain{
-			Sender:    sender,
-			Receiver:  receiver,
-			Amount:    amountInt,
-			Counter:   counter,
-			Timestamp: timestamp,
-		}, &Plain{
-			Sender:    receiver,
-			Receiver:  sender,
-			Amount:    -amountInt,
-			Counter:   counter,
-			Timestamp: timestamp,
-		}
+func NewPairPlain(sender, receiver string, amount money.Amount, counter uint64, timestamp int64) (*Plain, *Plain) {
+	return NewPlain(sender, receiver, amount, counter, timestamp),
+		NewPlain(receiver, sender, amount.Neg(), counter, timestamp)
 }
 
-// Hidden is the struct for hidden transaction
+// Hidden is the struct for hidden transaction, the asset code and the scheme of the commitment are public
 type Hidden struct {
 	Sender     []byte
 	Receiver   []byte
 	Commitment []byte
+	Asset      string
+	Scheme     crypto.CommitmentScheme
 	Timestamp  int64
+	RangeProof []byte
 }
 
 func (p *Plain) Hide() (*Hidden, kyber.Point, error) {
-	// sender hash
-	sha256Func := sha256.New()
-	sha256Func.Write([]byte(p.Sender))
-	senderHash := sha256Func.Sum(nil)
-	// receiver hash
-	sha256Func.Reset()
-	sha256Func.Write([]byte(p.Receiver))
-	receiverHash := sha256Func.Sum(nil)
+	senderHash := hashing.Sum(hashing.TagOrgID, []byte(p.Sender))
+	receiverHash := hashing.Sum(hashing.TagOrgID, []byte(p.Receiver))
 	commitment, hashPoint, err := crypto.PedersonCommitWithHash(
-		p.Amount, p.Timestamp, receiverHash, p.Counter,
+		crypto.DefaultCommitmentScheme, p.Currency, p.Amount, p.Timestamp, receiverHash, p.Counter,
 	)
 	if err != nil {
 		return nil, nil, err
@@ -75,7 +62,10 @@ func (p *Plain) Hide() (*Hidden, kyber.Point, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	return NewHidden(senderHash, receiverHash, commitmentBytes, p.Timestamp), hashPoint, nil
+	hidden := NewHidden(senderHash, receiverHash, commitmentBytes, p.Timestamp)
+	hidden.Asset = p.Currency
+	hidden.Scheme = crypto.DefaultCommitmentScheme
+	return hidden, hashPoint, nil
 }
 
 func NewHidden(sender, receiver, commitment []byte, timestamp int64) *Hidden {
@@ -86,3 +76,23 @@ func NewHidden(sender, receiver, commitment []byte, timestamp int64) *Hidden {
 		Timestamp:  timestamp,
 	}
 }
+
+// HideWithRangeProof hides the transaction and attaches a range proof showing that
+// the commitment opens to a signed amount of constants.RangeProofBitLen bits.
+func (p *Plain) HideWithRangeProof(rand cipher.Stream) (*Hidden, kyber.Point, error) {
+	hidden, hashPoint, err := p.Hide()
+	if err != nil {
+		return nil, nil, err
+	}
+	rangeProof, err := crypto.PedersonCommitWithHashRangeProof(
+		hidden.Scheme, p.Currency, p.Amount, p.Timestamp, hidden.Receiver, p.Counter, constants.RangeProofBitLen, rand,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	hidden.RangeProof, err = rangeProof.Serialize()
+	if err != nil {
+		return nil, nil, err
+	}
+	return hidden, hashPoint, nil
+}
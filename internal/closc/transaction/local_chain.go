@@ -1,12 +1,15 @@
 package transaction
 
 import (
-	"crypto/sha256"
+	"crypto/cipher"
 	"encoding/hex"
-	"encoding/json"
+	"strconv"
 
 	mt "github.com/txaty/go-merkletree"
+	"go.dedis.ch/kyber/v3"
 
+	"github.com/auti-project/auti/internal/codec"
+	"github.com/auti-project/auti/internal/constants"
 	"github.com/auti-project/auti/internal/crypto"
 )
 
@@ -30,6 +33,8 @@ func (l *LocalCommitmentPlain) ToOnChain() *LocalCommitmentOnChain {
 
 type LocalCommitmentOnChain struct {
 	Commitment string `json:"commitment"`
+	Signer     string `json:"signer,omitempty"`
+	Signature  string `json:"signature,omitempty"`
 }
 
 func NewLocalCommitmentOnChain(commitment string) *LocalCommitmentOnChain {
@@ -46,20 +51,88 @@ func (l *LocalCommitmentOnChain) ToPlain() (*LocalCommitmentPlain, error) {
 	return NewLocalCommitmentPlain(commitment), nil
 }
 
+// MarshalBinary returns the canonical binary encoding of the transaction with the signature,
+// the JSON form is only for reading.
+func (l *LocalCommitmentOnChain) MarshalBinary() ([]byte, error) {
+	return codec.NewEncoder(codec.TypeCLOSCLocalCommitmentOnChain).
+		WriteBytes([]byte(l.Commitment)).
+		WriteBytes([]byte(l.Signer)).
+		WriteBytes([]byte(l.Signature)).
+		Bytes()
+}
+
+func (l *LocalCommitmentOnChain) UnmarshalBinary(data []byte) error {
+	dec, err := codec.NewDecoder(data, codec.TypeCLOSCLocalCommitmentOnChain)
+	if err != nil {
+		return err
+	}
+	decoded := LocalCommitmentOnChain{
+		Commitment: string(dec.ReadBytes()),
+		Signer:     string(dec.ReadBytes()),
+		Signature:  string(dec.ReadBytes()),
+	}
+	if err = dec.Finish(); err != nil {
+		return err
+	}
+	*l = decoded
+	return nil
+}
+
+// ContentID is the hash of the canonical binary encoding of the transaction without the signature,
+// it is the key of the transaction on the ledger and does not change if the transaction is signed again.
+func (l *LocalCommitmentOnChain) ContentID() (string, error) {
+	unsignedTX := *l
+	unsignedTX.Signature = ""
+	encoding, err := unsignedTX.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return codec.ContentID(encoding), nil
+}
+
+// KeyVal returns the content ID and the canonical binary encoding submitted to the chaincode.
 func (l *LocalCommitmentOnChain) KeyVal() (string, []byte, error) {
-	txJSON, err := json.Marshal(l)
+	key, err := l.ContentID()
 	if err != nil {
 		return "", nil, err
 	}
-	sha256Func := sha256.New()
-	sha256Func.Write(txJSON)
-	return hex.EncodeToString(sha256Func.Sum(nil)), txJSON, nil
+	val, err := l.MarshalBinary()
+	if err != nil {
+		return "", nil, err
+	}
+	return key, val, nil
+}
+
+// SigningMessage returns the canonical encoding of the transaction covered by the signature.
+func (l *LocalCommitmentOnChain) SigningMessage() []byte {
+	return crypto.SigningMessage(localCommitmentSigningTag, l.Commitment, l.Signer)
 }
 
+// Sign sets the signer to the public key of the signing key and signs the transaction.
+func (l *LocalCommitmentOnChain) Sign(signingKey kyber.Scalar, rand cipher.Stream) error {
+	var err error
+	if l.Signer, err = crypto.SignerHex(signingKey); err != nil {
+		return err
+	}
+	l.Signature, err = crypto.SignHex(signingKey, l.SigningMessage(), rand)
+	return err
+}
+
+// VerifySignature returns false if the transaction is unsigned, forged, or not signed by the public key.
+func (l *LocalCommitmentOnChain) VerifySignature(publicKey kyber.Point) (bool, error) {
+	return verifySignedBy(publicKey, l.Signer, l.Signature, l.SigningMessage())
+}
+
+// LocalPlain is a commitment on the local chain with its Merkle proof,
+// the asset code of the commitment is public so that its range proof can be verified,
+// and the scheme tells how the hash point of the commitment is derived.
 type LocalPlain struct {
 	Commitment  []byte
+	Asset       string
+	Scheme      crypto.CommitmentScheme
 	MerkleRoot  []byte
 	MerkleProof []byte
+	RangeProof  []byte
 }
 
 func NewLocalPlain(commitment, merkleRoot, merkleProof []byte) *LocalPlain {
@@ -70,6 +143,47 @@ func NewLocalPlain(commitment, merkleRoot, merkleProof []byte) *LocalPlain {
 	}
 }
 
+// MarshalBinary returns the canonical binary encoding of the transaction.
+func (l *LocalPlain) MarshalBinary() ([]byte, error) {
+	return codec.NewEncoder(codec.TypeCLOSCLocalPlain).
+		WriteBytes(l.Commitment).
+		WriteBytes([]byte(l.Asset)).
+		WriteInt64(int64(l.Scheme)).
+		WriteBytes(l.MerkleRoot).
+		WriteBytes(l.MerkleProof).
+		WriteBytes(l.RangeProof).
+		Bytes()
+}
+
+func (l *LocalPlain) UnmarshalBinary(data []byte) error {
+	dec, err := codec.NewDecoder(data, codec.TypeCLOSCLocalPlain)
+	if err != nil {
+		return err
+	}
+	decoded := LocalPlain{
+		Commitment:  dec.ReadBytes(),
+		Asset:       string(dec.ReadBytes()),
+		Scheme:      crypto.CommitmentScheme(dec.ReadInt64()),
+		MerkleRoot:  dec.ReadBytes(),
+		MerkleProof: dec.ReadBytes(),
+		RangeProof:  dec.ReadBytes(),
+	}
+	if err = dec.Finish(); err != nil {
+		return err
+	}
+	*l = decoded
+	return nil
+}
+
+// ContentID is the hash of the canonical binary encoding, it does not depend on the JSON form.
+func (l *LocalPlain) ContentID() (string, error) {
+	encoding, err := l.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return codec.ContentID(encoding), nil
+}
+
 func NewLocalPlainFromProof(commitment, merkleRoot []byte, merkleProof *mt.Proof) (*LocalPlain, error) {
 	merkleProofJSON, err := crypto.MerkleProofMarshal(merkleProof)
 	if err != nil {
@@ -82,18 +196,51 @@ func (l *LocalPlain) Serialize() ([]byte, error) {
 	return l.Commitment, nil
 }
 
+// VerifyRangeProof checks the attached range proof against the commitment,
+// a transaction without a range proof does not verify.
+// The hash point of the transaction is needed for crypto.CommitmentSchemeHashToCurve and ignored otherwise.
+func (l *LocalPlain) VerifyRangeProof(hashPoint kyber.Point) (bool, error) {
+	if len(l.RangeProof) == 0 {
+		return false, nil
+	}
+	commitment := crypto.KyberSuite.Point()
+	if err := commitment.UnmarshalBinary(l.Commitment); err != nil {
+		return false, err
+	}
+	rangeProof, err := crypto.DeserializeRangeProof(l.RangeProof)
+	if err != nil {
+		return false, err
+	}
+	if rangeProof.BitLen != constants.RangeProofBitLen {
+		return false, nil
+	}
+	return crypto.VerifyCommitWithHashRangeProof(l.Scheme, l.Asset, commitment, hashPoint, rangeProof)
+}
+
 func (l *LocalPlain) ToOnChain() *LocalOnChain {
-	return NewLocalOnChain(
+	onChainTX := NewLocalOnChain(
 		hex.EncodeToString(l.Commitment),
 		hex.EncodeToString(l.MerkleRoot),
 		hex.EncodeToString(l.MerkleProof),
 	)
+	onChainTX.Asset = l.Asset
+	// the entries of the first scheme have no scheme on chain
+	if l.Scheme != crypto.CommitmentSchemeHashScalar {
+		onChainTX.Scheme = strconv.Itoa(int(l.Scheme))
+	}
+	onChainTX.RangeProof = hex.EncodeToString(l.RangeProof)
+	return onChainTX
 }
 
 type LocalOnChain struct {
 	Commitment  string `json:"commitment"`
+	Asset       string `json:"asset,omitempty"`
+	Scheme      string `json:"scheme,omitempty"`
 	MerkleRoot  string `json:"merkle_root"`
 	MerkleProof string `json:"merkle_proof"`
+	RangeProof  string `json:"range_proof,omitempty"`
+	Signer      string `json:"signer,omitempty"`
+	Signature   string `json:"signature,omitempty"`
 }
 
 func NewLocalOnChain(commitment, merkleRoot, merkleProof string) *LocalOnChain {
@@ -104,14 +251,88 @@ func NewLocalOnChain(commitment, merkleRoot, merkleProof string) *LocalOnChain {
 	}
 }
 
+// MarshalBinary returns the canonical binary encoding of the transaction with the signature,
+// the JSON form is only for reading.
+func (l *LocalOnChain) MarshalBinary() ([]byte, error) {
+	return codec.NewEncoder(codec.TypeCLOSCLocalOnChain).
+		WriteBytes([]byte(l.Commitment)).
+		WriteBytes([]byte(l.Asset)).
+		WriteBytes([]byte(l.Scheme)).
+		WriteBytes([]byte(l.MerkleRoot)).
+		WriteBytes([]byte(l.MerkleProof)).
+		WriteBytes([]byte(l.RangeProof)).
+		WriteBytes([]byte(l.Signer)).
+		WriteBytes([]byte(l.Signature)).
+		Bytes()
+}
+
+func (l *LocalOnChain) UnmarshalBinary(data []byte) error {
+	dec, err := codec.NewDecoder(data, codec.TypeCLOSCLocalOnChain)
+	if err != nil {
+		return err
+	}
+	decoded := LocalOnChain{
+		Commitment:  string(dec.ReadBytes()),
+		Asset:       string(dec.ReadBytes()),
+		Scheme:      string(dec.ReadBytes()),
+		MerkleRoot:  string(dec.ReadBytes()),
+		MerkleProof: string(dec.ReadBytes()),
+		RangeProof:  string(dec.ReadBytes()),
+		Signer:      string(dec.ReadBytes()),
+		Signature:   string(dec.ReadBytes()),
+	}
+	if err = dec.Finish(); err != nil {
+		return err
+	}
+	*l = decoded
+	return nil
+}
+
+// ContentID is the hash of the canonical binary encoding of the transaction without the signature,
+// it is the key of the transaction on the ledger and does not change if the transaction is signed again.
+func (l *LocalOnChain) ContentID() (string, error) {
+	unsignedTX := *l
+	unsignedTX.Signature = ""
+	encoding, err := unsignedTX.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return codec.ContentID(encoding), nil
+}
+
+// KeyVal returns the content ID and the canonical binary encoding submitted to the chaincode.
 func (l *LocalOnChain) KeyVal() (string, []byte, error) {
-	txJSON, err := json.Marshal(l)
+	key, err := l.ContentID()
 	if err != nil {
 		return "", nil, err
 	}
-	sha256Func := sha256.New()
-	sha256Func.Write(txJSON)
-	return hex.EncodeToString(sha256Func.Sum(nil)), txJSON, nil
+	val, err := l.MarshalBinary()
+	if err != nil {
+		return "", nil, err
+	}
+	return key, val, nil
+}
+
+// SigningMessage returns the canonical encoding of the transaction covered by the signature.
+func (l *LocalOnChain) SigningMessage() []byte {
+	return crypto.SigningMessage(
+		localSigningTag, l.Commitment, l.Asset, l.Scheme, l.MerkleRoot, l.MerkleProof, l.RangeProof, l.Signer,
+	)
+}
+
+// Sign sets the signer to the public key of the signing key and signs the transaction.
+func (l *LocalOnChain) Sign(signingKey kyber.Scalar, rand cipher.Stream) error {
+	var err error
+	if l.Signer, err = crypto.SignerHex(signingKey); err != nil {
+		return err
+	}
+	l.Signature, err = crypto.SignHex(signingKey, l.SigningMessage(), rand)
+	return err
+}
+
+// VerifySignature returns false if the transaction is unsigned, forged, or not signed by the public key.
+func (l *LocalOnChain) VerifySignature(publicKey kyber.Point) (bool, error) {
+	return verifySignedBy(publicKey, l.Signer, l.Signature, l.SigningMessage())
 }
 
 func (l *LocalOnChain) ToPlain() (*LocalPlain, error) {
@@ -127,5 +348,21 @@ func (l *LocalOnChain) ToPlain() (*LocalPlain, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewLocalPlain(commitment, merkleRoot, merkleProof), nil
+	rangeProof, err := hex.DecodeString(l.RangeProof)
+	if err != nil {
+		return nil, err
+	}
+	plainTX := NewLocalPlain(commitment, merkleRoot, merkleProof)
+	plainTX.Asset = l.Asset
+	if l.Scheme != "" {
+		scheme, err := strconv.ParseUint(l.Scheme, 10, 8)
+		if err != nil {
+			return nil, err
+		}
+		plainTX.Scheme = crypto.CommitmentScheme(scheme)
+	}
+	if len(rangeProof) > 0 {
+		plainTX.RangeProof = rangeProof
+	}
+	return plainTX, nil
 }
@@ -0,0 +1,109 @@
+package transaction
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/money"
+)
+
+func TestLocalPlain_MarshalBinary(t *testing.T) {
+	tests := []struct {
+		name string
+		tx   *LocalPlain
+	}{
+		{
+			name: "test_without_range_proof",
+			tx:   NewLocalPlain([]byte("commitment"), []byte("merkle_root"), []byte("merkle_proof")),
+		},
+		{
+			name: "test_with_range_proof",
+			tx: &LocalPlain{
+				Commitment:  []byte("commitment"),
+				Asset:       "USD",
+				Scheme:      crypto.CommitmentSchemeHashToCurve,
+				MerkleRoot:  []byte("merkle_root"),
+				MerkleProof: []byte("merkle_proof"),
+				RangeProof:  []byte("range_proof"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, err := tt.tx.MarshalBinary()
+			if err != nil {
+				t.Errorf("MarshalBinary() error = %v", err)
+				return
+			}
+			decoded := new(LocalPlain)
+			if err = decoded.UnmarshalBinary(encoding); err != nil {
+				t.Errorf("UnmarshalBinary() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(decoded, tt.tx) {
+				t.Errorf("UnmarshalBinary() = %v, want %v", decoded, tt.tx)
+			}
+			id, err := tt.tx.ContentID()
+			if err != nil {
+				t.Errorf("ContentID() error = %v", err)
+				return
+			}
+			if decodedID, _ := decoded.ContentID(); decodedID != id {
+				t.Errorf("ContentID() = %s, want %s", decodedID, id)
+			}
+		})
+	}
+}
+
+func TestLocalPlain_VerifyRangeProof(t *testing.T) {
+	tests := []struct {
+		name           string
+		wrongHashPoint bool
+		want           bool
+	}{
+		{
+			name: "test_hash_point",
+			want: true,
+		},
+		{
+			name:           "test_wrong_hash_point",
+			wrongHashPoint: true,
+			want:           false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hiddenTX, hashPoint, err := NewPlain("org1", "org2", money.MustParse("-12.5", "EUR"), 1, 1).
+				HideWithRangeProof(crypto.RandomStream())
+			if err != nil {
+				t.Errorf("HideWithRangeProof() error = %v", err)
+				return
+			}
+			plainTX := NewLocalPlain(hiddenTX.Commitment, []byte("merkle_root"), []byte("merkle_proof"))
+			plainTX.Asset = hiddenTX.Asset
+			plainTX.Scheme = hiddenTX.Scheme
+			plainTX.RangeProof = hiddenTX.RangeProof
+			// the asset and the scheme survive the on-chain form
+			decoded, err := plainTX.ToOnChain().ToPlain()
+			if err != nil {
+				t.Errorf("ToPlain() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(decoded, plainTX) {
+				t.Errorf("ToPlain() = %v, want %v", decoded, plainTX)
+			}
+			if tt.wrongHashPoint {
+				hashPoint = crypto.KyberSuite.Point().Pick(crypto.RandomStream())
+			}
+			got, err := decoded.VerifyRangeProof(hashPoint)
+			if err != nil {
+				t.Errorf("VerifyRangeProof() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("VerifyRangeProof() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package transaction
 
 import (
-	"crypto/sha256"
+	"crypto/cipher"
 	"encoding/hex"
-	"encoding/json"
+
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/codec"
+	"github.com/auti-project/auti/internal/crypto"
 )
 
 type OrgPlain struct {
@@ -22,6 +26,8 @@ func (o *OrgPlain) ToOnChain() *OrgOnChain {
 
 type OrgOnChain struct {
 	MerkleRoot string `json:"merkle_root"`
+	Signer     string `json:"signer,omitempty"`
+	Signature  string `json:"signature,omitempty"`
 }
 
 func NewOrgOnChain(merkleRoot string) *OrgOnChain {
@@ -38,12 +44,74 @@ func (o *OrgOnChain) ToPlain() (*OrgPlain, error) {
 	return NewOrgPlain(merkleRoot), nil
 }
 
+// MarshalBinary returns the canonical binary encoding of the transaction with the signature,
+// the JSON form is only for reading.
+func (o *OrgOnChain) MarshalBinary() ([]byte, error) {
+	return codec.NewEncoder(codec.TypeCLOSCOrgOnChain).
+		WriteBytes([]byte(o.MerkleRoot)).
+		WriteBytes([]byte(o.Signer)).
+		WriteBytes([]byte(o.Signature)).
+		Bytes()
+}
+
+func (o *OrgOnChain) UnmarshalBinary(data []byte) error {
+	dec, err := codec.NewDecoder(data, codec.TypeCLOSCOrgOnChain)
+	if err != nil {
+		return err
+	}
+	decoded := OrgOnChain{
+		MerkleRoot: string(dec.ReadBytes()),
+		Signer:     string(dec.ReadBytes()),
+		Signature:  string(dec.ReadBytes()),
+	}
+	if err = dec.Finish(); err != nil {
+		return err
+	}
+	*o = decoded
+	return nil
+}
+
+// ContentID is the hash of the canonical binary encoding of the transaction without the signature,
+// it is the key of the transaction on the ledger and does not change if the transaction is signed again.
+func (o *OrgOnChain) ContentID() (string, error) {
+	unsignedTX := *o
+	unsignedTX.Signature = ""
+	encoding, err := unsignedTX.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return codec.ContentID(encoding), nil
+}
+
+// KeyVal returns the content ID and the canonical binary encoding submitted to the chaincode.
 func (o *OrgOnChain) KeyVal() (string, []byte, error) {
-	txJSON, err := json.Marshal(o)
+	key, err := o.ContentID()
+	if err != nil {
+		return "", nil, err
+	}
+	val, err := o.MarshalBinary()
 	if err != nil {
 		return "", nil, err
 	}
-	sha256Func := sha256.New()
-	sha256Func.Write(txJSON)
-	return hex.EncodeToString(sha256Func.Sum(nil)), txJSON, nil
+	return key, val, nil
+}
+
+// SigningMessage returns the canonical encoding of the transaction covered by the signature.
+func (o *OrgOnChain) SigningMessage() []byte {
+	return crypto.SigningMessage(orgSigningTag, o.MerkleRoot, o.Signer)
+}
+
+// Sign sets the signer to the public key of the signing key and signs the transaction.
+func (o *OrgOnChain) Sign(signingKey kyber.Scalar, rand cipher.Stream) error {
+	var err error
+	if o.Signer, err = crypto.SignerHex(signingKey); err != nil {
+		return err
+	}
+	o.Signature, err = crypto.SignHex(signingKey, o.SigningMessage(), rand)
+	return err
+}
+
+// VerifySignature returns false if the transaction is unsigned, forged, or not signed by the public key.
+func (o *OrgOnChain) VerifySignature(publicKey kyber.Point) (bool, error) {
+	return verifySignedBy(publicKey, o.Signer, o.Signature, o.SigningMessage())
 }
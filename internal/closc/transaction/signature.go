@@ -0,0 +1,27 @@
+package transaction
+
+import (
+	"go.dedis.ch/kyber/v3"
+
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+// the tags separate the signing messages of the on-chain transaction types
+const (
+	localCommitmentSigningTag = "auti-closc-local-commitment"
+	localSigningTag           = "auti-closc-local"
+	orgSigningTag             = "auti-closc-org"
+	audSigningTag             = "auti-closc-aud"
+)
+
+// verifySignedBy checks that the transaction is signed by the given public key.
+func verifySignedBy(publicKey kyber.Point, signer, signature string, msg []byte) (bool, error) {
+	publicKeyHex, err := crypto.PublicKeyHex(publicKey)
+	if err != nil {
+		return false, err
+	}
+	if signer != publicKeyHex {
+		return false, nil
+	}
+	return crypto.VerifySignatureHex(signer, signature, msg)
+}
@@ -0,0 +1,174 @@
+package codec
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/auti-project/auti/internal/hashing"
+)
+
+// TypeTag is the second byte of every encoding, it keeps the encodings of different types apart.
+type TypeTag byte
+
+const (
+	TypeCLOLCLocalHidden            TypeTag = 0x01
+	TypeCLOLCOrgPlain               TypeTag = 0x02
+	TypeCLOLCAudPlain               TypeTag = 0x03
+	TypeCLOLCLocalOnChain           TypeTag = 0x04
+	TypeCLOLCOrgOnChain             TypeTag = 0x05
+	TypeCLOLCAudOnChain             TypeTag = 0x06
+	TypeCLOSCLocalPlain             TypeTag = 0x11
+	TypeCLOSCAudPlain               TypeTag = 0x12
+	TypeCLOSCLocalOnChain           TypeTag = 0x13
+	TypeCLOSCAudOnChain             TypeTag = 0x14
+	TypeCLOSCOrgOnChain             TypeTag = 0x15
+	TypeCLOSCLocalCommitmentOnChain TypeTag = 0x16
+	TypeKeyStoreEntry               TypeTag = 0x21
+	TypeKeyStoreHeader              TypeTag = 0x22
+	TypeKeyStoreSecrets             TypeTag = 0x23
+)
+
+// layout is the version of the current layout of a type, the first byte of its encodings,
+// and the oldest version with the same layout, whose encodings are still decoded.
+type layout struct {
+	version byte
+	oldest  byte
+}
+
+// layouts keep a version per type, it is bumped only when the layout of the type itself changes,
+// so the encodings of the other types stay readable. The versions up to 3 were shared by all the types.
+var layouts = map[TypeTag]layout{
+	TypeCLOLCLocalHidden:            {version: 4, oldest: 4},
+	TypeCLOLCOrgPlain:               {version: 3, oldest: 1},
+	TypeCLOLCAudPlain:               {version: 3, oldest: 1},
+	TypeCLOLCLocalOnChain:           {version: 4, oldest: 4},
+	TypeCLOLCOrgOnChain:             {version: 3, oldest: 3},
+	TypeCLOLCAudOnChain:             {version: 3, oldest: 3},
+	TypeCLOSCLocalPlain:             {version: 3, oldest: 3},
+	TypeCLOSCAudPlain:               {version: 3, oldest: 1},
+	TypeCLOSCLocalOnChain:           {version: 3, oldest: 3},
+	TypeCLOSCAudOnChain:             {version: 3, oldest: 3},
+	TypeCLOSCOrgOnChain:             {version: 3, oldest: 3},
+	TypeCLOSCLocalCommitmentOnChain: {version: 3, oldest: 3},
+	TypeKeyStoreEntry:               {version: 3, oldest: 1},
+	TypeKeyStoreHeader:              {version: 3, oldest: 1},
+	TypeKeyStoreSecrets:             {version: 3, oldest: 1},
+}
+
+// Encoder writes the canonical encoding: the current version of the type, the type tag, and the fields in order.
+// Byte fields are prefixed with their length as a big-endian uint32, integers are 8-byte big-endian.
+type Encoder struct {
+	buf []byte
+	err error
+}
+
+func NewEncoder(tag TypeTag) *Encoder {
+	l, ok := layouts[tag]
+	if !ok {
+		return &Encoder{err: fmt.Errorf("unknown type tag: %d", tag)}
+	}
+	return &Encoder{buf: []byte{l.version, byte(tag)}}
+}
+
+func (e *Encoder) WriteBytes(data []byte) *Encoder {
+	if uint64(len(data)) > math.MaxUint32 {
+		e.err = errors.New("field is too large")
+		return e
+	}
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(len(data)))
+	e.buf = append(e.buf, data...)
+	return e
+}
+
+func (e *Encoder) WriteInt64(i int64) *Encoder {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(i))
+	return e
+}
+
+func (e *Encoder) Bytes() ([]byte, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.buf, nil
+}
+
+// Decoder reads the fields in the order they were written,
+// the first error is kept and returned by Finish.
+type Decoder struct {
+	data []byte
+	err  error
+}
+
+// NewDecoder accepts the versions of the type from the oldest one with the current layout on.
+func NewDecoder(data []byte, tag TypeTag) (*Decoder, error) {
+	if len(data) < 2 {
+		return nil, errors.New("encoding is too short")
+	}
+	if TypeTag(data[1]) != tag {
+		return nil, fmt.Errorf("unexpected type tag: %d, want %d", data[1], tag)
+	}
+	l, ok := layouts[tag]
+	if !ok {
+		return nil, fmt.Errorf("unknown type tag: %d", tag)
+	}
+	if data[0] < l.oldest || data[0] > l.version {
+		return nil, fmt.Errorf("unsupported encoding version: %d", data[0])
+	}
+	return &Decoder{data: data[2:]}, nil
+}
+
+// ReadBytes returns nil for an empty field.
+func (d *Decoder) ReadBytes() []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.data) < 4 {
+		d.err = errors.New("missing field length")
+		return nil
+	}
+	length := binary.BigEndian.Uint32(d.data)
+	d.data = d.data[4:]
+	if uint64(len(d.data)) < uint64(length) {
+		d.err = errors.New("field is truncated")
+		return nil
+	}
+	if length == 0 {
+		return nil
+	}
+	result := make([]byte, length)
+	copy(result, d.data)
+	d.data = d.data[length:]
+	return result
+}
+
+func (d *Decoder) ReadInt64() int64 {
+	if d.err != nil {
+		return 0
+	}
+	if len(d.data) < 8 {
+		d.err = errors.New("integer is truncated")
+		return 0
+	}
+	result := int64(binary.BigEndian.Uint64(d.data))
+	d.data = d.data[8:]
+	return result
+}
+
+// Finish rejects trailing bytes, so that every value has exactly one encoding.
+func (d *Decoder) Finish() error {
+	if d.err != nil {
+		return d.err
+	}
+	if len(d.data) != 0 {
+		return errors.New("trailing bytes after the last field")
+	}
+	return nil
+}
+
+// ContentID is the hex encoded hash of the canonical encoding under hashing.TagTXID,
+// the on-chain transactions are stored under their content IDs.
+func ContentID(encoding []byte) string {
+	return hashing.SumHex(hashing.TagTXID, encoding)
+}
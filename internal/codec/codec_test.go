@@ -0,0 +1,90 @@
+package codec
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	encoding, err := NewEncoder(TypeCLOLCOrgPlain).WriteBytes([]byte("field")).WriteInt64(-1).Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	// the layout of TypeCLOLCLocalHidden changed in version 2
+	localHiddenEncoding, err := NewEncoder(TypeCLOLCLocalHidden).WriteBytes([]byte("field")).WriteInt64(-1).Bytes()
+	if err != nil {
+		t.Fatalf("Bytes() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		tag     TypeTag
+		wantErr bool
+	}{
+		{
+			name:    "test_valid",
+			data:    encoding,
+			tag:     TypeCLOLCOrgPlain,
+			wantErr: false,
+		},
+		{
+			name:    "test_wrong_tag",
+			data:    encoding,
+			tag:     TypeCLOLCAudPlain,
+			wantErr: true,
+		},
+		{
+			name:    "test_future_version",
+			data:    append([]byte{layouts[TypeCLOLCOrgPlain].version + 1}, encoding[1:]...),
+			tag:     TypeCLOLCOrgPlain,
+			wantErr: true,
+		},
+		{
+			name:    "test_older_version_same_layout",
+			data:    append([]byte{1}, encoding[1:]...),
+			tag:     TypeCLOLCOrgPlain,
+			wantErr: false,
+		},
+		{
+			name:    "test_older_version_other_layout",
+			data:    append([]byte{1}, localHiddenEncoding[1:]...),
+			tag:     TypeCLOLCLocalHidden,
+			wantErr: true,
+		},
+		{
+			name:    "test_truncated",
+			data:    encoding[:len(encoding)-1],
+			tag:     TypeCLOLCOrgPlain,
+			wantErr: true,
+		},
+		{
+			name:    "test_trailing_bytes",
+			data:    append(append([]byte{}, encoding...), 0),
+			tag:     TypeCLOLCOrgPlain,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dec, err := NewDecoder(tt.data, tt.tag)
+			if err != nil {
+				if !tt.wantErr {
+					t.Errorf("NewDecoder() error = %v, wantErr %v", err, tt.wantErr)
+				}
+				return
+			}
+			field := dec.ReadBytes()
+			i := dec.ReadInt64()
+			if err = dec.Finish(); (err != nil) != tt.wantErr {
+				t.Errorf("Finish() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if !bytes.Equal(field, []byte("field")) || i != -1 {
+				t.Errorf("ReadBytes(), ReadInt64() = %s, %d, want %s, %d", field, i, "field", -1)
+			}
+		})
+	}
+}
@@ -2,5 +2,8 @@ package constants
 
 const (
 	SecurityParameterBytes int = 32
-	MaxNumTXInEpoch        int = 1024
+	// MaxNumTXInEpoch is the number of transactions per pair in the benchmarks,
+	// the protocols do not bound the number of transactions in an epoch.
+	MaxNumTXInEpoch  int = 1024
+	RangeProofBitLen int = 64
 )
@@ -0,0 +1,18 @@
+package crypto
+
+import (
+	"go.dedis.ch/kyber/v3"
+)
+
+// AssetGenerator returns the value generator of the asset, e.g., an ISO 4217 currency code,
+// the Pedersen commitments of amounts of the asset are amount*G_asset + r*H.
+// The generator is taken from the registry under GeneratorLabelAssetPrefix and the asset code,
+// so no discrete log relation between the generators of different assets is known,
+// and the commitments of different assets cannot cancel each other.
+// The empty asset is the untagged asset committed with PointG.
+func AssetGenerator(asset string) kyber.Point {
+	if asset == "" {
+		return KyberSuite.Point().Set(PointG)
+	}
+	return Generators.Generator(GeneratorLabelAssetPrefix + asset)
+}
@@ -2,26 +2,102 @@ package crypto
 
 import (
 	"bytes"
+	"crypto/cipher"
 	"crypto/sha256"
 	"encoding/binary"
+	"errors"
 	"fmt"
 
 	"go.dedis.ch/kyber/v3"
 )
 
-func PedersenCommit(amount int64) (kyber.Point, kyber.Scalar, error) {
-	amountScalar, err := amountToScalar(amount)
+// CommitmentScheme identifies how the hash point of a commitment with hash is derived from the transaction,
+// it is recorded with the local chain entries, so the auditors can recompute the hash points.
+type CommitmentScheme byte
+
+const (
+	// CommitmentSchemeHashScalar multiplies LegacyPointH by the SHA-256 hash of the transaction as a scalar,
+	// it is the scheme of the entries recorded before the schemes were versioned.
+	CommitmentSchemeHashScalar CommitmentScheme = iota
+	// CommitmentSchemeHashToCurve hashes the transaction to the curve with HashToCurve under HashPointDST,
+	// the hash point has no known discrete log relative to H.
+	CommitmentSchemeHashToCurve
+)
+
+// DefaultCommitmentScheme is the scheme of the new commitments.
+const DefaultCommitmentScheme = CommitmentSchemeHashToCurve
+
+// blindingGenerator returns the blinding generator of the range proofs of the scheme, the entries of
+// CommitmentSchemeHashScalar were committed when LegacyPointH was the only blinding generator.
+func (s CommitmentScheme) blindingGenerator() BlindingGeneratorVersion {
+	if s == CommitmentSchemeHashScalar {
+		return BlindingGeneratorLegacy
+	}
+	return BlindingGeneratorHashed
+}
+
+// CheckCommitmentScheme rejects the unknown schemes, and CommitmentSchemeHashScalar, whose commitments are not binding,
+// unless allowLegacy is set to migrate the data committed under it. The on-chain entries of CLOSC carry no timestamp
+// to compare with a cutoff, so the migration flag admits all of them.
+func CheckCommitmentScheme(scheme CommitmentScheme, allowLegacy bool) error {
+	switch scheme {
+	case CommitmentSchemeHashToCurve:
+		return nil
+	case CommitmentSchemeHashScalar:
+		if allowLegacy {
+			return nil
+		}
+		return errors.New("legacy commitment scheme without the migration flag")
+	default:
+		return fmt.Errorf("unknown commitment scheme: %d", scheme)
+	}
+}
+
+// HashPointDST is the domain-separation tag of the hash points of CommitmentSchemeHashToCurve.
+const HashPointDST = "AUTI-V01-CS01-with-" + HashToCurveSuiteID
+
+// PedersenCommit commits to the amount of the asset as amount*G_asset + r*H, see AssetGenerator,
+// H is the blinding generator of the version.
+func PedersenCommit(version BlindingGeneratorVersion, asset string, amount int64,
+	rand cipher.Stream) (kyber.Point, kyber.Scalar, error) {
+	randScalar := KyberSuite.Scalar().Pick(rand)
+	commitment, err := pedersenCommitWithRandomness(version, asset, amount, randScalar)
 	if err != nil {
 		return nil, nil, err
 	}
-	commitment := KyberSuite.Point().Mul(amountScalar, PointG)
-	randScalar := KyberSuite.Scalar().Pick(KyberSuite.RandomStream())
-	randPoint := KyberSuite.Point().Mul(randScalar, PointH)
-	commitment.Add(commitment, randPoint)
 	return commitment, randScalar, nil
 }
 
-func computeHashPoint(timestamp int64, receiverHash []byte, counter uint64) (kyber.Point, error) {
+// VerifyPedersenOpening checks that the commitment is amount*G_asset + r*H under the blinding generator
+// of the version, i.e., that the disclosed amount and randomness open the commitment.
+func VerifyPedersenOpening(commitment kyber.Point, version BlindingGeneratorVersion, asset string, amount int64,
+	randScalar kyber.Scalar) (bool, error) {
+	if randScalar == nil {
+		return false, errors.New("no randomness for the commitment")
+	}
+	expected, err := pedersenCommitWithRandomness(version, asset, amount, randScalar)
+	if err != nil {
+		return false, err
+	}
+	return commitment.Equal(expected), nil
+}
+
+func pedersenCommitWithRandomness(version BlindingGeneratorVersion, asset string, amount int64,
+	randScalar kyber.Scalar) (kyber.Point, error) {
+	blindingGen, err := BlindingGenerator(version)
+	if err != nil {
+		return nil, err
+	}
+	amountScalar, err := amountToScalar(amount)
+	if err != nil {
+		return nil, err
+	}
+	commitment := KyberSuite.Point().Mul(amountScalar, AssetGenerator(asset))
+	randPoint := KyberSuite.Point().Mul(randScalar, blindingGen)
+	return commitment.Add(commitment, randPoint), nil
+}
+
+func computeHashScalar(timestamp int64, receiverHash []byte, counter uint64) (kyber.Scalar, error) {
 	// concatenated bytes for calculating the commitment
 	timestampByte, err := int64ToBytes(timestamp)
 	if err != nil {
@@ -37,19 +113,47 @@ func computeHashPoint(timestamp int64, receiverHash []byte, counter uint64) (kyb
 	sha256Func := sha256.New()
 	sha256Func.Write(concatBytes)
 	concatByteHash := sha256Func.Sum(nil)
-	hashScalar := KyberSuite.Scalar().SetBytes(concatByteHash)
-	hashPoint := KyberSuite.Point().Mul(hashScalar, PointH)
-	return hashPoint, nil
+	return KyberSuite.Scalar().SetBytes(concatByteHash), nil
+}
+
+func computeHashPoint(scheme CommitmentScheme, timestamp int64, receiverHash []byte,
+	counter uint64) (kyber.Point, error) {
+	switch scheme {
+	case CommitmentSchemeHashScalar:
+		hashScalar, err := computeHashScalar(timestamp, receiverHash, counter)
+		if err != nil {
+			return nil, err
+		}
+		return KyberSuite.Point().Mul(hashScalar, LegacyPointH), nil
+	case CommitmentSchemeHashToCurve:
+		// the fixed-length fields come first, so the encoding is unambiguous
+		timestampByte, err := int64ToBytes(timestamp)
+		if err != nil {
+			return nil, err
+		}
+		counterByte, err := uint64ToBytes(counter)
+		if err != nil {
+			return nil, err
+		}
+		msg := append(timestampByte, counterByte...)
+		msg = append(msg, receiverHash...)
+		return HashToCurve(HashPointDST, msg)
+	default:
+		return nil, fmt.Errorf("unknown commitment scheme: %d", scheme)
+	}
 }
 
-func PedersonCommitWithHash(amount, timestamp int64,
+// PedersonCommitWithHash commits to the amount of the asset with the hash point of the transaction
+// as the blinding term, amount*G_asset + hashPoint, and returns the commitment and the hash point.
+// The hash point is derived as specified by the scheme.
+func PedersonCommitWithHash(scheme CommitmentScheme, asset string, amount, timestamp int64,
 	receiverHash []byte, counter uint64) (kyber.Point, kyber.Point, error) {
 	amountScalar, err := amountToScalar(amount)
 	if err != nil {
 		return nil, nil, err
 	}
-	commitment := KyberSuite.Point().Mul(amountScalar, PointG)
-	hashPoint, err := computeHashPoint(timestamp, receiverHash, counter)
+	commitment := KyberSuite.Point().Mul(amountScalar, AssetGenerator(asset))
+	hashPoint, err := computeHashPoint(scheme, timestamp, receiverHash, counter)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -57,21 +161,52 @@ func PedersonCommitWithHash(amount, timestamp int64,
 	return commitment, hashPoint, nil
 }
 
-func amountToScalar(amount int64) (kyber.Scalar, error) {
-	positive := true
-	if amount < 0 {
-		amount = -amount
-		positive = false
+// PedersonCommitWithHashRangeProof proves that the commitment produced by PedersonCommitWithHash
+// with the same inputs opens to a value in the signed range of bitLen bits.
+// Nobody knows the discrete log of the hash point of CommitmentSchemeHashToCurve,
+// so its proof is over the commitment without the hash point, see VerifyCommitWithHashRangeProof.
+func PedersonCommitWithHashRangeProof(scheme CommitmentScheme, asset string, amount, timestamp int64,
+	receiverHash []byte, counter uint64, bitLen int, rand cipher.Stream) (*RangeProof, error) {
+	switch scheme {
+	case CommitmentSchemeHashScalar:
+		hashScalar, err := computeHashScalar(timestamp, receiverHash, counter)
+		if err != nil {
+			return nil, err
+		}
+		return NewSignedRangeProof(
+			scheme.blindingGenerator(), asset, []int64{amount}, []kyber.Scalar{hashScalar}, bitLen, rand,
+		)
+	case CommitmentSchemeHashToCurve:
+		return NewSignedRangeProof(
+			scheme.blindingGenerator(), asset, []int64{amount}, []kyber.Scalar{KyberSuite.Scalar().Zero()}, bitLen, rand,
+		)
+	default:
+		return nil, fmt.Errorf("unknown commitment scheme: %d", scheme)
 	}
-	amountBytes, err := int64ToBytes(amount)
-	if err != nil {
-		return nil, err
-	}
-	amountScalar := KyberSuite.Scalar().SetBytes(amountBytes)
-	if !positive {
-		amountScalar.Neg(amountScalar)
+}
+
+// VerifyCommitWithHashRangeProof verifies the range proof of a commitment produced by PedersonCommitWithHash,
+// the hash point is only needed for CommitmentSchemeHashToCurve.
+func VerifyCommitWithHashRangeProof(scheme CommitmentScheme, asset string, commitment, hashPoint kyber.Point,
+	proof *RangeProof) (bool, error) {
+	switch scheme {
+	case CommitmentSchemeHashScalar:
+		return VerifySignedRangeProof(scheme.blindingGenerator(), asset, []kyber.Point{commitment}, proof)
+	case CommitmentSchemeHashToCurve:
+		if hashPoint == nil {
+			return false, errors.New("hash point is nil")
+		}
+		amountPoint := KyberSuite.Point().Sub(commitment, hashPoint)
+		return VerifySignedRangeProof(scheme.blindingGenerator(), asset, []kyber.Point{amountPoint}, proof)
+	default:
+		return false, fmt.Errorf("unknown commitment scheme: %d", scheme)
 	}
-	return amountScalar, nil
+}
+
+func amountToScalar(amount int64) (kyber.Scalar, error) {
+	// the scalar must be linear in the amount, otherwise the range proofs
+	// and the homomorphic accumulation of commitments are meaningless
+	return KyberSuite.Scalar().SetInt64(amount), nil
 }
 
 func int64ToBytes(i int64) ([]byte, error) {
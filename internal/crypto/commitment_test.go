@@ -2,40 +2,46 @@ package crypto
 
 import (
 	"testing"
+
+	"go.dedis.ch/kyber/v3"
 )
 
 func TestPedersenCommit(t *testing.T) {
 	tests := []struct {
 		name    string
+		asset   string
 		amount  int64
 		wantErr bool
 	}{
 		{
 			name:    "test 10",
+			asset:   "",
 			amount:  1,
 			wantErr: false,
 		},
 		{
 			name:    "test 100",
+			asset:   "USD",
 			amount:  1,
 			wantErr: false,
 		},
 		{
 			name:    "test 1000",
+			asset:   "JPY",
 			amount:  1,
 			wantErr: false,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			point1, randScalar1, err := PedersenCommit(tt.amount)
+			point1, randScalar1, err := PedersenCommit(DefaultBlindingGenerator, tt.asset, tt.amount, RandomStream())
 			if (err != nil) != tt.wantErr {
-				t.Errorf("PedersenCommit() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("PedersenCommit(DefaultBlindingGenerator, ) error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
-			point2, randScalar2, err := PedersenCommit(-tt.amount)
+			point2, randScalar2, err := PedersenCommit(DefaultBlindingGenerator, tt.asset, -tt.amount, RandomStream())
 			if (err != nil) != tt.wantErr {
-				t.Errorf("PedersenCommit() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("PedersenCommit(DefaultBlindingGenerator, ) error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			randPoint1 := KyberSuite.Point().Mul(randScalar1, PointH)
@@ -51,6 +57,127 @@ func TestPedersenCommit(t *testing.T) {
 	}
 }
 
+func TestVerifyPedersenOpening(t *testing.T) {
+	commitment, randScalar, err := PedersenCommit(DefaultBlindingGenerator, "USD", -250, RandomStream())
+	if err != nil {
+		t.Fatalf("PedersenCommit(DefaultBlindingGenerator, ) error = %v", err)
+	}
+	tests := []struct {
+		name       string
+		asset      string
+		amount     int64
+		randScalar func() kyber.Scalar
+		want       bool
+	}{
+		{
+			name:       "test_valid",
+			asset:      "USD",
+			amount:     -250,
+			randScalar: func() kyber.Scalar { return randScalar },
+			want:       true,
+		},
+		{
+			name:       "test_wrong_amount",
+			asset:      "USD",
+			amount:     250,
+			randScalar: func() kyber.Scalar { return randScalar },
+			want:       false,
+		},
+		{
+			name:       "test_wrong_asset",
+			asset:      "EUR",
+			amount:     -250,
+			randScalar: func() kyber.Scalar { return randScalar },
+			want:       false,
+		},
+		{
+			name:       "test_wrong_randomness",
+			asset:      "USD",
+			amount:     -250,
+			randScalar: func() kyber.Scalar { return KyberSuite.Scalar().Pick(RandomStream()) },
+			want:       false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyPedersenOpening(commitment, DefaultBlindingGenerator, tt.asset, tt.amount, tt.randScalar())
+			if err != nil || got != tt.want {
+				t.Errorf("VerifyPedersenOpening() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPedersenCommit_Assets(t *testing.T) {
+	tests := []struct {
+		name        string
+		asset1      string
+		asset2      string
+		wantCancels bool
+	}{
+		{
+			name:        "test_same_asset",
+			asset1:      "USD",
+			asset2:      "USD",
+			wantCancels: true,
+		},
+		{
+			name:        "test_different_assets",
+			asset1:      "USD",
+			asset2:      "EUR",
+			wantCancels: false,
+		},
+		{
+			name:        "test_untagged_asset",
+			asset1:      "",
+			asset2:      "USD",
+			wantCancels: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			point1, randScalar1, err := PedersenCommit(DefaultBlindingGenerator, tt.asset1, 100, RandomStream())
+			if err != nil {
+				t.Fatalf("PedersenCommit(DefaultBlindingGenerator, ) error = %v", err)
+			}
+			point2, randScalar2, err := PedersenCommit(DefaultBlindingGenerator, tt.asset2, -100, RandomStream())
+			if err != nil {
+				t.Fatalf("PedersenCommit(DefaultBlindingGenerator, ) error = %v", err)
+			}
+			randScalar1.Add(randScalar1, randScalar2)
+			point1.Add(point1, point2)
+			point1.Sub(point1, KyberSuite.Point().Mul(randScalar1, PointH))
+			if got := point1.Equal(KyberSuite.Point().Null()); got != tt.wantCancels {
+				t.Errorf("PedersenCommit(DefaultBlindingGenerator, ) cancels = %v, want %v", got, tt.wantCancels)
+			}
+		})
+	}
+}
+
+func TestAssetGenerator(t *testing.T) {
+	assets := []string{"", "USD", "EUR", "JPY"}
+	seen := make(map[string]string)
+	for _, asset := range assets {
+		generator := AssetGenerator(asset)
+		if !generator.Equal(AssetGenerator(asset)) {
+			t.Errorf("AssetGenerator(%q) is not deterministic", asset)
+		}
+		if generator.Equal(PointH) {
+			t.Errorf("AssetGenerator(%q) = PointH", asset)
+		}
+		key := generator.String()
+		if other, ok := seen[key]; ok {
+			t.Errorf("AssetGenerator(%q) = AssetGenerator(%q)", asset, other)
+		}
+		seen[key] = asset
+	}
+	// the returned generator is a copy, modifying it does not affect the cache
+	AssetGenerator("USD").Null()
+	if AssetGenerator("USD").Equal(KyberSuite.Point().Null()) {
+		t.Errorf("AssetGenerator() returned the cached point")
+	}
+}
+
 func Test_amountToScalar(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -78,8 +205,121 @@ func Test_amountToScalar(t *testing.T) {
 			point1 := KyberSuite.Point().Mul(got1, PointG)
 			point2 := KyberSuite.Point().Mul(got2, PointG)
 			point1.Add(point1, point2)
-			if !point1.Equal(PointG) {
-				t.Errorf("amount - amount = %v, want %v", point1, PointG)
+			neutralPoint := KyberSuite.Point().Null()
+			if !point1.Equal(neutralPoint) {
+				t.Errorf("amount - amount = %v, want %v", point1, neutralPoint)
+			}
+		})
+	}
+}
+
+func TestPedersonCommitWithHash(t *testing.T) {
+	receiverHash := []byte("receiver hash")
+	tests := []struct {
+		name    string
+		scheme  CommitmentScheme
+		wantErr bool
+	}{
+		{
+			name:    "test_hash_scalar",
+			scheme:  CommitmentSchemeHashScalar,
+			wantErr: false,
+		},
+		{
+			name:    "test_hash_to_curve",
+			scheme:  CommitmentSchemeHashToCurve,
+			wantErr: false,
+		},
+		{
+			name:    "test_unknown_scheme",
+			scheme:  CommitmentSchemeHashToCurve + 1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commitment, hashPoint, err := PedersonCommitWithHash(tt.scheme, "USD", -42, 1, receiverHash, 7)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("PedersonCommitWithHash() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			amountPoint := KyberSuite.Point().Mul(KyberSuite.Scalar().SetInt64(-42), AssetGenerator("USD"))
+			if !KyberSuite.Point().Sub(commitment, hashPoint).Equal(amountPoint) {
+				t.Errorf("PedersonCommitWithHash() does not open to the amount")
+			}
+			proof, err := PedersonCommitWithHashRangeProof(tt.scheme, "USD", -42, 1, receiverHash, 7, 16, RandomStream())
+			if err != nil {
+				t.Errorf("PedersonCommitWithHashRangeProof() error = %v", err)
+				return
+			}
+			ok, err := VerifyCommitWithHashRangeProof(tt.scheme, "USD", commitment, hashPoint, proof)
+			if err != nil || !ok {
+				t.Errorf("VerifyCommitWithHashRangeProof() = %v, error = %v, want true", ok, err)
+			}
+			// the hash point of a different counter
+			_, otherHashPoint, err := PedersonCommitWithHash(tt.scheme, "USD", -42, 1, receiverHash, 8)
+			if err != nil {
+				t.Errorf("PedersonCommitWithHash() error = %v", err)
+				return
+			}
+			if otherHashPoint.Equal(hashPoint) {
+				t.Errorf("PedersonCommitWithHash() hash points of different counters are equal")
+			}
+			if tt.scheme == CommitmentSchemeHashToCurve {
+				if ok, _ = VerifyCommitWithHashRangeProof(tt.scheme, "USD", commitment, otherHashPoint, proof); ok {
+					t.Errorf("VerifyCommitWithHashRangeProof() = %v with a wrong hash point, want false", ok)
+				}
+			}
+		})
+	}
+	// the schemes derive different hash points from the same transaction
+	_, hashPoint1, _ := PedersonCommitWithHash(CommitmentSchemeHashScalar, "USD", 1, 1, receiverHash, 7)
+	_, hashPoint2, _ := PedersonCommitWithHash(CommitmentSchemeHashToCurve, "USD", 1, 1, receiverHash, 7)
+	if hashPoint1.Equal(hashPoint2) {
+		t.Errorf("PedersonCommitWithHash() hash points of the schemes are equal")
+	}
+}
+
+func TestCheckCommitmentScheme(t *testing.T) {
+	tests := []struct {
+		name        string
+		scheme      CommitmentScheme
+		allowLegacy bool
+		wantErr     bool
+	}{
+		{
+			name:        "test_hash_to_curve",
+			scheme:      CommitmentSchemeHashToCurve,
+			allowLegacy: false,
+			wantErr:     false,
+		},
+		{
+			name:        "test_hash_scalar",
+			scheme:      CommitmentSchemeHashScalar,
+			allowLegacy: false,
+			wantErr:     true,
+		},
+		{
+			name:        "test_hash_scalar_migration",
+			scheme:      CommitmentSchemeHashScalar,
+			allowLegacy: true,
+			wantErr:     false,
+		},
+		{
+			name:        "test_unknown",
+			scheme:      CommitmentSchemeHashToCurve + 1,
+			allowLegacy: true,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckCommitmentScheme(tt.scheme, tt.allowLegacy)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckCommitmentScheme() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
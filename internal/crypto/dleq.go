@@ -0,0 +1,93 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+
+	"go.dedis.ch/kyber/v3"
+)
+
+const dleqDomainTag = "auti-dleq"
+
+// DecryptionProof is a non-interactive Chaum-Pedersen proof that
+// log_G(X) = log_H(Y) without revealing the discrete log.
+type DecryptionProof struct {
+	Challenge kyber.Scalar
+	Response  kyber.Scalar
+}
+
+// newDLEQProof proves that x*G = X and x*H = Y.
+func newDLEQProof(x kyber.Scalar, G, H, X, Y kyber.Point, rand cipher.Stream) (*DecryptionProof, error) {
+	nonce := KyberSuite.Scalar().Pick(rand)
+	announcementG := KyberSuite.Point().Mul(nonce, G)
+	announcementH := KyberSuite.Point().Mul(nonce, H)
+	challenge, err := dleqChallenge(G, H, X, Y, announcementG, announcementH)
+	if err != nil {
+		return nil, err
+	}
+	// z = k + c*x
+	response := KyberSuite.Scalar().Mul(challenge, x)
+	response.Add(response, nonce)
+	return &DecryptionProof{
+		Challenge: challenge,
+		Response:  response,
+	}, nil
+}
+
+func verifyDLEQProof(proof *DecryptionProof, G, H, X, Y kyber.Point) (bool, error) {
+	// A_G = z*G - c*X, A_H = z*H - c*Y
+	announcementG := KyberSuite.Point().Mul(proof.Response, G)
+	announcementG.Sub(announcementG, KyberSuite.Point().Mul(proof.Challenge, X))
+	announcementH := KyberSuite.Point().Mul(proof.Response, H)
+	announcementH.Sub(announcementH, KyberSuite.Point().Mul(proof.Challenge, Y))
+	challenge, err := dleqChallenge(G, H, X, Y, announcementG, announcementH)
+	if err != nil {
+		return false, err
+	}
+	return challenge.Equal(proof.Challenge), nil
+}
+
+func dleqChallenge(points ...kyber.Point) (kyber.Scalar, error) {
+	sha256Func := sha256.New()
+	sha256Func.Write([]byte(dleqDomainTag))
+	for _, point := range points {
+		pointBytes, err := point.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		sha256Func.Write(pointBytes)
+	}
+	return KyberSuite.Scalar().SetBytes(sha256Func.Sum(nil)), nil
+}
+
+func (p *DecryptionProof) Serialize() ([]byte, error) {
+	challengeBytes, err := p.Challenge.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	responseBytes, err := p.Response.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(challengeBytes, responseBytes...), nil
+}
+
+func DeserializeDecryptionProof(data []byte) (*DecryptionProof, error) {
+	scalarSize := KyberSuite.Scalar().MarshalSize()
+	if len(data) != 2*scalarSize {
+		return nil, errors.New("invalid decryption proof length")
+	}
+	challenge := KyberSuite.Scalar()
+	if err := challenge.UnmarshalBinary(data[:scalarSize]); err != nil {
+		return nil, err
+	}
+	response := KyberSuite.Scalar()
+	if err := response.UnmarshalBinary(data[scalarSize:]); err != nil {
+		return nil, err
+	}
+	return &DecryptionProof{
+		Challenge: challenge,
+		Response:  response,
+	}, nil
+}
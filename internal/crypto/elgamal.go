@@ -1,7 +1,9 @@
 package crypto
 
 import (
+	"crypto/cipher"
 	"errors"
+	"fmt"
 
 	"go.dedis.ch/kyber/v3"
 	"go.dedis.ch/kyber/v3/group/edwards25519"
@@ -11,20 +13,22 @@ var (
 	KyberSuite       = edwards25519.NewBlakeSHA256Ed25519()
 	maxAmountByteLen = KyberSuite.Point().EmbedLen()
 	PointG           = KyberSuite.Point().Base()
-	hScalarBytes     = []byte{
+	// hScalarBytes is the discrete log of LegacyPointH
+	hScalarBytes = []byte{
 		88, 110, 203, 46, 52, 29, 230, 201, 240, 164, 50, 0,
 		116, 207, 45, 187, 223, 113, 166, 40, 12, 27, 15, 50,
 		235, 140, 55, 192, 37, 22, 130, 239,
 	}
 	hScalar = KyberSuite.Scalar().SetBytes(hScalarBytes)
-	PointH  = KyberSuite.Point().Base().Mul(hScalar, nil)
+	// PointH is the generator of the blinding terms, hashed to the curve, see GeneratorDST
+	PointH = Generators.Generator(GeneratorLabelBlinding)
 )
 
 type TypePublicKey kyber.Point
 type TypePrivateKey kyber.Scalar
 
-func KeyGen() (privateKey TypePrivateKey, publicKey TypePublicKey, err error) {
-	privateKey = KyberSuite.Scalar().Pick(KyberSuite.RandomStream())
+func KeyGen(rand cipher.Stream) (privateKey TypePrivateKey, publicKey TypePublicKey, err error) {
+	privateKey = KyberSuite.Scalar().Pick(rand)
 	publicKey = KyberSuite.Point().Mul(privateKey, nil)
 	return
 }
@@ -51,9 +55,14 @@ func (c *CipherText) Serialize() ([]byte, error) {
 	return append(c1Bytes, c2Bytes...), nil
 }
 
+// DeserializeCipherText decodes the two points written by Serialize, the data may come from untrusted peers.
 func DeserializeCipherText(data []byte) (*CipherText, error) {
-	c1Bytes := data[:KyberSuite.Point().MarshalSize()]
-	c2Bytes := data[KyberSuite.Point().MarshalSize():]
+	pointLen := KyberSuite.PointLen()
+	if len(data) != 2*pointLen {
+		return nil, fmt.Errorf("ciphertext of %d bytes, want %d", len(data), 2*pointLen)
+	}
+	c1Bytes := data[:pointLen]
+	c2Bytes := data[pointLen:]
 	c1 := KyberSuite.Point()
 	err := c1.UnmarshalBinary(c1Bytes)
 	if err != nil {
@@ -67,7 +76,7 @@ func DeserializeCipherText(data []byte) (*CipherText, error) {
 	return &CipherText{c1, c2}, nil
 }
 
-func Encrypt(publicKey kyber.Point, amount int64) (*CipherText, error) {
+func Encrypt(publicKey kyber.Point, amount int64, rand cipher.Stream) (*CipherText, error) {
 	// Embed the amount into a curve point
 	amountBytes, err := int64ToBytes(amount)
 	if err != nil {
@@ -76,15 +85,15 @@ func Encrypt(publicKey kyber.Point, amount int64) (*CipherText, error) {
 	if maxAmountByteLen < len(amountBytes) {
 		return nil, errors.New("amount is too large")
 	}
-	amountPoint := KyberSuite.Point().Embed(amountBytes, KyberSuite.RandomStream())
-	randomScalar := KyberSuite.Scalar().Pick(KyberSuite.RandomStream())
+	amountPoint := KyberSuite.Point().Embed(amountBytes, rand)
+	randomScalar := KyberSuite.Scalar().Pick(rand)
 	c1 := KyberSuite.Point().Mul(randomScalar, nil)
 	c2 := KyberSuite.Point().Add(amountPoint, KyberSuite.Point().Mul(randomScalar, publicKey))
 	return &CipherText{c1, c2}, nil
 }
 
-func EncryptPoint(publicKey, data kyber.Point) (*CipherText, error) {
-	randomScalar := KyberSuite.Scalar().Pick(KyberSuite.RandomStream())
+func EncryptPoint(publicKey, data kyber.Point, rand cipher.Stream) (*CipherText, error) {
+	randomScalar := KyberSuite.Scalar().Pick(rand)
 	c1 := KyberSuite.Point().Mul(randomScalar, nil)
 	c2 := KyberSuite.Point().Add(data, KyberSuite.Point().Mul(randomScalar, publicKey))
 	return &CipherText{c1, c2}, nil
@@ -114,3 +123,39 @@ func DecryptPoint(privateKey kyber.Scalar, cipherTextBytes []byte) (kyber.Point,
 	dataPoint.Add(dataPoint, cipherText.C2)
 	return dataPoint, nil
 }
+
+// DecryptPointWithProof decrypts the ciphertext and proves with a Chaum-Pedersen DLEQ proof
+// that the plaintext is correctly decrypted under the public key of privateKey.
+func DecryptPointWithProof(privateKey kyber.Scalar, cipherTextBytes []byte,
+	rand cipher.Stream) (kyber.Point, *DecryptionProof, error) {
+	cipherText, err := DeserializeCipherText(cipherTextBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	if privateKey == nil {
+		return nil, nil, errors.New("private key is nil")
+	}
+	sharedPoint := KyberSuite.Point().Mul(privateKey, cipherText.C1)
+	dataPoint := KyberSuite.Point().Sub(cipherText.C2, sharedPoint)
+	publicKey := KyberSuite.Point().Mul(privateKey, nil)
+	proof, err := newDLEQProof(privateKey, PointG, cipherText.C1, publicKey, sharedPoint, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return dataPoint, proof, nil
+}
+
+// VerifyDecryptionProof checks that dataPoint is the decryption of the ciphertext
+// under the private key corresponding to publicKey.
+func VerifyDecryptionProof(publicKey kyber.Point, cipherTextBytes []byte,
+	dataPoint kyber.Point, proof *DecryptionProof) (bool, error) {
+	if proof == nil {
+		return false, errors.New("decryption proof is nil")
+	}
+	cipherText, err := DeserializeCipherText(cipherTextBytes)
+	if err != nil {
+		return false, err
+	}
+	sharedPoint := KyberSuite.Point().Sub(cipherText.C2, dataPoint)
+	return verifyDLEQProof(proof, PointG, cipherText.C1, publicKey, sharedPoint)
+}
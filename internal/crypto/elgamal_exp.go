@@ -0,0 +1,146 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"math"
+
+	"go.dedis.ch/kyber/v3"
+)
+
+// BSGSTable is the precomputed baby-step table for recovering amounts in [-Bound, Bound]
+// from amount*G with the baby-step giant-step algorithm, G is the generator of the asset of the table.
+// The table is read-only after construction and safe for concurrent use.
+type BSGSTable struct {
+	Asset     string
+	Bound     uint64
+	stepSize  uint64
+	babySteps map[string]uint64
+	giantStep kyber.Point
+	shift     kyber.Point
+}
+
+// NewBSGSTable precomputes sqrt(2*bound+1) baby steps of PointG.
+func NewBSGSTable(bound uint64) (*BSGSTable, error) {
+	return NewAssetBSGSTable("", bound)
+}
+
+// NewAssetBSGSTable precomputes sqrt(2*bound+1) baby steps of the generator of the asset,
+// for recovering amounts from the openings of the commitments of the asset.
+func NewAssetBSGSTable(asset string, bound uint64) (*BSGSTable, error) {
+	if bound == 0 || bound > math.MaxInt64 {
+		return nil, fmt.Errorf("invalid bound: %d", bound)
+	}
+	generator := AssetGenerator(asset)
+	// the shifted amount lies in [0, 2*bound]
+	stepSize := uint64(math.Ceil(math.Sqrt(float64(bound)*2 + 1)))
+	table := &BSGSTable{
+		Asset:     asset,
+		Bound:     bound,
+		stepSize:  stepSize,
+		babySteps: make(map[string]uint64, stepSize),
+	}
+	point := KyberSuite.Point().Null()
+	for j := uint64(0); j < stepSize; j++ {
+		pointBytes, err := point.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		table.babySteps[string(pointBytes)] = j
+		point.Add(point, generator)
+	}
+	// point is stepSize*G now
+	table.giantStep = point.Neg(point)
+	table.shift = KyberSuite.Point().Mul(uint64ToScalar(bound), generator)
+	return table, nil
+}
+
+// Solve returns the amount such that amount*G equals the given point.
+func (t *BSGSTable) Solve(amountPoint kyber.Point) (int64, error) {
+	point := KyberSuite.Point().Add(amountPoint, t.shift)
+	numGiantSteps := (2*t.Bound)/t.stepSize + 1
+	for i := uint64(0); i <= numGiantSteps; i++ {
+		pointBytes, err := point.MarshalBinary()
+		if err != nil {
+			return 0, err
+		}
+		if j, ok := t.babySteps[string(pointBytes)]; ok {
+			shifted := i*t.stepSize + j
+			if shifted > 2*t.Bound {
+				break
+			}
+			return int64(shifted - t.Bound), nil
+		}
+		point.Add(point, t.giantStep)
+	}
+	return 0, errors.New("amount is out of the bound of the table")
+}
+
+// EncryptExp encrypts the amount with exponential ElGamal, i.e., (r*G, amount*G + r*pk),
+// the ciphertexts are additively homomorphic.
+func EncryptExp(publicKey kyber.Point, amount int64, rand cipher.Stream) (*CipherText, error) {
+	amountScalar, err := amountToScalar(amount)
+	if err != nil {
+		return nil, err
+	}
+	amountPoint := KyberSuite.Point().Mul(amountScalar, PointG)
+	return EncryptPoint(publicKey, amountPoint, rand)
+}
+
+// DecryptExp decrypts an exponential ElGamal ciphertext,
+// the amount must be within the bound of the table.
+func DecryptExp(privateKey kyber.Scalar, cipherText *CipherText, table *BSGSTable) (int64, error) {
+	if privateKey == nil {
+		return 0, errors.New("private key is nil")
+	}
+	if table == nil {
+		return 0, errors.New("baby-step giant-step table is nil")
+	}
+	if table.Asset != "" {
+		return 0, fmt.Errorf("baby-step giant-step table for asset %q, want PointG", table.Asset)
+	}
+	amountPoint := KyberSuite.Point().Mul(privateKey, cipherText.C1)
+	amountPoint.Neg(amountPoint)
+	amountPoint.Add(amountPoint, cipherText.C2)
+	return table.Solve(amountPoint)
+}
+
+// Add returns the ciphertext of the sum of the two plaintexts.
+func (c *CipherText) Add(other *CipherText) *CipherText {
+	return &CipherText{
+		C1: KyberSuite.Point().Add(c.C1, other.C1),
+		C2: KyberSuite.Point().Add(c.C2, other.C2),
+	}
+}
+
+// Mul returns the ciphertext of the plaintext multiplied by factor.
+func (c *CipherText) Mul(factor int64) *CipherText {
+	factorScalar := KyberSuite.Scalar().SetInt64(factor)
+	return &CipherText{
+		C1: KyberSuite.Point().Mul(factorScalar, c.C1),
+		C2: KyberSuite.Point().Mul(factorScalar, c.C2),
+	}
+}
+
+// SumCipherTexts returns the ciphertext of the sum of all the plaintexts.
+func SumCipherTexts(cipherTexts []*CipherText) (*CipherText, error) {
+	if len(cipherTexts) == 0 {
+		return nil, errors.New("no cipher texts given")
+	}
+	result := &CipherText{
+		C1: KyberSuite.Point().Null(),
+		C2: KyberSuite.Point().Null(),
+	}
+	for _, cipherText := range cipherTexts {
+		result.C1.Add(result.C1, cipherText.C1)
+		result.C2.Add(result.C2, cipherText.C2)
+	}
+	return result, nil
+}
+
+func uint64ToScalar(i uint64) kyber.Scalar {
+	high := KyberSuite.Scalar().SetInt64(int64(i >> 1))
+	result := KyberSuite.Scalar().Add(high, high)
+	return result.Add(result, KyberSuite.Scalar().SetInt64(int64(i&1)))
+}
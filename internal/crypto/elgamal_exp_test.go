@@ -0,0 +1,145 @@
+package crypto
+
+import (
+	"testing"
+)
+
+func TestDecryptExp(t *testing.T) {
+	table, err := NewBSGSTable(1 << 20)
+	if err != nil {
+		t.Fatalf("NewBSGSTable() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		amounts []int64
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:    "test_single",
+			amounts: []int64{11},
+			want:    11,
+			wantErr: false,
+		},
+		{
+			name:    "test_sum",
+			amounts: []int64{100, -250, 1000},
+			want:    850,
+			wantErr: false,
+		},
+		{
+			name:    "test_lower_bound",
+			amounts: []int64{-1 << 20},
+			want:    -1 << 20,
+			wantErr: false,
+		},
+		{
+			name:    "test_out_of_bound",
+			amounts: []int64{1 << 20, 1},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateKey, publicKey, err := KeyGen(RandomStream())
+			if err != nil {
+				t.Errorf("KeyGen() error = %v", err)
+				return
+			}
+			cipherTexts := make([]*CipherText, len(tt.amounts))
+			for idx, amount := range tt.amounts {
+				cipherTexts[idx], err = EncryptExp(publicKey, amount, RandomStream())
+				if err != nil {
+					t.Errorf("EncryptExp() error = %v", err)
+					return
+				}
+			}
+			sum, err := SumCipherTexts(cipherTexts)
+			if err != nil {
+				t.Errorf("SumCipherTexts() error = %v", err)
+				return
+			}
+			plainText, err := DecryptExp(privateKey, sum, table)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DecryptExp() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && plainText != tt.want {
+				t.Errorf("DecryptExp() plainText = %v, want %v", plainText, tt.want)
+			}
+		})
+	}
+}
+
+func TestCipherText_Mul(t *testing.T) {
+	table, err := NewBSGSTable(1 << 16)
+	if err != nil {
+		t.Fatalf("NewBSGSTable() error = %v", err)
+	}
+	privateKey, publicKey, err := KeyGen(RandomStream())
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	cipherText1, err := EncryptExp(publicKey, 7, RandomStream())
+	if err != nil {
+		t.Fatalf("EncryptExp() error = %v", err)
+	}
+	cipherText2, err := EncryptExp(publicKey, -3, RandomStream())
+	if err != nil {
+		t.Fatalf("EncryptExp() error = %v", err)
+	}
+	// 7 * 5 + (-3) = 32
+	result := cipherText1.Mul(5).Add(cipherText2)
+	plainText, err := DecryptExp(privateKey, result, table)
+	if err != nil {
+		t.Fatalf("DecryptExp() error = %v", err)
+	}
+	if plainText != 32 {
+		t.Errorf("DecryptExp() plainText = %v, want %v", plainText, 32)
+	}
+}
+
+func TestNewAssetBSGSTable(t *testing.T) {
+	table, err := NewAssetBSGSTable("USD", 1<<16)
+	if err != nil {
+		t.Fatalf("NewAssetBSGSTable() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		asset   string
+		amount  int64
+		wantErr bool
+	}{
+		{
+			name:    "test_same_asset",
+			asset:   "USD",
+			amount:  -1234,
+			wantErr: false,
+		},
+		{
+			name:    "test_different_asset",
+			asset:   "EUR",
+			amount:  1234,
+			wantErr: true,
+		},
+		{
+			name:    "test_untagged",
+			asset:   "",
+			amount:  1234,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amountPoint := KyberSuite.Point().Mul(KyberSuite.Scalar().SetInt64(tt.amount), AssetGenerator(tt.asset))
+			got, err := table.Solve(amountPoint)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Solve() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.amount {
+				t.Errorf("Solve() got = %v, want %v", got, tt.amount)
+			}
+		})
+	}
+}
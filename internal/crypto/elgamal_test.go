@@ -28,13 +28,13 @@ func TestDecrypt(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			privateKey, publicKey, err := KeyGen()
+			privateKey, publicKey, err := KeyGen(RandomStream())
 			if err != nil {
 				t.Errorf("KeyGen() error = %v", err)
 				return
 			}
 			var cipherText *CipherText
-			cipherText, err = Encrypt(publicKey, tt.amount)
+			cipherText, err = Encrypt(publicKey, tt.amount, RandomStream())
 			if err != nil {
 				t.Errorf("Encrypt() error = %v", err)
 				return
@@ -92,3 +92,132 @@ func Test_int64ToBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestDecryptPointWithProof(t *testing.T) {
+	tests := []struct {
+		name    string
+		tamper  bool
+		wantOK  bool
+		wantErr bool
+	}{
+		{
+			name:    "test_honest",
+			tamper:  false,
+			wantOK:  true,
+			wantErr: false,
+		},
+		{
+			name:    "test_wrong_plaintext",
+			tamper:  true,
+			wantOK:  false,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			privateKey, publicKey, err := KeyGen(RandomStream())
+			if err != nil {
+				t.Errorf("KeyGen() error = %v", err)
+				return
+			}
+			dataPoint := KyberSuite.Point().Pick(KyberSuite.RandomStream())
+			cipherText, err := EncryptPoint(publicKey, dataPoint, RandomStream())
+			if err != nil {
+				t.Errorf("EncryptPoint() error = %v", err)
+				return
+			}
+			cipherTextBytes, err := cipherText.Serialize()
+			if err != nil {
+				t.Errorf("Serialize() error = %v", err)
+				return
+			}
+			decrypted, proof, err := DecryptPointWithProof(privateKey, cipherTextBytes, RandomStream())
+			if err != nil {
+				t.Errorf("DecryptPointWithProof() error = %v", err)
+				return
+			}
+			if !decrypted.Equal(dataPoint) {
+				t.Errorf("DecryptPointWithProof() = %v, want %v", decrypted, dataPoint)
+			}
+			proofBytes, err := proof.Serialize()
+			if err != nil {
+				t.Errorf("Serialize() error = %v", err)
+				return
+			}
+			proof, err = DeserializeDecryptionProof(proofBytes)
+			if err != nil {
+				t.Errorf("DeserializeDecryptionProof() error = %v", err)
+				return
+			}
+			if tt.tamper {
+				decrypted.Add(decrypted, PointG)
+			}
+			ok, err := VerifyDecryptionProof(publicKey, cipherTextBytes, decrypted, proof)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("VerifyDecryptionProof() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if ok != tt.wantOK {
+				t.Errorf("VerifyDecryptionProof() = %v, want %v", ok, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestDeserializeCipherText(t *testing.T) {
+	_, publicKey, err := KeyGen(RandomStream())
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	cipherText, err := EncryptPoint(publicKey, KyberSuite.Point().Pick(RandomStream()), RandomStream())
+	if err != nil {
+		t.Fatalf("EncryptPoint() error = %v", err)
+	}
+	cipherTextBytes, err := cipherText.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{
+			name:    "test_valid",
+			data:    cipherTextBytes,
+			wantErr: false,
+		},
+		{
+			name:    "test_empty",
+			data:    nil,
+			wantErr: true,
+		},
+		{
+			name:    "test_short",
+			data:    cipherTextBytes[:10],
+			wantErr: true,
+		},
+		{
+			name:    "test_one_point",
+			data:    cipherTextBytes[:len(cipherTextBytes)/2],
+			wantErr: true,
+		},
+		{
+			name:    "test_trailing_bytes",
+			data:    append(append([]byte{}, cipherTextBytes...), 0),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DeserializeCipherText(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("DeserializeCipherText() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && (!got.C1.Equal(cipherText.C1) || !got.C2.Equal(cipherText.C2)) {
+				t.Errorf("DeserializeCipherText() = %v, want %v", got, cipherText)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package crypto
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"go.dedis.ch/kyber/v3"
+)
+
+// The generators of the commitments are hashed to the curve with HashToCurve, the suite HashToCurveSuiteID of RFC 9380,
+// under the domain-separation tag of the registry and with the label as the message,
+// so no discrete log relation between them is known.
+// The domain-separation tag (DST) keeps the generators apart from every other use of the hash.
+const (
+	// GeneratorDST is the domain-separation tag of the generator registry.
+	GeneratorDST = "AUTI-V01-GEN01-with-" + HashToCurveSuiteID
+	// GeneratorLabelBlinding labels H, the generator of the blinding terms of the commitments.
+	GeneratorLabelBlinding = "pedersen-blinding"
+	// GeneratorLabelAssetPrefix prefixes the asset code in the label of an asset generator.
+	GeneratorLabelAssetPrefix = "pedersen-asset:"
+)
+
+// GeneratorRegistry derives and caches the labelled generators of a domain-separation tag.
+// It is safe for concurrent use.
+type GeneratorRegistry struct {
+	dst        string
+	generators sync.Map
+}
+
+// NewGeneratorRegistry creates a registry of the generators of the domain-separation tag.
+func NewGeneratorRegistry(dst string) (*GeneratorRegistry, error) {
+	if len(dst) == 0 || len(dst) > 255 {
+		return nil, errors.New("domain-separation tag must be 1 to 255 bytes long")
+	}
+	return &GeneratorRegistry{dst: dst}, nil
+}
+
+// DST returns the domain-separation tag of the registry.
+func (r *GeneratorRegistry) DST() string {
+	return r.dst
+}
+
+// Generator returns a copy of the generator of the label, the generator is derived on first use.
+func (r *GeneratorRegistry) Generator(label string) kyber.Point {
+	if generator, ok := r.generators.Load(label); ok {
+		return KyberSuite.Point().Set(generator.(kyber.Point))
+	}
+	generator, err := HashToCurve(r.dst, []byte(label))
+	if err != nil {
+		// the tag is checked by the constructor, the map fails only with negligible probability
+		panic(err)
+	}
+	r.generators.Store(label, generator)
+	return KyberSuite.Point().Set(generator)
+}
+
+// Generators is the registry of the generators of the commitments.
+var Generators = mustGeneratorRegistry(GeneratorDST)
+
+func mustGeneratorRegistry(dst string) *GeneratorRegistry {
+	registry, err := NewGeneratorRegistry(dst)
+	if err != nil {
+		panic(err)
+	}
+	return registry
+}
+
+// BlindingGeneratorVersion identifies the blinding generator H of a commitment, it is recorded with the CLOLC
+// local chain entries, so the entries committed under either generator can be verified side by side.
+type BlindingGeneratorVersion byte
+
+const (
+	// BlindingGeneratorLegacy is LegacyPointH, the generator of the entries recorded before the generators were versioned.
+	BlindingGeneratorLegacy BlindingGeneratorVersion = iota
+	// BlindingGeneratorHashed is PointH, hashed to the curve under GeneratorDST.
+	BlindingGeneratorHashed
+)
+
+// DefaultBlindingGenerator is the blinding generator of the new commitments.
+const DefaultBlindingGenerator = BlindingGeneratorHashed
+
+// LegacyPointH is the blinding generator of the first release, hScalar*G.
+// Its discrete log is public, so the commitments under it are not binding,
+// it is only kept to open and verify the data committed with it.
+var LegacyPointH = KyberSuite.Point().Mul(hScalar, nil)
+
+// BlindingGenerator returns the blinding generator H of the version.
+func BlindingGenerator(version BlindingGeneratorVersion) (kyber.Point, error) {
+	switch version {
+	case BlindingGeneratorLegacy:
+		return LegacyPointH, nil
+	case BlindingGeneratorHashed:
+		return PointH, nil
+	default:
+		return nil, fmt.Errorf("unknown blinding generator: %d", version)
+	}
+}
+
+// CheckBlindingGenerator rejects the unknown blinding generators, and BlindingGeneratorLegacy,
+// whose commitments are not binding, unless the entry was created at the timestamp before the legacy cutoff.
+// The cutoff in Unix seconds is the migration flag of the data committed under LegacyPointH,
+// the cutoff 0, the default, admits no legacy entry.
+func CheckBlindingGenerator(version BlindingGeneratorVersion, timestamp, legacyCutoff int64) error {
+	switch version {
+	case BlindingGeneratorHashed:
+		return nil
+	case BlindingGeneratorLegacy:
+		if timestamp < legacyCutoff {
+			return nil
+		}
+		return fmt.Errorf("legacy blinding generator for an entry created at %d, the cutoff is %d", timestamp, legacyCutoff)
+	default:
+		return fmt.Errorf("unknown blinding generator: %d", version)
+	}
+}
@@ -0,0 +1,200 @@
+package crypto
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"go.dedis.ch/kyber/v3"
+)
+
+func TestNewGeneratorRegistry(t *testing.T) {
+	tests := []struct {
+		name    string
+		dst     string
+		label   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "test_blinding_generator",
+			dst:     GeneratorDST,
+			label:   GeneratorLabelBlinding,
+			want:    "2af7402e8f5f222602eda094be92ad49c7a783656e2f2effe7ba2ab6dd7de45d",
+			wantErr: false,
+		},
+		{
+			name:    "test_asset_generator",
+			dst:     GeneratorDST,
+			label:   GeneratorLabelAssetPrefix + "USD",
+			want:    "bcde367fcd0de28f392665c897a548b28c4f4e8bd1cfc177d05c895d89d665de",
+			wantErr: false,
+		},
+		{
+			name:    "test_empty_dst",
+			dst:     "",
+			wantErr: true,
+		},
+		{
+			name:    "test_long_dst",
+			dst:     strings.Repeat("a", 256),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := NewGeneratorRegistry(tt.dst)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewGeneratorRegistry() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			gotBytes, err := registry.Generator(tt.label).MarshalBinary()
+			if err != nil {
+				t.Errorf("MarshalBinary() error = %v", err)
+				return
+			}
+			if hex.EncodeToString(gotBytes) != tt.want {
+				t.Errorf("Generator() = %x, want %s", gotBytes, tt.want)
+			}
+		})
+	}
+}
+
+func TestGeneratorRegistry_Generator(t *testing.T) {
+	registry, err := NewGeneratorRegistry("AUTI-V1-TEST")
+	if err != nil {
+		t.Fatalf("NewGeneratorRegistry() error = %v", err)
+	}
+	if registry.Generator("abc").Equal(Generators.Generator("abc")) {
+		t.Errorf("Generator() is not separated by the domain-separation tag")
+	}
+	if registry.Generator("abc").Equal(registry.Generator("abd")) {
+		t.Errorf("Generator() is not separated by the label")
+	}
+	want, _ := HashToCurve("AUTI-V1-TEST", []byte("abc"))
+	if !registry.Generator("abc").Equal(want) {
+		t.Errorf("Generator() = %v, want %v", registry.Generator("abc"), want)
+	}
+	// the returned generator is a copy, modifying it does not affect the cache
+	registry.Generator("abc").Null()
+	if !registry.Generator("abc").Equal(want) {
+		t.Errorf("Generator() returned the cached point")
+	}
+	if !PointH.Equal(Generators.Generator(GeneratorLabelBlinding)) {
+		t.Errorf("PointH is not the blinding generator of the registry")
+	}
+	if PointH.Equal(LegacyPointH) {
+		t.Errorf("PointH = LegacyPointH")
+	}
+}
+
+func TestBlindingGenerator(t *testing.T) {
+	tests := []struct {
+		name    string
+		version BlindingGeneratorVersion
+		other   BlindingGeneratorVersion
+		want    kyber.Point
+	}{
+		{
+			name:    "test_legacy",
+			version: BlindingGeneratorLegacy,
+			other:   BlindingGeneratorHashed,
+			want:    LegacyPointH,
+		},
+		{
+			name:    "test_hashed",
+			version: BlindingGeneratorHashed,
+			other:   BlindingGeneratorLegacy,
+			want:    PointH,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := BlindingGenerator(tt.version)
+			if err != nil || !got.Equal(tt.want) {
+				t.Fatalf("BlindingGenerator() = %v, %v, want %v", got, err, tt.want)
+			}
+			// the commitments of both versions are handled side by side, each under its own generator
+			commitment, randScalar, err := PedersenCommit(tt.version, "USD", 42, RandomStream())
+			if err != nil {
+				t.Fatalf("PedersenCommit() error = %v", err)
+			}
+			if ok, err := VerifyPedersenOpening(commitment, tt.version, "USD", 42, randScalar); err != nil || !ok {
+				t.Errorf("VerifyPedersenOpening() = %v, %v, want true", ok, err)
+			}
+			if ok, _ := VerifyPedersenOpening(commitment, tt.other, "USD", 42, randScalar); ok {
+				t.Errorf("VerifyPedersenOpening() under the other generator = %v, want false", ok)
+			}
+			proof, err := NewSignedRangeProof(tt.version, "USD", []int64{42}, []kyber.Scalar{randScalar}, 8, RandomStream())
+			if err != nil {
+				t.Fatalf("NewSignedRangeProof() error = %v", err)
+			}
+			if ok, err := VerifySignedRangeProof(tt.version, "USD", []kyber.Point{commitment}, proof); err != nil || !ok {
+				t.Errorf("VerifySignedRangeProof() = %v, %v, want true", ok, err)
+			}
+			if ok, _ := VerifySignedRangeProof(tt.other, "USD", []kyber.Point{commitment}, proof); ok {
+				t.Errorf("VerifySignedRangeProof() under the other generator = %v, want false", ok)
+			}
+		})
+	}
+	if _, err := BlindingGenerator(BlindingGeneratorHashed + 1); err == nil {
+		t.Errorf("BlindingGenerator() of an unknown version error = nil, want error")
+	}
+}
+
+func TestCheckBlindingGenerator(t *testing.T) {
+	tests := []struct {
+		name         string
+		version      BlindingGeneratorVersion
+		timestamp    int64
+		legacyCutoff int64
+		wantErr      bool
+	}{
+		{
+			name:         "test_hashed",
+			version:      BlindingGeneratorHashed,
+			timestamp:    100,
+			legacyCutoff: 0,
+			wantErr:      false,
+		},
+		{
+			name:         "test_legacy_without_cutoff",
+			version:      BlindingGeneratorLegacy,
+			timestamp:    100,
+			legacyCutoff: 0,
+			wantErr:      true,
+		},
+		{
+			name:         "test_legacy_before_cutoff",
+			version:      BlindingGeneratorLegacy,
+			timestamp:    99,
+			legacyCutoff: 100,
+			wantErr:      false,
+		},
+		{
+			name:         "test_legacy_at_cutoff",
+			version:      BlindingGeneratorLegacy,
+			timestamp:    100,
+			legacyCutoff: 100,
+			wantErr:      true,
+		},
+		{
+			name:         "test_unknown",
+			version:      BlindingGeneratorHashed + 1,
+			timestamp:    99,
+			legacyCutoff: 100,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckBlindingGenerator(tt.version, tt.timestamp, tt.legacyCutoff)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckBlindingGenerator() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
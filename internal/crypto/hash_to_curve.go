@@ -0,0 +1,185 @@
+package crypto
+
+import (
+	"crypto/sha512"
+	"errors"
+	"math/big"
+
+	"go.dedis.ch/kyber/v3"
+)
+
+// HashToCurveSuiteID is the RFC 9380 suite implemented by HashToCurve:
+// expand_message_xmd with SHA-512, hash_to_field with L = 48, Elligator 2 on curve25519
+// with the rational map to edwards25519, and the cofactor 8 cleared.
+const HashToCurveSuiteID = "edwards25519_XMD:SHA-512_ELL2_RO_"
+
+const (
+	// hashToFieldLen is L = ceil((ceil(log2(p)) + k) / 8) for k = 128
+	hashToFieldLen = 48
+	// sha512BlockSize is the input block size s_in_bytes of SHA-512
+	sha512BlockSize = 128
+)
+
+var (
+	// fieldP is the prime 2^255 - 19 of the field of curve25519 and edwards25519
+	fieldP = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	// montgomeryJ is the coefficient J of curve25519, K is 1
+	montgomeryJ = big.NewInt(486662)
+	// elligatorZ is the non-square Z of the Elligator 2 map
+	elligatorZ = big.NewInt(2)
+	// edwardsC1 is sqrt(-486664) with sgn0 equal to 0, the scale of the rational map
+	edwardsC1 = func() *big.Int {
+		c1 := fieldSqrt(new(big.Int).Sub(fieldP, big.NewInt(486664)))
+		if c1.Bit(0) == 1 {
+			c1.Sub(fieldP, c1)
+		}
+		return c1
+	}()
+)
+
+// HashToCurve hashes the message to a point of the prime-order subgroup of edwards25519
+// as specified for the suite HashToCurveSuiteID in RFC 9380, the domain-separation tag must be 1 to 255 bytes long.
+func HashToCurve(dst string, msg []byte) (kyber.Point, error) {
+	if len(dst) == 0 || len(dst) > 255 {
+		return nil, errors.New("domain-separation tag must be 1 to 255 bytes long")
+	}
+	u := hashToField(msg, []byte(dst), 2)
+	q0, err := mapToEdwards25519(u[0])
+	if err != nil {
+		return nil, err
+	}
+	q1, err := mapToEdwards25519(u[1])
+	if err != nil {
+		return nil, err
+	}
+	point := KyberSuite.Point().Add(q0, q1)
+	// clear_cofactor with h_eff = 8
+	return point.Mul(KyberSuite.Scalar().SetInt64(8), point), nil
+}
+
+// expandMessageXMD is expand_message_xmd of RFC 9380 with SHA-512, lenInBytes is at most 255 * 64.
+func expandMessageXMD(msg, dst []byte, lenInBytes int) []byte {
+	ell := (lenInBytes + sha512.Size - 1) / sha512.Size
+	dstPrime := append(append([]byte{}, dst...), byte(len(dst)))
+	h := sha512.New()
+	h.Write(make([]byte, sha512BlockSize))
+	h.Write(msg)
+	h.Write([]byte{byte(lenInBytes >> 8), byte(lenInBytes), 0})
+	h.Write(dstPrime)
+	b0 := h.Sum(nil)
+	h.Reset()
+	h.Write(b0)
+	h.Write([]byte{1})
+	h.Write(dstPrime)
+	bi := h.Sum(nil)
+	uniformBytes := append([]byte{}, bi...)
+	for i := 2; i <= ell; i++ {
+		xored := make([]byte, sha512.Size)
+		for j := range xored {
+			xored[j] = b0[j] ^ bi[j]
+		}
+		h.Reset()
+		h.Write(xored)
+		h.Write([]byte{byte(i)})
+		h.Write(dstPrime)
+		bi = h.Sum(nil)
+		uniformBytes = append(uniformBytes, bi...)
+	}
+	return uniformBytes[:lenInBytes]
+}
+
+// hashToField is hash_to_field of RFC 9380 for the field of edwards25519.
+func hashToField(msg, dst []byte, count int) []*big.Int {
+	uniformBytes := expandMessageXMD(msg, dst, count*hashToFieldLen)
+	u := make([]*big.Int, count)
+	for i := range u {
+		u[i] = new(big.Int).SetBytes(uniformBytes[i*hashToFieldLen : (i+1)*hashToFieldLen])
+		u[i].Mod(u[i], fieldP)
+	}
+	return u
+}
+
+// mapToEdwards25519 maps the field element to curve25519 with Elligator 2,
+// and then to edwards25519 with the rational map of RFC 9380 Appendix D.1.
+func mapToEdwards25519(u *big.Int) (kyber.Point, error) {
+	s, t := mapToCurveElligator2(u)
+	// the rational map is undefined for t == 0 and s == -1, both map to the identity
+	sPlusOne := new(big.Int).Add(s, big.NewInt(1))
+	sPlusOne.Mod(sPlusOne, fieldP)
+	if t.Sign() == 0 || sPlusOne.Sign() == 0 {
+		return KyberSuite.Point().Null(), nil
+	}
+	// x = sqrt(-486664) * s / t, y = (s - 1) / (s + 1)
+	x := new(big.Int).Mul(edwardsC1, s)
+	x.Mul(x, new(big.Int).ModInverse(t, fieldP))
+	x.Mod(x, fieldP)
+	y := new(big.Int).Sub(s, big.NewInt(1))
+	y.Mul(y, new(big.Int).ModInverse(sPlusOne, fieldP))
+	y.Mod(y, fieldP)
+	return edwardsPoint(x, y)
+}
+
+// mapToCurveElligator2 is the Elligator 2 method of RFC 9380 Section 6.7.1 for curve25519.
+func mapToCurveElligator2(u *big.Int) (*big.Int, *big.Int) {
+	// x1 = -J * inv0(1 + Z * u^2), x1 = -J if x1 == 0
+	x1 := new(big.Int).Mul(u, u)
+	x1.Mul(x1, elligatorZ)
+	x1.Add(x1, big.NewInt(1))
+	x1.Mod(x1, fieldP)
+	if x1.Sign() != 0 {
+		x1.ModInverse(x1, fieldP)
+	}
+	x1.Mul(x1, montgomeryJ)
+	x1.Neg(x1)
+	x1.Mod(x1, fieldP)
+	if x1.Sign() == 0 {
+		x1.Sub(fieldP, montgomeryJ)
+	}
+	// x2 = -x1 - J
+	x2 := new(big.Int).Add(x1, montgomeryJ)
+	x2.Neg(x2)
+	x2.Mod(x2, fieldP)
+	// the square root of gx1 has sgn0 equal to 1, and the one of gx2 has sgn0 equal to 0
+	if gx1 := montgomeryRHS(x1); big.Jacobi(gx1, fieldP) >= 0 {
+		y := fieldSqrt(gx1)
+		if y.Bit(0) == 0 && y.Sign() != 0 {
+			y.Sub(fieldP, y)
+		}
+		return x1, y
+	}
+	y := fieldSqrt(montgomeryRHS(x2))
+	if y.Bit(0) == 1 {
+		y.Sub(fieldP, y)
+	}
+	return x2, y
+}
+
+// montgomeryRHS returns x^3 + J * x^2 + x.
+func montgomeryRHS(x *big.Int) *big.Int {
+	rhs := new(big.Int).Add(x, montgomeryJ)
+	rhs.Mul(rhs, x)
+	rhs.Add(rhs, big.NewInt(1))
+	rhs.Mul(rhs, x)
+	return rhs.Mod(rhs, fieldP)
+}
+
+// fieldSqrt returns a square root of the square.
+func fieldSqrt(square *big.Int) *big.Int {
+	return new(big.Int).ModSqrt(square, fieldP)
+}
+
+// edwardsPoint converts the affine coordinates to a point by decoding their compressed encoding,
+// the little-endian y with the sign of x in the most significant bit.
+func edwardsPoint(x, y *big.Int) (kyber.Point, error) {
+	encoding := make([]byte, 32)
+	y.FillBytes(encoding)
+	for i, j := 0, len(encoding)-1; i < j; i, j = i+1, j-1 {
+		encoding[i], encoding[j] = encoding[j], encoding[i]
+	}
+	encoding[31] |= byte(x.Bit(0)) << 7
+	point := KyberSuite.Point()
+	if err := point.UnmarshalBinary(encoding); err != nil {
+		return nil, err
+	}
+	return point, nil
+}
@@ -0,0 +1,65 @@
+package crypto
+
+import (
+	"math/big"
+	"testing"
+)
+
+// the test vectors of edwards25519_XMD:SHA-512_ELL2_RO_ from RFC 9380 Appendix J.5.1
+const rfc9380TestDST = "QUUX-V01-CS02-with-edwards25519_XMD:SHA-512_ELL2_RO_"
+
+func TestHashToCurve(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+		u0   string
+		u1   string
+		x    string
+		y    string
+	}{
+		{
+			name: "test_empty",
+			msg:  "",
+			u0:   "03fef4813c8cb5f98c6eef88fae174e6e7d5380de2b007799ac7ee712d203f3a",
+			u1:   "780bdddd137290c8f589dc687795aafae35f6b674668d92bf92ae793e6a60c75",
+			x:    "3c3da6925a3c3c268448dcabb47ccde5439559d9599646a8260e47b1e4822fc6",
+			y:    "09a6c8561a0b22bef63124c588ce4c62ea83a3c899763af26d795302e115dc21",
+		},
+		{
+			name: "test_abc",
+			msg:  "abc",
+			u0:   "5081955c4141e4e7d02ec0e36becffaa1934df4d7a270f70679c78f9bd57c227",
+			u1:   "005bdc17a9b378b6272573a31b04361f21c371b256252ae5463119aa0b925b76",
+			x:    "608040b42285cc0d72cbb3985c6b04c935370c7361f4b7fbdb1ae7f8c1a8ecad",
+			y:    "1a8395b88338f22e435bbd301183e7f20a5f9de643f11882fb237f88268a5531",
+		},
+	}
+	hexToInt := func(s string) *big.Int {
+		i, _ := new(big.Int).SetString(s, 16)
+		return i
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := hashToField([]byte(tt.msg), []byte(rfc9380TestDST), 2)
+			if u[0].Cmp(hexToInt(tt.u0)) != 0 || u[1].Cmp(hexToInt(tt.u1)) != 0 {
+				t.Errorf("hashToField() = [%x, %x], want [%s, %s]", u[0], u[1], tt.u0, tt.u1)
+			}
+			got, err := HashToCurve(rfc9380TestDST, []byte(tt.msg))
+			if err != nil {
+				t.Errorf("HashToCurve() error = %v", err)
+				return
+			}
+			want, err := edwardsPoint(hexToInt(tt.x), hexToInt(tt.y))
+			if err != nil {
+				t.Errorf("edwardsPoint() error = %v", err)
+				return
+			}
+			if !got.Equal(want) {
+				t.Errorf("HashToCurve() = %v, want %v", got, want)
+			}
+		})
+	}
+	if _, err := HashToCurve("", nil); err == nil {
+		t.Errorf("HashToCurve() error = %v, want an error for the empty tag", err)
+	}
+}
@@ -81,11 +81,10 @@ func NewMerkleBatchProof(dataBlocks []mt.DataBlock, proofs []*mt.Proof) (*Merkle
 	batchProofs := new(MerkleBatchProof)
 	batchProofs.Indexes = make([]int, len(dataBlocks))
 	for i := 0; i < len(dataBlocks); i++ {
-		blockBytes, err := dataBlocks[i].Serialize()
+		blockHash, err := leafBlock{block: dataBlocks[i]}.Serialize()
 		if err != nil {
 			return nil, err
 		}
-		blockHash, _ := hashFunc(blockBytes)
 		nodeIdx := maxNodeIdx - proofs[i].Path
 		batchProofs.Indexes[i] = int(nodeIdx)
 		nodeBuffer[0][nodeIdx] = blockHash
@@ -153,14 +152,13 @@ func VerifyMerkleBatchProof(dataBlocks []mt.DataBlock, batchProof *MerkleBatchPr
 		heap.Push(pq, proofNode)
 	}
 	for idx, block := range dataBlocks {
-		blockBytes, err := block.Serialize()
+		blockHash, err := leafBlock{block: block}.Serialize()
 		if err != nil {
 			return false, err
 		}
-		//blockHash, _ := hashFunc(blockBytes)
 		heap.Push(pq, ProofNode{
 			Coordinate: [2]int{0, batchProof.Indexes[idx]},
-			Data:       blockBytes,
+			Data:       blockHash,
 		})
 	}
 	for len(*pq) > 1 {
@@ -1,28 +1,43 @@
 package crypto
 
 import (
-	"crypto/sha256"
 	"encoding/json"
 
 	mt "github.com/txaty/go-merkletree"
-)
 
-var (
-	sha256Digest     = sha256.New()
-	merkleTreeConfig = &mt.Config{
-		HashFunc:           hashFunc,
-		DisableLeafHashing: true,
-	}
+	"github.com/auti-project/auti/internal/hashing"
 )
 
+// the tree hashes the inner nodes, and the leaves are hashed under their own tag by leafBlock
+var merkleTreeConfig = &mt.Config{
+	HashFunc:           hashFunc,
+	DisableLeafHashing: true,
+}
+
 func hashFunc(data []byte) ([]byte, error) {
-	defer sha256Digest.Reset()
-	sha256Digest.Write(data)
-	return sha256Digest.Sum(nil), nil
+	return hashing.Sum(hashing.TagMerkleNode, data), nil
+}
+
+// leafBlock serializes to the hash of the data block under hashing.TagMerkleLeaf,
+// so a leaf can never be taken for an inner node.
+type leafBlock struct {
+	block mt.DataBlock
+}
+
+func (l leafBlock) Serialize() ([]byte, error) {
+	blockBytes, err := l.block.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return hashing.Sum(hashing.TagMerkleLeaf, blockBytes), nil
 }
 
 func GenerateMerkleProofs(dataBlocks []mt.DataBlock) ([]*mt.Proof, []byte, error) {
-	tree, err := mt.New(merkleTreeConfig, dataBlocks)
+	leafBlocks := make([]mt.DataBlock, len(dataBlocks))
+	for idx, block := range dataBlocks {
+		leafBlocks[idx] = leafBlock{block: block}
+	}
+	tree, err := mt.New(merkleTreeConfig, leafBlocks)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -30,7 +45,7 @@ func GenerateMerkleProofs(dataBlocks []mt.DataBlock) ([]*mt.Proof, []byte, error
 }
 
 func VerifyMerkleProof(block mt.DataBlock, proof *mt.Proof, root []byte) (bool, error) {
-	return mt.Verify(block, proof, root, merkleTreeConfig)
+	return mt.Verify(leafBlock{block: block}, proof, root, merkleTreeConfig)
 }
 
 type MerkleProof struct {
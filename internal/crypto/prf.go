@@ -0,0 +1,33 @@
+package crypto
+
+import (
+	"encoding/binary"
+
+	"go.dedis.ch/kyber/v3"
+)
+
+const prfTag = "auti-prf-scalar"
+
+// PRFScalar returns the scalar at the index of the pseudorandom sequence keyed by the seed,
+// anyone holding the seed derives the same scalar without materializing the sequence.
+func PRFScalar(seed []byte, idx uint64) kyber.Scalar {
+	// tag || len(seed) || seed || idx, the length prefix keeps the encoding unambiguous
+	input := make([]byte, 0, len(prfTag)+len(seed)+16)
+	input = append(input, prfTag...)
+	input = binary.BigEndian.AppendUint64(input, uint64(len(seed)))
+	input = append(input, seed...)
+	input = binary.BigEndian.AppendUint64(input, idx)
+	return KyberSuite.Scalar().Pick(KyberSuite.XOF(input))
+}
+
+// PRFScalars returns the scalars at the indexes in [start, end) of the sequence keyed by the seed.
+func PRFScalars(seed []byte, start, end uint64) []kyber.Scalar {
+	if end < start {
+		return nil
+	}
+	results := make([]kyber.Scalar, end-start)
+	for idx := range results {
+		results[idx] = PRFScalar(seed, start+uint64(idx))
+	}
+	return results
+}
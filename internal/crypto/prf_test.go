@@ -0,0 +1,74 @@
+package crypto
+
+import (
+	"testing"
+)
+
+func TestPRFScalar(t *testing.T) {
+	tests := []struct {
+		name      string
+		seed1     []byte
+		idx1      uint64
+		seed2     []byte
+		idx2      uint64
+		wantEqual bool
+	}{
+		{
+			name:      "test_same_seed_same_index",
+			seed1:     []byte("seed"),
+			idx1:      7,
+			seed2:     []byte("seed"),
+			idx2:      7,
+			wantEqual: true,
+		},
+		{
+			name:      "test_same_seed_different_indexes",
+			seed1:     []byte("seed"),
+			idx1:      7,
+			seed2:     []byte("seed"),
+			idx2:      8,
+			wantEqual: false,
+		},
+		{
+			name:      "test_different_seeds",
+			seed1:     []byte("seed1"),
+			idx1:      7,
+			seed2:     []byte("seed2"),
+			idx2:      7,
+			wantEqual: false,
+		},
+		{
+			name:      "test_seed_index_boundary",
+			seed1:     []byte("seed\x00"),
+			idx1:      0,
+			seed2:     []byte("seed"),
+			idx2:      0,
+			wantEqual: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scalar1 := PRFScalar(tt.seed1, tt.idx1)
+			scalar2 := PRFScalar(tt.seed2, tt.idx2)
+			if got := scalar1.Equal(scalar2); got != tt.wantEqual {
+				t.Errorf("PRFScalar() equal = %v, want %v", got, tt.wantEqual)
+			}
+		})
+	}
+}
+
+func TestPRFScalars(t *testing.T) {
+	seed := []byte("seed")
+	scalars := PRFScalars(seed, 1000, 1010)
+	if len(scalars) != 10 {
+		t.Fatalf("PRFScalars() length = %d, want %d", len(scalars), 10)
+	}
+	for idx, scalar := range scalars {
+		if !scalar.Equal(PRFScalar(seed, 1000+uint64(idx))) {
+			t.Errorf("PRFScalars()[%d] does not match PRFScalar()", idx)
+		}
+	}
+	if scalars = PRFScalars(seed, 5, 4); len(scalars) != 0 {
+		t.Errorf("PRFScalars() length = %d, want %d", len(scalars), 0)
+	}
+}
@@ -1,26 +1,49 @@
 package crypto
 
 import (
-	"crypto/rand"
+	"crypto/cipher"
+	"sync"
 
 	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/util/random"
 
 	"github.com/auti-project/auti/internal/constants"
 )
 
-func RandBytes() ([]byte, error) {
+// RandomStream returns the default randomness source, which is backed by the OS.
+func RandomStream() cipher.Stream {
+	return KyberSuite.RandomStream()
+}
+
+// NewSeededStream returns a deterministic randomness source derived from the seed.
+// It is only meant for tests and benchmark replays, never for production runs.
+// The stream is safe for concurrent use, but the values drawn by concurrent callers
+// are only reproducible if the calls happen in the same order.
+func NewSeededStream(seed []byte) cipher.Stream {
+	return &lockedStream{stream: KyberSuite.XOF(seed)}
+}
+
+type lockedStream struct {
+	mu     sync.Mutex
+	stream cipher.Stream
+}
+
+func (l *lockedStream) XORKeyStream(dst, src []byte) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stream.XORKeyStream(dst, src)
+}
+
+func RandBytes(rand cipher.Stream) ([]byte, error) {
 	result := make([]byte, constants.SecurityParameterBytes)
-	_, err := rand.Read(result)
-	if err != nil {
-		return nil, err
-	}
+	random.Bytes(result, rand)
 	return result, nil
 }
 
-func RandScalars(size int) []kyber.Scalar {
+func RandScalars(size int, rand cipher.Stream) []kyber.Scalar {
 	results := make([]kyber.Scalar, size)
 	for i := 0; i < size; i++ {
-		randScalar := KyberSuite.Scalar().Pick(KyberSuite.RandomStream())
+		randScalar := KyberSuite.Scalar().Pick(rand)
 		results[i] = randScalar
 	}
 	return results
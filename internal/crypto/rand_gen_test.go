@@ -0,0 +1,73 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestNewSeededStream(t *testing.T) {
+	tests := []struct {
+		name      string
+		seed1     []byte
+		seed2     []byte
+		wantEqual bool
+	}{
+		{
+			name:      "test_same_seed",
+			seed1:     []byte("seed"),
+			seed2:     []byte("seed"),
+			wantEqual: true,
+		},
+		{
+			name:      "test_different_seeds",
+			seed1:     []byte("seed1"),
+			seed2:     []byte("seed2"),
+			wantEqual: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream1 := NewSeededStream(tt.seed1)
+			stream2 := NewSeededStream(tt.seed2)
+			randBytes1, err := RandBytes(stream1)
+			if err != nil {
+				t.Errorf("RandBytes() error = %v", err)
+				return
+			}
+			randBytes2, err := RandBytes(stream2)
+			if err != nil {
+				t.Errorf("RandBytes() error = %v", err)
+				return
+			}
+			if got := bytes.Equal(randBytes1, randBytes2); got != tt.wantEqual {
+				t.Errorf("RandBytes() equal = %v, want %v", got, tt.wantEqual)
+			}
+			_, publicKey1, err := KeyGen(stream1)
+			if err != nil {
+				t.Errorf("KeyGen() error = %v", err)
+				return
+			}
+			_, publicKey2, err := KeyGen(stream2)
+			if err != nil {
+				t.Errorf("KeyGen() error = %v", err)
+				return
+			}
+			if got := publicKey1.Equal(publicKey2); got != tt.wantEqual {
+				t.Errorf("KeyGen() equal = %v, want %v", got, tt.wantEqual)
+			}
+			commitment1, _, err := PedersenCommit(DefaultBlindingGenerator, "USD", 100, stream1)
+			if err != nil {
+				t.Errorf("PedersenCommit(DefaultBlindingGenerator, ) error = %v", err)
+				return
+			}
+			commitment2, _, err := PedersenCommit(DefaultBlindingGenerator, "USD", 100, stream2)
+			if err != nil {
+				t.Errorf("PedersenCommit(DefaultBlindingGenerator, ) error = %v", err)
+				return
+			}
+			if got := commitment1.Equal(commitment2); got != tt.wantEqual {
+				t.Errorf("PedersenCommit(DefaultBlindingGenerator, ) equal = %v, want %v", got, tt.wantEqual)
+			}
+		})
+	}
+}
@@ -0,0 +1,370 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"go.dedis.ch/kyber/v3"
+)
+
+const (
+	maxRangeProofBitLen = 64
+	rangeProofDomainTag = "auti-range-proof"
+)
+
+// bitProof is the OR-proof that a bit commitment opens to either 0 or 1.
+type bitProof struct {
+	Commitment kyber.Point
+	E0         kyber.Scalar
+	Z0         kyber.Scalar
+	Z1         kyber.Scalar
+}
+
+// RangeProof proves that each Pedersen commitment of an asset in a batch opens to a value in [0, 2^BitLen).
+// The values are decomposed into bit commitments, and every bit carries a
+// Chaum-Pedersen OR-proof. All the OR-proofs in a batch share one Fiat-Shamir challenge,
+// which binds the value generator of the asset.
+type RangeProof struct {
+	BitLen    int
+	BitProofs [][]*bitProof
+	Challenge kyber.Scalar
+}
+
+// NewRangeProof proves that amount[i]*G_asset + randScalars[i]*H opens to a value in [0, 2^bitLen),
+// H is the blinding generator of the version.
+func NewRangeProof(version BlindingGeneratorVersion, asset string, amounts []int64, randScalars []kyber.Scalar,
+	bitLen int, rand cipher.Stream) (*RangeProof, error) {
+	if err := checkRangeProofBitLen(bitLen); err != nil {
+		return nil, err
+	}
+	blindingGen, err := BlindingGenerator(version)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]uint64, len(amounts))
+	for idx, amount := range amounts {
+		if amount < 0 || (bitLen < 63 && amount >= int64(1)<<uint(bitLen)) {
+			return nil, fmt.Errorf("amount %d out of range [0, 2^%d)", amount, bitLen)
+		}
+		values[idx] = uint64(amount)
+	}
+	return proveRange(AssetGenerator(asset), blindingGen, values, randScalars, bitLen, rand)
+}
+
+// NewSignedRangeProof proves that amount[i]*G_asset + randScalars[i]*H opens to a value
+// in [-2^(bitLen-1), 2^(bitLen-1)), H is the blinding generator of the version.
+func NewSignedRangeProof(version BlindingGeneratorVersion, asset string, amounts []int64,
+	randScalars []kyber.Scalar, bitLen int, rand cipher.Stream) (*RangeProof, error) {
+	if err := checkRangeProofBitLen(bitLen); err != nil {
+		return nil, err
+	}
+	if bitLen < 2 {
+		return nil, errors.New("signed range proof requires at least 2 bits")
+	}
+	blindingGen, err := BlindingGenerator(version)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]uint64, len(amounts))
+	for idx, amount := range amounts {
+		if bitLen < maxRangeProofBitLen {
+			bound := int64(1) << uint(bitLen-1)
+			if amount < -bound || amount >= bound {
+				return nil, fmt.Errorf("amount %d out of range [-2^%d, 2^%d)", amount, bitLen-1, bitLen-1)
+			}
+		}
+		// shift the amount into [0, 2^bitLen), the uint64 wraparound is intended
+		values[idx] = uint64(amount) + uint64(1)<<uint(bitLen-1)
+	}
+	return proveRange(AssetGenerator(asset), blindingGen, values, randScalars, bitLen, rand)
+}
+
+// VerifyRangeProof verifies that every commitment of the asset under the blinding generator of the version
+// opens to a value in [0, 2^proof.BitLen).
+func VerifyRangeProof(version BlindingGeneratorVersion, asset string, commitments []kyber.Point,
+	proof *RangeProof) (bool, error) {
+	blindingGen, err := BlindingGenerator(version)
+	if err != nil {
+		return false, err
+	}
+	return verifyRange(AssetGenerator(asset), blindingGen, commitments, proof, false)
+}
+
+// VerifySignedRangeProof verifies that every commitment of the asset under the blinding generator of the version
+// opens to a value in [-2^(proof.BitLen-1), 2^(proof.BitLen-1)).
+func VerifySignedRangeProof(version BlindingGeneratorVersion, asset string, commitments []kyber.Point,
+	proof *RangeProof) (bool, error) {
+	blindingGen, err := BlindingGenerator(version)
+	if err != nil {
+		return false, err
+	}
+	return verifyRange(AssetGenerator(asset), blindingGen, commitments, proof, true)
+}
+
+func checkRangeProofBitLen(bitLen int) error {
+	if bitLen <= 0 || bitLen > maxRangeProofBitLen {
+		return fmt.Errorf("invalid range proof bit length: %d", bitLen)
+	}
+	return nil
+}
+
+func proveRange(valueGen, blindingGen kyber.Point, values []uint64, randScalars []kyber.Scalar, bitLen int,
+	rand cipher.Stream) (*RangeProof, error) {
+	if len(values) != len(randScalars) {
+		return nil, errors.New("number of amounts and random scalars must be equal")
+	}
+	if len(values) == 0 {
+		return nil, errors.New("no amounts given")
+	}
+	proof := &RangeProof{
+		BitLen:    bitLen,
+		BitProofs: make([][]*bitProof, len(values)),
+	}
+	// the nonces and the simulated challenges of the branches, and the announcements of both branches
+	nonces := make([][]kyber.Scalar, len(values))
+	fakeChallenges := make([][]kyber.Scalar, len(values))
+	announcements := make([][][2]kyber.Point, len(values))
+	bitRandScalars := make([][]kyber.Scalar, len(values))
+	for idx, value := range values {
+		proof.BitProofs[idx] = make([]*bitProof, bitLen)
+		nonces[idx] = make([]kyber.Scalar, bitLen)
+		fakeChallenges[idx] = make([]kyber.Scalar, bitLen)
+		announcements[idx] = make([][2]kyber.Point, bitLen)
+		bitRandScalars[idx] = make([]kyber.Scalar, bitLen)
+		// r_0 = r - sum_{i > 0} 2^i * r_i, so that sum_i 2^i * C_i = v*G_asset + r*H
+		randSum := KyberSuite.Scalar().Zero()
+		for i := 1; i < bitLen; i++ {
+			bitRandScalars[idx][i] = KyberSuite.Scalar().Pick(rand)
+			tmp := KyberSuite.Scalar().Mul(powerOfTwoScalar(i), bitRandScalars[idx][i])
+			randSum.Add(randSum, tmp)
+		}
+		bitRandScalars[idx][0] = KyberSuite.Scalar().Sub(randScalars[idx], randSum)
+		for i := 0; i < bitLen; i++ {
+			bit := int((value >> uint(i)) & 1)
+			commitment := KyberSuite.Point().Mul(bitRandScalars[idx][i], blindingGen)
+			if bit == 1 {
+				commitment.Add(commitment, valueGen)
+			}
+			// simulate the branch that is not true
+			fakeE := KyberSuite.Scalar().Pick(rand)
+			fakeZ := KyberSuite.Scalar().Pick(rand)
+			fakeA := KyberSuite.Point().Mul(fakeZ, blindingGen)
+			fakeA.Sub(fakeA, KyberSuite.Point().Mul(fakeE, bitStatement(valueGen, commitment, 1-bit)))
+			// commit to the true branch
+			nonce := KyberSuite.Scalar().Pick(rand)
+			realA := KyberSuite.Point().Mul(nonce, blindingGen)
+			bp := &bitProof{Commitment: commitment}
+			if bit == 0 {
+				bp.Z1 = fakeZ
+				announcements[idx][i] = [2]kyber.Point{realA, fakeA}
+			} else {
+				bp.E0 = fakeE
+				bp.Z0 = fakeZ
+				announcements[idx][i] = [2]kyber.Point{fakeA, realA}
+			}
+			nonces[idx][i] = nonce
+			fakeChallenges[idx][i] = fakeE
+			proof.BitProofs[idx][i] = bp
+		}
+	}
+	challenge, err := rangeProofChallenge(valueGen, bitLen, proof.BitProofs, announcements)
+	if err != nil {
+		return nil, err
+	}
+	proof.Challenge = challenge
+	for idx, value := range values {
+		for i := 0; i < bitLen; i++ {
+			bp := proof.BitProofs[idx][i]
+			// the challenge of the true branch is e - e_fake
+			realE := KyberSuite.Scalar().Sub(challenge, fakeChallenges[idx][i])
+			realZ := KyberSuite.Scalar().Mul(realE, bitRandScalars[idx][i])
+			realZ.Add(realZ, nonces[idx][i])
+			if (value>>uint(i))&1 == 0 {
+				bp.E0 = realE
+				bp.Z0 = realZ
+			} else {
+				bp.Z1 = realZ
+			}
+		}
+	}
+	return proof, nil
+}
+
+func verifyRange(valueGen, blindingGen kyber.Point, commitments []kyber.Point, proof *RangeProof, signed bool) (bool, error) {
+	if proof == nil {
+		return false, errors.New("range proof is nil")
+	}
+	if err := checkRangeProofBitLen(proof.BitLen); err != nil {
+		return false, err
+	}
+	if len(commitments) != len(proof.BitProofs) {
+		return false, errors.New("number of commitments and range proofs must be equal")
+	}
+	if len(commitments) == 0 {
+		return false, errors.New("no commitments given")
+	}
+	announcements := make([][][2]kyber.Point, len(commitments))
+	for idx, commitment := range commitments {
+		bitProofs := proof.BitProofs[idx]
+		if len(bitProofs) != proof.BitLen {
+			return false, errors.New("invalid number of bit proofs")
+		}
+		// sum_i 2^i * C_i must be equal to the (shifted) commitment
+		acc := KyberSuite.Point().Null()
+		for i := proof.BitLen - 1; i >= 0; i-- {
+			acc.Add(acc, acc)
+			acc.Add(acc, bitProofs[i].Commitment)
+		}
+		target := KyberSuite.Point().Set(commitment)
+		if signed {
+			shift := KyberSuite.Point().Mul(powerOfTwoScalar(proof.BitLen-1), valueGen)
+			target.Add(target, shift)
+		}
+		if !acc.Equal(target) {
+			return false, nil
+		}
+		announcements[idx] = make([][2]kyber.Point, proof.BitLen)
+		for i, bp := range bitProofs {
+			e1 := KyberSuite.Scalar().Sub(proof.Challenge, bp.E0)
+			a0 := KyberSuite.Point().Mul(bp.Z0, blindingGen)
+			a0.Sub(a0, KyberSuite.Point().Mul(bp.E0, bitStatement(valueGen, bp.Commitment, 0)))
+			a1 := KyberSuite.Point().Mul(bp.Z1, blindingGen)
+			a1.Sub(a1, KyberSuite.Point().Mul(e1, bitStatement(valueGen, bp.Commitment, 1)))
+			announcements[idx][i] = [2]kyber.Point{a0, a1}
+		}
+	}
+	challenge, err := rangeProofChallenge(valueGen, proof.BitLen, proof.BitProofs, announcements)
+	if err != nil {
+		return false, err
+	}
+	return challenge.Equal(proof.Challenge), nil
+}
+
+// bitStatement returns C if bit is 0 and C - G_asset if bit is 1,
+// the prover knows the discrete log of the statement with respect to H.
+func bitStatement(valueGen, commitment kyber.Point, bit int) kyber.Point {
+	if bit == 0 {
+		return commitment
+	}
+	return KyberSuite.Point().Sub(commitment, valueGen)
+}
+
+func rangeProofChallenge(valueGen kyber.Point, bitLen int, bitProofs [][]*bitProof,
+	announcements [][][2]kyber.Point) (kyber.Scalar, error) {
+	sha256Func := sha256.New()
+	sha256Func.Write([]byte(rangeProofDomainTag))
+	valueGenBytes, err := valueGen.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	sha256Func.Write(valueGenBytes)
+	header := make([]byte, 8)
+	binary.BigEndian.PutUint32(header[:4], uint32(bitLen))
+	binary.BigEndian.PutUint32(header[4:], uint32(len(bitProofs)))
+	sha256Func.Write(header)
+	for idx := range bitProofs {
+		for i, bp := range bitProofs[idx] {
+			for _, point := range []kyber.Point{
+				bp.Commitment, announcements[idx][i][0], announcements[idx][i][1],
+			} {
+				pointBytes, err := point.MarshalBinary()
+				if err != nil {
+					return nil, err
+				}
+				sha256Func.Write(pointBytes)
+			}
+		}
+	}
+	return KyberSuite.Scalar().SetBytes(sha256Func.Sum(nil)), nil
+}
+
+func powerOfTwoScalar(exp int) kyber.Scalar {
+	// scalars are little-endian encoded
+	buf := make([]byte, KyberSuite.Scalar().MarshalSize())
+	buf[exp/8] = 1 << uint(exp%8)
+	return KyberSuite.Scalar().SetBytes(buf)
+}
+
+// Serialize encodes the range proof as
+// bitLen (4 bytes) || numValues (4 bytes) || {C_i || e0_i || z0_i || z1_i} || challenge.
+func (p *RangeProof) Serialize() ([]byte, error) {
+	pointSize := KyberSuite.Point().MarshalSize()
+	scalarSize := KyberSuite.Scalar().MarshalSize()
+	result := make([]byte, 8, 8+len(p.BitProofs)*p.BitLen*(pointSize+3*scalarSize)+scalarSize)
+	binary.BigEndian.PutUint32(result[:4], uint32(p.BitLen))
+	binary.BigEndian.PutUint32(result[4:], uint32(len(p.BitProofs)))
+	for _, bitProofs := range p.BitProofs {
+		if len(bitProofs) != p.BitLen {
+			return nil, errors.New("invalid number of bit proofs")
+		}
+		for _, bp := range bitProofs {
+			for _, m := range []interface{ MarshalBinary() ([]byte, error) }{
+				bp.Commitment, bp.E0, bp.Z0, bp.Z1,
+			} {
+				data, err := m.MarshalBinary()
+				if err != nil {
+					return nil, err
+				}
+				result = append(result, data...)
+			}
+		}
+	}
+	challengeBytes, err := p.Challenge.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return append(result, challengeBytes...), nil
+}
+
+// DeserializeRangeProof decodes a range proof produced by RangeProof.Serialize.
+func DeserializeRangeProof(data []byte) (*RangeProof, error) {
+	if len(data) < 8 {
+		return nil, errors.New("range proof is too short")
+	}
+	bitLen := int(binary.BigEndian.Uint32(data[:4]))
+	numValues := int(binary.BigEndian.Uint32(data[4:8]))
+	if err := checkRangeProofBitLen(bitLen); err != nil {
+		return nil, err
+	}
+	pointSize := KyberSuite.Point().MarshalSize()
+	scalarSize := KyberSuite.Scalar().MarshalSize()
+	bitProofSize := pointSize + 3*scalarSize
+	if len(data) != 8+numValues*bitLen*bitProofSize+scalarSize {
+		return nil, errors.New("invalid range proof length")
+	}
+	proof := &RangeProof{
+		BitLen:    bitLen,
+		BitProofs: make([][]*bitProof, numValues),
+	}
+	offset := 8
+	for idx := 0; idx < numValues; idx++ {
+		proof.BitProofs[idx] = make([]*bitProof, bitLen)
+		for i := 0; i < bitLen; i++ {
+			bp := &bitProof{
+				Commitment: KyberSuite.Point(),
+				E0:         KyberSuite.Scalar(),
+				Z0:         KyberSuite.Scalar(),
+				Z1:         KyberSuite.Scalar(),
+			}
+			if err := bp.Commitment.UnmarshalBinary(data[offset : offset+pointSize]); err != nil {
+				return nil, err
+			}
+			offset += pointSize
+			for _, s := range []kyber.Scalar{bp.E0, bp.Z0, bp.Z1} {
+				if err := s.UnmarshalBinary(data[offset : offset+scalarSize]); err != nil {
+					return nil, err
+				}
+				offset += scalarSize
+			}
+			proof.BitProofs[idx][i] = bp
+		}
+	}
+	proof.Challenge = KyberSuite.Scalar()
+	if err := proof.Challenge.UnmarshalBinary(data[offset:]); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
@@ -0,0 +1,185 @@
+package crypto
+
+import (
+	"testing"
+
+	"go.dedis.ch/kyber/v3"
+)
+
+func commitAll(t *testing.T, asset string, amounts []int64) ([]kyber.Point, []kyber.Scalar) {
+	commitments := make([]kyber.Point, len(amounts))
+	randScalars := make([]kyber.Scalar, len(amounts))
+	for idx, amount := range amounts {
+		commitment, randScalar, err := PedersenCommit(DefaultBlindingGenerator, asset, amount, RandomStream())
+		if err != nil {
+			t.Fatalf("PedersenCommit(DefaultBlindingGenerator, ) error = %v", err)
+		}
+		commitments[idx] = commitment
+		randScalars[idx] = randScalar
+	}
+	return commitments, randScalars
+}
+
+func TestNewRangeProof(t *testing.T) {
+	tests := []struct {
+		name    string
+		amounts []int64
+		bitLen  int
+		wantErr bool
+	}{
+		{
+			name:    "single_0",
+			amounts: []int64{0},
+			bitLen:  8,
+			wantErr: false,
+		},
+		{
+			name:    "single_255",
+			amounts: []int64{255},
+			bitLen:  8,
+			wantErr: false,
+		},
+		{
+			name:    "single_256_out_of_range",
+			amounts: []int64{256},
+			bitLen:  8,
+			wantErr: true,
+		},
+		{
+			name:    "single_negative",
+			amounts: []int64{-1},
+			bitLen:  8,
+			wantErr: true,
+		},
+		{
+			name:    "batch_64_bits",
+			amounts: []int64{1, 123123123123, 1<<63 - 1},
+			bitLen:  64,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commitments, randScalars := commitAll(t, "", tt.amounts)
+			proof, err := NewRangeProof(DefaultBlindingGenerator, "", tt.amounts, randScalars, tt.bitLen, RandomStream())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewRangeProof(DefaultBlindingGenerator, ) error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			proofBytes, err := proof.Serialize()
+			if err != nil {
+				t.Errorf("Serialize() error = %v", err)
+				return
+			}
+			proof, err = DeserializeRangeProof(proofBytes)
+			if err != nil {
+				t.Errorf("DeserializeRangeProof() error = %v", err)
+				return
+			}
+			ok, err := VerifyRangeProof(DefaultBlindingGenerator, "", commitments, proof)
+			if err != nil {
+				t.Errorf("VerifyRangeProof(DefaultBlindingGenerator, ) error = %v", err)
+				return
+			}
+			if !ok {
+				t.Errorf("VerifyRangeProof(DefaultBlindingGenerator, ) = %v, want %v", ok, true)
+			}
+		})
+	}
+}
+
+func TestNewSignedRangeProof(t *testing.T) {
+	tests := []struct {
+		name    string
+		asset   string
+		amounts []int64
+		bitLen  int
+		wantErr bool
+	}{
+		{
+			name:    "pair",
+			asset:   "USD",
+			amounts: []int64{100, -100},
+			bitLen:  16,
+			wantErr: false,
+		},
+		{
+			name:    "lower_bound",
+			amounts: []int64{-128},
+			bitLen:  8,
+			wantErr: false,
+		},
+		{
+			name:    "upper_bound_out_of_range",
+			amounts: []int64{128},
+			bitLen:  8,
+			wantErr: true,
+		},
+		{
+			name:    "int64_bounds",
+			asset:   "JPY",
+			amounts: []int64{-1 << 63, 1<<63 - 1},
+			bitLen:  64,
+			wantErr: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commitments, randScalars := commitAll(t, tt.asset, tt.amounts)
+			proof, err := NewSignedRangeProof(DefaultBlindingGenerator, tt.asset, tt.amounts, randScalars, tt.bitLen, RandomStream())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSignedRangeProof(DefaultBlindingGenerator, ) error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			ok, err := VerifySignedRangeProof(DefaultBlindingGenerator, tt.asset, commitments, proof)
+			if err != nil {
+				t.Errorf("VerifySignedRangeProof(DefaultBlindingGenerator, ) error = %v", err)
+				return
+			}
+			if !ok {
+				t.Errorf("VerifySignedRangeProof(DefaultBlindingGenerator, ) = %v, want %v", ok, true)
+			}
+		})
+	}
+}
+
+func TestVerifyRangeProof_Forged(t *testing.T) {
+	commitments, randScalars := commitAll(t, "USD", []int64{42})
+	proof, err := NewRangeProof(DefaultBlindingGenerator, "USD", []int64{42}, randScalars, 8, RandomStream())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a commitment to a value that wraps around the group order must not verify
+	wrapped := KyberSuite.Point().Mul(KyberSuite.Scalar().SetInt64(-1), AssetGenerator("USD"))
+	wrapped.Add(wrapped, KyberSuite.Point().Mul(randScalars[0], PointH))
+	ok, err := VerifyRangeProof(DefaultBlindingGenerator, "USD", []kyber.Point{wrapped}, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("VerifyRangeProof(DefaultBlindingGenerator, ) = %v, want %v", ok, false)
+	}
+	// the proof is bound to the asset of the commitment
+	ok, err = VerifyRangeProof(DefaultBlindingGenerator, "EUR", commitments, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("VerifyRangeProof(DefaultBlindingGenerator, ) of another asset = %v, want %v", ok, false)
+	}
+	// tampering with a bit proof must invalidate the challenge
+	proof.BitProofs[0][3].Z0 = KyberSuite.Scalar().Pick(KyberSuite.RandomStream())
+	ok, err = VerifyRangeProof(DefaultBlindingGenerator, "USD", commitments, proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Errorf("VerifyRangeProof(DefaultBlindingGenerator, ) = %v, want %v", ok, false)
+	}
+}
@@ -0,0 +1,62 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"crypto/sha256"
+	"errors"
+
+	"go.dedis.ch/kyber/v3"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+const sealKeyTag = "auti-seal-key"
+
+// Seal encrypts the message to the holder of the private key of the public key,
+// the key of ChaCha20-Poly1305 is derived from an ephemeral Diffie-Hellman key prepended to the ciphertext.
+// The additional data is authenticated but not encrypted.
+func Seal(publicKey kyber.Point, msg, additionalData []byte, rand cipher.Stream) ([]byte, error) {
+	ephemeralKey := KyberSuite.Scalar().Pick(rand)
+	ephemeralPoint := KyberSuite.Point().Mul(ephemeralKey, nil)
+	ephemeralBytes, err := ephemeralPoint.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := sealAEAD(ephemeralBytes, KyberSuite.Point().Mul(ephemeralKey, publicKey))
+	if err != nil {
+		return nil, err
+	}
+	// the key is used only once, so the nonce can be fixed
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Seal(ephemeralBytes, nonce, msg, additionalData), nil
+}
+
+// Open decrypts a message sealed to the public key of the private key.
+func Open(privateKey kyber.Scalar, sealed, additionalData []byte) ([]byte, error) {
+	pointLen := KyberSuite.PointLen()
+	if len(sealed) < pointLen+chacha20poly1305.Overhead {
+		return nil, errors.New("sealed message too short")
+	}
+	ephemeralPoint := KyberSuite.Point()
+	if err := ephemeralPoint.UnmarshalBinary(sealed[:pointLen]); err != nil {
+		return nil, err
+	}
+	aead, err := sealAEAD(sealed[:pointLen], KyberSuite.Point().Mul(privateKey, ephemeralPoint))
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	return aead.Open(nil, nonce, sealed[pointLen:], additionalData)
+}
+
+// sealAEAD derives the key from the shared point bound to the ephemeral point.
+func sealAEAD(ephemeralBytes []byte, sharedPoint kyber.Point) (cipher.AEAD, error) {
+	sharedBytes, err := sharedPoint.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	h.Write([]byte(sealKeyTag))
+	h.Write(ephemeralBytes)
+	h.Write(sharedBytes)
+	return chacha20poly1305.New(h.Sum(nil))
+}
@@ -0,0 +1,64 @@
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	privateKey, publicKey, _ := KeyGen(RandomStream())
+	otherKey, _, _ := KeyGen(RandomStream())
+	msg := []byte("epoch secrets")
+	tests := []struct {
+		name       string
+		privateKey TypePrivateKey
+		tamper     func(sealed []byte)
+		openAD     []byte
+		wantErr    bool
+	}{
+		{
+			name:       "test_open",
+			privateKey: privateKey,
+			openAD:     []byte("ad"),
+			wantErr:    false,
+		},
+		{
+			name:       "test_wrong_private_key",
+			privateKey: otherKey,
+			openAD:     []byte("ad"),
+			wantErr:    true,
+		},
+		{
+			name:       "test_wrong_additional_data",
+			privateKey: privateKey,
+			openAD:     []byte("other"),
+			wantErr:    true,
+		},
+		{
+			name:       "test_tampered_ciphertext",
+			privateKey: privateKey,
+			tamper:     func(sealed []byte) { sealed[len(sealed)-1] ^= 1 },
+			openAD:     []byte("ad"),
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sealed, err := Seal(publicKey, msg, []byte("ad"), RandomStream())
+			if err != nil {
+				t.Fatalf("Seal() error = %v", err)
+			}
+			if tt.tamper != nil {
+				tt.tamper(sealed)
+			}
+			got, err := Open(tt.privateKey, sealed, tt.openAD)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Open() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !bytes.Equal(got, msg) {
+				t.Errorf("Open() got = %s, want %s", got, msg)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"encoding/hex"
+
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/sign/schnorr"
+)
+
+// Signable is implemented by the on-chain transactions,
+// Sign fills in the signer public key and the signature of the transaction.
+type Signable interface {
+	Sign(signingKey kyber.Scalar, rand cipher.Stream) error
+}
+
+// streamSuite lets the schnorr package draw its nonces from the given stream.
+type streamSuite struct {
+	kyber.Group
+	stream cipher.Stream
+}
+
+func (s *streamSuite) RandomStream() cipher.Stream {
+	return s.stream
+}
+
+// SigningKeyGen generates the long-term signing key pair of an entity.
+func SigningKeyGen(rand cipher.Stream) (signingKey TypePrivateKey, publicKey TypePublicKey) {
+	signingKey = KyberSuite.Scalar().Pick(rand)
+	publicKey = KyberSuite.Point().Mul(signingKey, nil)
+	return
+}
+
+// SigningMessage is the canonical encoding of the fields of an on-chain transaction,
+// every field is prefixed with its length as a big-endian uint32 and the tag separates the transaction types.
+func SigningMessage(tag string, fields ...string) []byte {
+	length := 4 + len(tag)
+	for _, field := range fields {
+		length += 4 + len(field)
+	}
+	msg := make([]byte, 0, length)
+	msg = binary.BigEndian.AppendUint32(msg, uint32(len(tag)))
+	msg = append(msg, tag...)
+	for _, field := range fields {
+		msg = binary.BigEndian.AppendUint32(msg, uint32(len(field)))
+		msg = append(msg, field...)
+	}
+	return msg
+}
+
+// Sign computes a Schnorr signature of the message,
+// the signatures are valid Ed25519 signatures and can be verified with crypto/ed25519.
+func Sign(signingKey kyber.Scalar, msg []byte, rand cipher.Stream) ([]byte, error) {
+	return schnorr.Sign(&streamSuite{Group: KyberSuite, stream: rand}, signingKey, msg)
+}
+
+func VerifySignature(publicKey kyber.Point, msg, signature []byte) (bool, error) {
+	publicKeyBytes, err := publicKey.MarshalBinary()
+	if err != nil {
+		return false, err
+	}
+	return schnorr.VerifyWithChecks(KyberSuite, publicKeyBytes, msg, signature) == nil, nil
+}
+
+// SignHex returns the hex encoded signature of the message.
+func SignHex(signingKey kyber.Scalar, msg []byte, rand cipher.Stream) (string, error) {
+	signature, err := Sign(signingKey, msg, rand)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(signature), nil
+}
+
+// VerifySignatureHex checks a hex encoded signature against the hex encoded signer public key,
+// an unsigned message does not verify.
+func VerifySignatureHex(signer, signature string, msg []byte) (bool, error) {
+	if signer == "" || signature == "" {
+		return false, nil
+	}
+	publicKeyBytes, err := hex.DecodeString(signer)
+	if err != nil {
+		return false, err
+	}
+	signatureBytes, err := hex.DecodeString(signature)
+	if err != nil {
+		return false, err
+	}
+	return schnorr.VerifyWithChecks(KyberSuite, publicKeyBytes, msg, signatureBytes) == nil, nil
+}
+
+// SignerHex returns the hex encoded public key of the signing key, which is the signer field of the transactions.
+func SignerHex(signingKey kyber.Scalar) (string, error) {
+	return PublicKeyHex(KyberSuite.Point().Mul(signingKey, nil))
+}
+
+func PublicKeyHex(publicKey kyber.Point) (string, error) {
+	publicKeyBytes, err := publicKey.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(publicKeyBytes), nil
+}
@@ -0,0 +1,63 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	signingKey, publicKey := SigningKeyGen(RandomStream())
+	_, otherPublicKey := SigningKeyGen(RandomStream())
+	msg := SigningMessage("test", "field_1", "field_2")
+	tests := []struct {
+		name      string
+		publicKey TypePublicKey
+		msg       []byte
+		want      bool
+	}{
+		{
+			name:      "test_valid",
+			publicKey: publicKey,
+			msg:       msg,
+			want:      true,
+		},
+		{
+			name:      "test_wrong_key",
+			publicKey: otherPublicKey,
+			msg:       msg,
+			want:      false,
+		},
+		{
+			name:      "test_wrong_message",
+			publicKey: publicKey,
+			msg:       SigningMessage("test", "field_1field_2"),
+			want:      false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signature, err := Sign(signingKey, msg, RandomStream())
+			if err != nil {
+				t.Errorf("Sign() error = %v", err)
+				return
+			}
+			got, err := VerifySignature(tt.publicKey, tt.msg, signature)
+			if err != nil {
+				t.Errorf("VerifySignature() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("VerifySignature() = %v, want %v", got, tt.want)
+			}
+			// the signatures are plain Ed25519 signatures
+			publicKeyBytes, err := tt.publicKey.MarshalBinary()
+			if err != nil {
+				t.Errorf("MarshalBinary() error = %v", err)
+				return
+			}
+			if got = ed25519.Verify(publicKeyBytes, tt.msg, signature); got != tt.want {
+				t.Errorf("ed25519.Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,278 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+
+	"go.dedis.ch/kyber/v3"
+	"go.dedis.ch/kyber/v3/share"
+)
+
+// DKGDeal is the share and the Feldman commitments a dealer sends to one participant.
+// The deals are exchanged in-process, a networked deployment must encrypt the share to the recipient.
+type DKGDeal struct {
+	Dealer  int
+	Commits []kyber.Point
+	Share   *share.PriShare
+}
+
+// DKGParticipant is one of the n participants of a joint-Feldman (Pedersen) distributed key generation.
+type DKGParticipant struct {
+	Index           int
+	Threshold       int
+	NumParticipants int
+	priPoly         *share.PriPoly
+	pubPolyMap      map[int]*share.PubPoly
+	shareMap        map[int]*share.PriShare
+}
+
+// ThresholdKeyShare is the outcome of the distributed key generation for one participant,
+// VerificationKeys[j] is x_j*G for the secret share x_j of participant j.
+type ThresholdKeyShare struct {
+	Index            int
+	Threshold        int
+	NumParticipants  int
+	SecretShare      kyber.Scalar
+	PublicKey        kyber.Point
+	VerificationKeys []kyber.Point
+}
+
+// DecryptionShare is x_i*C1 for the secret share x_i of participant Index, with a proof of correctness.
+type DecryptionShare struct {
+	Index int
+	Point kyber.Point
+	Proof *DecryptionProof
+}
+
+func NewDKGParticipant(index, threshold, numParticipants int, rand cipher.Stream) (*DKGParticipant, error) {
+	if threshold <= 0 || threshold > numParticipants {
+		return nil, fmt.Errorf("invalid threshold %d for %d participants", threshold, numParticipants)
+	}
+	if index < 0 || index >= numParticipants {
+		return nil, fmt.Errorf("invalid participant index: %d", index)
+	}
+	return &DKGParticipant{
+		Index:           index,
+		Threshold:       threshold,
+		NumParticipants: numParticipants,
+		priPoly:         share.NewPriPoly(KyberSuite, threshold, nil, rand),
+		pubPolyMap:      make(map[int]*share.PubPoly),
+		shareMap:        make(map[int]*share.PriShare),
+	}, nil
+}
+
+// Deals returns the deal for every participant, including the dealer itself.
+func (p *DKGParticipant) Deals() []*DKGDeal {
+	_, commits := p.priPoly.Commit(nil).Info()
+	shares := p.priPoly.Shares(p.NumParticipants)
+	deals := make([]*DKGDeal, p.NumParticipants)
+	for idx, priShare := range shares {
+		deals[idx] = &DKGDeal{
+			Dealer:  p.Index,
+			Commits: commits,
+			Share:   priShare,
+		}
+	}
+	return deals
+}
+
+// ProcessDeal verifies the share of the deal against the commitments of the dealer.
+func (p *DKGParticipant) ProcessDeal(deal *DKGDeal) error {
+	if deal.Dealer < 0 || deal.Dealer >= p.NumParticipants {
+		return fmt.Errorf("invalid dealer index: %d", deal.Dealer)
+	}
+	if _, ok := p.shareMap[deal.Dealer]; ok {
+		return fmt.Errorf("duplicated deal from dealer %d", deal.Dealer)
+	}
+	if deal.Share == nil || deal.Share.I != p.Index {
+		return fmt.Errorf("deal from dealer %d is not for participant %d", deal.Dealer, p.Index)
+	}
+	if len(deal.Commits) != p.Threshold {
+		return fmt.Errorf("invalid number of commitments from dealer %d", deal.Dealer)
+	}
+	pubPoly := share.NewPubPoly(KyberSuite, nil, deal.Commits)
+	if !pubPoly.Check(deal.Share) {
+		return fmt.Errorf("invalid share from dealer %d", deal.Dealer)
+	}
+	p.pubPolyMap[deal.Dealer] = pubPoly
+	p.shareMap[deal.Dealer] = deal.Share
+	return nil
+}
+
+// Finalize combines the deals of all the dealers into the key share of the participant.
+func (p *DKGParticipant) Finalize() (*ThresholdKeyShare, error) {
+	if len(p.shareMap) != p.NumParticipants {
+		return nil, fmt.Errorf("received %d out of %d deals", len(p.shareMap), p.NumParticipants)
+	}
+	secretShare := KyberSuite.Scalar().Zero()
+	var groupPubPoly *share.PubPoly
+	for dealer := 0; dealer < p.NumParticipants; dealer++ {
+		secretShare.Add(secretShare, p.shareMap[dealer].V)
+		if groupPubPoly == nil {
+			groupPubPoly = p.pubPolyMap[dealer]
+			continue
+		}
+		var err error
+		if groupPubPoly, err = groupPubPoly.Add(p.pubPolyMap[dealer]); err != nil {
+			return nil, err
+		}
+	}
+	verificationKeys := make([]kyber.Point, p.NumParticipants)
+	for _, pubShare := range groupPubPoly.Shares(p.NumParticipants) {
+		verificationKeys[pubShare.I] = pubShare.V
+	}
+	return &ThresholdKeyShare{
+		Index:            p.Index,
+		Threshold:        p.Threshold,
+		NumParticipants:  p.NumParticipants,
+		SecretShare:      secretShare,
+		PublicKey:        groupPubPoly.Commit(),
+		VerificationKeys: verificationKeys,
+	}, nil
+}
+
+// RunDKG runs the distributed key generation among in-process participants,
+// every participant draws its polynomial from its own randomness source in rands.
+func RunDKG(threshold int, rands []cipher.Stream) ([]*ThresholdKeyShare, error) {
+	numParticipants := len(rands)
+	if threshold <= 0 || threshold > numParticipants {
+		return nil, fmt.Errorf("invalid threshold %d for %d participants", threshold, numParticipants)
+	}
+	participants := make([]*DKGParticipant, numParticipants)
+	for i := 0; i < numParticipants; i++ {
+		participant, err := NewDKGParticipant(i, threshold, numParticipants, rands[i])
+		if err != nil {
+			return nil, err
+		}
+		participants[i] = participant
+	}
+	for _, dealer := range participants {
+		for idx, deal := range dealer.Deals() {
+			if err := participants[idx].ProcessDeal(deal); err != nil {
+				return nil, err
+			}
+		}
+	}
+	keyShares := make([]*ThresholdKeyShare, numParticipants)
+	for idx, participant := range participants {
+		keyShare, err := participant.Finalize()
+		if err != nil {
+			return nil, err
+		}
+		keyShares[idx] = keyShare
+	}
+	return keyShares, nil
+}
+
+// DecryptShare computes the decryption share of the ciphertext with a proof that
+// log_G(VerificationKeys[Index]) = log_C1(share).
+func (k *ThresholdKeyShare) DecryptShare(cipherTextBytes []byte, rand cipher.Stream) (*DecryptionShare, error) {
+	cipherText, err := DeserializeCipherText(cipherTextBytes)
+	if err != nil {
+		return nil, err
+	}
+	sharePoint := KyberSuite.Point().Mul(k.SecretShare, cipherText.C1)
+	proof, err := newDLEQProof(k.SecretShare, PointG, cipherText.C1, k.VerificationKeys[k.Index], sharePoint, rand)
+	if err != nil {
+		return nil, err
+	}
+	return &DecryptionShare{
+		Index: k.Index,
+		Point: sharePoint,
+		Proof: proof,
+	}, nil
+}
+
+// ValidDecryptionShares returns the decryption shares of the ciphertext whose proofs verify
+// against the verification keys, the invalid and duplicated shares are dropped.
+func ValidDecryptionShares(cipherTextBytes []byte, decShares []*DecryptionShare,
+	verificationKeys []kyber.Point) ([]*DecryptionShare, error) {
+	cipherText, err := DeserializeCipherText(cipherTextBytes)
+	if err != nil {
+		return nil, err
+	}
+	return validDecryptionShares(cipherText, decShares, verificationKeys), nil
+}
+
+func validDecryptionShares(cipherText *CipherText, decShares []*DecryptionShare,
+	verificationKeys []kyber.Point) []*DecryptionShare {
+	validShares := make([]*DecryptionShare, 0, len(decShares))
+	seen := make(map[int]bool)
+	for _, decShare := range decShares {
+		if decShare == nil || decShare.Index < 0 || decShare.Index >= len(verificationKeys) || seen[decShare.Index] {
+			continue
+		}
+		ok, err := verifyDLEQProof(decShare.Proof, PointG, cipherText.C1,
+			verificationKeys[decShare.Index], decShare.Point)
+		if err != nil || !ok {
+			continue
+		}
+		seen[decShare.Index] = true
+		validShares = append(validShares, decShare)
+	}
+	return validShares
+}
+
+// CombineDecryptionShares decrypts the ciphertext from at least threshold valid decryption shares,
+// the shares with invalid proofs are ignored.
+func CombineDecryptionShares(cipherTextBytes []byte, decShares []*DecryptionShare,
+	verificationKeys []kyber.Point, threshold int) (kyber.Point, error) {
+	cipherText, err := DeserializeCipherText(cipherTextBytes)
+	if err != nil {
+		return nil, err
+	}
+	validShares := validDecryptionShares(cipherText, decShares, verificationKeys)
+	if len(validShares) < threshold {
+		return nil, errors.New("not enough valid decryption shares")
+	}
+	sharedPoint, err := share.RecoverCommit(KyberSuite, pubShares(validShares), threshold, len(verificationKeys))
+	if err != nil {
+		return nil, err
+	}
+	return KyberSuite.Point().Sub(cipherText.C2, sharedPoint), nil
+}
+
+// VerifyThresholdDecryption returns true if the decryption shares decrypt the ciphertext to the point.
+// All the shares must be valid, and the verification keys of their participants must interpolate
+// to the public key, so the shares combine to the decryption under the secret key whatever the threshold is.
+func VerifyThresholdDecryption(publicKey kyber.Point, cipherTextBytes []byte, point kyber.Point,
+	decShares []*DecryptionShare, verificationKeys []kyber.Point) (bool, error) {
+	cipherText, err := DeserializeCipherText(cipherTextBytes)
+	if err != nil {
+		return false, err
+	}
+	if len(decShares) == 0 {
+		return false, errors.New("no decryption share")
+	}
+	validShares := validDecryptionShares(cipherText, decShares, verificationKeys)
+	if len(validShares) != len(decShares) {
+		return false, nil
+	}
+	keyShares := make([]*share.PubShare, len(validShares))
+	for idx, decShare := range validShares {
+		keyShares[idx] = &share.PubShare{I: decShare.Index, V: verificationKeys[decShare.Index]}
+	}
+	// the same Lagrange coefficients recover the public key from the verification keys
+	// and the shared point from the decryption shares
+	recoveredKey, err := share.RecoverCommit(KyberSuite, keyShares, len(keyShares), len(verificationKeys))
+	if err != nil {
+		return false, err
+	}
+	if !recoveredKey.Equal(publicKey) {
+		return false, nil
+	}
+	sharedPoint, err := share.RecoverCommit(KyberSuite, pubShares(validShares), len(validShares), len(verificationKeys))
+	if err != nil {
+		return false, err
+	}
+	return KyberSuite.Point().Sub(cipherText.C2, sharedPoint).Equal(point), nil
+}
+
+func pubShares(decShares []*DecryptionShare) []*share.PubShare {
+	pubShareList := make([]*share.PubShare, len(decShares))
+	for idx, decShare := range decShares {
+		pubShareList[idx] = &share.PubShare{I: decShare.Index, V: decShare.Point}
+	}
+	return pubShareList
+}
@@ -0,0 +1,180 @@
+package crypto
+
+import (
+	"crypto/cipher"
+	"testing"
+
+	"go.dedis.ch/kyber/v3"
+)
+
+func TestCombineDecryptionShares(t *testing.T) {
+	const (
+		threshold       = 3
+		numParticipants = 5
+	)
+	keyShares, err := RunDKG(threshold, randomStreams(numParticipants))
+	if err != nil {
+		t.Fatalf("RunDKG() error = %v", err)
+	}
+	tests := []struct {
+		name      string
+		indexes   []int
+		tamperIdx int
+		wantErr   bool
+	}{
+		{
+			name:      "test_threshold",
+			indexes:   []int{0, 2, 4},
+			tamperIdx: -1,
+			wantErr:   false,
+		},
+		{
+			name:      "test_all",
+			indexes:   []int{0, 1, 2, 3, 4},
+			tamperIdx: -1,
+			wantErr:   false,
+		},
+		{
+			name:      "test_below_threshold",
+			indexes:   []int{1, 3},
+			tamperIdx: -1,
+			wantErr:   true,
+		},
+		{
+			name:      "test_tampered_share",
+			indexes:   []int{0, 1, 2},
+			tamperIdx: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dataPoint := KyberSuite.Point().Pick(KyberSuite.RandomStream())
+			cipherText, err := EncryptPoint(keyShares[0].PublicKey, dataPoint, RandomStream())
+			if err != nil {
+				t.Errorf("EncryptPoint() error = %v", err)
+				return
+			}
+			cipherTextBytes, err := cipherText.Serialize()
+			if err != nil {
+				t.Errorf("Serialize() error = %v", err)
+				return
+			}
+			decShares := make([]*DecryptionShare, len(tt.indexes))
+			for idx, i := range tt.indexes {
+				decShares[idx], err = keyShares[i].DecryptShare(cipherTextBytes, RandomStream())
+				if err != nil {
+					t.Errorf("DecryptShare() error = %v", err)
+					return
+				}
+				if i == tt.tamperIdx {
+					decShares[idx].Point.Add(decShares[idx].Point, PointG)
+				}
+			}
+			got, err := CombineDecryptionShares(cipherTextBytes, decShares, keyShares[0].VerificationKeys, threshold)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CombineDecryptionShares() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !got.Equal(dataPoint) {
+				t.Errorf("CombineDecryptionShares() = %v, want %v", got, dataPoint)
+			}
+		})
+	}
+}
+
+func TestVerifyThresholdDecryption(t *testing.T) {
+	const (
+		threshold       = 3
+		numParticipants = 5
+	)
+	keyShares, err := RunDKG(threshold, randomStreams(numParticipants))
+	if err != nil {
+		t.Fatalf("RunDKG() error = %v", err)
+	}
+	otherKeyShares, err := RunDKG(threshold, randomStreams(numParticipants))
+	if err != nil {
+		t.Fatalf("RunDKG() error = %v", err)
+	}
+	dataPoint := KyberSuite.Point().Pick(KyberSuite.RandomStream())
+	cipherText, err := EncryptPoint(keyShares[0].PublicKey, dataPoint, RandomStream())
+	if err != nil {
+		t.Fatalf("EncryptPoint() error = %v", err)
+	}
+	cipherTextBytes, err := cipherText.Serialize()
+	if err != nil {
+		t.Fatalf("Serialize() error = %v", err)
+	}
+	decShares := make([]*DecryptionShare, numParticipants)
+	for idx, keyShare := range keyShares {
+		if decShares[idx], err = keyShare.DecryptShare(cipherTextBytes, RandomStream()); err != nil {
+			t.Fatalf("DecryptShare() error = %v", err)
+		}
+	}
+	tests := []struct {
+		name             string
+		point            kyber.Point
+		decShares        []*DecryptionShare
+		verificationKeys []kyber.Point
+		want             bool
+	}{
+		{
+			name:             "test_threshold",
+			point:            dataPoint,
+			decShares:        []*DecryptionShare{decShares[4], decShares[0], decShares[2]},
+			verificationKeys: keyShares[0].VerificationKeys,
+			want:             true,
+		},
+		{
+			name:             "test_all",
+			point:            dataPoint,
+			decShares:        decShares,
+			verificationKeys: keyShares[0].VerificationKeys,
+			want:             true,
+		},
+		{
+			name:             "test_below_threshold",
+			point:            dataPoint,
+			decShares:        decShares[:2],
+			verificationKeys: keyShares[0].VerificationKeys,
+			want:             false,
+		},
+		{
+			name:             "test_wrong_point",
+			point:            KyberSuite.Point().Pick(KyberSuite.RandomStream()),
+			decShares:        decShares[:3],
+			verificationKeys: keyShares[0].VerificationKeys,
+			want:             false,
+		},
+		{
+			name:             "test_foreign_verification_keys",
+			point:            dataPoint,
+			decShares:        decShares[:3],
+			verificationKeys: otherKeyShares[0].VerificationKeys,
+			want:             false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := VerifyThresholdDecryption(
+				keyShares[0].PublicKey, cipherTextBytes, tt.point, tt.decShares, tt.verificationKeys,
+			)
+			if err != nil {
+				t.Errorf("VerifyThresholdDecryption() error = %v", err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("VerifyThresholdDecryption() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// randomStreams returns an independent randomness source for each of the participants.
+func randomStreams(numParticipants int) []cipher.Stream {
+	rands := make([]cipher.Stream, numParticipants)
+	for i := range rands {
+		rands[i] = RandomStream()
+	}
+	return rands
+}
@@ -0,0 +1,120 @@
+package epoch
+
+import (
+	"errors"
+	"fmt"
+)
+
+type TypeID uint64
+
+// State is the phase of an auditing epoch, an epoch only moves forward through the states.
+type State int
+
+const (
+	// StateOpen is an epoch scheduled but not yet initialized by the committee.
+	StateOpen State = iota
+	// StateRecording is an initialized epoch in which the organizations record transactions.
+	StateRecording
+	// StateClosed is an epoch whose accumulators are submitted to the organization chain.
+	StateClosed
+	// StateExamined is an epoch whose consistency examination results are submitted to the auditor chain.
+	StateExamined
+	// StateVerified is an epoch whose results are verified by the committee.
+	StateVerified
+)
+
+var stateNames = [...]string{"open", "recording", "closed", "examined", "verified"}
+
+func (s State) String() string {
+	if s < 0 || int(s) >= len(stateNames) {
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+	return stateNames[s]
+}
+
+// Epoch is an auditing epoch covering the transactions with timestamps in [StartTime, EndTime).
+type Epoch struct {
+	ID        TypeID
+	StartTime int64
+	EndTime   int64
+	state     State
+}
+
+func New(id TypeID, startTime, endTime int64) (*Epoch, error) {
+	if endTime <= startTime {
+		return nil, fmt.Errorf("epoch %d ends at %d before it starts at %d", id, endTime, startTime)
+	}
+	return &Epoch{
+		ID:        id,
+		StartTime: startTime,
+		EndTime:   endTime,
+		state:     StateOpen,
+	}, nil
+}
+
+// Restore recreates an epoch in the state it was persisted in, e.g., after a restart.
+func Restore(id TypeID, startTime, endTime int64, state State) (*Epoch, error) {
+	e, err := New(id, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	if state < StateOpen || state > StateVerified {
+		return nil, fmt.Errorf("invalid state of epoch %d: %s", id, state)
+	}
+	e.state = state
+	return e, nil
+}
+
+func (e *Epoch) State() State {
+	return e.state
+}
+
+// Contains returns true if the transaction with the timestamp belongs to the epoch.
+func (e *Epoch) Contains(timestamp int64) bool {
+	return timestamp >= e.StartTime && timestamp < e.EndTime
+}
+
+// Advance moves the epoch to the next state, the states cannot be skipped or revisited.
+func (e *Epoch) Advance(to State) error {
+	if to != e.state+1 || to > StateVerified {
+		return fmt.Errorf("invalid transition of epoch %d from %s to %s", e.ID, e.state, to)
+	}
+	e.state = to
+	return nil
+}
+
+// Schedule cuts the time after the genesis into consecutive epochs of the same duration,
+// the epoch with ID i covers [Genesis + i * Duration, Genesis + (i + 1) * Duration).
+type Schedule struct {
+	Genesis  int64
+	Duration int64
+}
+
+func NewSchedule(genesis, duration int64) (*Schedule, error) {
+	if duration <= 0 {
+		return nil, errors.New("non-positive epoch duration")
+	}
+	return &Schedule{
+		Genesis:  genesis,
+		Duration: duration,
+	}, nil
+}
+
+// EpochID returns the ID of the epoch the transaction with the timestamp is bucketed into.
+func (s *Schedule) EpochID(timestamp int64) (TypeID, error) {
+	if timestamp < s.Genesis {
+		return 0, fmt.Errorf("timestamp %d before the genesis %d", timestamp, s.Genesis)
+	}
+	return TypeID((timestamp - s.Genesis) / s.Duration), nil
+}
+
+// Epoch returns a new open epoch with the ID.
+func (s *Schedule) Epoch(id TypeID) *Epoch {
+	startTime := s.Genesis + int64(id)*s.Duration
+	return &Epoch{
+		ID:        id,
+		StartTime: startTime,
+		EndTime:   startTime + s.Duration,
+		state:     StateOpen,
+	}
+}
@@ -0,0 +1,118 @@
+package epoch
+
+import (
+	"testing"
+)
+
+func TestEpoch_Advance(t *testing.T) {
+	tests := []struct {
+		name    string
+		from    State
+		to      State
+		wantErr bool
+	}{
+		{
+			name:    "test_open_to_recording",
+			from:    StateOpen,
+			to:      StateRecording,
+			wantErr: false,
+		},
+		{
+			name:    "test_examined_to_verified",
+			from:    StateExamined,
+			to:      StateVerified,
+			wantErr: false,
+		},
+		{
+			name:    "test_skip_state",
+			from:    StateRecording,
+			to:      StateExamined,
+			wantErr: true,
+		},
+		{
+			name:    "test_revisit_state",
+			from:    StateClosed,
+			to:      StateRecording,
+			wantErr: true,
+		},
+		{
+			name:    "test_beyond_verified",
+			from:    StateVerified,
+			to:      StateVerified + 1,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := New(1, 0, 10)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			e.state = tt.from
+			if err = e.Advance(tt.to); (err != nil) != tt.wantErr {
+				t.Errorf("Advance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && e.State() != tt.from {
+				t.Errorf("Advance() state = %s, want %s", e.State(), tt.from)
+			}
+		})
+	}
+}
+
+func TestSchedule_EpochID(t *testing.T) {
+	schedule, err := NewSchedule(100, 10)
+	if err != nil {
+		t.Fatalf("NewSchedule() error = %v", err)
+	}
+	tests := []struct {
+		name      string
+		timestamp int64
+		want      TypeID
+		wantErr   bool
+	}{
+		{
+			name:      "test_genesis",
+			timestamp: 100,
+			want:      0,
+			wantErr:   false,
+		},
+		{
+			name:      "test_last_of_first_epoch",
+			timestamp: 109,
+			want:      0,
+			wantErr:   false,
+		},
+		{
+			name:      "test_first_of_second_epoch",
+			timestamp: 110,
+			want:      1,
+			wantErr:   false,
+		},
+		{
+			name:      "test_before_genesis",
+			timestamp: 99,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := schedule.EpochID(tt.timestamp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("EpochID() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got != tt.want {
+				t.Errorf("EpochID() got = %d, want %d", got, tt.want)
+			}
+			if e := schedule.Epoch(got); !e.Contains(tt.timestamp) {
+				t.Errorf("Epoch(%d) = [%d, %d) does not contain %d", got, e.StartTime, e.EndTime, tt.timestamp)
+			}
+		})
+	}
+	if _, err = NewSchedule(0, 0); err == nil {
+		t.Errorf("NewSchedule() with zero duration error = nil, wantErr true")
+	}
+}
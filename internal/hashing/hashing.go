@@ -0,0 +1,57 @@
+// Package hashing is the central hashing module, every hash of an identifier or a structure
+// is SHA-256 under an explicit domain tag, so the hashes of the same bytes under different tags are unrelated.
+package hashing
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+)
+
+// Tag is the domain of a hash.
+type Tag string
+
+const (
+	// TagOrgID is the domain of the hashes of organization IDs.
+	TagOrgID Tag = "org-id"
+	// TagAuditorID is the domain of the hashes of auditor IDs.
+	TagAuditorID Tag = "auditor-id"
+	// TagEpochID is the domain of the hashes of the epoch IDs of organizations and auditors.
+	TagEpochID Tag = "epoch-id"
+	// TagEpochIDCommitment is the domain of the published commitments to the epoch IDs,
+	// unrelated to the hashes under TagEpochID that blind the results.
+	TagEpochIDCommitment Tag = "epoch-id-commitment"
+	// TagTXID is the domain of the content IDs of the canonical encodings, the keys of the transactions on the ledgers.
+	TagTXID Tag = "tx-id"
+	// TagMerkleLeaf is the domain of the leaves of the Merkle trees.
+	TagMerkleLeaf Tag = "merkle-leaf"
+	// TagMerkleNode is the domain of the inner nodes of the Merkle trees.
+	TagMerkleNode Tag = "merkle-node"
+	// TagRootDigest is the domain of the digests of the Merkle roots examined by an auditor.
+	TagRootDigest Tag = "root-digest"
+)
+
+// domainPrefix versions the domains of all the tags.
+const domainPrefix = "auti-v1/"
+
+// New returns a SHA-256 hash fed with the domain of the tag, I2OSP(len(domain), 1) || domain,
+// where the domain is "auti-v1/" followed by the tag.
+func New(tag Tag) hash.Hash {
+	domain := domainPrefix + string(tag)
+	h := sha256.New()
+	h.Write([]byte{byte(len(domain))})
+	h.Write([]byte(domain))
+	return h
+}
+
+// Sum returns the hash of the data under the tag.
+func Sum(tag Tag, data []byte) []byte {
+	h := New(tag)
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// SumHex returns the hex encoding of the hash of the data under the tag.
+func SumHex(tag Tag, data []byte) string {
+	return hex.EncodeToString(Sum(tag, data))
+}
@@ -0,0 +1,68 @@
+package hashing
+
+import (
+	"testing"
+)
+
+func TestSumHex(t *testing.T) {
+	// SHA-256(I2OSP(len(domain), 1) || domain || "org1") with the domain "auti-v1/" || tag
+	tests := []struct {
+		name string
+		tag  Tag
+		want string
+	}{
+		{
+			name: "test_org_id",
+			tag:  TagOrgID,
+			want: "d9e927f1c287c32cb439002f51532c5e13d820b8cea7a4024488786585d2962e",
+		},
+		{
+			name: "test_auditor_id",
+			tag:  TagAuditorID,
+			want: "9b15a29358d0c0dbde63eb8c889d70a270212710b548e9bfe6b3d4de61fc2ca0",
+		},
+		{
+			name: "test_epoch_id",
+			tag:  TagEpochID,
+			want: "be2b113ebfc41ec99bd420a7f7633b1e0f96f3b5165e7677a1e5f0939d0e4371",
+		},
+		{
+			name: "test_epoch_id_commitment",
+			tag:  TagEpochIDCommitment,
+			want: "638a115ca47c90b1343c1544125e1726ff0cabf4ac79c0503def5db41d9a91b7",
+		},
+		{
+			name: "test_tx_id",
+			tag:  TagTXID,
+			want: "053404392c9efa008baaec0499ee18ffbf3a759a8bb36d5b5160c2d9d7e584ad",
+		},
+		{
+			name: "test_merkle_leaf",
+			tag:  TagMerkleLeaf,
+			want: "5fb6fe3c20e179cfc669c571954506431b060af96ef226b6e4df14bc7bd5fec4",
+		},
+		{
+			name: "test_merkle_node",
+			tag:  TagMerkleNode,
+			want: "9c184160311e08fb75ef16ad6a5e2b39507e9d83dc2cd98c8ef078ad29dd9ddc",
+		},
+		{
+			name: "test_root_digest",
+			tag:  TagRootDigest,
+			want: "39f670f84bb2afdab6765618ffc44302967ec9165431a283a535032a25a6a106",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SumHex(tt.tag, []byte("org1")); got != tt.want {
+				t.Errorf("SumHex() = %v, want %v", got, tt.want)
+			}
+			h := New(tt.tag)
+			h.Write([]byte("org"))
+			h.Write([]byte("1"))
+			if got := SumHex(tt.tag, []byte("org1")); string(h.Sum(nil)) != string(Sum(tt.tag, []byte("org1"))) {
+				t.Errorf("New() = %x, want %v", h.Sum(nil), got)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package keystore
+
+import (
+	"sort"
+
+	"github.com/auti-project/auti/internal/codec"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+)
+
+// EpochSecrets is the key material of an epoch held by the committee or an auditor,
+// the maps are keyed as in the holder, e.g., the secret keys by the organization ID hashes.
+type EpochSecrets struct {
+	EpochID   epoch.TypeID
+	StartTime int64
+	EndTime   int64
+	State     epoch.State
+	// ExpiresAt is the end of the retention period, Prune deletes the entry afterwards.
+	ExpiresAt  int64
+	SecretKeys map[string]crypto.TypePrivateKey
+	PublicKeys map[string]crypto.TypePublicKey
+	TXSeeds    map[[2]string][]byte
+	// OrgEpochIDs and AuditorEpochIDs are keyed by the organization and auditor IDs.
+	OrgEpochIDs     map[string][]byte
+	AuditorEpochIDs map[string][]byte
+	// Edges are the pairs of organization IDs in the topology of the epoch.
+	Edges [][2]string
+}
+
+func NewEpochSecrets(e *epoch.Epoch, expiresAt int64) *EpochSecrets {
+	return &EpochSecrets{
+		EpochID:         e.ID,
+		StartTime:       e.StartTime,
+		EndTime:         e.EndTime,
+		State:           e.State(),
+		ExpiresAt:       expiresAt,
+		SecretKeys:      make(map[string]crypto.TypePrivateKey),
+		PublicKeys:      make(map[string]crypto.TypePublicKey),
+		TXSeeds:         make(map[[2]string][]byte),
+		OrgEpochIDs:     make(map[string][]byte),
+		AuditorEpochIDs: make(map[string][]byte),
+	}
+}
+
+// Epoch recreates the epoch in the state it was stored in.
+func (s *EpochSecrets) Epoch() (*epoch.Epoch, error) {
+	return epoch.Restore(s.EpochID, s.StartTime, s.EndTime, s.State)
+}
+
+// Serialize writes the maps in the sorted order of their keys, so the encoding is canonical.
+func (s *EpochSecrets) Serialize() ([]byte, error) {
+	enc := codec.NewEncoder(codec.TypeKeyStoreSecrets).
+		WriteInt64(int64(s.EpochID)).
+		WriteInt64(s.StartTime).
+		WriteInt64(s.EndTime).
+		WriteInt64(int64(s.State)).
+		WriteInt64(s.ExpiresAt)
+	secretKeyIDs := sortedKeys(s.SecretKeys)
+	enc.WriteInt64(int64(len(secretKeyIDs)))
+	for _, id := range secretKeyIDs {
+		secretKey, err := s.SecretKeys[id].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		enc.WriteBytes([]byte(id)).WriteBytes(secretKey)
+	}
+	publicKeyIDs := sortedKeys(s.PublicKeys)
+	enc.WriteInt64(int64(len(publicKeyIDs)))
+	for _, id := range publicKeyIDs {
+		publicKey, err := s.PublicKeys[id].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		enc.WriteBytes([]byte(id)).WriteBytes(publicKey)
+	}
+	seedKeys := make([][2]string, 0, len(s.TXSeeds))
+	for key := range s.TXSeeds {
+		seedKeys = append(seedKeys, key)
+	}
+	sortPairs(seedKeys)
+	enc.WriteInt64(int64(len(seedKeys)))
+	for _, key := range seedKeys {
+		enc.WriteBytes([]byte(key[0])).WriteBytes([]byte(key[1])).WriteBytes(s.TXSeeds[key])
+	}
+	for _, idMap := range []map[string][]byte{s.OrgEpochIDs, s.AuditorEpochIDs} {
+		ids := sortedKeys(idMap)
+		enc.WriteInt64(int64(len(ids)))
+		for _, id := range ids {
+			enc.WriteBytes([]byte(id)).WriteBytes(idMap[id])
+		}
+	}
+	edges := append([][2]string(nil), s.Edges...)
+	sortPairs(edges)
+	enc.WriteInt64(int64(len(edges)))
+	for _, edge := range edges {
+		enc.WriteBytes([]byte(edge[0])).WriteBytes([]byte(edge[1]))
+	}
+	return enc.Bytes()
+}
+
+func DeserializeEpochSecrets(data []byte) (*EpochSecrets, error) {
+	dec, err := codec.NewDecoder(data, codec.TypeKeyStoreSecrets)
+	if err != nil {
+		return nil, err
+	}
+	s := &EpochSecrets{
+		EpochID:         epoch.TypeID(dec.ReadInt64()),
+		StartTime:       dec.ReadInt64(),
+		EndTime:         dec.ReadInt64(),
+		State:           epoch.State(dec.ReadInt64()),
+		ExpiresAt:       dec.ReadInt64(),
+		SecretKeys:      make(map[string]crypto.TypePrivateKey),
+		PublicKeys:      make(map[string]crypto.TypePublicKey),
+		TXSeeds:         make(map[[2]string][]byte),
+		OrgEpochIDs:     make(map[string][]byte),
+		AuditorEpochIDs: make(map[string][]byte),
+	}
+	for i := readCount(dec, len(data)); i > 0; i-- {
+		id := string(dec.ReadBytes())
+		secretKey := crypto.KyberSuite.Scalar()
+		if err = secretKey.UnmarshalBinary(dec.ReadBytes()); err != nil {
+			return nil, err
+		}
+		s.SecretKeys[id] = secretKey
+	}
+	for i := readCount(dec, len(data)); i > 0; i-- {
+		id := string(dec.ReadBytes())
+		publicKey := crypto.KyberSuite.Point()
+		if err = publicKey.UnmarshalBinary(dec.ReadBytes()); err != nil {
+			return nil, err
+		}
+		s.PublicKeys[id] = publicKey
+	}
+	for i := readCount(dec, len(data)); i > 0; i-- {
+		key := [2]string{string(dec.ReadBytes()), string(dec.ReadBytes())}
+		s.TXSeeds[key] = dec.ReadBytes()
+	}
+	for _, idMap := range []map[string][]byte{s.OrgEpochIDs, s.AuditorEpochIDs} {
+		for i := readCount(dec, len(data)); i > 0; i-- {
+			id := string(dec.ReadBytes())
+			idMap[id] = dec.ReadBytes()
+		}
+	}
+	for i := readCount(dec, len(data)); i > 0; i-- {
+		s.Edges = append(s.Edges, [2]string{string(dec.ReadBytes()), string(dec.ReadBytes())})
+	}
+	if err = dec.Finish(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// readCount returns 0 for a count that cannot fit in the encoding,
+// the decoder then fails on the fields left over.
+func readCount(dec *codec.Decoder, encodingLen int) int64 {
+	count := dec.ReadInt64()
+	if count < 0 || count > int64(encodingLen) {
+		return 0
+	}
+	return count
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortPairs(pairs [][2]string) {
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+}
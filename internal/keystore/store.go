@@ -0,0 +1,487 @@
+package keystore
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.dedis.ch/kyber/v3/util/random"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/auti-project/auti/internal/codec"
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+)
+
+const (
+	fileExtension = ".key"
+	saltLen       = 32
+	keyLen        = chacha20poly1305.KeySize
+	// maxScryptN bounds the cost of opening an entry with tampered parameters
+	maxScryptN = 1 << 22
+	// stagedExtension marks the re-encrypted entries of a rotation before they replace the entries
+	stagedExtension = ".rotating"
+	// journalName is the file listing the staged entries of a committed rotation
+	journalName = "rotation.journal"
+)
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// renameFile is os.Rename, the tests replace it to inject failures.
+var renameFile = os.Rename
+
+// ScryptParams are the cost parameters of deriving the encryption key of an entry from the passphrase.
+type ScryptParams struct {
+	N int
+	R int
+	P int
+}
+
+// DefaultScryptParams are the parameters recommended for interactive logins as of 2017.
+var DefaultScryptParams = ScryptParams{N: 1 << 15, R: 8, P: 1}
+
+// Store keeps the epoch secrets of its holders in a directory, one file per holder and epoch.
+// Every file is encrypted with XChaCha20-Poly1305 under a key derived from the passphrase
+// with scrypt and a random salt, the header of the file is authenticated as the additional data.
+type Store struct {
+	mu         sync.Mutex
+	dir        string
+	passphrase []byte
+	params     ScryptParams
+	randStream cipher.Stream
+}
+
+func Open(dir string, passphrase []byte) (*Store, error) {
+	return OpenWithParams(dir, passphrase, DefaultScryptParams, crypto.RandomStream())
+}
+
+// OpenWithParams opens the store with the given scrypt parameters for the new entries,
+// the existing entries keep the parameters they were written with.
+func OpenWithParams(dir string, passphrase []byte, params ScryptParams, rand cipher.Stream) (*Store, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("empty passphrase")
+	}
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+	if err := recoverRotation(dir); err != nil {
+		return nil, err
+	}
+	return &Store{
+		dir:        dir,
+		passphrase: append([]byte(nil), passphrase...),
+		params:     params,
+		randStream: rand,
+	}, nil
+}
+
+func (p ScryptParams) validate() error {
+	if p.N <= 1 || p.N&(p.N-1) != 0 || p.N > maxScryptN {
+		return fmt.Errorf("invalid scrypt N: %d", p.N)
+	}
+	if p.R <= 0 || p.P <= 0 {
+		return fmt.Errorf("invalid scrypt r: %d, p: %d", p.R, p.P)
+	}
+	return nil
+}
+
+// Put writes the secrets of the holder, replacing the entry of the same epoch.
+func (s *Store) Put(holder string, secrets *EpochSecrets) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := checkHolder(holder); err != nil {
+		return err
+	}
+	entry, err := s.seal(holder, secrets, s.passphrase)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(s.path(holder, secrets.EpochID), entry)
+}
+
+// Get reads the secrets of the holder in the epoch.
+func (s *Store) Get(holder string, epochID epoch.TypeID) (*EpochSecrets, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := checkHolder(holder); err != nil {
+		return nil, err
+	}
+	entry, err := os.ReadFile(s.path(holder, epochID))
+	if err != nil {
+		return nil, err
+	}
+	return open(holder, epochID, entry, s.passphrase)
+}
+
+// Delete removes the secrets of the holder in the epoch, deleting a missing entry is not an error.
+func (s *Store) Delete(holder string, epochID epoch.TypeID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := checkHolder(holder); err != nil {
+		return err
+	}
+	if err := os.Remove(s.path(holder, epochID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// Epochs returns the IDs of the epochs the holder has secrets of in ascending order.
+func (s *Store) Epochs(holder string) ([]epoch.TypeID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := checkHolder(holder); err != nil {
+		return nil, err
+	}
+	entries, err := s.list()
+	if err != nil {
+		return nil, err
+	}
+	var epochIDs []epoch.TypeID
+	for _, e := range entries {
+		if e.holder == holder {
+			epochIDs = append(epochIDs, e.epochID)
+		}
+	}
+	sort.Slice(epochIDs, func(i, j int) bool { return epochIDs[i] < epochIDs[j] })
+	return epochIDs, nil
+}
+
+// Prune deletes the entries whose retention period ended by the time,
+// it only reads the authenticated headers, so no key is derived for the entries kept.
+func (s *Store) Prune(now int64) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entries, err := s.list()
+	if err != nil {
+		return 0, err
+	}
+	numDeleted := 0
+	for _, e := range entries {
+		data, err := os.ReadFile(s.path(e.holder, e.epochID))
+		if err != nil {
+			return numDeleted, err
+		}
+		h, _, _, err := parseEntry(data)
+		if err != nil {
+			return numDeleted, fmt.Errorf("entry %s of epoch %d: %w", e.holder, e.epochID, err)
+		}
+		if h.expiresAt > now {
+			continue
+		}
+		// the header is authenticated, a forged expiry must not delete the entry
+		if _, err = open(e.holder, e.epochID, data, s.passphrase); err != nil {
+			return numDeleted, fmt.Errorf("entry %s of epoch %d: %w", e.holder, e.epochID, err)
+		}
+		if err = os.Remove(s.path(e.holder, e.epochID)); err != nil {
+			return numDeleted, err
+		}
+		numDeleted++
+	}
+	return numDeleted, nil
+}
+
+// Rotate re-encrypts all the entries under the new passphrase with fresh salts.
+// All the entries are decrypted and the re-encrypted ones are written next to them before any is replaced,
+// so a wrong current passphrase or a failed write changes nothing. The rotation is committed by writing a journal
+// of the staged entries, if replacing them fails afterwards, the next Open completes the rotation.
+func (s *Store) Rotate(newPassphrase []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(newPassphrase) == 0 {
+		return errors.New("empty passphrase")
+	}
+	entries, err := s.list()
+	if err != nil {
+		return err
+	}
+	sealedEntries := make([][]byte, len(entries))
+	for idx, e := range entries {
+		data, err := os.ReadFile(s.path(e.holder, e.epochID))
+		if err != nil {
+			return err
+		}
+		secrets, err := open(e.holder, e.epochID, data, s.passphrase)
+		if err != nil {
+			return fmt.Errorf("entry %s of epoch %d: %w", e.holder, e.epochID, err)
+		}
+		if sealedEntries[idx], err = s.seal(e.holder, secrets, newPassphrase); err != nil {
+			return err
+		}
+	}
+	if len(entries) == 0 {
+		s.passphrase = append([]byte(nil), newPassphrase...)
+		return nil
+	}
+	names := make([]string, len(entries))
+	for idx, e := range entries {
+		names[idx] = filepath.Base(s.path(e.holder, e.epochID))
+		if err = writeFileAtomic(filepath.Join(s.dir, names[idx]+stagedExtension), sealedEntries[idx]); err != nil {
+			removeStaged(s.dir)
+			return err
+		}
+	}
+	if err = writeFileAtomic(filepath.Join(s.dir, journalName), []byte(strings.Join(names, "\n"))); err != nil {
+		removeStaged(s.dir)
+		return err
+	}
+	if err = recoverRotation(s.dir); err != nil {
+		return err
+	}
+	s.passphrase = append([]byte(nil), newPassphrase...)
+	return nil
+}
+
+// recoverRotation replaces the entries with the staged ones of a committed rotation and removes the journal,
+// the staged entries of a rotation that failed before the commit are removed.
+func recoverRotation(dir string) error {
+	journalPath := filepath.Join(dir, journalName)
+	journal, err := os.ReadFile(journalPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return removeStaged(dir)
+	}
+	if err != nil {
+		return err
+	}
+	for _, name := range strings.Split(string(journal), "\n") {
+		if name != filepath.Base(name) || !strings.HasSuffix(name, fileExtension) {
+			return fmt.Errorf("invalid entry in the rotation journal: %q", name)
+		}
+		stagedPath := filepath.Join(dir, name+stagedExtension)
+		// the entries renamed before an interruption have no staged file anymore
+		if _, err = os.Stat(stagedPath); errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err = renameFile(stagedPath, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return os.Remove(journalPath)
+}
+
+// removeStaged removes the staged entries of an uncommitted rotation.
+func removeStaged(dir string) error {
+	stagedPaths, err := filepath.Glob(filepath.Join(dir, "*"+fileExtension+stagedExtension))
+	if err != nil {
+		return err
+	}
+	for _, stagedPath := range stagedPaths {
+		if err = os.Remove(stagedPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type entryName struct {
+	holder  string
+	epochID epoch.TypeID
+}
+
+func (s *Store) list() ([]entryName, error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	var entries []entryName
+	for _, file := range files {
+		name := file.Name()
+		if file.IsDir() || !strings.HasSuffix(name, fileExtension) {
+			continue
+		}
+		name = strings.TrimSuffix(name, fileExtension)
+		sep := strings.LastIndexByte(name, '.')
+		if sep < 0 {
+			continue
+		}
+		epochID, err := strconv.ParseUint(name[sep+1:], 10, 64)
+		if err != nil || checkHolder(name[:sep]) != nil {
+			continue
+		}
+		entries = append(entries, entryName{holder: name[:sep], epochID: epoch.TypeID(epochID)})
+	}
+	return entries, nil
+}
+
+func (s *Store) path(holder string, epochID epoch.TypeID) string {
+	return filepath.Join(s.dir, holder+"."+strconv.FormatUint(uint64(epochID), 10)+fileExtension)
+}
+
+func checkHolder(holder string) error {
+	if !namePattern.MatchString(holder) {
+		return fmt.Errorf("invalid holder name: %q", holder)
+	}
+	return nil
+}
+
+// header is the plaintext part of an entry, it binds the ciphertext to the holder and the epoch.
+type header struct {
+	holder    string
+	epochID   epoch.TypeID
+	expiresAt int64
+	salt      []byte
+	params    ScryptParams
+	nonce     []byte
+}
+
+func (h *header) serialize() ([]byte, error) {
+	return codec.NewEncoder(codec.TypeKeyStoreHeader).
+		WriteBytes([]byte(h.holder)).
+		WriteInt64(int64(h.epochID)).
+		WriteInt64(h.expiresAt).
+		WriteBytes(h.salt).
+		WriteInt64(int64(h.params.N)).
+		WriteInt64(int64(h.params.R)).
+		WriteInt64(int64(h.params.P)).
+		WriteBytes(h.nonce).
+		Bytes()
+}
+
+func deserializeHeader(data []byte) (*header, error) {
+	dec, err := codec.NewDecoder(data, codec.TypeKeyStoreHeader)
+	if err != nil {
+		return nil, err
+	}
+	h := &header{
+		holder:    string(dec.ReadBytes()),
+		epochID:   epoch.TypeID(dec.ReadInt64()),
+		expiresAt: dec.ReadInt64(),
+		salt:      dec.ReadBytes(),
+		params: ScryptParams{
+			N: int(dec.ReadInt64()),
+			R: int(dec.ReadInt64()),
+			P: int(dec.ReadInt64()),
+		},
+		nonce: dec.ReadBytes(),
+	}
+	if err = dec.Finish(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (s *Store) seal(holder string, secrets *EpochSecrets, passphrase []byte) ([]byte, error) {
+	h := &header{
+		holder:    holder,
+		epochID:   secrets.EpochID,
+		expiresAt: secrets.ExpiresAt,
+		salt:      make([]byte, saltLen),
+		params:    s.params,
+		nonce:     make([]byte, chacha20poly1305.NonceSizeX),
+	}
+	random.Bytes(h.salt, s.randStream)
+	random.Bytes(h.nonce, s.randStream)
+	headerBytes, err := h.serialize()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := secrets.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(passphrase, h)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := aead.Seal(nil, h.nonce, plaintext, headerBytes)
+	return codec.NewEncoder(codec.TypeKeyStoreEntry).
+		WriteBytes(headerBytes).
+		WriteBytes(ciphertext).
+		Bytes()
+}
+
+func parseEntry(entry []byte) (h *header, headerBytes, ciphertext []byte, err error) {
+	dec, err := codec.NewDecoder(entry, codec.TypeKeyStoreEntry)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	headerBytes = dec.ReadBytes()
+	ciphertext = dec.ReadBytes()
+	if err = dec.Finish(); err != nil {
+		return nil, nil, nil, err
+	}
+	if h, err = deserializeHeader(headerBytes); err != nil {
+		return nil, nil, nil, err
+	}
+	return h, headerBytes, ciphertext, nil
+}
+
+// open decrypts the entry and checks that it belongs to the holder and the epoch,
+// so that an entry copied under another file name is rejected.
+func open(holder string, epochID epoch.TypeID, entry, passphrase []byte) (*EpochSecrets, error) {
+	h, headerBytes, ciphertext, err := parseEntry(entry)
+	if err != nil {
+		return nil, err
+	}
+	if h.holder != holder || h.epochID != epochID {
+		return nil, fmt.Errorf("entry of %s in epoch %d found for %s in epoch %d", h.holder, h.epochID, holder, epochID)
+	}
+	if err = h.params.validate(); err != nil {
+		return nil, err
+	}
+	if len(h.nonce) != chacha20poly1305.NonceSizeX {
+		return nil, errors.New("invalid nonce length")
+	}
+	aead, err := newAEAD(passphrase, h)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, h.nonce, ciphertext, headerBytes)
+	if err != nil {
+		return nil, errors.New("wrong passphrase or corrupted entry")
+	}
+	secrets, err := DeserializeEpochSecrets(plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if secrets.EpochID != h.epochID || secrets.ExpiresAt != h.expiresAt {
+		return nil, errors.New("header does not match the secrets")
+	}
+	return secrets, nil
+}
+
+func newAEAD(passphrase []byte, h *header) (cipher.AEAD, error) {
+	key, err := scrypt.Key(passphrase, h.salt, h.params.N, h.params.R, h.params.P, keyLen)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.NewX(key)
+}
+
+// writeFileAtomic writes the data to a temporary file in the same directory and renames it,
+// so a crash never leaves a partially written entry.
+func writeFileAtomic(path string, data []byte) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+	if err = tmpFile.Chmod(0o600); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if _, err = tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+	return renameFile(tmpPath, path)
+}
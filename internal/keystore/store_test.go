@@ -0,0 +1,307 @@
+package keystore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/auti-project/auti/internal/crypto"
+	"github.com/auti-project/auti/internal/epoch"
+)
+
+// testParams keeps the key derivation cheap in the tests.
+var testParams = ScryptParams{N: 1 << 4, R: 8, P: 1}
+
+func newTestSecrets(t *testing.T, epochID epoch.TypeID, expiresAt int64) *EpochSecrets {
+	e, err := epoch.New(epochID, 0, 10)
+	if err != nil {
+		t.Fatalf("epoch.New() error = %v", err)
+	}
+	secrets := NewEpochSecrets(e, expiresAt)
+	secretKey, publicKey, err := crypto.KeyGen(crypto.RandomStream())
+	if err != nil {
+		t.Fatalf("KeyGen() error = %v", err)
+	}
+	secrets.SecretKeys["org1"] = secretKey
+	secrets.PublicKeys["org1"] = publicKey
+	secrets.TXSeeds[[2]string{"org1", "org2"}] = []byte("seed")
+	secrets.OrgEpochIDs["org1"] = []byte("org epoch id")
+	secrets.AuditorEpochIDs["aud1"] = []byte("aud epoch id")
+	secrets.Edges = [][2]string{{"org1", "org2"}}
+	return secrets
+}
+
+func openTestStore(t *testing.T, dir string, passphrase string) *Store {
+	store, err := OpenWithParams(dir, []byte(passphrase), testParams, crypto.RandomStream())
+	if err != nil {
+		t.Fatalf("OpenWithParams() error = %v", err)
+	}
+	return store
+}
+
+func TestStore_Get(t *testing.T) {
+	tests := []struct {
+		name       string
+		passphrase string
+		// prepare may tamper with the directory after the entry of aud1 in epoch 1 is written
+		prepare func(t *testing.T, dir string)
+		holder  string
+		epochID epoch.TypeID
+		wantErr bool
+	}{
+		{
+			name:       "test_round_trip",
+			passphrase: "passphrase",
+			holder:     "aud1",
+			epochID:    1,
+			wantErr:    false,
+		},
+		{
+			name:       "test_wrong_passphrase",
+			passphrase: "wrong",
+			holder:     "aud1",
+			epochID:    1,
+			wantErr:    true,
+		},
+		{
+			name:       "test_missing_entry",
+			passphrase: "passphrase",
+			holder:     "aud1",
+			epochID:    2,
+			wantErr:    true,
+		},
+		{
+			name:       "test_renamed_entry",
+			passphrase: "passphrase",
+			prepare: func(t *testing.T, dir string) {
+				if err := os.Rename(filepath.Join(dir, "aud1.1.key"), filepath.Join(dir, "aud2.1.key")); err != nil {
+					t.Fatalf("Rename() error = %v", err)
+				}
+			},
+			holder:  "aud2",
+			epochID: 1,
+			wantErr: true,
+		},
+		{
+			name:       "test_corrupted_entry",
+			passphrase: "passphrase",
+			prepare: func(t *testing.T, dir string) {
+				path := filepath.Join(dir, "aud1.1.key")
+				data, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("ReadFile() error = %v", err)
+				}
+				data[len(data)-1] ^= 1
+				if err = os.WriteFile(path, data, 0o600); err != nil {
+					t.Fatalf("WriteFile() error = %v", err)
+				}
+			},
+			holder:  "aud1",
+			epochID: 1,
+			wantErr: true,
+		},
+		{
+			name:       "test_invalid_holder",
+			passphrase: "passphrase",
+			holder:     "../aud1",
+			epochID:    1,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			secrets := newTestSecrets(t, 1, 100)
+			if err := openTestStore(t, dir, "passphrase").Put("aud1", secrets); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			if tt.prepare != nil {
+				tt.prepare(t, dir)
+			}
+			got, err := openTestStore(t, dir, tt.passphrase).Get(tt.holder, tt.epochID)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Get() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			want, _ := secrets.Serialize()
+			gotBytes, err := got.Serialize()
+			if err != nil || string(gotBytes) != string(want) {
+				t.Errorf("Get() = %+v, want %+v", got, secrets)
+			}
+		})
+	}
+}
+
+func TestStore_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	store := openTestStore(t, dir, "old")
+	for epochID := epoch.TypeID(0); epochID < 3; epochID++ {
+		if err := store.Put("com", newTestSecrets(t, epochID, 100)); err != nil {
+			t.Fatalf("Put() error = %v", err)
+		}
+	}
+	// a store opened with a wrong passphrase cannot rotate anything
+	if err := openTestStore(t, dir, "wrong").Rotate([]byte("new")); err == nil {
+		t.Errorf("Rotate() with wrong passphrase error = nil, wantErr true")
+	}
+	if err := store.Rotate([]byte("new")); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	for epochID := epoch.TypeID(0); epochID < 3; epochID++ {
+		if _, err := store.Get("com", epochID); err != nil {
+			t.Errorf("Get() after Rotate() error = %v", err)
+		}
+		if _, err := openTestStore(t, dir, "old").Get("com", epochID); err == nil {
+			t.Errorf("Get() with old passphrase error = nil, wantErr true")
+		}
+		if _, err := openTestStore(t, dir, "new").Get("com", epochID); err != nil {
+			t.Errorf("Get() with new passphrase error = %v", err)
+		}
+	}
+}
+
+func TestStore_RotateFailure(t *testing.T) {
+	// the rotation of 3 entries renames 3 staged entries, the journal, and then the 3 entries
+	tests := []struct {
+		name           string
+		failAt         int
+		wantPassphrase string
+	}{
+		{
+			name:           "test_fail_staging",
+			failAt:         2,
+			wantPassphrase: "old",
+		},
+		{
+			name:           "test_fail_journal",
+			failAt:         4,
+			wantPassphrase: "old",
+		},
+		{
+			name:           "test_fail_replacing",
+			failAt:         6,
+			wantPassphrase: "new",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			store := openTestStore(t, dir, "old")
+			for epochID := epoch.TypeID(0); epochID < 3; epochID++ {
+				if err := store.Put("com", newTestSecrets(t, epochID, 100)); err != nil {
+					t.Fatalf("Put() error = %v", err)
+				}
+			}
+			numRenames := 0
+			renameFile = func(oldPath, newPath string) error {
+				numRenames++
+				if numRenames == tt.failAt {
+					return errors.New("injected failure")
+				}
+				return os.Rename(oldPath, newPath)
+			}
+			err := store.Rotate([]byte("new"))
+			renameFile = os.Rename
+			if err == nil {
+				t.Fatalf("Rotate() error = nil, wantErr true")
+			}
+			// reopening the store removes the staged entries or completes the committed rotation
+			reopened := openTestStore(t, dir, tt.wantPassphrase)
+			for epochID := epoch.TypeID(0); epochID < 3; epochID++ {
+				if _, err = reopened.Get("com", epochID); err != nil {
+					t.Errorf("Get() with passphrase %q error = %v", tt.wantPassphrase, err)
+				}
+			}
+			files, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatalf("ReadDir() error = %v", err)
+			}
+			for _, file := range files {
+				if filepath.Ext(file.Name()) != fileExtension {
+					t.Errorf("file %s left after recovery", file.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestStore_Prune(t *testing.T) {
+	store := openTestStore(t, t.TempDir(), "passphrase")
+	expiries := []int64{10, 20, 30}
+	for idx, expiresAt := range expiries {
+		for _, holder := range []string{"com", "aud1"} {
+			if err := store.Put(holder, newTestSecrets(t, epoch.TypeID(idx), expiresAt)); err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+		}
+	}
+	numDeleted, err := store.Prune(20)
+	if err != nil || numDeleted != 4 {
+		t.Errorf("Prune() = %d, %v, want %d", numDeleted, err, 4)
+	}
+	for _, holder := range []string{"com", "aud1"} {
+		epochIDs, err := store.Epochs(holder)
+		if err != nil || len(epochIDs) != 1 || epochIDs[0] != 2 {
+			t.Errorf("Epochs(%s) = %v, %v, want [2]", holder, epochIDs, err)
+		}
+	}
+	if err = store.Delete("com", 2); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err = store.Delete("com", 2); err != nil {
+		t.Errorf("Delete() of a deleted entry error = %v", err)
+	}
+	if epochIDs, _ := store.Epochs("com"); len(epochIDs) != 0 {
+		t.Errorf("Epochs() after Delete() = %v, want []", epochIDs)
+	}
+}
+
+// version1Entry is the entry of com in epoch 1 written by the key store with the codec version 1,
+// the secrets are those of newTestSecrets expiring at 1000, sealed with "passphrase" and testParams.
+const version1Entry = "" +
+	"012100000071012200000003636f6d000000000000000100000000000003e800000020017b3358143f0c679e478cf5f2" +
+	"e2d6d934f99056dfd33b8ded928e606dc106250000000000000010000000000000000800000000000000010000001888" +
+	"dd01cf037979ba55189866bcbf927a4a638abc8556844f0000011aebffb32f17f2a8c72d7cf4c132f2f6bb1cc5b4e43e" +
+	"e0e86e89865e15d5ac3eac2bd8576b142d3af48159b704e5be9191f3bc560e8b234e5061ae0ff184f4943f8be540d790" +
+	"a4df17c432f2cae6aadd0bf475f95e3fa5fd96cc5c5f7f578993ca0224c21201c8ec5315a1ff3fc9336d6d2ff5bd4d47" +
+	"f327af34191b83efe67ddd8f3ff083024e5f2f955efd5e8fa747b8db3ab17e897a1cdf77b6a0f25928a06f552c67c1c6" +
+	"736337a57ab3b3d46c54a250c2937350ae98b8b2795b67491f4b9f11b4b9c31d4767193e80c2992ff9e6d4722711b8d6" +
+	"b25cc6b9ad566c0763da103d6b02bdf36385ab8a2590e22b69806bcda2a005a81be36cc15fef03b3af5226802b942450" +
+	"f75af1ebb00578a34c7d69bd1a054dbcbabb11bba3"
+
+func TestStore_GetVersion1Entry(t *testing.T) {
+	entry, err := hex.DecodeString(version1Entry)
+	if err != nil {
+		t.Fatalf("DecodeString() error = %v", err)
+	}
+	dir := t.TempDir()
+	if err = os.WriteFile(filepath.Join(dir, "com.1"+fileExtension), entry, 0o600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	store := openTestStore(t, dir, "passphrase")
+	secrets, err := store.Get("com", 1)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if secrets.EpochID != 1 || secrets.ExpiresAt != 1000 || len(secrets.SecretKeys) != 1 || len(secrets.PublicKeys) != 1 {
+		t.Errorf("Get() = %+v, want the secrets of epoch 1 expiring at 1000", secrets)
+	}
+	if !bytes.Equal(secrets.TXSeeds[[2]string{"org1", "org2"}], []byte("seed")) ||
+		!bytes.Equal(secrets.OrgEpochIDs["org1"], []byte("org epoch id")) ||
+		len(secrets.Edges) != 1 || secrets.Edges[0] != [2]string{"org1", "org2"} {
+		t.Errorf("Get() = %+v, want the secrets of newTestSecrets", secrets)
+	}
+	// the entry is rewritten in the current version by a rotation
+	if err = store.Rotate([]byte("new")); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if _, err = openTestStore(t, dir, "new").Get("com", 1); err != nil {
+		t.Errorf("Get() after Rotate() error = %v", err)
+	}
+}
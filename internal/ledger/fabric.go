@@ -0,0 +1,154 @@
+package ledger
+
+import (
+	"crypto/cipher"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/hyperledger/fabric-sdk-go/pkg/gateway"
+
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+const (
+	channelName           = "mychannel"
+	createTXFuncName      = "CreateTX"
+	createBatchTXFuncName = "CreateBatchTXs"
+	txExistsName          = "TXExists"
+	readTXFuncName        = "ReadTX"
+	readAllTXsByPageName  = "ReadAllTXsByPage"
+	initLedgerFuncName    = "InitLedger"
+	enrollSignerFuncName  = "EnrollSigner"
+	revokeSignerFuncName  = "RevokeSigner"
+)
+
+// Fabric is the ledger backed by a chaincode on a Hyperledger Fabric network,
+// the transactions are submitted in their canonical encodings and read back in the JSON form of the chaincode.
+type Fabric[T Transaction] struct {
+	gw *gateway.Gateway
+	ct *gateway.Contract
+}
+
+type pageResponse[T any] struct {
+	Bookmark string `json:"bookmark"`
+	TXs      []T    `json:"txs"`
+}
+
+// NewFabric takes over the connected gateway.
+func NewFabric[T Transaction](gw *gateway.Gateway, contractType string) (*Fabric[T], error) {
+	network, err := gw.GetNetwork(channelName)
+	if err != nil {
+		return nil, err
+	}
+	return &Fabric[T]{
+		gw: gw,
+		ct: network.GetContract(contractType),
+	}, nil
+}
+
+func (f *Fabric[T]) Close() {
+	f.gw.Close()
+}
+
+func (f *Fabric[T]) SubmitTX(tx T) (string, error) {
+	_, encoding, err := tx.KeyVal()
+	if err != nil {
+		return "", err
+	}
+	txID, err := f.ct.SubmitTransaction(createTXFuncName, hex.EncodeToString(encoding))
+	if err != nil {
+		return "", err
+	}
+	return string(txID), nil
+}
+
+// SubmitBatchTXs submits the JSON list of the hex encoded canonical encodings of the transactions.
+func (f *Fabric[T]) SubmitBatchTXs(txList []T) ([]string, error) {
+	encodingList := make([]string, len(txList))
+	for i, tx := range txList {
+		_, encoding, err := tx.KeyVal()
+		if err != nil {
+			return nil, err
+		}
+		encodingList[i] = hex.EncodeToString(encoding)
+	}
+	encodingListJSON, err := json.Marshal(encodingList)
+	if err != nil {
+		return nil, err
+	}
+	resBytes, err := f.ct.SubmitTransaction(createBatchTXFuncName, string(encodingListJSON))
+	if err != nil {
+		return nil, err
+	}
+	var txIDList []string
+	if err = json.Unmarshal(resBytes, &txIDList); err != nil {
+		return nil, err
+	}
+	return txIDList, nil
+}
+
+func (f *Fabric[T]) TXExists(txID string) (bool, error) {
+	resBytes, err := f.ct.EvaluateTransaction(txExistsName, txID)
+	if err != nil {
+		return false, err
+	}
+	var result bool
+	if err = json.Unmarshal(resBytes, &result); err != nil {
+		return false, err
+	}
+	return result, nil
+}
+
+func (f *Fabric[T]) ReadTX(txID string) (T, error) {
+	var tx T
+	result, err := f.ct.EvaluateTransaction(readTXFuncName, txID)
+	if err != nil {
+		return tx, err
+	}
+	err = json.Unmarshal(result, &tx)
+	return tx, err
+}
+
+func (f *Fabric[T]) ReadAllTXsByPage(bookmark string) ([]T, string, error) {
+	results, err := f.ct.EvaluateTransaction(readAllTXsByPageName, bookmark)
+	if err != nil {
+		return nil, "", err
+	}
+	var response pageResponse[T]
+	if err = json.Unmarshal(results, &response); err != nil {
+		return nil, "", err
+	}
+	return response.TXs, response.Bookmark, nil
+}
+
+// EnrollSigners pins the public key of the registrar on the chaincode, unless it is already pinned,
+// and enrolls the hex encoded signers with the signatures of the registrar.
+func (f *Fabric[T]) EnrollSigners(registrarKey crypto.TypePrivateKey, rand cipher.Stream, signers ...string) error {
+	registrar, err := crypto.SignerHex(registrarKey)
+	if err != nil {
+		return err
+	}
+	if _, err = f.ct.SubmitTransaction(initLedgerFuncName, registrar); err != nil {
+		return err
+	}
+	for _, signer := range signers {
+		signature, err := crypto.SignHex(registrarKey, EnrollmentMessage(signer), rand)
+		if err != nil {
+			return err
+		}
+		if _, err = f.ct.SubmitTransaction(enrollSignerFuncName, signer, signature); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RevokeSigner revokes the hex encoded signer with the signature of the registrar.
+func (f *Fabric[T]) RevokeSigner(registrarKey crypto.TypePrivateKey, rand cipher.Stream, signer string) error {
+	signature, err := crypto.SignHex(registrarKey, RevocationMessage(signer), rand)
+	if err != nil {
+		return err
+	}
+	_, err = f.ct.SubmitTransaction(revokeSignerFuncName, signer, signature)
+	return err
+}
@@ -0,0 +1,65 @@
+package ledger
+
+import (
+	"encoding"
+	"errors"
+
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+const (
+	// enrollmentTag and revocationTag are the tags of the messages the registrar signs,
+	// they must match the chaincode.
+	enrollmentTag = "auti-signer-enrollment"
+	revocationTag = "auti-signer-revocation"
+)
+
+// PageSize is the number of transactions per page of ReadAllTXsByPage, the same as the chaincode.
+const PageSize = 10000
+
+// Ledger is the client of one of the chains, i.e., the local chains, the organization chain and the auditor chain.
+// The transaction IDs are the keys of the transactions in the world state.
+type Ledger[T any] interface {
+	SubmitTX(tx T) (string, error)
+	SubmitBatchTXs(txList []T) ([]string, error)
+	TXExists(txID string) (bool, error)
+	ReadTX(txID string) (T, error)
+	// ReadAllTXsByPage returns one page of transactions starting from the bookmark,
+	// and the bookmark of the next page, which is empty after the last page.
+	ReadAllTXsByPage(bookmark string) ([]T, string, error)
+	Close()
+}
+
+// Transaction is implemented by the on-chain transactions, KeyVal returns the key of the transaction
+// in the world state and its canonical binary encoding, which is submitted to the chaincode and stored,
+// the same way as the chaincode.
+type Transaction interface {
+	KeyVal() (string, []byte, error)
+	encoding.BinaryUnmarshaler
+}
+
+// VerifySignature checks the signature of a transaction against its signer field, like the chaincode does.
+func VerifySignature(signer, signature string, msg []byte) error {
+	if signer == "" || signature == "" {
+		return errors.New("the transaction is not signed")
+	}
+	ok, err := crypto.VerifySignatureHex(signer, signature, msg)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("invalid transaction signature")
+	}
+	return nil
+}
+
+// EnrollmentMessage is the message the registrar pinned on the chaincode signs to enroll the hex encoded signer,
+// the chaincode accepts the transactions of the enrolled signers only.
+func EnrollmentMessage(signer string) []byte {
+	return crypto.SigningMessage(enrollmentTag, signer)
+}
+
+// RevocationMessage is the message the registrar signs to revoke the signer.
+func RevocationMessage(signer string) []byte {
+	return crypto.SigningMessage(revocationTag, signer)
+}
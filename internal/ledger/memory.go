@@ -0,0 +1,128 @@
+package ledger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Memory is an in-memory world state with the semantics of the chaincode, it is safe for concurrent use.
+// The transactions are stored in their canonical encodings, so the transactions read back do not alias
+// the submitted ones. T is the pointer to the transaction type E.
+type Memory[E any, T transactionPointer[E]] struct {
+	mu       sync.RWMutex
+	state    map[string][]byte
+	keys     []string
+	verify   func(tx T) error
+	pageSize int
+}
+
+// transactionPointer is the constraint of the pointers to the transaction types.
+type transactionPointer[E any] interface {
+	*E
+	Transaction
+}
+
+// NewMemory creates an empty ledger, verify is called on every submitted transaction and may be nil.
+func NewMemory[E any, T transactionPointer[E]](verify func(tx T) error) *Memory[E, T] {
+	return &Memory[E, T]{
+		state:    make(map[string][]byte),
+		verify:   verify,
+		pageSize: PageSize,
+	}
+}
+
+func (m *Memory[E, T]) Close() {}
+
+func (m *Memory[E, T]) SubmitTX(tx T) (string, error) {
+	txIDList, err := m.SubmitBatchTXs([]T{tx})
+	if err != nil {
+		return "", err
+	}
+	return txIDList[0], nil
+}
+
+// SubmitBatchTXs stores either all the transactions or none of them.
+func (m *Memory[E, T]) SubmitBatchTXs(txList []T) ([]string, error) {
+	keys := make([]string, len(txList))
+	vals := make([][]byte, len(txList))
+	for i, tx := range txList {
+		if m.verify != nil {
+			if err := m.verify(tx); err != nil {
+				return nil, err
+			}
+		}
+		var err error
+		if keys[i], vals[i], err = tx.KeyVal(); err != nil {
+			return nil, err
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	batchKeys := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		if _, ok := m.state[key]; ok || batchKeys[key] {
+			return nil, fmt.Errorf("the transaction %s already exists", key)
+		}
+		batchKeys[key] = true
+	}
+	for i, key := range keys {
+		m.state[key] = vals[i]
+		idx := sort.SearchStrings(m.keys, key)
+		m.keys = append(m.keys, "")
+		copy(m.keys[idx+1:], m.keys[idx:])
+		m.keys[idx] = key
+	}
+	return keys, nil
+}
+
+func (m *Memory[E, T]) TXExists(txID string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	_, ok := m.state[txID]
+	return ok, nil
+}
+
+func (m *Memory[E, T]) ReadTX(txID string) (T, error) {
+	var tx T
+	m.mu.RLock()
+	val, ok := m.state[txID]
+	m.mu.RUnlock()
+	if !ok {
+		return tx, fmt.Errorf("the transaction %s does not exist", txID)
+	}
+	return m.decode(val)
+}
+
+// ReadAllTXsByPage iterates the transactions in the lexical order of their keys like a range query of Fabric,
+// the bookmark is the key the next page starts from.
+func (m *Memory[E, T]) ReadAllTXsByPage(bookmark string) ([]T, string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	start := sort.SearchStrings(m.keys, bookmark)
+	end := start + m.pageSize
+	if end > len(m.keys) {
+		end = len(m.keys)
+	}
+	txList := make([]T, 0, end-start)
+	for _, key := range m.keys[start:end] {
+		tx, err := m.decode(m.state[key])
+		if err != nil {
+			return nil, "", err
+		}
+		txList = append(txList, tx)
+	}
+	var nextBookmark string
+	if end < len(m.keys) {
+		nextBookmark = m.keys[end]
+	}
+	return txList, nextBookmark, nil
+}
+
+func (m *Memory[E, T]) decode(val []byte) (T, error) {
+	tx := T(new(E))
+	if err := tx.UnmarshalBinary(val); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
@@ -0,0 +1,167 @@
+package ledger
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/auti-project/auti/internal/clolc/transaction"
+	"github.com/auti-project/auti/internal/crypto"
+)
+
+func verifyOrgOnChain(tx *transaction.OrgOnChain) error {
+	return VerifySignature(tx.Signer, tx.Signature, tx.SigningMessage())
+}
+
+func signedOrgOnChainTXs(numTXs int) ([]*transaction.OrgOnChain, error) {
+	signingKey, _ := crypto.SigningKeyGen(crypto.RandomStream())
+	txList := make([]*transaction.OrgOnChain, numTXs)
+	for i := range txList {
+		txList[i] = transaction.NewOrgOnChain(fmt.Sprintf("%064x", i))
+		if err := txList[i].Sign(signingKey, crypto.RandomStream()); err != nil {
+			return nil, err
+		}
+	}
+	return txList, nil
+}
+
+func TestMemory_SubmitBatchTXs(t *testing.T) {
+	txList, err := signedOrgOnChainTXs(3)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	unsignedTX := transaction.NewOrgOnChain("unsigned")
+	forgedTX := *txList[2]
+	forgedTX.Accumulator = "forged"
+	tests := []struct {
+		name    string
+		txList  []*transaction.OrgOnChain
+		wantErr bool
+	}{
+		{
+			name:    "test_valid",
+			txList:  txList[:2],
+			wantErr: false,
+		},
+		{
+			name:    "test_existing",
+			txList:  txList[1:],
+			wantErr: true,
+		},
+		{
+			name:    "test_duplicated_in_batch",
+			txList:  []*transaction.OrgOnChain{txList[2], txList[2]},
+			wantErr: true,
+		},
+		{
+			name:    "test_unsigned",
+			txList:  []*transaction.OrgOnChain{unsignedTX},
+			wantErr: true,
+		},
+		{
+			name:    "test_forged",
+			txList:  []*transaction.OrgOnChain{&forgedTX},
+			wantErr: true,
+		},
+	}
+	memory := NewMemory(verifyOrgOnChain)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txIDList, err := memory.SubmitBatchTXs(tt.txList)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SubmitBatchTXs() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			for _, txID := range txIDList {
+				tx, err := memory.ReadTX(txID)
+				if err != nil {
+					t.Errorf("ReadTX() error = %v", err)
+					return
+				}
+				if key, _, _ := tx.KeyVal(); key != txID {
+					t.Errorf("ReadTX() key = %s, want %s", key, txID)
+				}
+			}
+		})
+	}
+	// the failed batches are not partially stored
+	key, _, _ := txList[2].KeyVal()
+	if exists, _ := memory.TXExists(key); exists {
+		t.Errorf("TXExists() = %v, want %v", exists, false)
+	}
+}
+
+func TestMemory_ReadAllTXsByPage(t *testing.T) {
+	tests := []struct {
+		name      string
+		numTXs    int
+		pageSize  int
+		wantPages int
+	}{
+		{
+			name:      "test_empty",
+			numTXs:    0,
+			pageSize:  4,
+			wantPages: 1,
+		},
+		{
+			name:      "test_one_page",
+			numTXs:    3,
+			pageSize:  4,
+			wantPages: 1,
+		},
+		{
+			name:      "test_full_pages",
+			numTXs:    8,
+			pageSize:  4,
+			wantPages: 2,
+		},
+		{
+			name:      "test_partial_page",
+			numTXs:    9,
+			pageSize:  4,
+			wantPages: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txList, err := signedOrgOnChainTXs(tt.numTXs)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			memory := NewMemory(verifyOrgOnChain)
+			memory.pageSize = tt.pageSize
+			if _, err = memory.SubmitBatchTXs(txList); err != nil {
+				t.Fatalf("SubmitBatchTXs() error = %v", err)
+			}
+			var (
+				bookmark string
+				lastKey  string
+				numPages int
+				numTXs   int
+			)
+			for {
+				var pageTXList []*transaction.OrgOnChain
+				pageTXList, bookmark, err = memory.ReadAllTXsByPage(bookmark)
+				if err != nil {
+					t.Fatalf("ReadAllTXsByPage() error = %v", err)
+				}
+				numPages++
+				numTXs += len(pageTXList)
+				for _, tx := range pageTXList {
+					key, _, _ := tx.KeyVal()
+					if key <= lastKey {
+						t.Errorf("ReadAllTXsByPage() key %s is not after %s", key, lastKey)
+					}
+					lastKey = key
+				}
+				if bookmark == "" {
+					break
+				}
+			}
+			if numPages != tt.wantPages || numTXs != tt.numTXs {
+				t.Errorf("ReadAllTXsByPage() pages = %d, txs = %d, want %d, %d",
+					numPages, numTXs, tt.wantPages, tt.numTXs)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Currency is an ISO 4217 currency, Scale is the number of decimal digits of its minor unit.
+type Currency struct {
+	Code  string
+	Scale int
+}
+
+// currencies are the active currencies of the ISO 4217 list one, including the fund codes, with their minor units.
+// The precious metals and the other codes without a minor unit are left out since amounts are kept in minor units.
+var currencies = map[string]int{
+	"AED": 2, "AFN": 2, "ALL": 2, "AMD": 2, "AOA": 2, "ARS": 2, "AUD": 2, "AWG": 2, "AZN": 2, "BAM": 2,
+	"BBD": 2, "BDT": 2, "BGN": 2, "BHD": 3, "BIF": 0, "BMD": 2, "BND": 2, "BOB": 2, "BOV": 2, "BRL": 2,
+	"BSD": 2, "BTN": 2, "BWP": 2, "BYN": 2, "BZD": 2, "CAD": 2, "CDF": 2, "CHE": 2, "CHF": 2, "CHW": 2,
+	"CLF": 4, "CLP": 0, "CNY": 2, "COP": 2, "COU": 2, "CRC": 2, "CUP": 2, "CVE": 2, "CZK": 2, "DJF": 0,
+	"DKK": 2, "DOP": 2, "DZD": 2, "EGP": 2, "ERN": 2, "ETB": 2, "EUR": 2, "FJD": 2, "FKP": 2, "GBP": 2,
+	"GEL": 2, "GHS": 2, "GIP": 2, "GMD": 2, "GNF": 0, "GTQ": 2, "GYD": 2, "HKD": 2, "HNL": 2, "HTG": 2,
+	"HUF": 2, "IDR": 2, "ILS": 2, "INR": 2, "IQD": 3, "IRR": 2, "ISK": 0, "JMD": 2, "JOD": 3, "JPY": 0,
+	"KES": 2, "KGS": 2, "KHR": 2, "KMF": 0, "KPW": 2, "KRW": 0, "KWD": 3, "KYD": 2, "KZT": 2, "LAK": 2,
+	"LBP": 2, "LKR": 2, "LRD": 2, "LSL": 2, "LYD": 3, "MAD": 2, "MDL": 2, "MGA": 2, "MKD": 2, "MMK": 2,
+	"MNT": 2, "MOP": 2, "MRU": 2, "MUR": 2, "MVR": 2, "MWK": 2, "MXN": 2, "MXV": 2, "MYR": 2, "MZN": 2,
+	"NAD": 2, "NGN": 2, "NIO": 2, "NOK": 2, "NPR": 2, "NZD": 2, "OMR": 3, "PAB": 2, "PEN": 2, "PGK": 2,
+	"PHP": 2, "PKR": 2, "PLN": 2, "PYG": 0, "QAR": 2, "RON": 2, "RSD": 2, "RUB": 2, "RWF": 0, "SAR": 2,
+	"SBD": 2, "SCR": 2, "SDG": 2, "SEK": 2, "SGD": 2, "SHP": 2, "SLE": 2, "SOS": 2, "SRD": 2, "SSP": 2,
+	"STN": 2, "SVC": 2, "SYP": 2, "SZL": 2, "THB": 2, "TJS": 2, "TMT": 2, "TND": 3, "TOP": 2, "TRY": 2,
+	"TTD": 2, "TWD": 2, "TZS": 2, "UAH": 2, "UGX": 0, "USD": 2, "USN": 2, "UYI": 0, "UYU": 2, "UYW": 4,
+	"UZS": 2, "VED": 2, "VES": 2, "VND": 0, "VUV": 0, "WST": 2, "XAF": 0, "XCD": 2, "XCG": 2, "XOF": 0,
+	"XPF": 0, "YER": 2, "ZAR": 2, "ZMW": 2, "ZWG": 2,
+}
+
+// LookupCurrency returns the currency of the ISO 4217 code.
+func LookupCurrency(code string) (Currency, error) {
+	scale, ok := currencies[code]
+	if !ok {
+		return Currency{}, fmt.Errorf("unknown currency %q", code)
+	}
+	return Currency{Code: code, Scale: scale}, nil
+}
+
+// Amount is an exact amount of a currency as an integer number of its minor units.
+// The magnitude is at most math.MaxInt64 minor units, so negating an amount never overflows.
+type Amount struct {
+	minorUnits int64
+	currency   Currency
+}
+
+// New creates an amount of the given number of minor units of the currency.
+func New(minorUnits int64, code string) (Amount, error) {
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return Amount{}, err
+	}
+	if minorUnits == math.MinInt64 {
+		return Amount{}, errors.New("amount overflows")
+	}
+	return Amount{minorUnits: minorUnits, currency: currency}, nil
+}
+
+// Parse reads the decimal string of an amount of the currency, e.g., "-100.25" for USD.
+// The string has an optional sign, the integer digits, and at most the scale of the currency fractional digits,
+// amounts not representable exactly in minor units are rejected rather than rounded.
+func Parse(value, code string) (Amount, error) {
+	currency, err := LookupCurrency(code)
+	if err != nil {
+		return Amount{}, err
+	}
+	digits := value
+	negative := false
+	if len(digits) > 0 && (digits[0] == '-' || digits[0] == '+') {
+		negative = digits[0] == '-'
+		digits = digits[1:]
+	}
+	intPart, fracPart, hasPoint := strings.Cut(digits, ".")
+	if intPart == "" || (hasPoint && fracPart == "") {
+		return Amount{}, fmt.Errorf("invalid amount %q", value)
+	}
+	if len(fracPart) > currency.Scale {
+		return Amount{}, fmt.Errorf("amount %q has more than %d decimals of %s", value, currency.Scale, code)
+	}
+	// pad the fraction to the scale, the amount is then the digits read as minor units
+	fracPart += strings.Repeat("0", currency.Scale-len(fracPart))
+	var minorUnits int64
+	for _, c := range intPart + fracPart {
+		if c < '0' || c > '9' {
+			return Amount{}, fmt.Errorf("invalid amount %q", value)
+		}
+		digit := int64(c - '0')
+		if minorUnits > (math.MaxInt64-digit)/10 {
+			return Amount{}, fmt.Errorf("amount %q overflows", value)
+		}
+		minorUnits = minorUnits*10 + digit
+	}
+	if negative {
+		minorUnits = -minorUnits
+	}
+	return Amount{minorUnits: minorUnits, currency: currency}, nil
+}
+
+// MustParse is like Parse but panics if the amount is invalid, for amounts known at compile time.
+func MustParse(value, code string) Amount {
+	a, err := Parse(value, code)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// MinorUnits returns the amount as an integer number of minor units, e.g., cents.
+func (a Amount) MinorUnits() int64 {
+	return a.minorUnits
+}
+
+func (a Amount) Currency() Currency {
+	return a.currency
+}
+
+// Neg returns the amount with the opposite sign.
+func (a Amount) Neg() Amount {
+	return Amount{minorUnits: -a.minorUnits, currency: a.currency}
+}
+
+// Add returns the sum of two amounts of the same currency.
+func (a Amount) Add(b Amount) (Amount, error) {
+	if a.currency != b.currency {
+		return Amount{}, fmt.Errorf("add %s to %s", b.currency.Code, a.currency.Code)
+	}
+	if (b.minorUnits > 0 && a.minorUnits > math.MaxInt64-b.minorUnits) ||
+		(b.minorUnits < 0 && a.minorUnits < -math.MaxInt64-b.minorUnits) {
+		return Amount{}, errors.New("amount overflows")
+	}
+	return Amount{minorUnits: a.minorUnits + b.minorUnits, currency: a.currency}, nil
+}
+
+// String returns the decimal string of the amount followed by the currency code, e.g., "-100.25 USD".
+func (a Amount) String() string {
+	sign := ""
+	magnitude := uint64(a.minorUnits)
+	if a.minorUnits < 0 {
+		sign = "-"
+		magnitude = uint64(-a.minorUnits)
+	}
+	digits := fmt.Sprintf("%0*d", a.currency.Scale+1, magnitude)
+	if a.currency.Scale > 0 {
+		split := len(digits) - a.currency.Scale
+		digits = digits[:split] + "." + digits[split:]
+	}
+	return sign + digits + " " + a.currency.Code
+}
@@ -0,0 +1,219 @@
+package money
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		code    string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name:  "test_cents",
+			value: "0.29",
+			code:  "USD",
+			want:  29,
+		},
+		{
+			name:  "test_short_fraction",
+			value: "100.5",
+			code:  "EUR",
+			want:  10050,
+		},
+		{
+			name:  "test_negative",
+			value: "-100.25",
+			code:  "USD",
+			want:  -10025,
+		},
+		{
+			name:  "test_zero_decimals",
+			value: "1500",
+			code:  "JPY",
+			want:  1500,
+		},
+		{
+			name:  "test_three_decimals",
+			value: "+1.234",
+			code:  "KWD",
+			want:  1234,
+		},
+		{
+			name:  "test_max",
+			value: "92233720368547758.07",
+			code:  "USD",
+			want:  math.MaxInt64,
+		},
+		{
+			name:  "test_min",
+			value: "-92233720368547758.07",
+			code:  "USD",
+			want:  -math.MaxInt64,
+		},
+		{
+			name:    "test_overflow",
+			value:   "92233720368547758.08",
+			code:    "USD",
+			wantErr: true,
+		},
+		{
+			name:    "test_excess_precision",
+			value:   "1.5",
+			code:    "JPY",
+			wantErr: true,
+		},
+		{
+			name:  "test_argentine_peso",
+			value: "1500.75",
+			code:  "ARS",
+			want:  150075,
+		},
+		{
+			name:    "test_withdrawn_currency",
+			value:   "1",
+			code:    "HRK",
+			wantErr: true,
+		},
+		{
+			name:    "test_unknown_currency",
+			value:   "1",
+			code:    "XYZ",
+			wantErr: true,
+		},
+		{
+			name:    "test_exponent",
+			value:   "1e3",
+			code:    "USD",
+			wantErr: true,
+		},
+		{
+			name:    "test_missing_integer_part",
+			value:   ".5",
+			code:    "USD",
+			wantErr: true,
+		},
+		{
+			name:    "test_trailing_point",
+			value:   "5.",
+			code:    "USD",
+			wantErr: true,
+		},
+		{
+			name:    "test_sign_only",
+			value:   "-",
+			code:    "USD",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.value, tt.code)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.MinorUnits() != tt.want {
+				t.Errorf("Parse() got = %d, want %d", got.MinorUnits(), tt.want)
+			}
+		})
+	}
+}
+
+func TestAmount_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		code  string
+		want  string
+	}{
+		{
+			name:  "test_cents",
+			value: "0.05",
+			code:  "USD",
+			want:  "0.05 USD",
+		},
+		{
+			name:  "test_negative",
+			value: "-100.5",
+			code:  "EUR",
+			want:  "-100.50 EUR",
+		},
+		{
+			name:  "test_zero_decimals",
+			value: "1500",
+			code:  "JPY",
+			want:  "1500 JPY",
+		},
+		{
+			name:  "test_min",
+			value: "-92233720368547758.07",
+			code:  "USD",
+			want:  "-92233720368547758.07 USD",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MustParse(tt.value, tt.code).String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAmount_Add(t *testing.T) {
+	tests := []struct {
+		name    string
+		a       Amount
+		b       Amount
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "test_same_currency",
+			a:    MustParse("0.1", "USD"),
+			b:    MustParse("0.2", "USD"),
+			want: 30,
+		},
+		{
+			name: "test_negated",
+			a:    MustParse("92233720368547758.07", "USD"),
+			b:    MustParse("92233720368547758.07", "USD").Neg(),
+			want: 0,
+		},
+		{
+			name:    "test_different_currencies",
+			a:       MustParse("1", "USD"),
+			b:       MustParse("1", "EUR"),
+			wantErr: true,
+		},
+		{
+			name:    "test_overflow",
+			a:       MustParse("92233720368547758.07", "USD"),
+			b:       MustParse("0.01", "USD"),
+			wantErr: true,
+		},
+		{
+			name:    "test_underflow",
+			a:       MustParse("-92233720368547758.07", "USD"),
+			b:       MustParse("-0.01", "USD"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.a.Add(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Add() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got.MinorUnits() != tt.want {
+				t.Errorf("Add() got = %d, want %d", got.MinorUnits(), tt.want)
+			}
+		})
+	}
+}